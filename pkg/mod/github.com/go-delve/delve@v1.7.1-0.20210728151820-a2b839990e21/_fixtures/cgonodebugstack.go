@@ -0,0 +1,19 @@
+package main
+
+// #cgo CFLAGS: -g0 -O0
+/*
+#include <stddef.h>
+
+void nodebug_sigsegv(int x) {
+	int *p = NULL;
+	*p = x;
+}
+void nodebug_testfn(int x) {
+	nodebug_sigsegv(x);
+}
+*/
+import "C"
+
+func main() {
+	C.nodebug_testfn(C.int(10))
+}
@@ -0,0 +1,13 @@
+package main
+
+import "fmt"
+
+func helper(x int) int {
+	return x + 1
+}
+
+func main() {
+	fmt.Println("hello")
+	x := helper(1)
+	fmt.Println(x)
+}
@@ -80,8 +80,28 @@ type Config struct {
 	// DebugFileDirectories is the list of directories Delve will use
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
+
+	// StepSkipPackages is a list of package path patterns that 'step'
+	// should not stop in, patterns ending in "/..." also match all the
+	// packages below them.
+	StepSkipPackages []string `yaml:"step-skip-packages"`
+	// StepSkipFiles is a list of file path patterns that 'step' should not
+	// stop in, "**" matches any number of path elements and relative
+	// patterns can match at any depth.
+	StepSkipFiles []string `yaml:"step-skip-files"`
+	// If StepMainModuleOnly is true 'step' will only stop in code that
+	// belongs to the main module.
+	StepMainModuleOnly bool `yaml:"step-main-module-only"`
+
+	// If SessionAutoload is true the terminal will load the session file
+	// SessionFile from the current directory, if it exists, when it starts.
+	SessionAutoload bool `yaml:"session-autoload"`
 }
 
+// SessionFile is the name of the per-project session file loaded from the
+// current directory when SessionAutoload is set.
+const SessionFile = ".dlv-session.yml"
+
 func (c *Config) GetSourceListLineCount() int {
 	n := 5 // default value
 	lcp := c.SourceListLineCount
@@ -274,6 +294,17 @@ substitute-path:
 
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
+
+# Uncomment the following lines to stop the step command from stepping into
+# the runtime, the standard library, vendored code or anything outside of the
+# main module.
+# step-skip-packages: ["runtime", "runtime/...", "internal/...", "fmt"]
+# step-skip-files: ["vendor/**"]
+# step-main-module-only: true
+
+# Uncomment the following line to load the session saved with "session save .dlv-session.yml"
+# from the current directory every time the terminal starts.
+# session-autoload: true
 `)
 	return err
 }
@@ -61,6 +61,11 @@ type BinaryInfo struct {
 	// SymNames maps addr to a description *elf.Symbol of this addr.
 	SymNames map[uint64]*elf.Symbol
 
+	// symbols is a list of all function symbols found in the ELF symbol
+	// tables of the loaded images, sorted by entry point. It is used to
+	// name frames of functions that have no debug_info entry.
+	symbols []elfSymbol
+
 	// Images is a list of loaded shared libraries (also known as
 	// shared objects on linux or DLLs on windows).
 	Images []*Image
@@ -289,6 +294,14 @@ type compileUnit struct {
 	image *Image // parent image of this compilation unit.
 }
 
+// elfSymbol is a function symbol read from the symbol table of an ELF
+// file, relocated to the address it is loaded at.
+type elfSymbol struct {
+	name       string
+	entry, end uint64
+	image      *Image
+}
+
 type fileLine struct {
 	file string
 	line int
@@ -637,9 +650,30 @@ func (bi *BinaryInfo) PCToInlineFunc(pc uint64) *Function {
 // PCToImage returns the image containing the given PC address.
 func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	fn := bi.PCToFunc(pc)
+	if fn == nil {
+		if sym := bi.pcToSymbol(pc); sym != nil {
+			return sym.image
+		}
+	}
 	return bi.funcToImage(fn)
 }
 
+// pcToSymbol returns the ELF function symbol containing the given PC
+// address, or nil if there isn't one.
+func (bi *BinaryInfo) pcToSymbol(pc uint64) *elfSymbol {
+	i := sort.Search(len(bi.symbols), func(i int) bool {
+		return bi.symbols[i].entry > pc
+	})
+	if i == 0 {
+		return nil
+	}
+	sym := &bi.symbols[i-1]
+	if pc >= sym.end {
+		return nil
+	}
+	return sym
+}
+
 // Image represents a loaded library file (shared object on linux, DLL on windows).
 type Image struct {
 	Path       string
@@ -1258,7 +1292,17 @@ func (bi *BinaryInfo) loadSymbolName(image *Image, file *elf.File, wg *sync.Wait
 				bi.SymNames[symSec.Value+image.StaticBase] = &s
 			}
 		}
+	} else {
+		// Stripped shared libraries usually still have a dynamic symbol table,
+		// use it to name the frames of their functions.
+		symSecs, _ = file.DynamicSymbols()
+	}
+	for _, symSec := range symSecs {
+		if elf.ST_TYPE(symSec.Info) == elf.STT_FUNC && symSec.Value != 0 && symSec.Size != 0 {
+			bi.symbols = append(bi.symbols, elfSymbol{name: symSec.Name, entry: symSec.Value + image.StaticBase, end: symSec.Value + symSec.Size + image.StaticBase, image: image})
+		}
 	}
+	sort.Slice(bi.symbols, func(i, j int) bool { return bi.symbols[i].entry < bi.symbols[j].entry })
 }
 
 func (bi *BinaryInfo) parseDebugFrameElf(image *Image, exe *elf.File, debugInfoBytes []byte, wg *sync.WaitGroup) {
@@ -54,6 +54,11 @@ type EvalScope struct {
 	// will have one assigned by looking at their position in the argument
 	// list.
 	trustArgOrder bool
+
+	// iterVars contains the variables bound by the filter, count and find
+	// builtins while they evaluate their condition on each element, they
+	// shadow local and global variables with the same name.
+	iterVars map[string]*Variable
 }
 
 // ConvertEvalScope returns a new EvalScope in the context of the
@@ -957,6 +962,23 @@ func (scope *EvalScope) evalBuiltinCall(node *ast.CallExpr) (*Variable, error) {
 		return callBuiltinWithArgs(realBuiltin)
 	}
 
+	// The following builtins are not part of the Go language, a variable or
+	// function of the target program with the same name takes precedence.
+	if scope.isTargetSymbol(fnnode.Name) {
+		return nil, nil
+	}
+
+	switch fnnode.Name {
+	case "filter":
+		return callBuiltinWithArgs(scope.filterBuiltin)
+	case "count":
+		return callBuiltinWithArgs(scope.countBuiltin)
+	case "find":
+		return callBuiltinWithArgs(scope.findBuiltin)
+	case "map_keys":
+		return callBuiltinWithArgs(scope.mapKeysBuiltin)
+	}
+
 	return nil, nil
 }
 
@@ -1122,6 +1144,212 @@ func realBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
 	return newConstant(constant.Real(arg.Value), arg.mem), nil
 }
 
+// isTargetSymbol returns true if name is the name of a local variable or of
+// a package variable or function of the current package.
+func (scope *EvalScope) isTargetSymbol(name string) bool {
+	if vars, err := scope.Locals(); err == nil {
+		for i := range vars {
+			if vars[i].Name == name && vars[i].Flags&VariableShadowed == 0 {
+				return true
+			}
+		}
+	}
+	if scope.Fn != nil {
+		if _, err := scope.findGlobal(scope.Fn.PackageName(), name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// iterCondVarName is the name the condition argument of the filter, count
+// and find builtins uses to refer to the current element.
+const iterCondVarName = "x"
+
+// filterBuiltin implements filter(s, cond), it returns a new slice
+// containing the elements of the array or slice s for which the boolean
+// expression cond is true.
+func (scope *EvalScope) filterBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	var elems []*Variable
+	err := scope.iterCond("filter", args, nodeargs, func(elem *Variable) bool {
+		elems = append(elems, elem)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scope.newFakeSlice(args[0].fieldType, elems)
+}
+
+// countBuiltin implements count(s, cond), it returns the number of elements
+// of the array or slice s for which the boolean expression cond is true.
+func (scope *EvalScope) countBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	n := int64(0)
+	err := scope.iterCond("count", args, nodeargs, func(*Variable) bool {
+		n++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newConstant(constant.MakeInt64(n), scope.Mem), nil
+}
+
+// findBuiltin implements find(s, cond), it returns the first element of the
+// array or slice s for which the boolean expression cond is true.
+func (scope *EvalScope) findBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	var r *Variable
+	err := scope.iterCond("find", args, nodeargs, func(elem *Variable) bool {
+		r = elem
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf("no element of %s satisfies %s", exprToString(nodeargs[0]), exprToString(nodeargs[1]))
+	}
+	return r, nil
+}
+
+// iterCond evaluates the condition args[1] on every element of the array or
+// slice args[0], calling fn on the elements that satisfy it until fn
+// returns false.
+// The condition is a string constant containing a boolean expression that
+// refers to the element as x, for example filter(s, "x.Active").
+func (scope *EvalScope) iterCond(name string, args []*Variable, nodeargs []ast.Expr, fn func(*Variable) bool) error {
+	if len(args) != 2 {
+		return fmt.Errorf("wrong number of arguments to %s: %d", name, len(args))
+	}
+
+	s := args[0].maybeDereference()
+	if s.Unreadable != nil {
+		return s.Unreadable
+	}
+	if s.Kind != reflect.Array && s.Kind != reflect.Slice {
+		return fmt.Errorf("invalid argument %s (type %s) for %s", exprToString(nodeargs[0]), args[0].TypeString(), name)
+	}
+
+	condv := args[1]
+	condv.loadValue(loadFullValue)
+	if condv.Unreadable != nil {
+		return condv.Unreadable
+	}
+	if condv.Kind != reflect.String || condv.Value == nil {
+		return fmt.Errorf("invalid argument %s (type %s) for %s, must be a string constant", exprToString(nodeargs[1]), condv.TypeString(), name)
+	}
+	cond, err := parser.ParseExpr(constant.StringVal(condv.Value))
+	if err != nil {
+		return fmt.Errorf("could not parse condition of %s: %v", name, err)
+	}
+
+	oldIterVars := scope.iterVars
+	defer func() {
+		scope.iterVars = oldIterVars
+	}()
+	scope.iterVars = make(map[string]*Variable, len(oldIterVars)+1)
+	for k, v := range oldIterVars {
+		scope.iterVars[k] = v
+	}
+
+	for i := int64(0); i < s.Len; i++ {
+		elem, err := s.sliceAccess(int(i))
+		if err != nil {
+			return err
+		}
+		elem.Name = fmt.Sprintf("%s[%d]", exprToString(nodeargs[0]), i)
+		// the condition gets its own copy of the element, so that loading it
+		// doesn't change the variable we pass to fn.
+		x := *elem
+		x.Name = iterCondVarName
+		scope.iterVars[iterCondVarName] = &x
+		v, err := scope.evalAST(cond)
+		if err != nil {
+			return fmt.Errorf("error evaluating condition of %s on element %d: %v", name, i, err)
+		}
+		v.loadValue(loadFullValue)
+		if v.Unreadable != nil {
+			return fmt.Errorf("condition of %s unreadable on element %d: %v", name, i, v.Unreadable)
+		}
+		if v.Kind != reflect.Bool {
+			return fmt.Errorf("condition of %s not boolean", name)
+		}
+		if constant.BoolVal(v.Value) && !fn(elem) {
+			break
+		}
+	}
+	return nil
+}
+
+// mapKeysBuiltin implements map_keys(m), it returns a new slice containing
+// the keys of map m.
+func (scope *EvalScope) mapKeysBuiltin(args []*Variable, nodeargs []ast.Expr) (*Variable, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("wrong number of arguments to map_keys: %d", len(args))
+	}
+	m := args[0]
+	if m.Kind != reflect.Map {
+		return nil, fmt.Errorf("invalid argument %s (type %s) for map_keys", exprToString(nodeargs[0]), m.TypeString())
+	}
+	it := m.mapIterator()
+	if it == nil {
+		return nil, m.Unreadable
+	}
+	keyType := m.RealType.(*godwarf.MapType).KeyType
+	var keys []*Variable
+	for it.next() {
+		key := it.key()
+		// the type of keys stored in buckets could differ from the declared
+		// key type (for example large keys are stored indirectly)
+		keyType = key.DwarfType
+		keys = append(keys, key)
+	}
+	if m.Unreadable != nil {
+		return nil, m.Unreadable
+	}
+	return scope.newFakeSlice(keyType, keys)
+}
+
+// newFakeSlice returns a slice of elemType containing a copy of elems.
+// The elements are stored in fake memory (see compositeMemory), pointers
+// contained in them still refer to the memory of the target process.
+func (scope *EvalScope) newFakeSlice(elemType godwarf.Type, elems []*Variable) (*Variable, error) {
+	sz := elemType.Size()
+	data := make([]byte, int64(len(elems))*sz)
+	for i, elem := range elems {
+		if _, err := elem.mem.ReadMemory(data[int64(i)*sz:int64(i+1)*sz], elem.Addr); err != nil {
+			return nil, err
+		}
+	}
+	elemsMem := &compositeMemory{realmem: DereferenceMemory(scope.Mem), arch: scope.BinInfo.Arch, regs: scope.Regs, data: data}
+	scope.registerFakeMemory(elemsMem)
+
+	// The elements of a slice are read through DereferenceMemory(r.mem),
+	// wrap elemsMem so that it is what dereferencing returns.
+	mem := &compositeMemory{realmem: elemsMem, arch: scope.BinInfo.Arch, regs: scope.Regs}
+
+	r := newVariable("", 0, fakeSliceType(elemType), scope.BinInfo, mem)
+	r.Base = elemsMem.base
+	r.Len = int64(len(elems))
+	r.Cap = r.Len
+	r.stride = sz
+	r.fieldType = elemType
+	r.Flags |= VariableFakeAddress
+	return r, nil
+}
+
+// registerFakeMemory assigns an address to mem. Scopes without a target
+// (for example the ones used to evaluate breakpoint conditions) can not
+// register memory with it, they put all fake memory at the same address,
+// which is fine as long as nothing tries to look it up by address.
+func (scope *EvalScope) registerFakeMemory(mem *compositeMemory) {
+	if scope.target != nil {
+		scope.target.registerFakeMemory(mem)
+		return
+	}
+	mem.base = fakeAddressBase
+}
+
 // Evaluates identifier expressions
 func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 	switch node.Name {
@@ -1131,6 +1359,10 @@ func (scope *EvalScope) evalIdent(node *ast.Ident) (*Variable, error) {
 		return nilVariable, nil
 	}
 
+	if v := scope.iterVars[node.Name]; v != nil {
+		return v, nil
+	}
+
 	vars, err := scope.Locals()
 	if err != nil {
 		return nil, err
@@ -2013,12 +2245,11 @@ func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
 		typ = fakeSliceType(v.fieldType)
 	}
 
-	mem := v.mem
-	if v.Kind != reflect.Array {
-		mem = DereferenceMemory(mem)
-	}
-
-	r := v.newVariable("", 0, typ, mem)
+	// The elements of slices and strings are read through
+	// DereferenceMemory(r.mem) when they are loaded, do not dereference it
+	// here or slices stored in fake memory (see newFakeSlice) would lose track
+	// of their elements.
+	r := v.newVariable("", 0, typ, v.mem)
 	r.Cap = len
 	r.Len = len
 	r.Base = base
@@ -3164,6 +3164,8 @@ func logStacktrace(t *testing.T, p *proc.Target, frames []proc.Stackframe) {
 		name := "?"
 		if frames[j].Current.Fn != nil {
 			name = frames[j].Current.Fn.Name
+		} else if frames[j].Symbol != "" {
+			name = frames[j].Symbol
 		}
 		if frames[j].Call.Fn != nil && frames[j].Current.Fn != frames[j].Call.Fn {
 			name = fmt.Sprintf("%s inlined in %s", frames[j].Call.Fn.Name, frames[j].Current.Fn.Name)
@@ -3210,6 +3212,8 @@ func stacktraceCheck(t *testing.T, tc []string, frames []proc.Stackframe) []int
 			name := "?"
 			if frames[j].Current.Fn != nil {
 				name = frames[j].Current.Fn.Name
+			} else if frames[j].Symbol != "" {
+				name = frames[j].Symbol
 			}
 			if name == tcname {
 				m[i] = j
@@ -4511,6 +4515,34 @@ func TestCgoStacktrace2(t *testing.T) {
 	})
 }
 
+func TestCgoStacktraceNoDebugInfo(t *testing.T) {
+	skipOn(t, "upstream issue", "windows")
+	skipOn(t, "broken", "386")
+	skipOn(t, "broken", "arm64")
+	skipUnlessOn(t, "ELF symbol tables only", "linux")
+	protest.MustHaveCgo(t)
+	// C functions compiled without debug info should be unwound and named
+	// after their ELF symbols.
+	withTestProcess("cgonodebugstack", t, func(p *proc.Target, fixture protest.Fixture) {
+		p.Continue()
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 100)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p, frames)
+		m := stacktraceCheck(t, []string{"!nodebug_sigsegv", "!nodebug_testfn", "main.main"}, frames)
+		if m == nil {
+			t.Fatal("see previous loglines")
+		}
+		for _, i := range m[:2] {
+			if frames[i].Current.Fn != nil || frames[i].Current.File != "?" {
+				t.Errorf("frame %d of %s has debug info: %s:%d", i, frames[i].Symbol, frames[i].Current.File, frames[i].Current.Line)
+			}
+			if img := p.BinInfo().PCToImage(frames[i].Current.PC); img == nil {
+				t.Errorf("no image for frame %d of %s", i, frames[i].Symbol)
+			}
+		}
+	})
+}
+
 func TestIssue1656(t *testing.T) {
 	skipUnlessOn(t, "amd64 only", "amd64")
 	withTestProcess("issue1656/", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -5409,3 +5441,36 @@ func TestDwrapStartLocation(t *testing.T) {
 		}
 	})
 }
+
+func TestStepFilter(t *testing.T) {
+	// Step should step over calls to functions excluded by the step filter
+	// but still stop in the functions that aren't.
+	withTestProcess("stepfilter", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFileBreakpoint(p, t, fixture.Source, 10)
+		assertNoError(p.Continue(), t, "Continue()")
+		p.SetStepFilter(&proc.StepFilter{SkipPackages: []string{"fmt"}})
+
+		assertNoError(p.Step(), t, "Step()")
+		assertLineNumber(p, t, 11, "Step did not step over fmt.Println")
+
+		assertNoError(p.Step(), t, "Step()")
+		loc, err := p.CurrentThread().Location()
+		assertNoError(err, t, "Location()")
+		if loc.Fn == nil || loc.Fn.Name != "main.helper" || loc.Line != 6 {
+			t.Fatalf("expected to be in main.helper at line 6, got %s:%d", loc.File, loc.Line)
+		}
+
+		assertNoError(p.StepOut(), t, "StepOut()")
+		assertNoError(p.Next(), t, "Next()")
+		assertLineNumber(p, t, 12, "Next did not stop on the last line")
+
+		// A nil filter disables filtering.
+		p.SetStepFilter(nil)
+		assertNoError(p.Step(), t, "Step()")
+		loc, err = p.CurrentThread().Location()
+		assertNoError(err, t, "Location()")
+		if loc.Fn == nil || loc.Fn.PackageName() != "fmt" {
+			t.Fatalf("expected to be in package fmt, got %s:%d", loc.File, loc.Line)
+		}
+	})
+}
@@ -1,7 +1,13 @@
 package proc
 
 import (
+	"encoding/binary"
+	"errors"
+	"go/ast"
+	"go/constant"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 )
 
 func TestAlignAddr(t *testing.T) {
@@ -96,3 +102,179 @@ func TestConvertInt(t *testing.T) {
 		}
 	}
 }
+
+func TestPCToSymbol(t *testing.T) {
+	bi := &BinaryInfo{symbols: []elfSymbol{
+		{name: "a", entry: 0x100, end: 0x110},
+		{name: "b", entry: 0x110, end: 0x120},
+		{name: "c", entry: 0x200, end: 0x210},
+	}}
+	for _, tc := range []struct {
+		pc   uint64
+		name string
+	}{
+		{0x50, ""},
+		{0x100, "a"},
+		{0x10f, "a"},
+		{0x110, "b"},
+		{0x150, ""},
+		{0x20f, "c"},
+		{0x210, ""},
+	} {
+		name := ""
+		if sym := bi.pcToSymbol(tc.pc); sym != nil {
+			name = sym.name
+		}
+		if name != tc.name {
+			t.Errorf("pcToSymbol(%#x) = %q, expected %q", tc.pc, name, tc.name)
+		}
+	}
+}
+
+// testMemory is a MemoryReadWriter for a single chunk of memory starting at
+// base.
+type testMemory struct {
+	base uint64
+	data []byte
+}
+
+func (mem *testMemory) ReadMemory(data []byte, addr uint64) (int, error) {
+	if addr < mem.base || addr+uint64(len(data)) > mem.base+uint64(len(mem.data)) {
+		return 0, errors.New("read out of bounds")
+	}
+	copy(data, mem.data[addr-mem.base:])
+	return len(data), nil
+}
+
+func (mem *testMemory) WriteMemory(addr uint64, data []byte) (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func TestIterBuiltins(t *testing.T) {
+	const base = 0x1000
+	mem := &testMemory{base: base}
+	for _, x := range []uint64{5, 1, 7, 3, 9} {
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, x)
+		mem.data = append(mem.data, buf...)
+	}
+	bi := &BinaryInfo{Arch: AMD64Arch("linux")}
+	inttyp := &godwarf.IntType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: 8, Name: "int"}, BitSize: 64}}
+	scope := &EvalScope{Mem: mem, BinInfo: bi}
+
+	newSlice := func() *Variable {
+		s := newVariable("s", 0, fakeSliceType(inttyp), bi, mem)
+		s.Base = base
+		s.Len, s.Cap = 5, 5
+		s.stride = 8
+		s.fieldType = inttyp
+		return s
+	}
+
+	call := func(builtin func([]*Variable, []ast.Expr) (*Variable, error), cond string) (*Variable, error) {
+		condv := newConstant(constant.MakeString(cond), mem)
+		return builtin([]*Variable{newSlice(), condv}, []ast.Expr{&ast.Ident{Name: "s"}, &ast.BasicLit{Value: cond}})
+	}
+
+	values := func(v *Variable) []int64 {
+		v.loadValue(loadFullValue)
+		if v.Unreadable != nil {
+			t.Fatalf("unreadable: %v", v.Unreadable)
+		}
+		r := []int64{}
+		for i := range v.Children {
+			n, _ := constant.Int64Val(v.Children[i].Value)
+			r = append(r, n)
+		}
+		return r
+	}
+
+	checkValues := func(tgt []int64, v *Variable) {
+		t.Helper()
+		out := values(v)
+		if len(out) != len(tgt) {
+			t.Fatalf("got %v, expected %v", out, tgt)
+		}
+		for i := range out {
+			if out[i] != tgt[i] {
+				t.Fatalf("got %v, expected %v", out, tgt)
+			}
+		}
+	}
+
+	v, err := call(scope.filterBuiltin, "x > 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Len != 3 {
+		t.Errorf("wrong length of filtered slice: %d", v.Len)
+	}
+	checkValues([]int64{5, 7, 9}, v)
+
+	v, err = call(scope.filterBuiltin, "x > 4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err = v.reslice(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues([]int64{7, 9}, v)
+
+	v, err = call(scope.countBuiltin, "x < 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := constant.Int64Val(v.Value); n != 3 {
+		t.Errorf("wrong count: %d", n)
+	}
+
+	v, err = call(scope.findBuiltin, "x > 6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.loadValue(loadFullValue)
+	if n, _ := constant.Int64Val(v.Value); n != 7 || v.Addr != base+16 {
+		t.Errorf("wrong element found: %d at %#x", n, v.Addr)
+	}
+
+	if _, err := call(scope.findBuiltin, "x > 100"); err == nil {
+		t.Errorf("expected error for find without matches")
+	}
+	if _, err := call(scope.filterBuiltin, "x + 1"); err == nil {
+		t.Errorf("expected error for non-boolean condition")
+	}
+}
+
+func TestStepFilterMatch(t *testing.T) {
+	filter := &StepFilter{
+		SkipPackages: []string{"runtime", "golang.org/x/...", "internal/*"},
+		SkipFiles:    []string{"vendor/**", "*_gen.go", "/usr/local/go/src/**"},
+	}
+	tests := []struct {
+		pkg, file string
+		skip      bool
+	}{
+		{"runtime", "/usr/lib/go/src/runtime/proc.go", true},
+		{"runtime/debug", "/home/a/runtime/debug/x.go", false},
+		{"golang.org/x", "/home/a/x.go", true},
+		{"golang.org/x/tools/go/packages", "/home/a/x.go", true},
+		{"golang.org/xy", "/home/a/x.go", false},
+		{"internal/poll", "/home/a/x.go", true},
+		{"internal/poll/sub", "/home/a/x.go", false},
+		{"main", "/home/a/proj/main.go", false},
+		{"example.com/dep", "/home/a/proj/vendor/example.com/dep/dep.go", true},
+		{"example.com/proj/api", "/home/a/proj/api/api_gen.go", true},
+		{"fmt", "/usr/local/go/src/fmt/print.go", true},
+		{"fmt", "/opt/usr/local/go/src/fmt/print.go", false},
+	}
+	for _, tc := range tests {
+		if got := matchStepFilter(filter, tc.pkg, tc.file); got != tc.skip {
+			t.Errorf("matchStepFilter(%q, %q) = %v, expected %v", tc.pkg, tc.file, got, tc.skip)
+		}
+	}
+
+	if !inMainModule("/home/a/proj", "/home/a/proj/pkg/x.go") || inMainModule("/home/a/proj", "/home/a/proj/vendor/dep/x.go") || inMainModule("/home/a/proj", "/home/a/project/x.go") {
+		t.Error("inMainModule returned the wrong result")
+	}
+}
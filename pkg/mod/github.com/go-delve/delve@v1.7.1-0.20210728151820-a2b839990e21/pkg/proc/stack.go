@@ -49,6 +49,10 @@ type Stackframe struct {
 	Inlined bool
 	// Bottom is true if this is the bottom of the stack
 	Bottom bool
+	// Symbol is the name of the ELF symbol containing Current.PC, it is only
+	// set when Current.Fn is nil, i.e. for frames of functions without debug
+	// info such as C functions called through cgo.
+	Symbol string
 
 	// lastpc is a memory address guaranteed to belong to the last instruction
 	// executed in this stack frame.
@@ -269,13 +273,17 @@ func (it *stackIterator) newStackframe(ret, retaddr uint64) Stackframe {
 		return Stackframe{}
 	}
 	f, l, fn := it.bi.PCToLine(it.pc)
+	var symbol string
 	if fn == nil {
 		f = "?"
 		l = -1
+		if sym := it.bi.pcToSymbol(it.pc); sym != nil {
+			symbol = sym.name
+		}
 	} else {
 		it.regs.FrameBase = it.frameBase(fn)
 	}
-	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, lastpc: it.pc}
+	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, lastpc: it.pc, Symbol: symbol}
 	r.Call = r.Current
 	if !it.top && r.Current.Fn != nil && it.pc != r.Current.Fn.Entry {
 		// if the return address is the entry point of the function that
@@ -368,6 +376,7 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 			SystemStack: frame.SystemStack,
 			Inlined:     true,
 			lastpc:      frame.lastpc,
+			Symbol:      frame.Symbol,
 		})
 
 		frame.Call.File = filepath
@@ -395,7 +404,31 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 		framectx = it.bi.Arch.fixFrameUnwindContext(fde.EstablishFrame(it.pc), it.pc, it.bi)
 	}
 
+	callFrameRegs, ret, retaddr = it.executeFrameContext(framectx)
+	if it.err != nil && fde != nil && !it.isGoFrame() {
+		// The call frame information of C code is sometimes incomplete or
+		// wrong (for example hand written assembly in libc), if executing it
+		// failed try again following the frame pointer.
+		err, cfa := it.err, it.regs.CFA
+		it.err = nil
+		fpCallFrameRegs, fpRet, fpRetaddr := it.executeFrameContext(it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi))
+		if it.err == nil && it.plausibleCallFrame(fpCallFrameRegs) {
+			return fpCallFrameRegs, fpRet, fpRetaddr
+		}
+		it.err, it.regs.CFA = err, cfa
+	}
+
+	return callFrameRegs, ret, retaddr
+}
+
+// executeFrameContext calculates the DwarfRegisters for the next stack
+// frame by executing the rules in framectx, see advanceRegs.
+func (it *stackIterator) executeFrameContext(framectx *frame.FrameContext) (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64) {
 	cfareg, err := it.executeFrameRegRule(0, framectx.CFA, 0)
+	if err != nil {
+		it.err = fmt.Errorf("could not compute CFA at PC %#x: %v", it.pc, err)
+		return op.DwarfRegisters{}, 0, 0
+	}
 	if cfareg == nil {
 		it.err = fmt.Errorf("CFA becomes undefined at PC %#x", it.pc)
 		return op.DwarfRegisters{}, 0, 0
@@ -440,6 +473,26 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 	return callFrameRegs, ret, retaddr
 }
 
+// isGoFrame returns true if it.pc belongs to a function compiled by the Go
+// compiler.
+func (it *stackIterator) isGoFrame() bool {
+	fn := it.bi.PCToFunc(it.pc)
+	return fn != nil && fn.cu.isgo
+}
+
+// plausibleCallFrame returns true if callFrameRegs, calculated by
+// following the frame pointer, describe a frame that could be the caller of
+// the current frame. Code compiled without frame pointers uses BP as a
+// general purpose register, the values we get by following it can not be
+// trusted unless the stack pointer moves towards the base of the stack.
+func (it *stackIterator) plausibleCallFrame(callFrameRegs op.DwarfRegisters) bool {
+	sp := callFrameRegs.Reg(callFrameRegs.SPRegNum)
+	if sp == nil || sp.Uint64Val <= it.regs.SP() {
+		return false
+	}
+	return it.stackhi == 0 || sp.Uint64Val <= it.stackhi
+}
+
 func (it *stackIterator) executeFrameRegRule(regnum uint64, rule frame.DWRule, cfa int64) (*op.DwarfRegister, error) {
 	switch rule.Rule {
 	default:
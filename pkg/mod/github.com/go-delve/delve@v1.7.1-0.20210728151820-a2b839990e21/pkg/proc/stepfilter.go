@@ -0,0 +1,156 @@
+package proc
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StepFilter describes the functions that Step should not stop in. Calls
+// to filtered functions are stepped over and returning to a filtered
+// function continues until a frame that is not filtered is reached.
+type StepFilter struct {
+	// SkipPackages is a list of package path patterns, matched using
+	// path.Match. A pattern ending in "/..." also matches all the packages
+	// below it, for example "golang.org/x/..." matches all the packages
+	// under golang.org/x.
+	SkipPackages []string
+	// SkipFiles is a list of file path patterns. Each path element is
+	// matched using path.Match, "**" matches any number of path elements.
+	// Relative patterns can match at any depth, for example "vendor/**"
+	// matches all vendored files and "*_test.go" all test files.
+	SkipFiles []string
+	// MainModuleOnly skips all functions that are not part of the main
+	// module, i.e. functions whose source file is not inside the directory
+	// of the go.mod file of package main (vendored dependencies are
+	// considered outside of the main module).
+	MainModuleOnly bool
+}
+
+// SetStepFilter sets the filter used by Step, a nil filter disables
+// filtering.
+func (dbp *Target) SetStepFilter(filter *StepFilter) {
+	if filter != nil && len(filter.SkipPackages) == 0 && len(filter.SkipFiles) == 0 && !filter.MainModuleOnly {
+		filter = nil
+	}
+	dbp.stepFilter = filter
+}
+
+// stepFiltered returns true if fn is excluded by the current step filter.
+func (dbp *Target) stepFiltered(fn *Function) bool {
+	if dbp.stepFilter == nil || fn == nil {
+		return false
+	}
+	file, _, _ := dbp.BinInfo().PCToLine(fn.Entry)
+	if dbp.stepFilter.MainModuleOnly {
+		root := dbp.mainModuleDir()
+		if root != "" && !inMainModule(root, file) {
+			return true
+		}
+	}
+	return matchStepFilter(dbp.stepFilter, fn.PackageName(), file)
+}
+
+func matchStepFilter(filter *StepFilter, pkg, file string) bool {
+	for _, pattern := range filter.SkipPackages {
+		if matchPackagePattern(pattern, pkg) {
+			return true
+		}
+	}
+	for _, pattern := range filter.SkipFiles {
+		if matchFilePattern(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFilePattern(pattern, file string) bool {
+	pattern = filepath.ToSlash(pattern)
+	if !strings.HasPrefix(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	return matchPathElems(strings.Split(pattern, "/"), strings.Split(filepath.ToSlash(file), "/"))
+}
+
+func matchPathElems(pattern, elems []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchPathElems(pattern[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], elems[0]); !ok {
+			return false
+		}
+		pattern, elems = pattern[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+func matchPackagePattern(pattern, pkg string) bool {
+	if strings.HasSuffix(pattern, "/...") {
+		prefix := pattern[:len(pattern)-len("/...")]
+		if ok, _ := path.Match(prefix, pkg); ok {
+			return true
+		}
+		// match prefix against the leading path components of pkg
+		n := strings.Count(prefix, "/") + 1
+		if fields := strings.SplitN(pkg, "/", n+1); len(fields) > n {
+			if ok, _ := path.Match(prefix, strings.Join(fields[:n], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}
+	ok, _ := path.Match(pattern, pkg)
+	return ok
+}
+
+func inMainModule(root, file string) bool {
+	file = filepath.ToSlash(file)
+	if !strings.HasPrefix(file, root+"/") {
+		return false
+	}
+	return !strings.HasPrefix(file[len(root):], "/vendor/")
+}
+
+// mainModuleDir returns the directory containing the go.mod file of the
+// main package, or the directory of the main package if no go.mod file is
+// found. An empty string is returned if it can not be determined.
+func (dbp *Target) mainModuleDir() string {
+	if dbp.mainModuleDirCache != nil {
+		return *dbp.mainModuleDirCache
+	}
+	root := ""
+	if fn := dbp.BinInfo().LookupFunc["main.main"]; fn != nil {
+		file, _, _ := dbp.BinInfo().PCToLine(fn.Entry)
+		root = findModuleRoot(filepath.Dir(file))
+	}
+	dbp.mainModuleDirCache = &root
+	return root
+}
+
+func findModuleRoot(dir string) string {
+	if dir == "." || dir == "" {
+		return ""
+	}
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return filepath.ToSlash(d)
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	return filepath.ToSlash(dir)
+}
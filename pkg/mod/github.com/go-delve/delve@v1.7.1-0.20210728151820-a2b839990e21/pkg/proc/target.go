@@ -74,6 +74,10 @@ type Target struct {
 	// can be given a unique address.
 	fakeMemoryRegistry    []*compositeMemory
 	fakeMemoryRegistryMap map[string]*compositeMemory
+
+	// stepFilter is the filter used by Step, see SetStepFilter.
+	stepFilter         *StepFilter
+	mainModuleDirCache *string
 }
 
 // ErrProcessExited indicates that the process has exited and contains both
@@ -629,6 +629,15 @@ func next(dbp *Target, stepInto, inlinedStepOut bool) error {
 
 	if !topframe.Inlined {
 		topframe, retframe := skipAutogeneratedWrappersOut(selg, curthread, &topframe, &retframe)
+		if stepInto && !backward && dbp.stepFiltered(retframe.Current.Fn) {
+			// Returning to a function excluded by the step filter, stop in the
+			// first frame that isn't excluded instead.
+			retframe = firstUnfilteredCaller(dbp, selg, curthread)
+			if retframe == nil {
+				success = true
+				return nil
+			}
+		}
 		retFrameCond := astutil.And(sameGCond, frameoffCondition(retframe))
 
 		// Add a breakpoint on the return address for the current frame.
@@ -650,6 +659,29 @@ func next(dbp *Target, stepInto, inlinedStepOut bool) error {
 	return nil
 }
 
+// firstUnfilteredCaller returns the first frame, after the topmost one,
+// that isn't excluded by the step filter, or nil if all frames are
+// excluded.
+func firstUnfilteredCaller(dbp *Target, g *G, thread Thread) *Stackframe {
+	const maxDepth = 50
+	var frames []Stackframe
+	var err error
+	if g == nil {
+		frames, err = ThreadStacktrace(thread, maxDepth)
+	} else {
+		frames, err = g.Stacktrace(maxDepth, 0)
+	}
+	if err != nil {
+		return nil
+	}
+	for i := 1; i < len(frames); i++ {
+		if frames[i].Current.Fn != nil && !dbp.stepFiltered(frames[i].Current.Fn) {
+			return &frames[i]
+		}
+	}
+	return nil
+}
+
 func setStepIntoBreakpoints(dbp *Target, curfn *Function, text []AsmInstruction, topframe Stackframe, sameGCond ast.Expr) error {
 	for _, instr := range text {
 		if instr.Loc.File != topframe.Current.File || instr.Loc.Line != topframe.Current.Line || !instr.IsCall() {
@@ -678,7 +710,7 @@ func setStepIntoBreakpointsReverse(dbp *Target, text []AsmInstruction, topframe
 			continue
 		}
 
-		if instr.DestLoc.Fn.privateRuntime() {
+		if instr.DestLoc.Fn.privateRuntime() || dbp.stepFiltered(instr.DestLoc.Fn) {
 			continue
 		}
 
@@ -762,10 +794,6 @@ func setStepIntoBreakpoint(dbp *Target, curfn *Function, text []AsmInstruction,
 		return nil
 	}
 
-	//TODO(aarzilli): if we want to let users hide functions
-	// or entire packages from being stepped into with 'step'
-	// those extra checks should be done here.
-
 	pc := instr.DestLoc.PC
 
 	// Skip InhibitStepInto functions for different arch.
@@ -775,6 +803,11 @@ func setStepIntoBreakpoint(dbp *Target, curfn *Function, text []AsmInstruction,
 
 	fn, pc = skipAutogeneratedWrappersIn(dbp, fn, pc)
 
+	// Skip functions excluded by the step filter
+	if dbp.stepFiltered(fn) {
+		return nil
+	}
+
 	// We want to skip the function prologue but we should only do it if the
 	// destination address of the CALL instruction is the entry point of the
 	// function.
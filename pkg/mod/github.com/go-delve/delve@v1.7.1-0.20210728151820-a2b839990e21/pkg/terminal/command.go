@@ -179,7 +179,9 @@ For example:
 	continue main.main
 	continue encoding/json.Marshal
 `},
-		{aliases: []string{"step", "s"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: "Single step through program."},
+		{aliases: []string{"step", "s"}, group: runCmds, cmdFn: c.step, allowedPrefixes: revPrefix, helpMsg: `Single step through program.
+
+Functions excluded by the step-skip-packages, step-skip-files and step-main-module-only configuration parameters are stepped over, see 'help config'.`},
 		{aliases: []string{"step-instruction", "si"}, group: runCmds, allowedPrefixes: revPrefix, cmdFn: c.stepInstruction, helpMsg: "Single step a single cpu instruction."},
 		{aliases: []string{"next", "n"}, group: runCmds, cmdFn: c.next, allowedPrefixes: revPrefix, helpMsg: `Step over to next source line.
 
@@ -478,7 +480,13 @@ Adds or removes a path substitution rule.
 	config alias <command> <alias>
 	config alias <alias>
 
-Defines <alias> as an alias to <command> or removes an alias.`},
+Defines <alias> as an alias to <command> or removes an alias.
+
+	config step-skip-packages <pattern>...
+	config step-skip-files <pattern>...
+	config step-main-module-only <true|false>
+
+Sets the step filters: the step command will not stop in packages and files matching one of the patterns or, if step-main-module-only is true, in code that doesn't belong to the main module. Package patterns ending in /... match all the packages below them, in file patterns ** matches any number of directories. Calling step-skip-packages or step-skip-files without arguments clears the list.`},
 
 		{aliases: []string{"edit", "ed"}, cmdFn: edit, helpMsg: `Open where you are in $DELVE_EDITOR or $EDITOR
 
@@ -520,6 +528,19 @@ If display is called without arguments it will print the value of all expression
 	dump <output file>
 
 The core dump is always written in ELF, even on systems (windows, macOS) where this is not customary. For environments other than linux/amd64 threads and registers are dumped in a format that only Delve can read back.`},
+
+		{aliases: []string{"session"}, cmdFn: session, helpMsg: `Saves or restores breakpoints, display expressions and configuration.
+
+	session save <file>
+	session load <file>
+
+'session save' writes all breakpoints, including their conditions and the commands attached to them with 'on', tracepoints, the expressions added with 'display' and the current configuration, including substitute-path rules, to file. The file is written in JSON if its extension is .json, in YAML otherwise.
+
+'session load' restores a session saved by 'session save'. Breakpoints are recreated using their file and line number, the ones that can not be recreated are reported and skipped. Watchpoints are not saved.
+
+When the 'session-autoload' configuration option is set the session file .dlv-session.yml is loaded from the current directory, if it exists, when Delve starts.
+
+Note that the session file is read and written by the Delve server, relative paths are interpreted relative to its working directory.`},
 	}
 
 	addrecorded := client == nil
@@ -3095,6 +3116,65 @@ func dump(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func session(t *Term, ctx callContext, args string) error {
+	v := strings.SplitN(args, " ", 2)
+	if len(v) != 2 || strings.TrimSpace(v[1]) == "" {
+		return fmt.Errorf("wrong number of arguments to session")
+	}
+	path := strings.TrimSpace(v[1])
+	switch v[0] {
+	case "save":
+		return t.saveSession(path)
+	case "load":
+		return t.loadSession(path)
+	default:
+		return fmt.Errorf("unknown session subcommand %q", v[0])
+	}
+}
+
+func (t *Term) saveSession(path string) error {
+	var sess api.Session
+	for _, display := range t.displays {
+		if display.expr != "" {
+			sess.Displays = append(sess.Displays, api.SessionDisplay{Expr: display.expr, Format: display.fmtstr})
+		}
+	}
+	conf := *t.conf
+	conf.Aliases = nil // aliases are not part of the session
+	sess.Config = &conf
+	if err := t.client.SaveSession(path, sess); err != nil {
+		return err
+	}
+	fmt.Fprintf(t.stdout, "Session saved to %s\n", path)
+	return nil
+}
+
+func (t *Term) loadSession(path string) error {
+	sess, bps, discarded, err := t.client.LoadSession(path)
+	if err != nil {
+		return err
+	}
+	for _, bp := range bps {
+		fmt.Fprintf(t.stdout, "%s set at %s\n", formatBreakpointName(bp, true), t.formatBreakpointLocation(bp))
+	}
+	for _, d := range discarded {
+		fmt.Fprintf(t.stdout, "Could not recreate %s at %s:%d: %s\n", formatBreakpointName(d.Breakpoint, false), t.formatPath(d.Breakpoint.File), d.Breakpoint.Line, d.Reason)
+	}
+	for _, display := range sess.Displays {
+		t.addDisplay(display.Expr, display.Format)
+	}
+	if sess.Config != nil {
+		aliases := t.conf.Aliases
+		*t.conf = *sess.Config
+		t.conf.Aliases = aliases
+		t.substitutePathRulesCache = nil
+		lcfg := t.loadConfig()
+		t.client.SetReturnValuesLoadConfig(&lcfg)
+		t.client.SetStepFilter(t.stepFilter())
+	}
+	return nil
+}
+
 func formatBreakpointName(bp *api.Breakpoint, upcase bool) string {
 	thing := "breakpoint"
 	if bp.Tracepoint {
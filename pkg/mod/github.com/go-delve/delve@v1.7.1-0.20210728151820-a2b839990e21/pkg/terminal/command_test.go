@@ -812,6 +812,28 @@ func TestConfig(t *testing.T) {
 	if findCmdName(term.cmds, "blah", noPrefix) != "" {
 		t.Fatalf("new alias found after delete")
 	}
+
+	err = configureCmd(&term, callContext{}, `step-skip-packages runtime "golang.org/x/..."`)
+	if err != nil {
+		t.Fatalf("error executing configureCmd(step-skip-packages): %v", err)
+	}
+	if len(term.conf.StepSkipPackages) != 2 || term.conf.StepSkipPackages[0] != "runtime" || term.conf.StepSkipPackages[1] != "golang.org/x/..." {
+		t.Fatalf("unexpected StepSkipPackages %q", term.conf.StepSkipPackages)
+	}
+	if sf := term.stepFilter(); sf == nil || len(sf.SkipPackages) != 2 {
+		t.Fatalf("unexpected step filter %v", sf)
+	}
+
+	err = configureCmd(&term, callContext{}, "step-skip-packages")
+	if err != nil {
+		t.Fatalf("error executing configureCmd(step-skip-packages): %v", err)
+	}
+	if len(term.conf.StepSkipPackages) != 0 {
+		t.Fatalf("unexpected StepSkipPackages after clear %q", term.conf.StepSkipPackages)
+	}
+	if sf := term.stepFilter(); sf != nil {
+		t.Fatalf("unexpected step filter after clear %v", sf)
+	}
 }
 
 func TestIssue1090(t *testing.T) {
@@ -27,6 +27,7 @@ func configureCmd(t *Term, ctx callContext, args string) error {
 		if t.client != nil { // only happens in tests
 			lcfg := t.loadConfig()
 			t.client.SetReturnValuesLoadConfig(&lcfg)
+			t.client.SetStepFilter(t.stepFilter())
 		}
 		return nil
 	}
@@ -116,6 +117,12 @@ func configureSet(t *Term, args string) error {
 		return configureSetSubstitutePath(t, rest)
 	}
 
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String {
+		// replace the whole list, an empty list clears it
+		field.Set(reflect.ValueOf(config.SplitQuotedFields(rest, '"')))
+		return nil
+	}
+
 	simpleArg := func(typ reflect.Type) (reflect.Value, error) {
 		switch typ.Kind() {
 		case reflect.Int:
@@ -130,6 +130,7 @@ func New(client service.Client, conf *config.Config) *Term {
 	if client != nil {
 		lcfg := t.loadConfig()
 		client.SetReturnValuesLoadConfig(&lcfg)
+		client.SetStepFilter(t.stepFilter())
 	}
 
 	t.starlarkEnv = starbind.New(starlarkContext{t})
@@ -244,6 +245,14 @@ func (t *Term) Run() (int, error) {
 
 	fmt.Println("Type 'help' for list of commands.")
 
+	if t.conf.SessionAutoload {
+		if _, err := os.Stat(config.SessionFile); err == nil {
+			if err := t.loadSession(config.SessionFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error loading session file: %s\n", err)
+			}
+		}
+	}
+
 	if t.InitFile != "" {
 		err := t.cmds.executeFile(t, t.InitFile)
 		if err != nil {
@@ -457,6 +466,18 @@ func (t *Term) loadConfig() api.LoadConfig {
 	return r
 }
 
+// stepFilter returns the step filter specified in the configuration file.
+func (t *Term) stepFilter() *api.StepFilter {
+	if t.conf == nil || (len(t.conf.StepSkipPackages) == 0 && len(t.conf.StepSkipFiles) == 0 && !t.conf.StepMainModuleOnly) {
+		return nil
+	}
+	return &api.StepFilter{
+		SkipPackages:   t.conf.StepSkipPackages,
+		SkipFiles:      t.conf.StepSkipFiles,
+		MainModuleOnly: t.conf.StepMainModuleOnly,
+	}
+}
+
 func (t *Term) removeDisplay(n int) error {
 	if n < 0 || n >= len(t.displays) {
 		return fmt.Errorf("%d is out of range", n)
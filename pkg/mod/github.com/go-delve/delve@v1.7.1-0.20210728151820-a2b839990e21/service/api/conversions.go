@@ -361,6 +361,18 @@ func LoadConfigToProc(cfg *LoadConfig) *proc.LoadConfig {
 	}
 }
 
+// StepFilterToProc converts an api.StepFilter to proc.StepFilter.
+func StepFilterToProc(filter *StepFilter) *proc.StepFilter {
+	if filter == nil {
+		return nil
+	}
+	return &proc.StepFilter{
+		SkipPackages:   filter.SkipPackages,
+		SkipFiles:      filter.SkipFiles,
+		MainModuleOnly: filter.MainModuleOnly,
+	}
+}
+
 // LoadConfigFromProc converts a proc.LoadConfig to api.LoadConfig.
 func LoadConfigFromProc(cfg *proc.LoadConfig) *LoadConfig {
 	if cfg == nil {
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Session describes the state of a debugging session that can be saved to
+// a file and restored in a later session: breakpoints (including
+// tracepoints and the commands attached to them with 'on'), display
+// expressions and configuration (including substitute-path rules).
+type Session struct {
+	Breakpoints []*Breakpoint    `json:"breakpoints,omitempty" yaml:"breakpoints,omitempty"`
+	Displays    []SessionDisplay `json:"displays,omitempty" yaml:"displays,omitempty"`
+	Config      *config.Config   `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// SessionDisplay is an expression printed every time the program stops,
+// see the 'display' command.
+type SessionDisplay struct {
+	Expr   string `json:"expr" yaml:"expr"`
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+}
+
+// SessionBreakpoint returns a copy of bp that can be saved in a session
+// file, or nil if bp can not be recreated in a different session.
+// Addresses, IDs and hit counts are only valid in the session that created
+// them and are removed, breakpoints set on a source line are recreated
+// using their file and line.
+func SessionBreakpoint(bp *Breakpoint) *Breakpoint {
+	if bp.ID < 0 || bp.WatchExpr != "" {
+		// internal breakpoints and watchpoints
+		return nil
+	}
+	r := *bp
+	r.ID = 0
+	r.HitCount = nil
+	r.TotalHitCount = 0
+	if r.File != "" {
+		r.FunctionName = ""
+		r.Addr = 0
+		r.Addrs = nil
+	}
+	return &r
+}
+
+// ReadSessionFile reads a session from path. The file is decoded as JSON
+// if its extension is .json and as YAML otherwise.
+func ReadSessionFile(path string) (*Session, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sess := &Session{}
+	if isJSONSessionFile(path) {
+		err = json.Unmarshal(buf, sess)
+	} else {
+		err = yaml.Unmarshal(buf, sess)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read session file %s: %v", path, err)
+	}
+	return sess, nil
+}
+
+// WriteSessionFile writes sess to path. The file is encoded as JSON if its
+// extension is .json and as YAML otherwise.
+func WriteSessionFile(path string, sess *Session) error {
+	var buf []byte
+	var err error
+	if isJSONSessionFile(path) {
+		buf, err = json.MarshalIndent(sess, "", "\t")
+	} else {
+		buf, err = yaml.Marshal(sess)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0600)
+}
+
+func isJSONSessionFile(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".json"
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/config"
+)
+
+func TestSessionFileRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlv-session")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bp := SessionBreakpoint(&Breakpoint{ID: 3, Name: "bp", File: "/src/main.go", Line: 10, FunctionName: "main.main", Addr: 0x4000, Addrs: []uint64{0x4000}, Cond: "i == 2", Tracepoint: true, TotalHitCount: 4})
+	if bp.ID != 0 || bp.Addr != 0 || bp.Addrs != nil || bp.FunctionName != "" || bp.TotalHitCount != 0 {
+		t.Fatalf("session-specific fields not cleared: %#v", bp)
+	}
+	if SessionBreakpoint(&Breakpoint{ID: -1}) != nil || SessionBreakpoint(&Breakpoint{ID: 1, WatchExpr: "x"}) != nil {
+		t.Fatal("internal breakpoints and watchpoints should not be saved")
+	}
+
+	sess := &Session{
+		Breakpoints: []*Breakpoint{bp},
+		Displays:    []SessionDisplay{{Expr: "x", Format: "%x"}},
+		Config:      &config.Config{SubstitutePath: config.SubstitutePathRules{{From: "/a", To: "/b"}}},
+	}
+	for _, name := range []string{"session.json", "session.yml"} {
+		path := filepath.Join(dir, name)
+		if err := WriteSessionFile(path, sess); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		out, err := ReadSessionFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(out.Breakpoints) != 1 || out.Breakpoints[0].File != "/src/main.go" || out.Breakpoints[0].Line != 10 || out.Breakpoints[0].Cond != "i == 2" || !out.Breakpoints[0].Tracepoint {
+			t.Errorf("%s: wrong breakpoints %#v", name, out.Breakpoints)
+		}
+		if len(out.Displays) != 1 || out.Displays[0] != sess.Displays[0] {
+			t.Errorf("%s: wrong displays %#v", name, out.Displays)
+		}
+		if out.Config == nil || len(out.Config.SubstitutePath) != 1 || out.Config.SubstitutePath[0].To != "/b" {
+			t.Errorf("%s: wrong config %#v", name, out.Config)
+		}
+	}
+}
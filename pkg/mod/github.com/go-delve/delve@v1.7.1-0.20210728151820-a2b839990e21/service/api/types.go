@@ -385,6 +385,25 @@ type DebuggerCommand struct {
 	// violate the rules about stack objects you can disable this safety check
 	// by setting UnsafeCall to true.
 	UnsafeCall bool `json:"unsafeCall,omitempty"`
+
+	// StepFilter is the step filter used by Step and ReverseStep commands,
+	// it replaces the filter of previous commands and nil disables filtering.
+	// The filter stays in effect for commands that complete an interrupted
+	// step.
+	StepFilter *StepFilter `json:"stepFilter,omitempty"`
+}
+
+// StepFilter describes the functions that Step should not stop in.
+type StepFilter struct {
+	// SkipPackages is a list of package path patterns to skip (see
+	// path.Match), patterns ending in "/..." also match all the packages
+	// below them.
+	SkipPackages []string `json:"skipPackages,omitempty"`
+	// SkipFiles is a list of file path patterns to skip, "**" matches any
+	// number of path elements and relative patterns can match at any depth.
+	SkipFiles []string `json:"skipFiles,omitempty"`
+	// MainModuleOnly skips all code outside of the main module.
+	MainModuleOnly bool `json:"mainModuleOnly,omitempty"`
 }
 
 // BreakpointInfo contains informations about the current breakpoint
@@ -496,6 +515,20 @@ type SetAPIVersionIn struct {
 type SetAPIVersionOut struct {
 }
 
+// SubscribeIn is the input for Subscribe and Unsubscribe.
+type SubscribeIn struct {
+	// Events lists the kinds of events to subscribe to (or unsubscribe
+	// from), if it is empty all kinds are selected.
+	Events []EventKind
+}
+
+// SubscribeOut is the output for Subscribe and Unsubscribe.
+type SubscribeOut struct {
+	// Events lists the kinds of events the connection is subscribed to
+	// after the call.
+	Events []EventKind
+}
+
 // Register holds information on a CPU register.
 type Register struct {
 	Name        string
@@ -624,3 +657,58 @@ type GoroutineGroupingOptions struct {
 	MaxGroupMembers int
 	MaxGroups       int
 }
+
+// EventKind is the kind of an Event.
+type EventKind string
+
+const (
+	// EventRunning is sent when the target process is resumed.
+	EventRunning EventKind = "running"
+	// EventStopped is sent when the target process stops after being
+	// resumed, State is set to the state of the debugger.
+	EventStopped EventKind = "stopped"
+	// EventBreakpoint is sent, after EventStopped, for every thread stopped
+	// at a breakpoint, Thread and Breakpoint are set.
+	EventBreakpoint EventKind = "breakpoint"
+	// EventGoroutineCreated is sent, after EventStopped, for every goroutine
+	// created since the previous stop, Goroutine is set.
+	EventGoroutineCreated EventKind = "goroutinecreated"
+	// EventOutput is sent when the target process writes to its standard
+	// output or standard error, Output and Stream are set.
+	// Output is only reported when stdout and stderr are redirected to a
+	// regular file.
+	EventOutput EventKind = "output"
+	// EventExited is sent when the target process exits, ExitStatus is set.
+	EventExited EventKind = "exited"
+)
+
+// EventKinds lists all valid event kinds.
+var EventKinds = []EventKind{EventRunning, EventStopped, EventBreakpoint, EventGoroutineCreated, EventOutput, EventExited}
+
+// Event is a notification pushed by the server to the clients that
+// subscribed to its kind.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	// State is the state of the debugger, set for EventStopped.
+	State *DebuggerState `json:"state,omitempty"`
+	// Thread and Breakpoint are set for EventBreakpoint.
+	Thread     *Thread     `json:"thread,omitempty"`
+	Breakpoint *Breakpoint `json:"breakpoint,omitempty"`
+	// Goroutine is set for EventGoroutineCreated.
+	Goroutine *Goroutine `json:"goroutine,omitempty"`
+	// Output and Stream ("stdout" or "stderr") are set for EventOutput.
+	Output string `json:"output,omitempty"`
+	Stream string `json:"stream,omitempty"`
+	// ExitStatus is set for EventExited.
+	ExitStatus int `json:"exitStatus"`
+}
+
+// ValidEventKind returns an error if kind is not a valid event kind.
+func ValidEventKind(kind EventKind) error {
+	for _, k := range EventKinds {
+		if k == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown event kind %q", kind)
+}
@@ -159,6 +159,9 @@ type Client interface {
 	// SetReturnValuesLoadConfig sets the load configuration for return values.
 	SetReturnValuesLoadConfig(*api.LoadConfig)
 
+	// SetStepFilter sets the filter used by Step and ReverseStep.
+	SetStepFilter(*api.StepFilter)
+
 	// IsMulticlien returns true if the headless instance is multiclient.
 	IsMulticlient() bool
 
@@ -180,6 +183,14 @@ type Client interface {
 	// CoreDumpCancel cancels a core dump in progress
 	CoreDumpCancel() error
 
+	// SaveSession saves the current breakpoints, along with the display
+	// expressions and configuration in sess, to the specified file.
+	SaveSession(path string, sess api.Session) error
+	// LoadSession creates the breakpoints saved in the specified session
+	// file, it returns the session read, the breakpoints created and the
+	// ones that could not be created.
+	LoadSession(path string) (*api.Session, []*api.Breakpoint, []api.DiscardedBreakpoint, error)
+
 	// Disconnect closes the connection to the server without sending a Detach request first.
 	// If cont is true a continue command will be sent instead.
 	Disconnect(cont bool) error
@@ -147,6 +147,9 @@ type launchAttachArgs struct {
 	// substitutePathServerToClient indicates rules for converting file paths between debugger and client.
 	// These must be directory paths.
 	substitutePathServerToClient [][2]string
+	// stepFilter describes the functions that stepIn should not stop in,
+	// set with the justMyCode, skipPackages and skipFiles attributes.
+	stepFilter *api.StepFilter
 }
 
 // defaultArgs borrows the defaults for the arguments from the original vscode-go adapter.
@@ -259,6 +262,35 @@ func (s *Server) setLaunchAttachArgs(request dap.LaunchAttachRequest) error {
 		s.args.substitutePathClientToServer = clientToServer
 		s.args.substitutePathServerToClient = serverToClient
 	}
+	var stepFilter api.StepFilter
+	justMyCode, ok := request.GetArguments()["justMyCode"].(bool)
+	if ok {
+		stepFilter.MainModuleOnly = justMyCode
+	}
+	for _, attr := range []struct {
+		name string
+		dst  *[]string
+	}{{"skipPackages", &stepFilter.SkipPackages}, {"skipFiles", &stepFilter.SkipFiles}} {
+		patterns, ok := request.GetArguments()[attr.name]
+		if !ok {
+			continue
+		}
+		typeMismatchError := fmt.Errorf("'%s' attribute '%v' in debug configuration is not a []string", attr.name, patterns)
+		patternsParsed, ok := patterns.([]interface{})
+		if !ok {
+			return typeMismatchError
+		}
+		for _, pattern := range patternsParsed {
+			pattern, ok := pattern.(string)
+			if !ok {
+				return typeMismatchError
+			}
+			*attr.dst = append(*attr.dst, pattern)
+		}
+	}
+	if stepFilter.MainModuleOnly || len(stepFilter.SkipPackages) > 0 || len(stepFilter.SkipFiles) > 0 {
+		s.args.stepFilter = &stepFilter
+	}
 	return nil
 }
 
@@ -1693,14 +1725,22 @@ func (s *Server) onStackTraceRequest(request *dap.StackTraceRequest) {
 		loc := &frame.Call
 		uniqueStackFrameID := s.stackFrameHandles.create(stackFrame{goroutineID, i})
 		stackFrames[i] = dap.StackFrame{Id: uniqueStackFrameID, Line: loc.Line, Name: fnName(loc)}
-		if loc.File != "<autogenerated>" {
+		if loc.Fn == nil && frame.Symbol != "" {
+			// C function without debug info, see proc.Stackframe.Symbol
+			stackFrames[i].Name = frame.Symbol
+		}
+		if loc.File != "<autogenerated>" && loc.File != "?" {
 			clientPath := s.toClientPath(loc.File)
 			stackFrames[i].Source = dap.Source{Name: filepath.Base(clientPath), Path: clientPath}
 		}
 		stackFrames[i].Column = 0
 
 		packageName := fnPackageName(loc)
-		if !isSystemGoroutine && packageName == "runtime" {
+		if loc.Fn == nil {
+			// there is no source to show for frames without debug info
+			stackFrames[i].Line = 0
+			stackFrames[i].PresentationHint = "subtle"
+		} else if !isSystemGoroutine && packageName == "runtime" {
 			stackFrames[i].Source.PresentationHint = "deemphasize"
 		}
 	}
@@ -2842,7 +2882,7 @@ func (s *Server) doRunCommand(command string, asyncSetupDone chan struct{}) {
 	// asyncSetupDone (e.g. when having an error next while nexting).
 	// So we should always close it ourselves just in case.
 	defer s.asyncCommandDone(asyncSetupDone)
-	state, err := s.debugger.Command(&api.DebuggerCommand{Name: command}, asyncSetupDone)
+	state, err := s.debugger.Command(&api.DebuggerCommand{Name: command, StepFilter: s.args.stepFilter}, asyncSetupDone)
 	if processExited(state, err) {
 		s.send(&dap.TerminatedEvent{Event: *newEvent("terminated")})
 		return
@@ -3360,6 +3360,44 @@ func TestNextAndStep(t *testing.T) {
 	})
 }
 
+func TestStepInStepFilter(t *testing.T) {
+	runTest(t, "stepfilter", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client, "launch",
+			// Launch
+			func() {
+				client.LaunchRequestWithArgs(map[string]interface{}{
+					"mode": "exec", "program": fixture.Path, "skipPackages": []string{"fmt"},
+				})
+			},
+			// Set breakpoints
+			fixture.Source, []int{10},
+			[]onBreakpoint{{ // Stop at line 10
+				execute: func() {
+					checkStop(t, client, 1, "main.main", 10)
+
+					expectStop := func(fun string, line int) {
+						t.Helper()
+						se := client.ExpectStoppedEvent(t)
+						if se.Body.Reason != "step" || se.Body.ThreadId != 1 || !se.Body.AllThreadsStopped {
+							t.Errorf("got %#v, want Reason=\"step\", ThreadId=1, AllThreadsStopped=true", se)
+						}
+						checkStop(t, client, 1, fun, line)
+					}
+
+					// fmt.Println is skipped
+					client.StepInRequest(1)
+					client.ExpectStepInResponse(t)
+					expectStop("main.main", 11)
+
+					client.StepInRequest(1)
+					client.ExpectStepInResponse(t)
+					expectStop("main.helper", 6)
+				},
+				disconnect: false,
+			}})
+	})
+}
+
 func TestNextParked(t *testing.T) {
 	if runtime.GOOS == "freebsd" {
 		t.SkipNow()
@@ -4704,6 +4742,15 @@ func TestBadLaunchRequests(t *testing.T) {
 		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
 			"Failed to launch: 'substitutePath' attribute '[map[from:path1 to:123]]' in debug configuration is not a []{'from': string, 'to': string}")
 
+		// Bad "skipPackages" and "skipFiles"
+		client.LaunchRequestWithArgs(map[string]interface{}{"mode": "debug", "program": fixture.Source, "skipPackages": 123})
+		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
+			"Failed to launch: 'skipPackages' attribute '123' in debug configuration is not a []string")
+
+		client.LaunchRequestWithArgs(map[string]interface{}{"mode": "debug", "program": fixture.Source, "skipFiles": []interface{}{123}})
+		checkFailedToLaunchWithMessage(client.ExpectInvisibleErrorResponse(t),
+			"Failed to launch: 'skipFiles' attribute '[123]' in debug configuration is not a []string")
+
 		// Bad "cwd"
 		client.LaunchRequestWithArgs(map[string]interface{}{"mode": "debug", "program": fixture.Source, "cwd": 123})
 		checkFailedToLaunchWithMessage(client.ExpectErrorResponse(t),
@@ -76,6 +76,8 @@ type Debugger struct {
 	// so lower layers like proc doesn't need to deal
 	// with them
 	disabledBreakpoints map[int]*api.Breakpoint
+
+	events eventState
 }
 
 type ExecuteKind int
@@ -517,6 +519,7 @@ func (d *Debugger) Restart(rerecord bool, pos string, resetArgs bool, newArgs []
 	discarded := []api.DiscardedBreakpoint{}
 	breakpoints := api.ConvertBreakpoints(d.breakpoints())
 	d.target = p
+	d.resetEventState()
 	maxID := 0
 	for _, oldBp := range breakpoints {
 		if oldBp.ID < 0 {
@@ -1097,9 +1100,7 @@ func (d *Debugger) IsRunning() bool {
 }
 
 // Command handles commands which control the debugger lifecycle
-func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struct{}) (*api.DebuggerState, error) {
-	var err error
-
+func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struct{}) (state *api.DebuggerState, err error) {
 	if command.Name == api.Halt {
 		// RequestManualStop does not invoke any ptrace syscalls, so it's safe to
 		// access the process directly.
@@ -1120,8 +1121,16 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 	d.setRunning(true)
 	defer d.setRunning(false)
 
-	if command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread && command.Name != api.Halt {
+	resumes := command.Name != api.SwitchGoroutine && command.Name != api.SwitchThread && command.Name != api.Halt
+	if resumes {
 		d.target.ResumeNotify(resumeNotify)
+		stopEvents := d.resumeEvents()
+		defer func() {
+			stopEvents()
+			if state != nil {
+				d.stopEvents(state)
+			}
+		}()
 	} else if resumeNotify != nil {
 		close(resumeNotify)
 	}
@@ -1175,12 +1184,14 @@ func (d *Debugger) Command(command *api.DebuggerCommand, resumeNotify chan struc
 		if err := d.target.ChangeDirection(proc.Forward); err != nil {
 			return nil, err
 		}
+		d.target.SetStepFilter(api.StepFilterToProc(command.StepFilter))
 		err = d.target.Step()
 	case api.ReverseStep:
 		d.log.Debug("reverse stepping")
 		if err := d.target.ChangeDirection(proc.Backward); err != nil {
 			return nil, err
 		}
+		d.target.SetStepFilter(api.StepFilterToProc(command.StepFilter))
 		err = d.target.Step()
 	case api.StepInstruction:
 		d.log.Debug("single stepping")
@@ -1740,6 +1751,9 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 
 			Bottom: rawlocs[i].Bottom,
 		}
+		if frame.Function == nil && rawlocs[i].Symbol != "" {
+			frame.Function = &api.Function{Name_: rawlocs[i].Symbol}
+		}
 		if rawlocs[i].Err != nil {
 			frame.Err = rawlocs[i].Err.Error()
 		}
@@ -0,0 +1,197 @@
+package debugger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+)
+
+// outputPollInterval is how often the files stdout and stderr are
+// redirected to are checked for new output while the target is running.
+const outputPollInterval = 100 * time.Millisecond
+
+// eventState holds the state needed to generate events.
+type eventState struct {
+	mu     sync.Mutex
+	notify func(*api.Event)
+	wants  func(api.EventKind) bool
+
+	// knownGoroutines is the set of goroutines that existed the last time
+	// the target stopped, used to generate EventGoroutineCreated.
+	knownGoroutines map[int]bool
+	// outputOffsets is the size of the files stdout and stderr are redirected
+	// to the last time they were read.
+	outputOffsets [2]int64
+}
+
+// SetEventFunc sets the function called for every event generated by the
+// debugger. The wants function is called to determine if somebody is
+// interested in events of a given kind, events nobody wants are not
+// generated.
+// Both functions can be called concurrently with other debugger methods and
+// must not call back into the debugger.
+func (d *Debugger) SetEventFunc(notify func(*api.Event), wants func(api.EventKind) bool) {
+	d.events.mu.Lock()
+	defer d.events.mu.Unlock()
+	d.events.notify = notify
+	d.events.wants = wants
+}
+
+func (d *Debugger) wantsEvent(kind api.EventKind) bool {
+	d.events.mu.Lock()
+	defer d.events.mu.Unlock()
+	return d.events.notify != nil && d.events.wants != nil && d.events.wants(kind)
+}
+
+func (d *Debugger) sendEvent(ev *api.Event) {
+	d.events.mu.Lock()
+	notify := d.events.notify
+	d.events.mu.Unlock()
+	if notify != nil {
+		notify(ev)
+	}
+}
+
+// resetEventState must be called when the target process is replaced.
+func (d *Debugger) resetEventState() {
+	d.events.mu.Lock()
+	defer d.events.mu.Unlock()
+	d.events.knownGoroutines = nil
+	d.events.outputOffsets = [2]int64{}
+}
+
+// resumeEvents is called before the target is resumed, it sends
+// EventRunning and starts polling the target's output. The returned
+// function must be called after the target stops.
+func (d *Debugger) resumeEvents() (stop func()) {
+	if d.wantsEvent(api.EventRunning) {
+		d.sendEvent(&api.Event{Kind: api.EventRunning})
+	}
+	if d.config.Redirects[1] == "" && d.config.Redirects[2] == "" {
+		return func() {}
+	}
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(outputPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				d.readOutput()
+				return
+			case <-ticker.C:
+				d.readOutput()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// readOutput sends an EventOutput for anything that was written to the
+// files stdout and stderr are redirected to since the last call.
+func (d *Debugger) readOutput() {
+	if !d.wantsEvent(api.EventOutput) {
+		return
+	}
+	for i, stream := range []string{"stdout", "stderr"} {
+		path := d.config.Redirects[i+1]
+		if path == "" {
+			continue
+		}
+		out := d.readOutputFile(i, path)
+		if len(out) > 0 {
+			d.sendEvent(&api.Event{Kind: api.EventOutput, Output: string(out), Stream: stream})
+		}
+	}
+}
+
+func (d *Debugger) readOutputFile(i int, path string) []byte {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer fh.Close()
+	fi, err := fh.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		// reading from a pipe would steal the output from its reader
+		return nil
+	}
+	d.events.mu.Lock()
+	off := d.events.outputOffsets[i]
+	d.events.mu.Unlock()
+	if fi.Size() < off {
+		// the file was truncated, the process was probably restarted
+		off = 0
+	}
+	if fi.Size() == off {
+		return nil
+	}
+	buf := make([]byte, fi.Size()-off)
+	n, err := fh.ReadAt(buf, off)
+	if err != nil && err != io.EOF {
+		return nil
+	}
+	d.events.mu.Lock()
+	d.events.outputOffsets[i] = off + int64(n)
+	d.events.mu.Unlock()
+	return buf[:n]
+}
+
+// stopEvents sends the events generated by the target stopping, the
+// target mutex must be held.
+func (d *Debugger) stopEvents(state *api.DebuggerState) {
+	if state.Exited {
+		if d.wantsEvent(api.EventExited) {
+			d.sendEvent(&api.Event{Kind: api.EventExited, ExitStatus: state.ExitStatus})
+		}
+		return
+	}
+	if d.wantsEvent(api.EventStopped) {
+		d.sendEvent(&api.Event{Kind: api.EventStopped, State: state})
+	}
+	if d.wantsEvent(api.EventBreakpoint) {
+		for _, th := range state.Threads {
+			if th.Breakpoint != nil {
+				d.sendEvent(&api.Event{Kind: api.EventBreakpoint, Thread: th, Breakpoint: th.Breakpoint})
+			}
+		}
+	}
+	if d.wantsEvent(api.EventGoroutineCreated) {
+		d.goroutineCreatedEvents()
+	}
+}
+
+// goroutineCreatedEvents sends an EventGoroutineCreated for every goroutine
+// that did not exist the last time it was called. The first time it is
+// called all goroutines are considered known.
+func (d *Debugger) goroutineCreatedEvents() {
+	gs, _, err := proc.GoroutinesInfo(d.target, 0, 0)
+	if err != nil {
+		d.log.Debugf("could not list goroutines: %v", err)
+		return
+	}
+	d.events.mu.Lock()
+	known := d.events.knownGoroutines
+	d.events.knownGoroutines = make(map[int]bool, len(gs))
+	for _, g := range gs {
+		d.events.knownGoroutines[g.ID] = true
+	}
+	d.events.mu.Unlock()
+	if known == nil {
+		return
+	}
+	for _, g := range gs {
+		if !known[g.ID] {
+			d.sendEvent(&api.Event{Kind: api.EventGoroutineCreated, Goroutine: api.ConvertGoroutine(d.target, g)})
+		}
+	}
+}
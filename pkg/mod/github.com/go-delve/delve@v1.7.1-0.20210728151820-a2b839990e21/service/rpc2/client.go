@@ -17,6 +17,7 @@ type RPCClient struct {
 	client *rpc.Client
 
 	retValLoadCfg *api.LoadConfig
+	stepFilter    *api.StepFilter
 }
 
 // Ensure the implementation satisfies the interface.
@@ -148,13 +149,13 @@ func (c *RPCClient) ReverseNext() (*api.DebuggerState, error) {
 
 func (c *RPCClient) Step() (*api.DebuggerState, error) {
 	var out CommandOut
-	err := c.call("Command", api.DebuggerCommand{Name: api.Step, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	err := c.call("Command", api.DebuggerCommand{Name: api.Step, ReturnInfoLoadConfig: c.retValLoadCfg, StepFilter: c.stepFilter}, &out)
 	return &out.State, err
 }
 
 func (c *RPCClient) ReverseStep() (*api.DebuggerState, error) {
 	var out CommandOut
-	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseStep, ReturnInfoLoadConfig: c.retValLoadCfg}, &out)
+	err := c.call("Command", api.DebuggerCommand{Name: api.ReverseStep, ReturnInfoLoadConfig: c.retValLoadCfg, StepFilter: c.stepFilter}, &out)
 	return &out.State, err
 }
 
@@ -446,6 +447,10 @@ func (c *RPCClient) SetReturnValuesLoadConfig(cfg *api.LoadConfig) {
 	c.retValLoadCfg = cfg
 }
 
+func (c *RPCClient) SetStepFilter(filter *api.StepFilter) {
+	c.stepFilter = filter
+}
+
 func (c *RPCClient) FunctionReturnLocations(fnName string) ([]uint64, error) {
 	var out FunctionReturnLocationsOut
 	err := c.call("FunctionReturnLocations", FunctionReturnLocationsIn{fnName}, &out)
@@ -503,6 +508,16 @@ func (c *RPCClient) CoreDumpCancel() error {
 	return c.call("DumpCancel", DumpCancelIn{}, out)
 }
 
+func (c *RPCClient) SaveSession(path string, sess api.Session) error {
+	return c.call("SaveSession", SaveSessionIn{Path: path, Session: sess}, &SaveSessionOut{})
+}
+
+func (c *RPCClient) LoadSession(path string) (*api.Session, []*api.Breakpoint, []api.DiscardedBreakpoint, error) {
+	var out LoadSessionOut
+	err := c.call("LoadSession", LoadSessionIn{Path: path}, &out)
+	return &out.Session, out.Breakpoints, out.Discarded, err
+}
+
 func (c *RPCClient) call(method string, args, reply interface{}) error {
 	return c.client.Call("RPCServer."+method, args, reply)
 }
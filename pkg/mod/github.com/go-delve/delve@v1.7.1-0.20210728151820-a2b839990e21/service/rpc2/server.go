@@ -986,3 +986,86 @@ func (s *RPCServer) CreateWatchpoint(arg CreateWatchpointIn, out *CreateWatchpoi
 	out.Breakpoint, err = s.debugger.CreateWatchpoint(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, arg.Type)
 	return err
 }
+
+type SaveSessionIn struct {
+	// Path of the session file, on the machine where the server is running.
+	Path string
+	// Session contains the state of the client (display expressions and
+	// configuration) to save, its Breakpoints field is ignored and replaced
+	// with the breakpoints currently set.
+	Session api.Session
+}
+
+type SaveSessionOut struct {
+}
+
+// SaveSession saves the breakpoints currently set, along with the client
+// state in arg.Session, to the file arg.Path.
+// The file is written as JSON if its extension is .json, as YAML otherwise.
+func (s *RPCServer) SaveSession(arg SaveSessionIn, out *SaveSessionOut) error {
+	sess := arg.Session
+	sess.Breakpoints = nil
+	bps := s.debugger.Breakpoints()
+	sort.Slice(bps, func(i, j int) bool { return bps[i].ID < bps[j].ID })
+	for _, bp := range bps {
+		if sbp := api.SessionBreakpoint(bp); sbp != nil {
+			sess.Breakpoints = append(sess.Breakpoints, sbp)
+		}
+	}
+	return api.WriteSessionFile(arg.Path, &sess)
+}
+
+type LoadSessionIn struct {
+	// Path of the session file, on the machine where the server is running.
+	Path string
+}
+
+type LoadSessionOut struct {
+	// Session is the session read from the file, restoring display
+	// expressions and configuration is up to the client.
+	Session api.Session
+	// Breakpoints lists the breakpoints that were created.
+	Breakpoints []*api.Breakpoint
+	// Discarded lists the breakpoints of the session that could not be
+	// created.
+	Discarded []api.DiscardedBreakpoint
+}
+
+// LoadSession reads a session file saved by SaveSession and creates all
+// the breakpoints it contains.
+func (s *RPCServer) LoadSession(arg LoadSessionIn, out *LoadSessionOut) error {
+	sess, err := api.ReadSessionFile(arg.Path)
+	if err != nil {
+		return err
+	}
+	out.Session = *sess
+	for _, bp := range sess.Breakpoints {
+		createdBp, err := s.createSessionBreakpoint(*bp)
+		if err != nil {
+			out.Discarded = append(out.Discarded, api.DiscardedBreakpoint{Breakpoint: bp, Reason: err.Error()})
+			continue
+		}
+		out.Breakpoints = append(out.Breakpoints, createdBp)
+	}
+	return nil
+}
+
+func (s *RPCServer) createSessionBreakpoint(bp api.Breakpoint) (*api.Breakpoint, error) {
+	if err := api.ValidBreakpointName(bp.Name); err != nil {
+		return nil, err
+	}
+	disabled := bp.Disabled
+	bp.ID = 0
+	bp.Disabled = false
+	createdBp, err := s.debugger.CreateBreakpoint(&bp)
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		createdBp.Disabled = true
+		if err := s.debugger.AmendBreakpoint(createdBp); err != nil {
+			return createdBp, err
+		}
+	}
+	return createdBp, nil
+}
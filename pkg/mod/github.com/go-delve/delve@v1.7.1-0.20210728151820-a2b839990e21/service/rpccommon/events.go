@@ -0,0 +1,184 @@
+package rpccommon
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+)
+
+// eventQueueSize is the maximum number of notifications waiting to be sent
+// to a client, events generated while the queue is full are dropped.
+const eventQueueSize = 1024
+
+// connection holds the state of a client connection needed to send it
+// notifications.
+type connection struct {
+	s       *ServerImpl
+	sending *sync.Mutex
+	w       io.Writer
+
+	mu            sync.Mutex
+	subscriptions map[api.EventKind]bool
+
+	events chan *api.Event
+	done   chan struct{}
+}
+
+// notification is a JSON-RPC 1.0 notification, i.e. a request with a null
+// id that does not receive a response.
+type notification struct {
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+	ID     interface{}   `json:"id"`
+}
+
+func (s *ServerImpl) newConnection(w io.Writer, sending *sync.Mutex) *connection {
+	c := &connection{
+		s:             s,
+		sending:       sending,
+		w:             w,
+		subscriptions: make(map[api.EventKind]bool),
+		events:        make(chan *api.Event, eventQueueSize),
+		done:          make(chan struct{}),
+	}
+	s.connsMu.Lock()
+	if s.conns == nil {
+		s.conns = make(map[*connection]struct{})
+	}
+	s.conns[c] = struct{}{}
+	s.connsMu.Unlock()
+	go c.sendLoop()
+	return c
+}
+
+func (s *ServerImpl) closeConnection(c *connection) {
+	s.connsMu.Lock()
+	delete(s.conns, c)
+	s.connsMu.Unlock()
+	close(c.done)
+}
+
+// wantsEvent returns true if at least one client is subscribed to events
+// of the specified kind.
+func (s *ServerImpl) wantsEvent(kind api.EventKind) bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	for c := range s.conns {
+		if c.subscribed(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// notify queues ev to be sent to all clients subscribed to its kind.
+func (s *ServerImpl) notify(ev *api.Event) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	for c := range s.conns {
+		if !c.subscribed(ev.Kind) {
+			continue
+		}
+		select {
+		case c.events <- ev:
+		default:
+			s.log.Warnf("dropping %s event, client is not reading notifications", ev.Kind)
+		}
+	}
+}
+
+func (c *connection) subscribed(kind api.EventKind) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscriptions[kind]
+}
+
+// subscribe changes the subscription of all the specified kinds of events
+// (all kinds if events is empty) to v and returns the list of kinds the
+// connection is subscribed to.
+func (c *connection) subscribe(events []api.EventKind, v bool) ([]api.EventKind, error) {
+	if len(events) == 0 {
+		events = api.EventKinds
+	}
+	for _, kind := range events {
+		if err := api.ValidEventKind(kind); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, kind := range events {
+		if v {
+			c.subscriptions[kind] = true
+		} else {
+			delete(c.subscriptions, kind)
+		}
+	}
+	r := []api.EventKind{}
+	for _, kind := range api.EventKinds {
+		if c.subscriptions[kind] {
+			r = append(r, kind)
+		}
+	}
+	return r, nil
+}
+
+// sendLoop writes queued events to the client until the connection is
+// closed. Events are written from a separate goroutine so that a slow
+// client does not block the debugger.
+func (c *connection) sendLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case ev := <-c.events:
+			if logflags.RPC() {
+				evbytes, _ := json.Marshal(ev)
+				c.s.log.Debugf("(notification) -> %s", evbytes)
+			}
+			c.sending.Lock()
+			err := json.NewEncoder(c.w).Encode(&notification{Method: "Event", Params: []interface{}{ev}})
+			c.sending.Unlock()
+			if err != nil {
+				c.s.log.Error("writing notification:", err)
+			}
+		}
+	}
+}
+
+// Subscribe subscribes the connection to events of the kinds listed in
+// args.Events, or all kinds if args.Events is empty.
+//
+// Events are sent as JSON-RPC notifications, on the same connection as
+// responses:
+//
+//	{"method":"Event","params":[{"kind":"stopped","state":{...}}],"id":null}
+//
+// see api.Event for a description of the event kinds.
+// Note that clients using the net/rpc/jsonrpc package, such as
+// service/rpc2.RPCClient, can not receive notifications and should not
+// subscribe to events.
+func (s *RPCServer) Subscribe(args api.SubscribeIn, cb service.RPCCallback) {
+	s.subscribe(args, cb, true)
+}
+
+// Unsubscribe unsubscribes the connection from events of the kinds listed
+// in args.Events, or all kinds if args.Events is empty.
+func (s *RPCServer) Unsubscribe(args api.SubscribeIn, cb service.RPCCallback) {
+	s.subscribe(args, cb, false)
+}
+
+func (s *RPCServer) subscribe(args api.SubscribeIn, cb service.RPCCallback, v bool) {
+	var out api.SubscribeOut
+	var err error
+	out.Events, err = cb.(*RPCCallback).conn.subscribe(args.Events, v)
+	if err != nil {
+		cb.Return(nil, err)
+		return
+	}
+	cb.Return(out, nil)
+}
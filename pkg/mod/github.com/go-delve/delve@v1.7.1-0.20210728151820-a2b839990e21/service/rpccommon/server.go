@@ -44,6 +44,10 @@ type ServerImpl struct {
 	// maps of served methods, one for each supported API.
 	methodMaps []map[string]*methodType
 	log        *logrus.Entry
+
+	// conns is the set of connected clients, used to send notifications.
+	connsMu sync.Mutex
+	conns   map[*connection]struct{}
 }
 
 type RPCCallback struct {
@@ -52,6 +56,7 @@ type RPCCallback struct {
 	codec     rpc.ServerCodec
 	req       rpc.Request
 	setupDone chan struct{}
+	conn      *connection
 }
 
 var _ service.RPCCallback = &RPCCallback{}
@@ -119,6 +124,7 @@ func (s *ServerImpl) Run() error {
 
 	s.s1 = rpc1.NewServer(s.config, s.debugger)
 	s.s2 = rpc2.NewServer(s.config, s.debugger)
+	s.debugger.SetEventFunc(s.notify, s.wantsEvent)
 
 	rpcServer := &RPCServer{s}
 
@@ -258,6 +264,8 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 
 	sending := new(sync.Mutex)
 	codec := jsonrpc.NewServerCodec(conn)
+	c := s.newConnection(conn, sending)
+	defer s.closeConnection(c)
 	var req rpc.Request
 	var resp rpc.Response
 	for {
@@ -334,7 +342,7 @@ func (s *ServerImpl) serveJSONCodec(conn io.ReadWriteCloser) {
 				s.log.Debugf("(async %d) <- %s(%T%s)", req.Seq, req.ServiceMethod, argv.Interface(), argvbytes)
 			}
 			function := mtype.method.Func
-			ctl := &RPCCallback{s, sending, codec, req, make(chan struct{})}
+			ctl := &RPCCallback{s, sending, codec, req, make(chan struct{}), c}
 			go func() {
 				defer func() {
 					if ierr := recover(); ierr != nil {
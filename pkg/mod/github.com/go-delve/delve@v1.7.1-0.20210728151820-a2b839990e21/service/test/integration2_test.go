@@ -1,6 +1,7 @@
 package service_test
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -2442,3 +2443,56 @@ func TestLongStringArg(t *testing.T) {
 		}
 	})
 }
+
+func TestEventNotifications(t *testing.T) {
+	clientConn, _ := startServer("continuetestprog", 0, t, [3]string{})
+	defer clientConn.Close()
+	enc := json.NewEncoder(clientConn)
+	dec := json.NewDecoder(clientConn)
+
+	type message struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+		ID     *int              `json:"id"`
+		Result json.RawMessage   `json:"result"`
+		Error  interface{}       `json:"error"`
+	}
+
+	call := func(id int, method string, arg interface{}) {
+		err := enc.Encode(map[string]interface{}{"method": "RPCServer." + method, "params": []interface{}{arg}, "id": id})
+		assertNoError(err, t, method)
+	}
+
+	call(1, "SetApiVersion", api.SetAPIVersionIn{APIVersion: 2})
+	call(2, "Subscribe", api.SubscribeIn{Events: []api.EventKind{api.EventRunning, api.EventExited}})
+	call(3, "Command", api.DebuggerCommand{Name: api.Continue})
+
+	var kinds []api.EventKind
+	continueDone := false
+	for !continueDone || len(kinds) < 2 {
+		var msg message
+		assertNoError(dec.Decode(&msg), t, "Decode")
+		switch {
+		case msg.ID == nil:
+			if msg.Method != "Event" || len(msg.Params) != 1 {
+				t.Fatalf("malformed notification %#v", msg)
+			}
+			var ev api.Event
+			assertNoError(json.Unmarshal(msg.Params[0], &ev), t, "Unmarshal")
+			kinds = append(kinds, ev.Kind)
+		case *msg.ID == 2:
+			var out api.SubscribeOut
+			assertNoError(json.Unmarshal(msg.Result, &out), t, "Unmarshal")
+			if len(out.Events) != 2 {
+				t.Fatalf("wrong subscriptions %v", out.Events)
+			}
+		case *msg.ID == 3:
+			continueDone = true
+		}
+	}
+	if kinds[0] != api.EventRunning || kinds[1] != api.EventExited {
+		t.Fatalf("wrong events %v", kinds)
+	}
+
+	call(4, "Detach", rpc2.DetachIn{Kill: true})
+}
@@ -719,6 +719,9 @@ func TestEvalExpression(t *testing.T) {
 		{"real(cpx1)", false, "1", "1", "", nil},
 		{"imag(3i)", false, "3", "3", "", nil},
 		{"real(4)", false, "4", "4", "", nil},
+		{"map_keys(m2)", false, "[]int len: 1, cap: 1, [1]", "[]int len: 1, cap: 1, [...]", "[]int", nil},
+		{"map_keys(zsvmap)", false, `[]string len: 1, cap: 1, ["testkey"]`, `[]string len: 1, cap: 1, [...]`, "[]string", nil},
+		{"map_keys(s1)", false, "", "", "", fmt.Errorf("invalid argument s1 (type []string) for map_keys")},
 
 		// nil
 		{"nil", false, "nil", "nil", "", nil},
@@ -1189,6 +1192,7 @@ func TestCallFunction(t *testing.T) {
 		{`intcallpanic(1) + 1`, []string{":int:2"}, nil},
 		{`intcallpanic(0) + 1`, []string{`~panic:interface {}:interface {}(string) "panic requested"`}, nil},
 		{`onetwothree(5)[1] + 2`, []string{":int:9"}, nil},
+		{`count(stringslice, "two")`, []string{":int:1"}, nil}, // function of the target named like a debugger builtin
 
 		// Call types tests (methods, function pointers, etc.)
 		// The following set of calls was constructed using https://docs.google.com/document/d/1bMwCey-gmqZVTpRax-ESeVuZGmjwbocYs1iHplK-cjo/pub as a reference
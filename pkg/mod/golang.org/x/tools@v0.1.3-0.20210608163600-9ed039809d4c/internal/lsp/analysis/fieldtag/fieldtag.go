@@ -0,0 +1,304 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fieldtag defines an Analyzer that checks the names and options of
+// the json, xml and yaml keys of struct field tags.
+package fieldtag
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check the names and options of struct field tags
+
+This analyzer complements structtag. It reports the unknown options of
+the json, xml and yaml keys of struct field tags, such as the misspelled
+option of
+	Name string ` + "`" + `json:"name,omitemty"` + "`" + `
+and the JSON names that encoding/json ignores because they are not valid.
+
+It also reports the fields that encoding/json silently ignores because
+another field of the struct, or of the structs it embeds, has the same
+JSON name at the same depth, for example:
+	type T struct {
+		ID    string
+		Other string ` + "`" + `json:"ID"` + "`" + `
+	}
+where the ID field is not encoded.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "fieldtag",
+	Doc:              Doc,
+	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	RunDespiteErrors: true,
+	Run:              run,
+}
+
+// knownOptions are the options of the values of the checked keys.
+var knownOptions = map[string][]string{
+	"json": {"omitempty", "omitzero", "string"},
+	"xml":  {"attr", "chardata", "cdata", "innerxml", "comment", "omitempty", "any"},
+	"yaml": {"omitempty", "flow", "inline"},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	switch pass.Pkg.Path() {
+	case "encoding/json", "encoding/xml":
+		// As in structtag, these packages test incorrect tags.
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.StructType)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		for _, f := range st.Fields.List {
+			checkOptions(pass, f)
+		}
+		styp, ok := pass.TypesInfo.TypeOf(st).(*types.Struct)
+		// Type information may be incomplete.
+		if !ok {
+			return
+		}
+		checkJSONNames(pass, st, styp)
+	})
+	return nil, nil
+}
+
+// checkOptions checks the names and options of the checked keys of the tag
+// of f.
+func checkOptions(pass *analysis.Pass, f *ast.Field) {
+	if f.Tag == nil {
+		return
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return
+	}
+	keys := make([]string, 0, len(knownOptions))
+	for key := range knownOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := reflect.StructTag(tag).Lookup(key)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(value, ",")
+		if key == "json" && parts[0] != "" && parts[0] != "-" && !isValidJSONName(parts[0]) {
+			pass.Reportf(f.Tag.Pos(), "invalid JSON name %q: encoding/json uses the field name instead", parts[0])
+		}
+	options:
+		for _, opt := range parts[1:] {
+			if opt == "" {
+				continue
+			}
+			for _, known := range knownOptions[key] {
+				if opt == known {
+					continue options
+				}
+			}
+			pass.Reportf(f.Tag.Pos(), "unknown %s option %q", key, opt)
+		}
+	}
+}
+
+// isValidJSONName reports whether name is used by encoding/json as the name
+// of a field.
+func isValidJSONName(name string) bool {
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but
+			// otherwise any punctuation chars are allowed
+			// in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// A jsonField is a field encoded by encoding/json, possibly promoted from an
+// embedded struct.
+type jsonField struct {
+	name   string // JSON name
+	tagged bool   // whether the name is given by the tag
+	depth  int    // depth of embedding
+	path   string // selector of the field, such as Inner.Name
+	top    int    // index of the field of the checked struct that declares or embeds it
+}
+
+// checkJSONNames reports the fields of styp that encoding/json ignores
+// because of other fields with the same JSON name, following its rules: of
+// the fields with a name, only those of the smallest depth are considered,
+// and among them, the only tagged one, if any. If there are several, all the
+// fields are ignored. Conflicts of tagged fields only are left to structtag.
+func checkJSONNames(pass *analysis.Pass, st *ast.StructType, styp *types.Struct) {
+	// The syntax of the fields of the struct, by index.
+	var decls []*ast.Field
+	for _, f := range st.Fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			decls = append(decls, f)
+		}
+	}
+	if len(decls) != styp.NumFields() {
+		return
+	}
+
+	byName := make(map[string][]jsonField)
+	var names []string
+	for _, f := range jsonFields(styp) {
+		if _, ok := byName[f.name]; !ok {
+			names = append(names, f.name)
+		}
+		byName[f.name] = append(byName[f.name], f)
+	}
+	for _, name := range names {
+		fields := byName[name]
+		depth := fields[0].depth
+		for _, f := range fields {
+			if f.depth < depth {
+				depth = f.depth
+			}
+		}
+		var dominant, tagged []jsonField
+		for _, f := range fields {
+			if f.depth == depth {
+				dominant = append(dominant, f)
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+		}
+		if len(dominant) == 1 || len(tagged) == len(dominant) {
+			continue
+		}
+		// Report at the last field of the struct involved, unless the
+		// conflict is within a single embedded struct, which is reported
+		// at its declaration.
+		last := dominant[0]
+		for _, f := range dominant {
+			if f.top > last.top {
+				last = f
+			}
+		}
+		if depth > 0 && dominant[0].top == last.top {
+			continue
+		}
+		decl := decls[last.top]
+		if len(tagged) == 1 {
+			var hidden []string
+			for _, f := range dominant {
+				if !f.tagged {
+					hidden = append(hidden, f.path)
+				}
+			}
+			pass.Reportf(decl.Pos(), "JSON name %q of field %s hides %s", name, tagged[0].path, fieldList(hidden))
+			continue
+		}
+		var paths []string
+		for _, f := range dominant {
+			paths = append(paths, f.path)
+		}
+		pass.Reportf(decl.Pos(), "%s have the same JSON name %q and are ignored by encoding/json", fieldList(paths), name)
+	}
+}
+
+// fieldList formats a list of field selectors.
+func fieldList(paths []string) string {
+	if len(paths) == 1 {
+		return "field " + paths[0]
+	}
+	return fmt.Sprintf("fields %s and %s", strings.Join(paths[:len(paths)-1], ", "), paths[len(paths)-1])
+}
+
+// jsonFields returns the fields of styp that encoding/json considers, in
+// breadth-first order of the embedded structs.
+func jsonFields(styp *types.Struct) []jsonField {
+	type embedded struct {
+		styp  *types.Struct
+		depth int
+		path  string
+		top   int
+	}
+	var fields []jsonField
+	visited := make(map[*types.Named]bool)
+	current := []embedded{{styp: styp, top: -1}}
+	for len(current) > 0 {
+		var next []embedded
+		for _, e := range current {
+			for i := 0; i < e.styp.NumFields(); i++ {
+				f := e.styp.Field(i)
+				top := e.top
+				if e.depth == 0 {
+					top = i
+				}
+				tag := reflect.StructTag(e.styp.Tag(i)).Get("json")
+				if tag == "-" {
+					continue
+				}
+				name := tag
+				if i := strings.IndexByte(tag, ','); i >= 0 {
+					name = tag[:i]
+				}
+				if !isValidJSONName(name) {
+					name = ""
+				}
+				typ := f.Type()
+				if ptr, ok := typ.(*types.Pointer); ok {
+					typ = ptr.Elem()
+				}
+				inner, isStruct := typ.Underlying().(*types.Struct)
+				if f.Anonymous() {
+					// Unexported embedded structs are still traversed.
+					if !f.Exported() && !isStruct {
+						continue
+					}
+				} else if !f.Exported() {
+					continue
+				}
+				if name == "" && f.Anonymous() && isStruct {
+					if named, ok := typ.(*types.Named); ok {
+						if visited[named] {
+							continue
+						}
+						visited[named] = true
+					}
+					next = append(next, embedded{inner, e.depth + 1, e.path + f.Name() + ".", top})
+					continue
+				}
+				fields = append(fields, jsonField{
+					name:   name,
+					tagged: name != "",
+					depth:  e.depth,
+					path:   e.path + f.Name(),
+					top:    top,
+				})
+				if name == "" {
+					fields[len(fields)-1].name = f.Name()
+				}
+			}
+		}
+		current = next
+	}
+	return fields
+}
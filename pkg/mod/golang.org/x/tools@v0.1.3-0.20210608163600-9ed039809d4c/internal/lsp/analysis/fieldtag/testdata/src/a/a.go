@@ -0,0 +1,44 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fieldtag
+
+type Options struct {
+	A string `json:"a,omitempty,string"`
+	B string `json:"b,omitemty"`         // want `unknown json option "omitemty"`
+	C string `xml:"c,attr" yaml:"c,flw"` // want `unknown yaml option "flw"`
+	D string `json:"d\\e"`               // want `invalid JSON name "d\\\\e": encoding/json uses the field name instead`
+	E string `json:"-,"`
+	F string `json:",omitempty"`
+}
+
+type Hidden struct {
+	ID    string
+	Other string `json:"ID"` // want `JSON name "ID" of field Other hides field ID`
+}
+
+type Inner struct {
+	Name string
+	Kind string
+}
+
+type Other struct {
+	Name string
+	Kind string `json:"kind"`
+}
+
+type Embedding struct {
+	Inner
+	Other // want `fields Inner.Name and Other.Name have the same JSON name "Name" and are ignored by encoding/json`
+}
+
+type Shadowing struct {
+	Inner
+	Name string
+}
+
+type Tagged struct {
+	A string `json:"x"`
+	B string `json:"x"`
+}
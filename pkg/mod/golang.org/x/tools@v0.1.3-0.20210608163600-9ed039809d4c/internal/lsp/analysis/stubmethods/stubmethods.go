@@ -0,0 +1,204 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stubmethods defines an Analyzer that notes concrete types that
+// fail to implement an interface they are used as, so that their missing
+// methods can be stubbed.
+package stubmethods
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/analysisinternal"
+	"golang.org/x/tools/internal/typesinternal"
+)
+
+const Doc = `stub methods analyzer
+
+This analyzer notes type errors where a value of a concrete type is
+assigned, returned, passed or converted to an interface type that the
+concrete type does not implement, for example:
+	var _ io.Reader = T{}
+Because the suggested fix, which declares the missing methods of T after
+its declaration, can edit other files of the package, callers should
+compute it separately.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "stubmethods",
+	Doc:              Doc,
+	Requires:         []*analysis.Analyzer{},
+	Run:              run,
+	RunDespiteErrors: true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	for _, err := range analysisinternal.GetTypeErrors(pass) {
+		if !FixesError(err.Msg) {
+			continue
+		}
+		_, start, end, ok := typesinternal.ReadGo116ErrorData(err)
+		if !ok || !end.IsValid() || end == start {
+			continue
+		}
+		var file *ast.File
+		for _, f := range pass.Files {
+			if f.Pos() <= start && start < f.End() {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			continue
+		}
+		path, _ := astutil.PathEnclosingInterval(file, start, end)
+		si := GetStubInfo(pass.TypesInfo, path)
+		if si == nil || si.Concrete.Obj().Pkg() != pass.Pkg {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: err.Msg,
+		})
+	}
+	return nil, nil
+}
+
+// FixesError reports whether msg is the message of a type error about a
+// type that does not implement an interface.
+func FixesError(msg string) bool {
+	return strings.Contains(msg, "does not implement") || strings.Contains(msg, "missing method")
+}
+
+// StubInfo describes a value of a concrete type that is used as an
+// interface type that the concrete type does not implement.
+type StubInfo struct {
+	// Interface is the interface type, which may be named or not.
+	Interface types.Type
+	// Concrete is the named type of the value, or of the value pointed to.
+	Concrete *types.Named
+	// Pointer reports whether the value is a pointer to Concrete.
+	Pointer bool
+}
+
+// GetStubInfo returns the StubInfo of the expression at the start of
+// path, as returned by astutil.PathEnclosingInterval, if it is the right
+// hand side of an assignment, a returned value, a call argument or the
+// operand of a conversion to an interface type that its type does not
+// implement. Otherwise it returns nil.
+func GetStubInfo(info *types.Info, path []ast.Node) *StubInfo {
+	for i := 0; i+1 < len(path); i++ {
+		expr, ok := path[i].(ast.Expr)
+		if !ok {
+			return nil
+		}
+		if _, ok := path[i+1].(*ast.ParenExpr); ok {
+			continue
+		}
+		target := targetType(info, expr, path[i+1:])
+		if target == nil {
+			continue
+		}
+		return newStubInfo(info.TypeOf(expr), target)
+	}
+	return nil
+}
+
+// targetType returns the type that expr, whose enclosing nodes are path,
+// is assigned to, or nil.
+func targetType(info *types.Info, expr ast.Expr, path []ast.Node) types.Type {
+	switch parent := path[0].(type) {
+	case *ast.AssignStmt:
+		if i := exprIndex(parent.Rhs, expr); i >= 0 && len(parent.Lhs) == len(parent.Rhs) && parent.Tok == token.ASSIGN {
+			return info.TypeOf(parent.Lhs[i])
+		}
+	case *ast.ValueSpec:
+		if i := exprIndex(parent.Values, expr); i >= 0 && parent.Type != nil {
+			return info.TypeOf(parent.Type)
+		}
+	case *ast.ReturnStmt:
+		i := exprIndex(parent.Results, expr)
+		if i < 0 {
+			return nil
+		}
+		for _, n := range path[1:] {
+			var T types.Type
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if obj := info.Defs[n.Name]; obj != nil {
+					T = obj.Type()
+				}
+			case *ast.FuncLit:
+				T = info.TypeOf(n)
+			default:
+				continue
+			}
+			sig, ok := T.(*types.Signature)
+			if !ok || sig.Results().Len() != len(parent.Results) {
+				return nil
+			}
+			return sig.Results().At(i).Type()
+		}
+	case *ast.CallExpr:
+		i := exprIndex(parent.Args, expr)
+		if i < 0 {
+			return nil
+		}
+		if tv, ok := info.Types[parent.Fun]; ok && tv.IsType() {
+			return tv.Type // a conversion
+		}
+		sig, ok := info.TypeOf(parent.Fun).(*types.Signature)
+		if !ok {
+			return nil
+		}
+		params := sig.Params()
+		switch {
+		case sig.Variadic() && i >= params.Len()-1 && parent.Ellipsis == token.NoPos:
+			if s, ok := params.At(params.Len() - 1).Type().(*types.Slice); ok {
+				return s.Elem()
+			}
+		case i < params.Len():
+			return params.At(i).Type()
+		}
+	}
+	return nil
+}
+
+func exprIndex(exprs []ast.Expr, expr ast.Expr) int {
+	for i, e := range exprs {
+		if e == expr {
+			return i
+		}
+	}
+	return -1
+}
+
+func newStubInfo(T, target types.Type) *StubInfo {
+	if T == nil || target == nil {
+		return nil
+	}
+	iface, ok := target.Underlying().(*types.Interface)
+	if !ok || types.Implements(T, iface) {
+		return nil
+	}
+	si := &StubInfo{Interface: target}
+	if ptr, ok := T.(*types.Pointer); ok {
+		si.Pointer = true
+		T = ptr.Elem()
+	}
+	named, ok := T.(*types.Named)
+	if !ok {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Interface); ok {
+		return nil
+	}
+	si.Concrete = named
+	return si
+}
@@ -0,0 +1,24 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stub
+
+import "io"
+
+type reader struct{}
+
+type closer struct{}
+
+func (*closer) Close() error { return nil }
+
+var _ io.Reader = reader{} // want "does not implement"
+
+var _ io.Closer = closer{} // want "does not implement"
+
+func read(r io.Reader) {}
+
+func x() io.Reader {
+	read(&reader{}) // want "does not implement"
+	return reader{} // want "does not implement"
+}
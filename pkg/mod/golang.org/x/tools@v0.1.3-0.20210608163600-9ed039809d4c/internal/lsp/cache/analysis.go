@@ -65,7 +65,10 @@ type actionHandle struct {
 	handle *memoize.Handle
 
 	analyzer *analysis.Analyzer
-	pkg      *pkg
+	// ph is the handle of the package, whose value the handle depends on.
+	// The package itself is not retained, so that it may be evicted with
+	// the action.
+	ph *packageHandle
 }
 
 type actionData struct {
@@ -76,6 +79,15 @@ type actionData struct {
 	err          error
 }
 
+// Size implements memoize.Sizer, so that the results of analyses may be
+// evicted from the cache to honor its memory budget, which in turn lets
+// the package that they depend on be evicted. The results of the analyzers
+// mostly refer to the syntax and types of the package, which are counted
+// with the package, so only the diagnostics and facts are estimated.
+func (d *actionData) Size() int64 {
+	return 256 + 128*int64(len(d.diagnostics)+len(d.objectFacts)+len(d.packageFacts))
+}
+
 type objectFactKey struct {
 	obj types.Object
 	typ reflect.Type
@@ -98,13 +110,9 @@ func (s *snapshot) actionHandle(ctx context.Context, id packageID, a *analysis.A
 	if len(ph.key) == 0 {
 		return nil, errors.Errorf("actionHandle: no key for package %s", id)
 	}
-	pkg, err := ph.check(ctx, s)
-	if err != nil {
-		return nil, err
-	}
 	act = &actionHandle{
 		analyzer: a,
-		pkg:      pkg,
+		ph:       ph,
 	}
 	var deps []*actionHandle
 	// Add a dependency on each required analyzers.
@@ -147,8 +155,23 @@ func (s *snapshot) actionHandle(ctx context.Context, id packageID, a *analysis.A
 				err: err,
 			}
 		}
-		return runAnalysis(ctx, snapshot, a, pkg, results)
+		pkg, err := ph.check(ctx, snapshot)
+		if err != nil {
+			return &actionData{
+				err: err,
+			}
+		}
+		data := runAnalysis(ctx, snapshot, a, pkg, results)
+		snapshot.persistFacts(ctx, ph.key, a, pkg, data)
+		return data
 	}, nil)
+	// The results of the analysis refer to the types of the package, so
+	// the package must not be evicted, and checked again, before them.
+	depHandles := []*memoize.Handle{ph.handle}
+	for _, dep := range deps {
+		depHandles = append(depHandles, dep.handle)
+	}
+	h.DependsOn(depHandles...)
 	act.handle = h
 
 	act = s.addActionHandle(act)
@@ -162,10 +185,10 @@ func (act *actionHandle) analyze(ctx context.Context, snapshot *snapshot) ([]*so
 	}
 	data, ok := d.(*actionData)
 	if !ok {
-		return nil, nil, errors.Errorf("unexpected type for %s:%s", act.pkg.ID(), act.analyzer.Name)
+		return nil, nil, errors.Errorf("unexpected type for %s:%s", act.ph.m.id, act.analyzer.Name)
 	}
 	if data == nil {
-		return nil, nil, errors.Errorf("unexpected nil analysis for %s:%s", act.pkg.ID(), act.analyzer.Name)
+		return nil, nil, errors.Errorf("unexpected nil analysis for %s:%s", act.ph.m.id, act.analyzer.Name)
 	}
 	return data.diagnostics, data.result, data.err
 }
@@ -175,7 +198,7 @@ func buildActionKey(a *analysis.Analyzer, ph *packageHandle) actionHandleKey {
 }
 
 func (act *actionHandle) String() string {
-	return fmt.Sprintf("%s@%s", act.analyzer, act.pkg.PkgPath())
+	return fmt.Sprintf("%s@%s", act.analyzer, act.ph.m.pkgPath)
 }
 
 func execAll(ctx context.Context, snapshot *snapshot, actions []*actionHandle) (map[*actionHandle]*actionData, error) {
@@ -220,8 +243,12 @@ func runAnalysis(ctx context.Context, snapshot *snapshot, analyzer *analysis.Ana
 	// into the inputs of this action.  Also facts.
 	inputs := make(map[*analysis.Analyzer]interface{})
 
+	// The dependencies are not analyzed, but the facts of the workspace
+	// packages that were may be stored.
+	snapshot.importFacts(ctx, analyzer, pkg, data)
+
 	for depHandle, depData := range deps {
-		if depHandle.pkg == pkg {
+		if depHandle.ph.m.id == pkg.m.id {
 			// Same package, different analysis (horizontal edge):
 			// in-memory outputs of prerequisite analyzers
 			// become inputs to this analysis pass.
@@ -230,11 +257,16 @@ func runAnalysis(ctx context.Context, snapshot *snapshot, analyzer *analysis.Ana
 			// Same analysis, different package (vertical edge):
 			// serialized facts produced by prerequisite analysis
 			// become available to this analysis pass.
+			depPkg, err := depHandle.ph.check(ctx, snapshot)
+			if err != nil {
+				data.err = err
+				return data
+			}
 			for key, fact := range depData.objectFacts {
 				// Filter out facts related to objects
 				// that are irrelevant downstream
 				// (equivalently: not in the compiler export data).
-				if !exportedFrom(key.obj, depHandle.pkg.types) {
+				if !exportedFrom(key.obj, depPkg.types) {
 					continue
 				}
 				data.objectFacts[key] = fact
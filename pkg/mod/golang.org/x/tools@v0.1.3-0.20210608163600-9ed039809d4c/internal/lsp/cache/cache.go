@@ -24,6 +24,7 @@ import (
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/gocommand"
 	"golang.org/x/tools/internal/lsp/debug/tag"
+	"golang.org/x/tools/internal/lsp/filecache"
 	"golang.org/x/tools/internal/lsp/source"
 	"golang.org/x/tools/internal/memoize"
 	"golang.org/x/tools/internal/span"
@@ -47,6 +48,9 @@ type Cache struct {
 
 	store memoize.Store
 
+	// disk is the persistent cache of the diagnostics of packages, or nil.
+	disk *filecache.Cache
+
 	fileMu      sync.Mutex
 	fileContent map[span.URI]*fileHandle
 }
@@ -197,6 +201,27 @@ var cacheIndex, sessionIndex, viewIndex int64
 func (c *Cache) ID() string                     { return c.id }
 func (c *Cache) MemStats() map[reflect.Type]int { return c.store.Stats() }
 
+// SetMemoryBudget sets the estimated number of bytes that the type-checked
+// packages of the cache may use before the least recently used ones are
+// evicted. A budget of 0 means no limit.
+func (c *Cache) SetMemoryBudget(bytes int64) {
+	c.store.SetBudget(bytes)
+}
+
+// SetDiskCache sets the persistent cache in which the diagnostics of
+// workspace packages are stored, so that they are reported after a restart
+// without type-checking the packages whose inputs are unchanged. A nil cache
+// disables it.
+func (c *Cache) SetDiskCache(disk *filecache.Cache) {
+	c.disk = disk
+}
+
+// MemoryBudget returns the memory statistics of the type-checked packages
+// of the cache.
+func (c *Cache) MemoryBudget() memoize.MemStats {
+	return c.store.MemStats()
+}
+
 type packageStat struct {
 	id        packageID
 	mode      source.ParseMode
@@ -207,6 +232,29 @@ type packageStat struct {
 	total     int64
 }
 
+// newPackageStat estimates the memory used by the type-checked package p.
+func newPackageStat(p *pkg) packageStat {
+	var typsCost, typInfoCost int64
+	if p.types != nil {
+		typsCost = typesCost(p.types.Scope())
+	}
+	if p.typesInfo != nil {
+		typInfoCost = typesInfoCost(p.typesInfo)
+	}
+	stat := packageStat{
+		id:        p.m.id,
+		mode:      p.mode,
+		types:     typsCost,
+		typesInfo: typInfoCost,
+	}
+	for _, f := range p.compiledGoFiles {
+		stat.file += int64(len(f.Src))
+		stat.ast += astCost(f.File)
+	}
+	stat.total = stat.file + stat.ast + stat.types + stat.typesInfo
+	return stat
+}
+
 func (c *Cache) PackageStats(withNames bool) template.HTML {
 	var packageStats []packageStat
 	c.store.DebugOnlyIterate(func(k, v interface{}) {
@@ -216,25 +264,7 @@ func (c *Cache) PackageStats(withNames bool) template.HTML {
 			if v.pkg == nil {
 				break
 			}
-			var typsCost, typInfoCost int64
-			if v.pkg.types != nil {
-				typsCost = typesCost(v.pkg.types.Scope())
-			}
-			if v.pkg.typesInfo != nil {
-				typInfoCost = typesInfoCost(v.pkg.typesInfo)
-			}
-			stat := packageStat{
-				id:        v.pkg.m.id,
-				mode:      v.pkg.mode,
-				types:     typsCost,
-				typesInfo: typInfoCost,
-			}
-			for _, f := range v.pkg.compiledGoFiles {
-				stat.file += int64(len(f.Src))
-				stat.ast += astCost(f.File)
-			}
-			stat.total = stat.file + stat.ast + stat.types + stat.typesInfo
-			packageStats = append(packageStats, stat)
+			packageStats = append(packageStats, newPackageStat(v.pkg))
 		}
 	})
 	var totalCost int64
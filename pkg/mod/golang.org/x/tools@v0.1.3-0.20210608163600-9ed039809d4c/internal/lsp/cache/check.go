@@ -45,6 +45,9 @@ type packageHandle struct {
 
 	// key is the hashed key for the package.
 	key packageHandleKey
+
+	// deps are the handles of the dependencies of the package, by path.
+	deps map[packagePath]*packageHandle
 }
 
 func (ph *packageHandle) packageKey() packageKey {
@@ -78,6 +81,15 @@ func (ph *packageHandle) imports(ctx context.Context, s source.Snapshot) (result
 type packageData struct {
 	pkg *pkg
 	err error
+
+	// size is the estimated memory cost of pkg.
+	size int64
+}
+
+// Size implements memoize.Sizer, so that type-checked packages may be
+// evicted from the cache to honor its memory budget.
+func (d *packageData) Size() int64 {
+	return d.size
 }
 
 // buildPackageHandle returns a packageHandle for a given package and mode.
@@ -117,13 +129,24 @@ func (s *snapshot) buildPackageHandle(ctx context.Context, id packageID, mode so
 		}
 
 		data := &packageData{}
-		data.pkg, data.err = typeCheck(ctx, snapshot, m, mode, deps)
+		data.pkg, data.err = typeCheck(ctx, snapshot, m, mode, deps, nil)
+		if data.pkg != nil {
+			data.size = newPackageStat(data.pkg).total
+			snapshot.persistExportData(ctx, key, data.pkg)
+		}
 		// Make sure that the workers above have finished before we return,
 		// especially in case of cancellation.
 		wg.Wait()
 
 		return data
 	}, nil)
+	// A package must be evicted before its dependencies, since the
+	// dependencies checked again would have distinct types.
+	depHandles := make([]*memoize.Handle, 0, len(deps))
+	for _, dep := range deps {
+		depHandles = append(depHandles, dep.handle)
+	}
+	h.DependsOn(depHandles...)
 	ph.handle = h
 
 	// Cache the handle in the snapshot. If a package handle has already
@@ -180,11 +203,39 @@ func (s *snapshot) buildKey(ctx context.Context, id packageID, mode source.Parse
 		deps[depHandle.m.pkgPath] = depHandle
 		depKeys = append(depKeys, depHandle.key)
 	}
-	experimentalKey := s.View().Options().ExperimentalPackageCacheKey
-	ph.key = checkPackageKey(ph.m.id, compiledGoFiles, m.config, depKeys, mode, experimentalKey)
+	// The packages of module versions and of the standard library are
+	// shared by the views that depend on the same versions of them.
+	shared := s.View().Options().ExperimentalPackageCacheKey || s.immutablePackage(m)
+	ph.key = checkPackageKey(ph.m.id, compiledGoFiles, m.config, m.typesSizes, depKeys, mode, shared)
+	ph.deps = deps
 	return ph, deps, nil
 }
 
+// immutablePackage reports whether the compiled files of the package
+// described by m belong to a module version in the module cache, or to the
+// standard library, so that their contents never change.
+func (s *snapshot) immutablePackage(m *metadata) bool {
+	var dir string
+	switch {
+	case m.module != nil:
+		if m.module.Main || m.module.Version == "" || m.module.Replace != nil {
+			return false
+		}
+		dir = m.module.Dir
+	case s.view.goroot != "":
+		dir = filepath.Join(s.view.goroot, "src")
+	}
+	if dir == "" || len(m.compiledGoFiles) == 0 {
+		return false
+	}
+	for _, uri := range m.compiledGoFiles {
+		if !source.InDir(dir, uri.Filename()) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *snapshot) workspaceParseMode(id packageID) source.ParseMode {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -209,15 +260,18 @@ func (s *snapshot) workspaceParseMode(id packageID) source.ParseMode {
 	return source.ParseExported
 }
 
-func checkPackageKey(id packageID, pghs []*parseGoHandle, cfg *packages.Config, deps []packageHandleKey, mode source.ParseMode, experimentalKey bool) packageHandleKey {
+func checkPackageKey(id packageID, pghs []*parseGoHandle, cfg *packages.Config, sizes types.Sizes, deps []packageHandleKey, mode source.ParseMode, shared bool) packageHandleKey {
 	b := bytes.NewBuffer(nil)
 	b.WriteString(string(id))
-	if !experimentalKey {
+	if !shared {
 		// cfg was used to produce the other hashed inputs (package ID, parsed Go
 		// files, and deps). It should not otherwise affect the inputs to the type
-		// checker, so this experiment omits it. This should increase cache hits on
-		// the daemon as cfg contains the environment and working directory.
+		// checker, so shared keys omit it. This increases cache hits on the
+		// daemon as cfg contains the environment and working directory.
 		b.WriteString(hashConfig(cfg))
+	} else {
+		// The sizes of types still depend on the environment.
+		fmt.Fprint(b, sizes)
 	}
 	b.WriteByte(byte(mode))
 	for _, dep := range deps {
@@ -301,12 +355,15 @@ func (s *snapshot) parseGoHandles(ctx context.Context, files []span.URI, mode so
 	return pghs, nil
 }
 
-func typeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source.ParseMode, deps map[packagePath]*packageHandle) (*pkg, error) {
+// typeCheck type-checks the package described by m. Its dependencies are
+// type-checked from source, or imported from their export data by exports
+// if it is non-nil, in which case the package has no imported packages.
+func typeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source.ParseMode, deps map[packagePath]*packageHandle, exports *exportImporter) (*pkg, error) {
 	var filter *unexportedFilter
 	if mode == source.ParseExported {
 		filter = &unexportedFilter{uses: map[string]bool{}}
 	}
-	pkg, err := doTypeCheck(ctx, snapshot, m, mode, deps, filter)
+	pkg, err := doTypeCheck(ctx, snapshot, m, mode, deps, filter, exports)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +375,7 @@ func typeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source
 		missing, unexpected := filter.ProcessErrors(pkg.typeErrors)
 		if len(unexpected) == 0 && len(missing) != 0 {
 			event.Log(ctx, fmt.Sprintf("discovered missing identifiers: %v", missing), tag.Package.Of(string(m.id)))
-			pkg, err = doTypeCheck(ctx, snapshot, m, mode, deps, filter)
+			pkg, err = doTypeCheck(ctx, snapshot, m, mode, deps, filter, exports)
 			if err != nil {
 				return nil, err
 			}
@@ -326,7 +383,7 @@ func typeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source
 		}
 		if len(unexpected) != 0 || len(missing) != 0 {
 			event.Log(ctx, fmt.Sprintf("falling back to safe trimming due to type errors: %v or still-missing identifiers: %v", unexpected, missing), tag.Package.Of(string(m.id)))
-			pkg, err = doTypeCheck(ctx, snapshot, m, mode, deps, nil)
+			pkg, err = doTypeCheck(ctx, snapshot, m, mode, deps, nil, exports)
 			if err != nil {
 				return nil, err
 			}
@@ -417,7 +474,7 @@ func typeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source
 	return pkg, nil
 }
 
-func doTypeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source.ParseMode, deps map[packagePath]*packageHandle, astFilter *unexportedFilter) (*pkg, error) {
+func doTypeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode source.ParseMode, deps map[packagePath]*packageHandle, astFilter *unexportedFilter, exports *exportImporter) (*pkg, error) {
 	ctx, done := event.Start(ctx, "cache.typeCheck", tag.Package.Of(string(m.id)))
 	defer done()
 
@@ -503,6 +560,9 @@ func doTypeCheck(ctx context.Context, snapshot *snapshot, m *metadata, mode sour
 			if !source.IsValidImport(string(m.pkgPath), string(dep.m.pkgPath)) {
 				return nil, errors.Errorf("invalid use of internal package %s", pkgPath)
 			}
+			if exports != nil {
+				return exports.importPackage(dep)
+			}
 			depPkg, err := dep.check(ctx, snapshot)
 			if err != nil {
 				return nil, err
@@ -0,0 +1,163 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestCheckPackageKey(t *testing.T) {
+	cfg1 := &packages.Config{Dir: "/a", Env: []string{"GOFLAGS=-mod=mod"}}
+	cfg2 := &packages.Config{Dir: "/b"}
+	sizes64 := &types.StdSizes{WordSize: 8, MaxAlign: 8}
+	sizes32 := &types.StdSizes{WordSize: 4, MaxAlign: 4}
+	deps := []packageHandleKey{"dep"}
+	key := func(cfg *packages.Config, sizes types.Sizes, shared bool) packageHandleKey {
+		return checkPackageKey("example.com/p", nil, cfg, sizes, deps, source.ParseExported, shared)
+	}
+
+	if key(cfg1, sizes64, false) == key(cfg2, sizes64, false) {
+		t.Error("the keys of unshared packages do not depend on the configuration")
+	}
+	if key(cfg1, sizes64, true) != key(cfg2, sizes64, true) {
+		t.Error("the keys of shared packages depend on the configuration")
+	}
+	if key(cfg1, sizes64, true) == key(cfg1, sizes32, true) {
+		t.Error("the keys of shared packages do not depend on the sizes of types")
+	}
+}
+
+func TestSharedPackageEviction(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	// Two modules in distinct views depend on the same standard library
+	// package, which is immutable.
+	dir, err := ioutil.TempDir("", "gopls-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a", "b"} {
+		files := map[string]string{
+			"go.mod": "module example.com/" + name + "\n\ngo 1.14\n",
+			"p.go":   "package p\n\nimport \"unicode/utf8\"\n\nfunc F(s string) int { return utf8.RuneCountInString(s) }\n",
+		}
+		for filename, content := range files {
+			filename = filepath.Join(dir, name, filename)
+			if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	c := New(nil)
+	session := c.NewSession(ctx)
+	var views []*View
+	for _, name := range []string{"a", "b"} {
+		v, _, release, err := session.NewView(ctx, name, span.URIFromPath(filepath.Join(dir, name)), "", source.DefaultOptions().Clone())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+		defer v.Shutdown(ctx)
+		views = append(views, v.(*View))
+	}
+
+	// checked returns the packages of path in views, checked while their
+	// snapshots are acquired, so that they cannot be evicted until it
+	// returns.
+	checked := func(views []*View, path string, analyze bool) []*pkg {
+		var (
+			pkgs     []*pkg
+			releases []func()
+		)
+		defer func() {
+			for _, release := range releases {
+				release()
+			}
+		}()
+		for _, v := range views {
+			s, release := v.Snapshot(ctx)
+			releases = append(releases, release)
+			known, err := s.KnownPackages(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var found *pkg
+			for _, p := range known {
+				if p.PkgPath() == path {
+					found = p.(*pkg)
+				}
+			}
+			if found == nil {
+				t.Fatalf("%s: no package %s", v.Name(), path)
+			}
+			if analyze {
+				noop := &analysis.Analyzer{
+					Name: "noop",
+					Doc:  "noop does nothing",
+					Run:  func(*analysis.Pass) (interface{}, error) { return nil, nil },
+				}
+				if _, err := s.Analyze(ctx, found.ID(), []*source.Analyzer{{Analyzer: noop, Enabled: true}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			pkgs = append(pkgs, found)
+		}
+		return pkgs
+	}
+
+	utf8 := checked(views, "unicode/utf8", false)
+	if utf8[0] != utf8[1] {
+		t.Fatal("unicode/utf8 is not shared between views")
+	}
+
+	// Evict all values, then check the package again: it is shared
+	// again, but it is a new package. With such a budget, values are also
+	// evicted whenever the snapshots are released.
+	c.SetMemoryBudget(1)
+	if c.MemoryBudget().Evictions == 0 {
+		t.Fatal("no package was evicted")
+	}
+	evicted := checked(views, "unicode/utf8", false)
+	if evicted[0] != evicted[1] {
+		t.Error("unicode/utf8 is not shared between views after eviction")
+	}
+	if evicted[0] == utf8[0] {
+		t.Error("unicode/utf8 was not evicted")
+	}
+
+	// The results of an analysis are evicted too, and with them the package
+	// that they depend on, and its dependencies.
+	before := c.MemoryBudget().Evictions
+	analyzed := checked(views[:1], "example.com/a", true)[0]
+	if c.MemoryBudget().Evictions == before {
+		t.Fatal("no value of an analyzed package was evicted")
+	}
+	if got := checked(views[:1], "example.com/a", false)[0]; got == analyzed {
+		t.Error("an analyzed package was not evicted")
+	}
+	if got := checked(views[:1], "unicode/utf8", false)[0]; got == analyzed.imports["unicode/utf8"] {
+		t.Error("the dependency of an analyzed package was not evicted")
+	}
+	if got := c.MemoryBudget().Size; got > 1 {
+		t.Errorf("%d bytes remain in the cache after its snapshots are released", got)
+	}
+}
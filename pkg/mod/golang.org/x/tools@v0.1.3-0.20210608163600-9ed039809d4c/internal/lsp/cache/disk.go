@@ -0,0 +1,447 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/debug/tag"
+	"golang.org/x/tools/internal/lsp/filecache"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// The persistent cache holds the diagnostics of workspace packages, which is
+// what the diagnostics pass needs after a restart, the export data of
+// type-checked packages, with which a changed workspace package is checked
+// without checking its unchanged dependencies, and the analysis facts of
+// analyzed packages, which the analyses of their importers use.
+//
+// The packages checked against export data are only used for their
+// diagnostics: the types of their dependencies are distinct from the types
+// of the packages checked from source, and their positions do not map to
+// the parsed files of the dependencies.
+
+// The kinds of the values in the persistent cache.
+const (
+	diagnosticsKind = "diagnostics"
+	exportKind      = "export"
+	factsKind       = "facts"
+)
+
+// storedDiagnostic is the encoding of a diagnostic in the persistent cache.
+type storedDiagnostic struct {
+	source.Diagnostic
+
+	// Analyzer is the name of the type error analyzer that added fixes to the
+	// diagnostic, if any.
+	Analyzer string
+}
+
+func (s *snapshot) StoredWorkspacePackages(ctx context.Context) ([]source.Package, []*source.StoredPackage, error) {
+	phs, err := s.workspacePackageHandles(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pkgs []source.Package
+	var stored []*source.StoredPackage
+	for _, ph := range phs {
+		if sp := s.storedPackage(ctx, ph); sp != nil {
+			stored = append(stored, sp)
+			continue
+		}
+		if sp := s.checkWithExportData(ctx, ph); sp != nil {
+			stored = append(stored, sp)
+			continue
+		}
+		pkg, err := ph.check(ctx, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, stored, nil
+}
+
+// storedPackage returns the package of ph with the diagnostics read from the
+// persistent cache, or nil if ph must be type-checked: because the
+// diagnostics are not stored, or the package is already type-checked, or it
+// has open files, whose packages are also analyzed.
+func (s *snapshot) storedPackage(ctx context.Context, ph *packageHandle) *source.StoredPackage {
+	disk := s.view.session.cache.disk
+	if disk == nil || !s.storable(ph) || s.hasOpenFiles(ph) {
+		return nil
+	}
+	if _, err := ph.cached(s.generation); err == nil {
+		return nil
+	}
+	data, err := disk.Get(diagnosticsKind, s.diagnosticsKey(ph))
+	if err != nil {
+		if err != filecache.ErrNotFound {
+			event.Error(ctx, "reading stored diagnostics", err, tag.Package.Of(string(ph.m.id)))
+		}
+		return nil
+	}
+	var encoded []storedDiagnostic
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		event.Error(ctx, "decoding stored diagnostics", err, tag.Package.Of(string(ph.m.id)))
+		return nil
+	}
+	analyzers := map[string]*source.Analyzer{}
+	for _, a := range s.View().Options().TypeErrorAnalyzers {
+		analyzers[a.Analyzer.Name] = a
+	}
+	diags := map[span.URI][]*source.Diagnostic{}
+	for _, sd := range encoded {
+		diag := sd.Diagnostic
+		if sd.Analyzer != "" {
+			diag.Analyzer = analyzers[sd.Analyzer]
+		}
+		diags[diag.URI] = append(diags[diag.URI], &diag)
+	}
+	return &source.StoredPackage{
+		ID:              string(ph.m.id),
+		CompiledGoFiles: ph.m.compiledGoFiles,
+		Diagnostics:     diags,
+	}
+}
+
+// checkWithExportData returns the package of ph with its diagnostics, if it
+// can be type-checked against the export data of its dependencies in the
+// persistent cache instead of checking them from source. Packages with type
+// errors are type-checked from source, so that the type error analyzers add
+// their fixes to the diagnostics. The diagnostics and the export data of the
+// package are stored for the next restart.
+func (s *snapshot) checkWithExportData(ctx context.Context, ph *packageHandle) *source.StoredPackage {
+	disk := s.view.session.cache.disk
+	if disk == nil || !s.storable(ph) || s.hasOpenFiles(ph) || !s.saved(ph.m) {
+		return nil
+	}
+	if _, err := ph.cached(s.generation); err == nil {
+		return nil
+	}
+	for _, dep := range ph.deps {
+		if dep.m.pkgPath != "unsafe" && !disk.Has(exportKind, exportKey(dep.key)) {
+			return nil
+		}
+	}
+	exports := &exportImporter{snapshot: s, imports: make(map[string]*types.Package)}
+	pkg, err := typeCheck(ctx, s, ph.m, ph.mode, ph.deps, exports)
+	if err != nil {
+		if ctx.Err() == nil {
+			event.Error(ctx, "checking against export data", err, tag.Package.Of(string(ph.m.id)))
+		}
+		return nil
+	}
+	if len(pkg.typeErrors) > 0 || pkg.hasFixedFiles {
+		return nil
+	}
+	diags := make(map[span.URI][]*source.Diagnostic)
+	for _, diag := range pkg.diagnostics {
+		diags[diag.URI] = append(diags[diag.URI], diag)
+	}
+	s.persistDiagnostics(ctx, ph, diags)
+	s.persistExportData(ctx, ph.key, pkg)
+	return &source.StoredPackage{
+		ID:              string(ph.m.id),
+		CompiledGoFiles: ph.m.compiledGoFiles,
+		Diagnostics:     diags,
+	}
+}
+
+// hasOpenFiles reports whether a compiled file of ph is open.
+func (s *snapshot) hasOpenFiles(ph *packageHandle) bool {
+	for _, uri := range ph.m.compiledGoFiles {
+		if s.IsOpen(uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// saved reports whether the files of the package described by m match their
+// contents on disk. Files that differ from them are unlikely to be
+// unchanged after a restart, so their values are not stored.
+func (s *snapshot) saved(m *metadata) bool {
+	for _, uris := range [][]span.URI{m.goFiles, m.compiledGoFiles} {
+		for _, uri := range uris {
+			if fh := s.FindFile(uri); fh == nil || !fh.Saved() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *snapshot) PersistDiagnostics(ctx context.Context, spkg source.Package, diags map[span.URI][]*source.Diagnostic) {
+	if s.view.session.cache.disk == nil {
+		return
+	}
+	pkg := spkg.(*pkg)
+	ph := s.getPackage(pkg.m.id, pkg.mode)
+	if ph == nil {
+		return
+	}
+	s.persistDiagnostics(ctx, ph, diags)
+}
+
+func (s *snapshot) persistDiagnostics(ctx context.Context, ph *packageHandle, diags map[span.URI][]*source.Diagnostic) {
+	disk := s.view.session.cache.disk
+	if disk == nil || !s.storable(ph) || !s.saved(ph.m) {
+		return
+	}
+	var encoded []storedDiagnostic
+	for _, ds := range diags {
+		for _, diag := range ds {
+			sd := storedDiagnostic{Diagnostic: *diag}
+			if diag.Analyzer != nil {
+				sd.Analyzer = diag.Analyzer.Analyzer.Name
+			}
+			sd.Diagnostic.Analyzer = nil
+			encoded = append(encoded, sd)
+		}
+	}
+	data, err := json.Marshal(encoded)
+	if err == nil {
+		err = disk.Set(diagnosticsKind, s.diagnosticsKey(ph), data)
+	}
+	if err != nil {
+		event.Error(ctx, "storing diagnostics", err, tag.Package.Of(string(ph.m.id)))
+	}
+}
+
+// persistExportData stores the export data of pkg, type-checked with the
+// key, if pkg is free of errors and its files are saved.
+func (s *snapshot) persistExportData(ctx context.Context, key packageHandleKey, pkg *pkg) {
+	disk := s.view.session.cache.disk
+	if disk == nil || pkg.m.pkgPath == "unsafe" || !s.saved(pkg.m) {
+		return
+	}
+	if len(pkg.m.errors) > 0 || len(pkg.parseErrors) > 0 || len(pkg.typeErrors) > 0 || pkg.hasFixedFiles {
+		return
+	}
+	ekey := exportKey(key)
+	if disk.Has(exportKind, ekey) {
+		return
+	}
+	var buf bytes.Buffer
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("encoding export data: %v", r)
+			}
+		}()
+		return gcexportdata.Write(&buf, s.FileSet(), pkg.types)
+	}()
+	if err == nil {
+		err = disk.Set(exportKind, ekey, buf.Bytes())
+	}
+	if err != nil {
+		event.Error(ctx, "storing export data", err, tag.Package.Of(string(pkg.m.id)))
+	}
+}
+
+// exportKey returns the key of the export data of the package with the key
+// in the persistent cache.
+func exportKey(key packageHandleKey) string {
+	return hashContents([]byte(executableID() + string(key)))
+}
+
+// An exportImporter imports the dependencies of a package from their export
+// data in the persistent cache. The packages that it imports share their
+// dependencies, so that their types are identical.
+type exportImporter struct {
+	snapshot *snapshot
+	imports  map[string]*types.Package // by package path
+}
+
+func (imp *exportImporter) importPackage(ph *packageHandle) (*types.Package, error) {
+	path := string(ph.m.pkgPath)
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	// A package that is only referenced by the export data of another
+	// package is incomplete.
+	if pkg := imp.imports[path]; pkg != nil && pkg.Complete() {
+		return pkg, nil
+	}
+	data, err := imp.snapshot.view.session.cache.disk.Get(exportKind, exportKey(ph.key))
+	if err != nil {
+		return nil, errors.Errorf("reading export data of %s: %w", path, err)
+	}
+	return gcexportdata.Read(bytes.NewReader(data), imp.snapshot.FileSet(), imp.imports, path)
+}
+
+// storedFact is the encoding of an analysis fact in the persistent cache. A
+// fact is about the object of a package with the path, or about the package
+// itself if the path is empty.
+type storedFact struct {
+	Object objectpath.Path
+	Fact   analysis.Fact
+}
+
+// persistFacts stores the facts that the analyzer exported for pkg, which
+// are used in the analysis of the importers of pkg.
+func (s *snapshot) persistFacts(ctx context.Context, key packageHandleKey, a *analysis.Analyzer, pkg *pkg, data *actionData) {
+	disk := s.view.session.cache.disk
+	if disk == nil || len(a.FactTypes) == 0 || data.err != nil || !s.saved(pkg.m) {
+		return
+	}
+	var facts []storedFact
+	for k, fact := range data.objectFacts {
+		if k.obj.Pkg() != pkg.types {
+			continue // a fact of a dependency
+		}
+		path, err := objectpath.For(k.obj)
+		if err != nil {
+			continue // not visible to importers
+		}
+		facts = append(facts, storedFact{path, fact})
+	}
+	for k, fact := range data.packageFacts {
+		if k.pkg == pkg.types {
+			facts = append(facts, storedFact{"", fact})
+		}
+	}
+	sort.Slice(facts, func(i, j int) bool {
+		return facts[i].Object < facts[j].Object
+	})
+	registerFacts(a)
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(facts)
+	if err == nil {
+		err = disk.Set(factsKind, factsKey(key, a), buf.Bytes())
+	}
+	if err != nil {
+		event.Error(ctx, "storing analysis facts", err, tag.Package.Of(string(pkg.m.id)))
+	}
+}
+
+// importFacts adds the stored facts of the analyzer about the workspace
+// packages that the analyzed package depends on to data, since the analyses of the
+// dependencies of a package are not run.
+func (s *snapshot) importFacts(ctx context.Context, a *analysis.Analyzer, analyzed *pkg, data *actionData) {
+	disk := s.view.session.cache.disk
+	if disk == nil || len(a.FactTypes) == 0 {
+		return
+	}
+	registerFacts(a)
+	seen := make(map[*pkg]bool)
+	var visit func(p *pkg)
+	visit = func(p *pkg) {
+		for _, dep := range p.imports {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			visit(dep)
+			if !s.isWorkspacePackage(dep.m.id) {
+				continue
+			}
+			ph := s.getPackage(dep.m.id, dep.mode)
+			if ph == nil {
+				continue
+			}
+			encoded, err := disk.Get(factsKind, factsKey(ph.key, a))
+			if err != nil {
+				continue
+			}
+			var facts []storedFact
+			if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&facts); err != nil {
+				event.Error(ctx, "decoding analysis facts", err, tag.Package.Of(string(dep.m.id)))
+				continue
+			}
+			for _, f := range facts {
+				if f.Object == "" {
+					data.packageFacts[packageFactKey{dep.types, factType(f.Fact)}] = f.Fact
+					continue
+				}
+				obj, err := objectpath.Object(dep.types, f.Object)
+				if err != nil {
+					continue
+				}
+				data.objectFacts[objectFactKey{obj, factType(f.Fact)}] = f.Fact
+			}
+		}
+	}
+	visit(analyzed)
+}
+
+// registerFacts registers the fact types of the analyzer with gob, which
+// encodes them as analysis.Fact interface values.
+func registerFacts(a *analysis.Analyzer) {
+	for _, f := range a.FactTypes {
+		gob.Register(f)
+	}
+}
+
+// factsKey returns the key of the facts of the analyzer about the package
+// with the key in the persistent cache.
+func factsKey(key packageHandleKey, a *analysis.Analyzer) string {
+	return hashContents([]byte(executableID() + string(key) + " " + a.Name))
+}
+
+// storable reports whether the diagnostics of ph only depend on its key and
+// on the options hashed by diagnosticsKey. Errors reported by go list are
+// not hashed, and the diagnostics of packages that are not parsed in full
+// are not computed. Packages with missing dependencies are also excluded, as
+// GetCriticalError must type-check them.
+func (s *snapshot) storable(ph *packageHandle) bool {
+	return ph.mode == source.ParseFull && len(ph.m.errors) == 0 && len(ph.m.depsErrors) == 0 && len(ph.m.missingDeps) == 0
+}
+
+// diagnosticsKey returns the key of the diagnostics of ph in the persistent
+// cache.
+func (s *snapshot) diagnosticsKey(ph *packageHandle) string {
+	b := bytes.NewBuffer(nil)
+	b.WriteString(executableID())
+	b.WriteString(string(ph.key))
+	opts := s.View().Options()
+	fmt.Fprintf(b, "related information: %v\n", opts.RelatedInformationSupported)
+	var names []string
+	for _, a := range opts.TypeErrorAnalyzers {
+		if a.IsEnabled(s.view) {
+			names = append(names, a.Analyzer.Name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Fprintf(b, "type error analyzers: %v\n", names)
+	return hashContents(b.Bytes())
+}
+
+var (
+	executableOnce sync.Once
+	executable     string
+)
+
+// executableID identifies the running gopls executable, since other builds
+// of gopls may diagnose the same package differently.
+func executableID() string {
+	executableOnce.Do(func() {
+		executable = runtime.Version()
+		path, err := os.Executable()
+		if err != nil {
+			return
+		}
+		if fi, err := os.Stat(path); err == nil {
+			executable += fmt.Sprintf(" %s %d %d", path, fi.Size(), fi.ModTime().UnixNano())
+		}
+	})
+	return executable
+}
@@ -0,0 +1,240 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"go/ast"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/internal/lsp/filecache"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	"golang.org/x/tools/internal/testenv"
+)
+
+// diskTest is a module in a temporary directory, with a persistent cache.
+type diskTest struct {
+	t    *testing.T
+	dir  string
+	disk *filecache.Cache
+
+	// analyzers are added to the default analyzers of the views.
+	analyzers []*source.Analyzer
+}
+
+func newDiskTest(t *testing.T, files map[string]string) *diskTest {
+	testenv.NeedsGoPackages(t)
+	dir, err := ioutil.TempDir("", "gopls-disk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	dt := &diskTest{t: t, dir: dir, disk: filecache.New(filepath.Join(dir, "cache"))}
+	dt.write(files)
+	return dt
+}
+
+func (dt *diskTest) write(files map[string]string) {
+	for name, content := range files {
+		filename := filepath.Join(dt.dir, "m", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			dt.t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			dt.t.Fatal(err)
+		}
+	}
+}
+
+// restart runs f with a snapshot of the module in a new cache, as after a
+// restart of gopls.
+func (dt *diskTest) restart(f func(s *snapshot)) {
+	ctx := context.Background()
+	c := New(nil)
+	c.SetDiskCache(dt.disk)
+	session := c.NewSession(ctx)
+	options := source.DefaultOptions().Clone()
+	for _, a := range dt.analyzers {
+		options.DefaultAnalyzers[a.Analyzer.Name] = a
+	}
+	v, _, release, err := session.NewView(ctx, "m", span.URIFromPath(filepath.Join(dt.dir, "m")), "", options)
+	if err != nil {
+		dt.t.Fatal(err)
+	}
+	release()
+	defer v.Shutdown(ctx)
+	s, release := v.Snapshot(ctx)
+	defer release()
+	f(s.(*snapshot))
+}
+
+// checked returns the sorted IDs of the workspace packages of s that are
+// type-checked from source.
+func checked(s *snapshot) []string {
+	var ids []string
+	for _, id := range []packageID{"example.com/m/a", "example.com/m/b"} {
+		if ph := s.getPackage(id, source.ParseFull); ph != nil {
+			if _, err := ph.cached(s.generation); err == nil {
+				ids = append(ids, string(id))
+			}
+		}
+	}
+	return ids
+}
+
+func TestCheckWithExportData(t *testing.T) {
+	dt := newDiskTest(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.14\n",
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar X = b.F()\n",
+		"b/b.go": "package b\n\nfunc F() int { return 1 }\n",
+	})
+	ctx := context.Background()
+
+	// storedPackages returns the sorted IDs of the stored and type-checked
+	// workspace packages, after diagnosing the type-checked ones.
+	storedPackages := func(s *snapshot) (stored, pkgs []string) {
+		checkedPkgs, storedPkgs, err := s.StoredWorkspacePackages(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sp := range storedPkgs {
+			stored = append(stored, sp.ID)
+		}
+		for _, pkg := range checkedPkgs {
+			diags, err := s.DiagnosePackage(ctx, pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.PersistDiagnostics(ctx, pkg, diags)
+			pkgs = append(pkgs, pkg.ID())
+		}
+		sort.Strings(stored)
+		sort.Strings(pkgs)
+		return stored, pkgs
+	}
+	equal := func(got []string, want ...string) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// The first run stores the export data of the packages, and their
+	// diagnostics.
+	dt.restart(func(s *snapshot) {
+		if stored, pkgs := storedPackages(s); len(stored)+len(pkgs) != 2 {
+			t.Errorf("first run: stored %v and checked %v, want the two packages", stored, pkgs)
+		}
+	})
+
+	// Only a changes: it is checked against the export data of b, whose
+	// diagnostics are stored.
+	dt.write(map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar X = b.F() + 1\n",
+	})
+	dt.restart(func(s *snapshot) {
+		stored, pkgs := storedPackages(s)
+		if !equal(stored, "example.com/m/a", "example.com/m/b") || len(pkgs) != 0 {
+			t.Errorf("after a change: stored %v and checked %v, want all packages stored", stored, pkgs)
+		}
+		if ids := checked(s); len(ids) != 0 {
+			t.Errorf("after a change: %v were type-checked from source", ids)
+		}
+		ph := s.getPackage("example.com/m/a", source.ParseFull)
+		if ph == nil || !dt.disk.Has(exportKind, exportKey(ph.key)) {
+			t.Error("the export data of a package checked against export data is not stored")
+		}
+	})
+
+	// A package with type errors is checked from source, so that the type
+	// error analyzers run.
+	dt.write(map[string]string{
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar X string = b.F()\n",
+	})
+	dt.restart(func(s *snapshot) {
+		stored, pkgs := storedPackages(s)
+		if !equal(stored, "example.com/m/b") || !equal(pkgs, "example.com/m/a") {
+			t.Errorf("after a type error: stored %v and checked %v, want b stored and a checked", stored, pkgs)
+		}
+	})
+}
+
+// marked is the fact of the marker analyzer about the functions that it
+// analyzed.
+type marked struct{}
+
+func (*marked) AFact() {}
+
+func (*marked) String() string { return "marked" }
+
+func TestStoredFacts(t *testing.T) {
+	dt := newDiskTest(t, map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.14\n",
+		"a/a.go": "package a\n\nimport \"example.com/m/b\"\n\nvar X = b.F()\n",
+		"b/b.go": "package b\n\nfunc F() int { return 1 }\n",
+	})
+	ctx := context.Background()
+
+	// The marker analyzer marks the functions of a package, and reports
+	// the calls of the marked functions of other packages.
+	marker := &analysis.Analyzer{
+		Name:      "marker",
+		Doc:       "marker reports calls of the functions of other analyzed packages",
+		FactTypes: []analysis.Fact{new(marked)},
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, f := range pass.Files {
+				ast.Inspect(f, func(n ast.Node) bool {
+					switch n := n.(type) {
+					case *ast.FuncDecl:
+						pass.ExportObjectFact(pass.TypesInfo.Defs[n.Name], new(marked))
+					case *ast.SelectorExpr:
+						obj := pass.TypesInfo.Uses[n.Sel]
+						if obj != nil && obj.Pkg() != pass.Pkg && pass.ImportObjectFact(obj, new(marked)) {
+							pass.Reportf(n.Pos(), "call of marked %s", obj.Name())
+						}
+					}
+					return true
+				})
+			}
+			return nil, nil
+		},
+	}
+	analyzer := &source.Analyzer{Analyzer: marker, Enabled: true}
+	dt.analyzers = append(dt.analyzers, analyzer)
+	analyze := func(s *snapshot, id string) []*source.Diagnostic {
+		if _, err := s.WorkspacePackages(ctx); err != nil {
+			t.Fatal(err)
+		}
+		diags, err := s.Analyze(ctx, id, []*source.Analyzer{analyzer})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return diags
+	}
+
+	dt.restart(func(s *snapshot) {
+		if diags := analyze(s, "example.com/m/a"); len(diags) != 0 {
+			t.Errorf("a is analyzed with facts about b before b is analyzed: %v", diags)
+		}
+		analyze(s, "example.com/m/b")
+	})
+	dt.restart(func(s *snapshot) {
+		diags := analyze(s, "example.com/m/a")
+		if len(diags) != 1 || diags[0].Message != "call of marked F" {
+			t.Errorf("a is analyzed with diagnostics %v, want the call of b.F", diags)
+		}
+	})
+}
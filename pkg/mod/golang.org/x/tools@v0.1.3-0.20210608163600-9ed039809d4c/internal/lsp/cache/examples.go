@@ -0,0 +1,151 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/printer"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/debug/tag"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/memoize"
+	"golang.org/x/tools/internal/span"
+)
+
+// examplesHandle holds the examples of a package, computed from the given
+// _test.go files.
+type examplesHandle struct {
+	handle *memoize.Handle
+	key    examplesKey
+	files  []span.URI
+}
+
+type examplesKey struct {
+	pkgPath packagePath
+	files   string // the hash of the identities of the _test.go files
+}
+
+type examplesData struct {
+	examples []*source.Example
+	err      error
+}
+
+// exampleOutputRx matches the comment that starts the output of an example.
+var exampleOutputRx = regexp.MustCompile(`(?i)//[[:space:]]*(unordered )?output:`)
+
+func (s *snapshot) PackageExamples(ctx context.Context, pkgPath string) ([]*source.Example, error) {
+	uris := s.testFiles(packagePath(pkgPath))
+	if len(uris) == 0 {
+		return nil, nil
+	}
+	var (
+		fhs      []source.FileHandle
+		identity strings.Builder
+	)
+	for _, uri := range uris {
+		fh, err := s.GetFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		fhs = append(fhs, fh)
+		fmt.Fprintf(&identity, "%s\n", fh.FileIdentity())
+	}
+	key := examplesKey{pkgPath: packagePath(pkgPath), files: hashContents([]byte(identity.String()))}
+
+	s.mu.Lock()
+	eh := s.examples[key.pkgPath]
+	s.mu.Unlock()
+	if eh == nil || eh.key != key {
+		h := s.generation.Bind(key, func(ctx context.Context, arg memoize.Arg) interface{} {
+			ctx, done := event.Start(ctx, "cache.PackageExamples", tag.Package.Of(pkgPath))
+			defer done()
+
+			examples, err := extractExamples(ctx, arg.(*snapshot), fhs)
+			return &examplesData{examples: examples, err: err}
+		}, nil)
+		eh = &examplesHandle{handle: h, key: key, files: uris}
+		s.mu.Lock()
+		s.examples[key.pkgPath] = eh
+		s.mu.Unlock()
+	}
+	v, err := eh.handle.Get(ctx, s.generation, s)
+	if err != nil {
+		return nil, err
+	}
+	data := v.(*examplesData)
+	return data.examples, data.err
+}
+
+// testFiles returns the sorted _test.go files of the test variants of the
+// package with the given path, in the package or in its external test
+// package. Only the tests of workspace packages are loaded.
+func (s *snapshot) testFiles(pkgPath packagePath) []span.URI {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[span.URI]bool)
+	var uris []span.URI
+	for _, m := range s.metadata {
+		if m.forTest != pkgPath || (m.pkgPath != pkgPath && m.pkgPath != pkgPath+"_test") {
+			continue
+		}
+		for _, uri := range m.compiledGoFiles {
+			if strings.HasSuffix(uri.Filename(), "_test.go") && !seen[uri] {
+				seen[uri] = true
+				uris = append(uris, uri)
+			}
+		}
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+	return uris
+}
+
+// extractExamples returns the examples declared in the given files.
+func extractExamples(ctx context.Context, snapshot *snapshot, fhs []source.FileHandle) ([]*source.Example, error) {
+	var files []*ast.File
+	for _, fh := range fhs {
+		// Avoid parsing the tests without examples.
+		if data, err := fh.Read(); err != nil || !bytes.Contains(data, []byte("func Example")) {
+			continue
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, source.ParseFull)
+		if err != nil {
+			continue
+		}
+		files = append(files, pgf.File)
+	}
+	var examples []*source.Example
+	for _, ex := range doc.Examples(files...) {
+		var b strings.Builder
+		if err := format.Node(&b, snapshot.FileSet(), &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments}); err != nil {
+			return nil, err
+		}
+		code := b.String()
+		if _, ok := ex.Code.(*ast.BlockStmt); ok {
+			// Remove the braces of the body, and unindent it.
+			code = strings.TrimSuffix(strings.TrimPrefix(code, "{\n"), "}")
+			code = strings.Replace(code, "\n\t", "\n", -1)
+			code = strings.TrimPrefix(code, "\t")
+		}
+		if loc := exampleOutputRx.FindStringIndex(code); loc != nil {
+			code = code[:loc[0]]
+		}
+		examples = append(examples, &source.Example{
+			Name:   ex.Name,
+			Code:   strings.TrimSpace(code),
+			Output: strings.TrimSpace(ex.Output),
+		})
+	}
+	return examples, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestPackageExamples(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir, err := ioutil.TempDir("", "gopls-examples")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod":    "module example.com/m\n\ngo 1.14\n",
+		"p.go":      "package p\n\nfunc F() {}\n",
+		"p_test.go": "package p\n\nfunc ExampleF() {\n\tF()\n\t// Output:\n}\n",
+		"x_test.go": "package p_test\n\nimport \"example.com/m\"\n\nfunc ExampleF_second() {\n\tp.F()\n}\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	c := New(nil)
+	session := c.NewSession(ctx)
+	v, _, release, err := session.NewView(ctx, "m", span.URIFromPath(dir), "", source.DefaultOptions().Clone())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	defer v.Shutdown(ctx)
+
+	examples := func(s source.Snapshot) map[string]string {
+		if _, err := s.WorkspacePackages(ctx); err != nil {
+			t.Fatal(err)
+		}
+		examples, err := s.PackageExamples(ctx, "example.com/m")
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[string]string)
+		for _, ex := range examples {
+			m[ex.Name] = ex.Code
+		}
+		return m
+	}
+
+	s, release := v.Snapshot(ctx)
+	got := examples(s)
+	release()
+	if len(got) != 2 || got["F"] != "F()" || got["F_second"] != "p.F()" {
+		t.Errorf("got examples %v, want F and F_second", got)
+	}
+
+	// The examples are read from the overlays of the open test files,
+	// including the new ones.
+	snapshots, releases, err := session.DidModifyFiles(ctx, []source.FileModification{
+		{
+			URI:        span.URIFromPath(filepath.Join(dir, "p_test.go")),
+			Action:     source.Open,
+			Version:    1,
+			Text:       []byte("package p\n\nfunc ExampleF() {\n\tF()\n\tF()\n}\n"),
+			LanguageID: "go",
+		},
+		{
+			URI:        span.URIFromPath(filepath.Join(dir, "y_test.go")),
+			Action:     source.Open,
+			Version:    1,
+			Text:       []byte("package p\n\nfunc ExampleF_third() {}\n"),
+			LanguageID: "go",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, release := range releases {
+			release()
+		}
+	}()
+	for s := range snapshots {
+		if _, err := s.PackagesForFile(ctx, span.URIFromPath(filepath.Join(dir, "y_test.go")), source.TypecheckWorkspace); err != nil {
+			t.Fatal(err)
+		}
+		got := examples(s)
+		if len(got) != 3 || got["F"] != "F()\nF()" {
+			t.Errorf("after an edit: got examples %v, want the edited F and F_third", got)
+		}
+	}
+}
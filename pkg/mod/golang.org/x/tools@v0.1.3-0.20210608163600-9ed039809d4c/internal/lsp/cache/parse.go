@@ -115,14 +115,25 @@ func (s *snapshot) astCacheData(ctx context.Context, spkg source.Package, pos to
 	if tok == nil {
 		return nil, fmt.Errorf("no file for pos %v", pos)
 	}
-	pgf, err := pkg.File(span.URIFromPath(tok.Name()))
-	if err != nil {
+	uri := span.URIFromPath(tok.Name())
+	if _, err := pkg.File(uri); err != nil {
 		return nil, err
 	}
-	astHandle := s.generation.Bind(astCacheKey{pkgHandle.key, pgf.URI}, func(ctx context.Context, arg memoize.Arg) interface{} {
+	astHandle := s.generation.Bind(astCacheKey{pkgHandle.key, uri}, func(ctx context.Context, arg memoize.Arg) interface{} {
 		snapshot := arg.(*snapshot)
+		// The package may have been evicted from the cache and checked
+		// again since the handle was bound, with distinct syntax trees.
+		pkg, err := pkgHandle.check(ctx, snapshot)
+		if err != nil {
+			return &astCacheData{err: err}
+		}
+		pgf, err := pkg.File(uri)
+		if err != nil {
+			return &astCacheData{err: err}
+		}
 		return buildASTCache(ctx, snapshot, pgf)
 	}, nil)
+	astHandle.DependsOn(pkgHandle.handle)
 
 	d, err := astHandle.Get(ctx, s.generation, s)
 	if err != nil {
@@ -158,6 +169,12 @@ type astCacheData struct {
 	posToField map[token.Pos]*ast.Field
 }
 
+// Size implements memoize.Sizer, so that the data is evicted from the cache
+// before the package it is computed from.
+func (d *astCacheData) Size() int64 {
+	return 32 * int64(len(d.posToDecl)+len(d.posToField))
+}
+
 // buildASTCache builds caches to aid in quickly going from the typed
 // world to the syntactic world.
 func buildASTCache(ctx context.Context, snapshot *snapshot, pgf *source.ParsedGoFile) *astCacheData {
@@ -15,6 +15,7 @@ import (
 	"golang.org/x/tools/internal/gocommand"
 	"golang.org/x/tools/internal/imports"
 	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/memoize"
 	"golang.org/x/tools/internal/span"
 	"golang.org/x/tools/internal/xcontext"
 	errors "golang.org/x/xerrors"
@@ -146,6 +147,62 @@ func (s *Session) Cache() interface{} {
 	return s.cache
 }
 
+// ViewMemStats describes the memory used by the type-checked packages of
+// the current snapshot of a view.
+type ViewMemStats struct {
+	Name     string
+	Packages int   // the number of type-checked packages
+	Size     int64 // their estimated size, in bytes
+}
+
+// SessionMemStats describes the memory used by the type-checked packages of
+// the views of a session.
+type SessionMemStats struct {
+	Views []ViewMemStats
+	// Size is the estimated size of the packages of all views, in bytes.
+	// The packages shared by several views are counted once.
+	Size int64
+	// Shared is the number of packages shared by several views.
+	Shared int
+}
+
+// MemStats returns the memory statistics of the type-checked packages of
+// the views of the session, for debugging.
+func (s *Session) MemStats() SessionMemStats {
+	s.viewMu.Lock()
+	views := append([]*View(nil), s.views...)
+	s.viewMu.Unlock()
+
+	var stats SessionMemStats
+	viewsOf := make(map[*memoize.Handle]int)
+	for _, v := range views {
+		v.snapshotMu.Lock()
+		snapshot := v.snapshot
+		v.snapshotMu.Unlock()
+		if snapshot == nil {
+			continue
+		}
+		vs := ViewMemStats{Name: v.Name()}
+		snapshot.mu.Lock()
+		for _, ph := range snapshot.packages {
+			size := ph.handle.Size()
+			if size == 0 {
+				continue
+			}
+			vs.Packages++
+			vs.Size += size
+			if viewsOf[ph.handle]++; viewsOf[ph.handle] == 1 {
+				stats.Size += size
+			} else if viewsOf[ph.handle] == 2 {
+				stats.Shared++
+			}
+		}
+		snapshot.mu.Unlock()
+		stats.Views = append(stats.Views, vs)
+	}
+	return stats
+}
+
 func (s *Session) NewView(ctx context.Context, name string, folder, tempWorkspace span.URI, options *source.Options) (source.View, source.Snapshot, func(), error) {
 	s.viewMu.Lock()
 	defer s.viewMu.Unlock()
@@ -231,6 +288,7 @@ func (s *Session) createView(ctx context.Context, name string, folder, tempWorks
 		parseModHandles:   make(map[span.URI]*parseModHandle),
 		modTidyHandles:    make(map[span.URI]*modTidyHandle),
 		modWhyHandles:     make(map[span.URI]*modWhyHandle),
+		examples:          make(map[packagePath]*examplesHandle),
 		workspace:         workspace,
 	}
 
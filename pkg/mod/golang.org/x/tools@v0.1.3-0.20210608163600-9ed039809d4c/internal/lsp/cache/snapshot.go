@@ -109,8 +109,18 @@ type snapshot struct {
 	modTidyHandles map[span.URI]*modTidyHandle
 	modWhyHandles  map[span.URI]*modWhyHandle
 
+	// examples maps package paths to the handles of their examples.
+	examples map[packagePath]*examplesHandle
+
 	workspace          *workspace
 	workspaceDirHandle *memoize.Handle
+
+	// vulnDB is the vulnerability database read by the snapshot, or the one
+	// of the previous snapshot until vulnChecked, when the snapshot has
+	// checked the database for changes.
+	vulnMu      sync.Mutex
+	vulnDB      *vulnDB
+	vulnChecked bool
 }
 
 type packageKey struct {
@@ -909,8 +919,8 @@ func (s *snapshot) addActionHandle(ah *actionHandle) *actionHandle {
 	key := actionKey{
 		analyzer: ah.analyzer,
 		pkg: packageKey{
-			id:   ah.pkg.m.id,
-			mode: ah.pkg.mode,
+			id:   ah.ph.m.id,
+			mode: ah.ph.mode,
 		},
 	}
 	if ah, ok := s.actions[key]; ok {
@@ -1066,7 +1076,19 @@ func (s *snapshot) GetCriticalError(ctx context.Context) *source.CriticalError {
 	// Even if packages didn't fail to load, we still may want to show
 	// additional warnings.
 	if loadErr == nil {
-		wsPkgs, _ := s.WorkspacePackages(ctx)
+		// Only the workspace packages that may have missing dependencies
+		// are type-checked, so that the packages whose diagnostics are
+		// stored in the persistent cache need not be.
+		phs, _ := s.workspacePackageHandles(ctx)
+		var wsPkgs []source.Package
+		for _, ph := range phs {
+			if len(ph.m.missingDeps) == 0 {
+				continue
+			}
+			if pkg, err := ph.check(ctx, s); err == nil {
+				wsPkgs = append(wsPkgs, pkg)
+			}
+		}
 		if msg := shouldShowAdHocPackagesWarning(s, wsPkgs); msg != "" {
 			return &source.CriticalError{
 				MainError: errors.New(msg),
@@ -1076,7 +1098,7 @@ func (s *snapshot) GetCriticalError(ctx context.Context) *source.CriticalError {
 		// with the user's workspace layout. Workspace packages that only have the
 		// ID "command-line-arguments" are usually a symptom of a bad workspace
 		// configuration.
-		if containsCommandLineArguments(wsPkgs) {
+		if containsCommandLineArguments(phs) {
 			return s.workspaceLayoutError(ctx)
 		}
 		return nil
@@ -1104,9 +1126,9 @@ func shouldShowAdHocPackagesWarning(snapshot source.Snapshot, pkgs []source.Pack
 	return ""
 }
 
-func containsCommandLineArguments(pkgs []source.Package) bool {
-	for _, pkg := range pkgs {
-		if source.IsCommandLineArguments(pkg.ID()) {
+func containsCommandLineArguments(phs []*packageHandle) bool {
+	for _, ph := range phs {
+		if source.IsCommandLineArguments(ph.ID()) {
 			return true
 		}
 	}
@@ -1359,6 +1381,7 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 		parseModHandles:   make(map[span.URI]*parseModHandle, len(s.parseModHandles)),
 		modTidyHandles:    make(map[span.URI]*modTidyHandle, len(s.modTidyHandles)),
 		modWhyHandles:     make(map[span.URI]*modWhyHandle, len(s.modWhyHandles)),
+		examples:          make(map[packagePath]*examplesHandle, len(s.examples)),
 		workspace:         newWorkspace,
 	}
 
@@ -1367,6 +1390,10 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 		newGen.Inherit(s.workspaceDirHandle)
 	}
 
+	s.vulnMu.Lock()
+	result.vulnDB = s.vulnDB
+	s.vulnMu.Unlock()
+
 	// Copy all of the FileHandles.
 	for k, v := range s.files {
 		result.files[k] = v
@@ -1404,6 +1431,20 @@ func (s *snapshot) clone(ctx, bgCtx context.Context, changes map[span.URI]*fileC
 		result.modWhyHandles[k] = v
 	}
 
+	// Copy the handles of the examples whose files are unchanged. The
+	// handles of packages with new test files are replaced when they are
+	// used, as their keys differ.
+copyExamples:
+	for k, v := range s.examples {
+		for _, uri := range v.files {
+			if _, ok := changes[uri]; ok {
+				continue copyExamples
+			}
+		}
+		newGen.Inherit(v.handle)
+		result.examples[k] = v
+	}
+
 	// directIDs keeps track of package IDs that have directly changed.
 	// It maps id->invalidateMetadata.
 	directIDs := map[packageID]bool{}
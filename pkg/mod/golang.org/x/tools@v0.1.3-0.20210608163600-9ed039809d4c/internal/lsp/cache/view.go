@@ -913,6 +913,10 @@ func (v *View) IsGoPrivatePath(target string) bool {
 	return globsMatchPath(v.goprivate, target)
 }
 
+func (v *View) GoModCache() string {
+	return v.gomodcache
+}
+
 func (v *View) ModuleUpgrades() map[string]string {
 	v.mu.Lock()
 	defer v.mu.Unlock()
@@ -0,0 +1,91 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/source"
+	errors "golang.org/x/xerrors"
+)
+
+// vulnDB is a vulnerability database, as read by a snapshot. It is shared
+// by the following snapshots until its files change.
+type vulnDB struct {
+	dir     string
+	stamp   string // the sizes and modification times of the files
+	entries []*source.OSVEntry
+	err     error
+}
+
+func (s *snapshot) VulnDatabase(ctx context.Context) ([]*source.OSVEntry, error) {
+	dir := s.view.Options().VulnDatabase
+	if dir == "" {
+		return nil, nil
+	}
+	s.vulnMu.Lock()
+	defer s.vulnMu.Unlock()
+	if !s.vulnChecked {
+		s.vulnDB = loadVulnDB(ctx, dir, s.vulnDB)
+		s.vulnChecked = true
+	}
+	return s.vulnDB.entries, s.vulnDB.err
+}
+
+// loadVulnDB reads the database in dir, unless it is prev and its files
+// have not changed. A file that cannot be parsed is reported as an error,
+// but does not prevent the use of the others.
+func loadVulnDB(ctx context.Context, dir string, prev *vulnDB) *vulnDB {
+	var files []string
+	var stamp strings.Builder
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != dir {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".json") {
+			files = append(files, path)
+			fmt.Fprintf(&stamp, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+	if err != nil {
+		return &vulnDB{dir: dir, err: err}
+	}
+	if prev != nil && prev.dir == dir && prev.stamp != "" && prev.stamp == stamp.String() {
+		return prev
+	}
+
+	ctx, done := event.Start(ctx, "cache.loadVulnDB")
+	defer done()
+
+	db := &vulnDB{dir: dir, stamp: stamp.String()}
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return &vulnDB{dir: dir, err: ctx.Err()}
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return &vulnDB{dir: dir, err: err}
+		}
+		entries, err := source.ParseOSV(data)
+		if err != nil {
+			if db.err == nil {
+				db.err = errors.Errorf("parsing %s: %w", file, err)
+			}
+			continue
+		}
+		db.entries = append(db.entries, entries...)
+	}
+	return db
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const textVuln = `{
+	"id": "GO-2021-0113",
+	"affected": [{
+		"package": {"name": "golang.org/x/text", "ecosystem": "Go"},
+		"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.3.7"}]}]
+	}],
+	"database_specific": {"url": "https://pkg.go.dev/vuln/GO-2021-0113"}
+}`
+
+func TestLoadVulnDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vulndb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("golang.org/x/text.json", "["+textVuln+"]")
+	write("index.json", `{"golang.org/x/text": "2021-10-06T00:00:00Z"}`)
+	write("ID/GO-2021-0113.json", textVuln)
+	write(".git/objects.json", "not JSON")
+
+	ctx := context.Background()
+	db := loadVulnDB(ctx, dir, nil)
+	if db.err != nil {
+		t.Fatal(db.err)
+	}
+	if len(db.entries) != 2 || db.entries[0].ID != "GO-2021-0113" || db.entries[0].URL() != "https://pkg.go.dev/vuln/GO-2021-0113" {
+		t.Fatalf("loadVulnDB returned %d entries, want 2 copies of GO-2021-0113", len(db.entries))
+	}
+
+	// The database is only parsed again when its files change.
+	if again := loadVulnDB(ctx, dir, db); again != db {
+		t.Errorf("loadVulnDB parsed the unchanged database again")
+	}
+	write("ID/GO-2021-0113.json", "not JSON")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "ID", "GO-2021-0113.json"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed := loadVulnDB(ctx, dir, db)
+	if changed == db {
+		t.Fatalf("loadVulnDB did not parse the changed database")
+	}
+	if changed.err == nil || len(changed.entries) != 1 {
+		t.Errorf("loadVulnDB returned %d entries and error %v, want 1 entry and a parse error", len(changed.entries), changed.err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"golang.org/x/tools/internal/lsp/filecache"
+	"golang.org/x/tools/internal/tool"
+)
+
+// cacheCommand is a top-level command for managing the persistent cache, in
+// which gopls stores the diagnostics, export data and analysis facts of
+// packages across restarts.
+type cacheCommand struct {
+	subcommands
+}
+
+func newCacheCommand() *cacheCommand {
+	return &cacheCommand{
+		subcommands: subcommands{
+			&cleanCache{},
+			&cacheStats{},
+		},
+	}
+}
+
+func (c *cacheCommand) Name() string { return "cache" }
+func (c *cacheCommand) ShortHelp() string {
+	return "manage the persistent cache of gopls"
+}
+
+// diskCache returns the persistent cache in dir, or in the default directory
+// if dir is empty or "auto".
+func diskCache(dir string) (*filecache.Cache, error) {
+	if dir == "" || dir == "auto" {
+		var err error
+		if dir, err = filecache.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return filecache.New(dir), nil
+}
+
+// cleanCache removes the contents of the persistent cache.
+type cleanCache struct {
+	Dir string `flag:"dir" help:"the directory of the cache, if not the default one"`
+}
+
+func (c *cleanCache) Name() string  { return "clean" }
+func (c *cleanCache) Usage() string { return "" }
+func (c *cleanCache) ShortHelp() string {
+	return "remove the contents of the persistent cache"
+}
+
+func (c *cleanCache) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+The persistent cache is used by gopls serve when it is given the
+-cache.dir flag, and is shared by the gopls processes that use the same
+directory. It holds the diagnostics of workspace packages, the export
+data of type-checked packages and the analysis facts of analyzed
+packages, so that after a restart only the changed packages are
+type-checked. It is rebuilt as packages are diagnosed again.
+
+Example:
+
+  $ gopls cache clean
+
+gopls cache clean flags are:
+`)
+	f.PrintDefaults()
+}
+
+func (c *cleanCache) Run(ctx context.Context, args ...string) error {
+	if len(args) > 0 {
+		return tool.CommandLineErrorf("cache clean does not take arguments, got %v", args)
+	}
+	disk, err := diskCache(c.Dir)
+	if err != nil {
+		return err
+	}
+	return disk.Clean()
+}
+
+// cacheStats prints the number and size of the entries of the persistent
+// cache.
+type cacheStats struct {
+	Dir string `flag:"dir" help:"the directory of the cache, if not the default one"`
+}
+
+func (c *cacheStats) Name() string  { return "stats" }
+func (c *cacheStats) Usage() string { return "" }
+func (c *cacheStats) ShortHelp() string {
+	return "print statistics about the persistent cache"
+}
+
+func (c *cacheStats) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Example:
+
+  $ gopls cache stats
+
+gopls cache stats flags are:
+`)
+	f.PrintDefaults()
+}
+
+func (c *cacheStats) Run(ctx context.Context, args ...string) error {
+	if len(args) > 0 {
+		return tool.CommandLineErrorf("cache stats does not take arguments, got %v", args)
+	}
+	disk, err := diskCache(c.Dir)
+	if err != nil {
+		return err
+	}
+	stats, err := disk.Stats()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("directory: %s\n", disk.Dir())
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "kind\tentries\tbytes")
+	var entries int
+	var bytes int64
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", s.Kind, s.Entries, s.Bytes)
+		entries += s.Entries
+		bytes += s.Bytes
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\n", entries, bytes)
+	return w.Flush()
+}
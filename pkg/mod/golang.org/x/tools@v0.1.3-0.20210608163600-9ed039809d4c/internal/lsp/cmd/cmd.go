@@ -172,6 +172,7 @@ func (app *Application) mainCommands() []tool.Application {
 		&bug{},
 		&apiJSON{},
 		&licenses{app: app},
+		newCacheCommand(),
 	}
 }
 
@@ -185,6 +186,8 @@ func (app *Application) featureCommands() []tool.Application {
 		&highlight{app: app},
 		&implementation{app: app},
 		&imports{app: app},
+		&index{app: app},
+		&inlayHints{app: app},
 		newRemote(app, ""),
 		newRemote(app, "inspect"),
 		&links{app: app},
@@ -195,6 +198,7 @@ func (app *Application) featureCommands() []tool.Application {
 		&signature{app: app},
 		&suggestedFix{app: app},
 		&symbols{app: app},
+		&unused{app: app},
 		newWorkspace(app),
 		&workspaceSymbol{app: app},
 	}
@@ -410,6 +414,13 @@ func (c *cmdClient) Configuration(ctx context.Context, p *protocol.ParamConfigur
 				"undeclaredname": true,
 			},
 		}
+		// Only the inlay_hints verb asks for inlay hints, so they may as
+		// well all be enabled.
+		hints := map[string]bool{}
+		for name := range source.AllInlayHints() {
+			hints[name] = true
+		}
+		m["hints"] = hints
 		if c.app.VeryVerbose {
 			m["verboseOutput"] = true
 		}
@@ -447,6 +458,10 @@ func (c *cmdClient) ShowDocument(context.Context, *protocol.ShowDocumentParams)
 	return nil, nil
 }
 
+func (c *cmdClient) DiagnosticRefresh(context.Context) error {
+	return nil
+}
+
 func (c *cmdClient) WorkDoneProgressCreate(context.Context, *protocol.WorkDoneProgressCreateParams) error {
 	return nil
 }
@@ -0,0 +1,696 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/internal/gocommand"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	"golang.org/x/tools/internal/tool"
+)
+
+// index implements the index verb for gopls.
+type index struct {
+	Output string `flag:"o" help:"write the dump to this file instead of stdout"`
+
+	app *Application
+	// stderr receives the errors of the requests, os.Stderr by default.
+	stderr io.Writer
+}
+
+func (i *index) Name() string      { return "index" }
+func (i *index) Usage() string     { return "[<directory>]" }
+func (i *index) ShortHelp() string { return "dump an LSIF index of the workspace" }
+func (i *index) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Index walks the Go files of the directory, the working directory by default,
+and emits an LSIF dump of their definitions, references, hovers,
+implementations and monikers. Directories named testdata or vendor, and those
+starting with '.' or '_', are skipped.
+
+The definitions are those of the document symbols, and those of the
+parameters, results and local variables of functions. Requests that fail are
+reported on stderr and leave their result out of the dump; the documents
+whose symbols can't be listed are left out and counted.
+
+The monikers identify objects by package path and object path, so that dumps
+of different repositories can be linked together. References to objects of
+dependencies and of the standard library get import monikers, linked to the
+module path and version of the dependency, or to "std" and the Go version.
+
+Example:
+
+  $ gopls index -o dump.lsif
+
+	gopls index flags are:
+`)
+	f.PrintDefaults()
+}
+
+func (i *index) Run(ctx context.Context, args ...string) error {
+	if len(args) > 1 {
+		return tool.CommandLineErrorf("index expects at most 1 argument (directory)")
+	}
+	dir := i.app.wd
+	if len(args) == 1 {
+		var err error
+		if dir, err = filepath.Abs(args[0]); err != nil {
+			return err
+		}
+	}
+	files, err := goFiles(dir)
+	if err != nil {
+		return err
+	}
+	packages, err := listPackages(ctx, dir, i.app.env)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if i.Output != "" {
+		f, err := os.Create(i.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Definitions are found among the document symbols, including those
+	// nested in other symbols such as fields and interface methods.
+	options := i.app.options
+	i.app.options = func(o *source.Options) {
+		if options != nil {
+			options(o)
+		}
+		o.HierarchicalDocumentSymbolSupport = true
+	}
+	conn, err := i.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	stderr := i.stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	ix := &lsifIndexer{
+		conn:         conn,
+		enc:          json.NewEncoder(out),
+		stderr:       stderr,
+		packages:     packages,
+		documents:    make(map[protocol.DocumentURI]int),
+		ranges:       make(map[protocol.Location]int),
+		contains:     make(map[int][]int),
+		next:         make(map[int]int),
+		imported:     make(map[string]int),
+		packageInfos: make(map[lsifPackage]int),
+	}
+	return ix.index(ctx, protocol.URIFromPath(dir), files)
+}
+
+// goFiles returns the Go files in the tree rooted at dir.
+func goFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			base := filepath.Base(path)
+			if path != dir && (base == "testdata" || base == "vendor" || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// lsifPackage identifies the module of a package in packageInformation
+// vertices.
+type lsifPackage struct {
+	name, version string
+	main          bool // whether the package belongs to the indexed module
+}
+
+// listPackages returns the modules of the packages of the tree rooted at
+// dir and of their dependencies, by package path. Packages of the standard
+// library belong to the "std" module, versioned by the Go version.
+func listPackages(ctx context.Context, dir string, env []string) (map[string]lsifPackage, error) {
+	var runner gocommand.Runner
+	version, err := runner.Run(ctx, gocommand.Invocation{
+		Verb:       "version",
+		Env:        env,
+		WorkingDir: dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The output looks like "go version go1.16.5 linux/amd64".
+	var goVersion string
+	if fields := strings.Fields(version.String()); len(fields) > 2 {
+		goVersion = fields[2]
+	}
+	stdout, err := runner.Run(ctx, gocommand.Invocation{
+		Verb:       "list",
+		Args:       []string{"-e", "-deps", "-json", "./..."},
+		Env:        env,
+		WorkingDir: dir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]lsifPackage)
+	for dec := json.NewDecoder(stdout); dec.More(); {
+		var p struct {
+			ImportPath string
+			Standard   bool
+			Module     *struct {
+				Path, Version string
+				Main          bool
+			}
+		}
+		if err := dec.Decode(&p); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.Standard:
+			packages[p.ImportPath] = lsifPackage{name: "std", version: goVersion}
+		case p.Module != nil:
+			packages[p.ImportPath] = lsifPackage{name: p.Module.Path, version: p.Module.Version, main: p.Module.Main}
+		}
+	}
+	return packages, nil
+}
+
+// lsifIndexer writes the LSIF graph of a set of files, as a stream of JSON
+// vertices and edges.
+type lsifIndexer struct {
+	conn     *connection
+	enc      *json.Encoder
+	err      error
+	id       int
+	packages map[string]lsifPackage
+	stderr   io.Writer
+	// skipped counts the documents left out of the dump.
+	skipped int
+
+	// documents and ranges map documents and ranges to their vertex.
+	documents map[protocol.DocumentURI]int
+	docOrder  []int
+	ranges    map[protocol.Location]int
+	// contains maps each document to its ranges.
+	contains map[int][]int
+	// next maps ranges to their result set.
+	next map[int]int
+	// imported maps the monikers of objects outside of the indexed module
+	// to their result set.
+	imported map[string]int
+	// packageInfos maps packages to their packageInformation vertex.
+	packageInfos map[lsifPackage]int
+}
+
+// lsifSymbol is a definition found in a document symbol, or a local
+// definition found by localSymbols.
+type lsifSymbol struct {
+	loc       protocol.Location
+	kind      protocol.SymbolKind
+	resultSet int
+}
+
+func (ix *lsifIndexer) index(ctx context.Context, root protocol.DocumentURI, files []string) error {
+	ix.vertex("metaData", map[string]interface{}{
+		"version":          "0.4.3",
+		"projectRoot":      root,
+		"positionEncoding": "utf-16",
+		"toolInfo":         map[string]string{"name": "gopls"},
+	})
+	project := ix.vertex("project", map[string]interface{}{"kind": "go"})
+
+	// Bind all definitions to their result sets first, so that the
+	// references found later don't claim their ranges.
+	var (
+		symbols  []*lsifSymbol
+		cmdFiles []*cmdFile
+	)
+	for _, filename := range files {
+		uri := span.URIFromPath(filename)
+		file := ix.conn.AddFile(ctx, uri)
+		if file.err != nil {
+			return file.err
+		}
+		found, err := ix.documentSymbols(ctx, protocol.URIFromSpanURI(uri))
+		if err != nil {
+			ix.errorf("%s: listing symbols: %v", filename, err)
+			ix.skipped++
+			continue
+		}
+		cmdFiles = append(cmdFiles, file)
+		ix.document(protocol.URIFromSpanURI(uri))
+		found = append(found, ix.localSymbols(ctx, file)...)
+		for _, sym := range found {
+			rng := ix.rangeOf(sym.loc)
+			if _, ok := ix.next[rng]; ok {
+				continue
+			}
+			sym.resultSet = ix.vertex("resultSet", nil)
+			ix.edge("next", rng, sym.resultSet, nil)
+			ix.next[rng] = sym.resultSet
+			symbols = append(symbols, sym)
+		}
+	}
+	for _, sym := range symbols {
+		ix.symbol(ctx, sym)
+	}
+	// The remaining identifiers may refer to objects of other modules.
+	for _, file := range cmdFiles {
+		ix.imports(ctx, file)
+	}
+
+	ix.edge("contains", project, 0, map[string]interface{}{"inVs": ix.docOrder})
+	for _, doc := range ix.docOrder {
+		if rngs := ix.contains[doc]; len(rngs) > 0 {
+			ix.edge("contains", doc, 0, map[string]interface{}{"inVs": rngs})
+		}
+	}
+	if ix.skipped > 0 {
+		ix.errorf("skipped %d of %d documents", ix.skipped, len(files))
+	}
+	return ix.err
+}
+
+// errorf reports an error that leaves a result out of the dump.
+func (ix *lsifIndexer) errorf(format string, args ...interface{}) {
+	fmt.Fprintf(ix.stderr, "index: "+format+"\n", args...)
+}
+
+// documentSymbols returns the symbols of the document, including nested
+// ones.
+func (ix *lsifIndexer) documentSymbols(ctx context.Context, uri protocol.DocumentURI) ([]*lsifSymbol, error) {
+	symbols, err := ix.conn.DocumentSymbol(ctx, &protocol.DocumentSymbolParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []*lsifSymbol
+	var add func(s protocol.DocumentSymbol)
+	add = func(s protocol.DocumentSymbol) {
+		result = append(result, &lsifSymbol{
+			loc:  protocol.Location{URI: uri, Range: s.SelectionRange},
+			kind: s.Kind,
+		})
+		for _, c := range s.Children {
+			add(c)
+		}
+	}
+	for _, s := range symbols {
+		if m, ok := s.(map[string]interface{}); ok {
+			if s, err = mapToSymbol(m); err != nil {
+				return nil, err
+			}
+		}
+		if s, ok := s.(protocol.DocumentSymbol); ok {
+			add(s)
+		}
+	}
+	return result, nil
+}
+
+// localSymbols returns the definitions of the parameters, results and local
+// variables of the functions of file, which aren't document symbols.
+func (ix *lsifIndexer) localSymbols(ctx context.Context, file *cmdFile) []*lsifSymbol {
+	fset, f, err := parseCmdFile(file)
+	if err != nil {
+		ix.errorf("%v", err)
+		return nil
+	}
+	uri := protocol.URIFromSpanURI(file.uri)
+	var result []*lsifSymbol
+	// add adds id, which defines a symbol of the kind unless it is on the
+	// left of a := that redeclares it, as the definition request tells.
+	add := func(id *ast.Ident, kind protocol.SymbolKind, redeclares bool) {
+		if id.Name == "_" {
+			return
+		}
+		spn, err := span.NewRange(fset, id.Pos(), id.End()).Span()
+		if err != nil {
+			return
+		}
+		rng, err := file.mapper.Range(spn)
+		if err != nil {
+			return
+		}
+		loc := protocol.Location{URI: uri, Range: rng}
+		if redeclares {
+			defs, err := ix.conn.Definition(ctx, &protocol.DefinitionParams{
+				TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+					TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+					Position:     rng.Start,
+				},
+			})
+			if err != nil {
+				ix.errorf("%s: definition: %v", locString(loc), err)
+				return
+			}
+			if len(defs) != 1 || defs[0] != loc {
+				return
+			}
+		}
+		result = append(result, &lsifSymbol{loc: loc, kind: kind})
+	}
+	fields := func(list *ast.FieldList) {
+		if list == nil {
+			return
+		}
+		for _, field := range list.List {
+			for _, name := range field.Names {
+				add(name, protocol.Variable, false)
+			}
+		}
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			fields(n.Recv)
+		case *ast.FuncType:
+			fields(n.Params)
+			fields(n.Results)
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						add(id, protocol.Variable, len(n.Lhs) > 1)
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				for _, x := range []ast.Expr{n.Key, n.Value} {
+					if id, ok := x.(*ast.Ident); ok {
+						add(id, protocol.Variable, false)
+					}
+				}
+			}
+		case *ast.GenDecl:
+			kind := protocol.Variable
+			if n.Tok == token.CONST {
+				kind = protocol.Constant
+			}
+			for _, spec := range n.Specs {
+				if spec, ok := spec.(*ast.ValueSpec); ok {
+					for _, name := range spec.Names {
+						add(name, kind, false)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// symbol emits the results of the requests on the definition of sym.
+// A failing request is reported, and leaves its result out.
+func (ix *lsifIndexer) symbol(ctx context.Context, sym *lsifSymbol) {
+	pos := protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: sym.loc.URI},
+		Position:     sym.loc.Range.Start,
+	}
+
+	def := ix.vertex("definitionResult", nil)
+	ix.edge("textDocument/definition", sym.resultSet, def, nil)
+	ix.items(def, []protocol.Location{sym.loc}, "")
+
+	if hover, err := ix.conn.Hover(ctx, &protocol.HoverParams{TextDocumentPositionParams: pos}); err != nil {
+		ix.errorf("%s: hover: %v", locString(sym.loc), err)
+	} else if hover != nil {
+		result := ix.vertex("hoverResult", map[string]interface{}{
+			"result": map[string]interface{}{"contents": hover.Contents},
+		})
+		ix.edge("textDocument/hover", sym.resultSet, result, nil)
+	}
+
+	if monikers, err := ix.conn.Moniker(ctx, &protocol.MonikerParams{TextDocumentPositionParams: pos}); err != nil {
+		ix.errorf("%s: moniker: %v", locString(sym.loc), err)
+	} else {
+		for _, m := range monikers {
+			moniker := ix.vertex("moniker", map[string]interface{}{
+				"scheme":     m.Scheme,
+				"identifier": m.Identifier,
+				"unique":     m.Unique,
+				"kind":       m.Kind,
+			})
+			ix.edge("moniker", sym.resultSet, moniker, nil)
+			ix.packageInformation(moniker, m.Identifier)
+		}
+	}
+
+	refs, err := ix.conn.References(ctx, &protocol.ReferenceParams{TextDocumentPositionParams: pos})
+	if err != nil {
+		ix.errorf("%s: references: %v", locString(sym.loc), err)
+	} else if len(refs) > 0 {
+		result := ix.vertex("referenceResult", nil)
+		ix.edge("textDocument/references", sym.resultSet, result, nil)
+		ix.items(result, []protocol.Location{sym.loc}, "definitions")
+		ix.items(result, refs, "references")
+		for _, ref := range refs {
+			if rng := ix.rangeOf(ref); ix.next[rng] == 0 {
+				ix.edge("next", rng, sym.resultSet, nil)
+				ix.next[rng] = sym.resultSet
+			}
+		}
+	}
+
+	switch sym.kind {
+	case protocol.Interface, protocol.Struct, protocol.Class, protocol.Method:
+		impls, err := ix.conn.Implementation(ctx, &protocol.ImplementationParams{TextDocumentPositionParams: pos})
+		if err != nil {
+			ix.errorf("%s: implementation: %v", locString(sym.loc), err)
+		} else if len(impls) > 0 {
+			result := ix.vertex("implementationResult", nil)
+			ix.edge("textDocument/implementation", sym.resultSet, result, nil)
+			ix.items(result, impls, "")
+		}
+	}
+}
+
+// imports binds the identifiers of file that refer to objects outside of the
+// indexed module to a result set with the import moniker of the object.
+func (ix *lsifIndexer) imports(ctx context.Context, file *cmdFile) {
+	fset, f, err := parseCmdFile(file)
+	if err != nil {
+		ix.errorf("%v", err)
+		return
+	}
+	uri := protocol.URIFromSpanURI(file.uri)
+	ast.Inspect(f, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || id.Name == "_" {
+			return true
+		}
+		spn, err := span.NewRange(fset, id.Pos(), id.End()).Span()
+		if err != nil {
+			return true
+		}
+		rng, err := file.mapper.Range(spn)
+		if err != nil {
+			return true
+		}
+		loc := protocol.Location{URI: uri, Range: rng}
+		if rng, ok := ix.ranges[loc]; ok && ix.next[rng] != 0 {
+			return true
+		}
+		monikers, err := ix.conn.Moniker(ctx, &protocol.MonikerParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+				Position:     rng.Start,
+			},
+		})
+		if err != nil {
+			ix.errorf("%s: moniker: %v", locString(loc), err)
+			return true
+		}
+		for _, m := range monikers {
+			if m.Kind != protocol.Import {
+				continue
+			}
+			if pkg, ok := ix.packages[monikerPackage(m.Identifier)]; !ok || pkg.main {
+				continue
+			}
+			resultSet, ok := ix.imported[m.Identifier]
+			if !ok {
+				resultSet = ix.vertex("resultSet", nil)
+				moniker := ix.vertex("moniker", map[string]interface{}{
+					"scheme":     m.Scheme,
+					"identifier": m.Identifier,
+					"unique":     m.Unique,
+					"kind":       m.Kind,
+				})
+				ix.edge("moniker", resultSet, moniker, nil)
+				ix.packageInformation(moniker, m.Identifier)
+				ix.imported[m.Identifier] = resultSet
+			}
+			rng := ix.rangeOf(loc)
+			ix.edge("next", rng, resultSet, nil)
+			ix.next[rng] = resultSet
+			break
+		}
+		return true
+	})
+}
+
+// parseCmdFile parses the content of file.
+func parseCmdFile(file *cmdFile) (*token.FileSet, *ast.File, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file.uri.Filename(), file.mapper.Content, 0)
+	return fset, f, err
+}
+
+// locString formats loc for the errors, as file:line:column.
+func locString(loc protocol.Location) string {
+	return fmt.Sprintf("%s:%d:%d", loc.URI.SpanURI().Filename(), loc.Range.Start.Line+1, loc.Range.Start.Character+1)
+}
+
+// packageInformation links the moniker vertex to the packageInformation
+// vertex of the module of the package of identifier, emitting it if needed.
+func (ix *lsifIndexer) packageInformation(moniker int, identifier string) {
+	pkg, ok := ix.packages[monikerPackage(identifier)]
+	if !ok {
+		return
+	}
+	info, ok := ix.packageInfos[pkg]
+	if !ok {
+		info = ix.vertex("packageInformation", map[string]interface{}{
+			"name":    pkg.name,
+			"manager": "gomod",
+			"version": pkg.version,
+		})
+		ix.packageInfos[pkg] = info
+	}
+	ix.edge("packageInformation", moniker, info, nil)
+}
+
+// monikerPackage returns the package path of a moniker identifier.
+func monikerPackage(identifier string) string {
+	if i := strings.Index(identifier, ":"); i >= 0 {
+		return identifier[:i]
+	}
+	return identifier
+}
+
+// items emits the item edges from result to the ranges of locs, one per
+// document.
+func (ix *lsifIndexer) items(result int, locs []protocol.Location, property string) {
+	locs = append([]protocol.Location(nil), locs...)
+	sort.Slice(locs, func(i, j int) bool {
+		if locs[i].URI != locs[j].URI {
+			return locs[i].URI < locs[j].URI
+		}
+		return protocol.CompareRange(locs[i].Range, locs[j].Range) < 0
+	})
+	for len(locs) > 0 {
+		n := 1
+		for n < len(locs) && locs[n].URI == locs[0].URI {
+			n++
+		}
+		var rngs []int
+		for _, loc := range locs[:n] {
+			rngs = append(rngs, ix.rangeOf(loc))
+		}
+		fields := map[string]interface{}{
+			"inVs":     rngs,
+			"document": ix.document(locs[0].URI),
+		}
+		if property != "" {
+			fields["property"] = property
+		}
+		ix.edge("item", result, 0, fields)
+		locs = locs[n:]
+	}
+}
+
+// document returns the vertex of the document, emitting it if needed.
+func (ix *lsifIndexer) document(uri protocol.DocumentURI) int {
+	if id, ok := ix.documents[uri]; ok {
+		return id
+	}
+	id := ix.vertex("document", map[string]interface{}{
+		"uri":        uri,
+		"languageId": "go",
+	})
+	ix.documents[uri] = id
+	ix.docOrder = append(ix.docOrder, id)
+	return id
+}
+
+// rangeOf returns the vertex of the range at loc, emitting it if needed.
+func (ix *lsifIndexer) rangeOf(loc protocol.Location) int {
+	if id, ok := ix.ranges[loc]; ok {
+		return id
+	}
+	doc := ix.document(loc.URI)
+	id := ix.vertex("range", map[string]interface{}{
+		"start": loc.Range.Start,
+		"end":   loc.Range.End,
+	})
+	ix.ranges[loc] = id
+	ix.contains[doc] = append(ix.contains[doc], id)
+	return id
+}
+
+func (ix *lsifIndexer) vertex(label string, fields map[string]interface{}) int {
+	return ix.emit("vertex", label, fields)
+}
+
+// edge emits an edge from outV to inV, or to the vertices of the "inVs"
+// field if inV is 0.
+func (ix *lsifIndexer) edge(label string, outV, inV int, fields map[string]interface{}) int {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	fields["outV"] = outV
+	if inV != 0 {
+		fields["inV"] = inV
+	}
+	return ix.emit("edge", label, fields)
+}
+
+func (ix *lsifIndexer) emit(typ, label string, fields map[string]interface{}) int {
+	ix.id++
+	element := map[string]interface{}{
+		"id":    ix.id,
+		"type":  typ,
+		"label": label,
+	}
+	for k, v := range fields {
+		element[k] = v
+	}
+	if err := ix.enc.Encode(element); err != nil && ix.err == nil {
+		ix.err = err
+	}
+	return ix.id
+}
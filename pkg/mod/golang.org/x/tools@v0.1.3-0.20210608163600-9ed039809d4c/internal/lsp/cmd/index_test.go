@@ -0,0 +1,217 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestIndex(t *testing.T) {
+	testenv.NeedsGo1Point(t, 14)
+
+	files := map[string]string{
+		"dep/go.mod": "module example.com/dep\n\ngo 1.14\n",
+		"dep/dep.go": "package dep\n\nfunc Dep() {}\n",
+		"m/go.mod": `module example.com/m
+
+go 1.14
+
+require example.com/dep v1.2.3
+
+replace example.com/dep v1.2.3 => ../dep
+`,
+		"m/m.go": `package m
+
+import (
+	"fmt"
+
+	"example.com/dep"
+)
+
+func F() {
+	dep.Dep()
+	fmt.Println()
+}
+
+func G(n int) int {
+	x, err := n+1, error(nil)
+	y, err := x*2, err
+	return x + y
+}
+`,
+	}
+	dir, err := ioutil.TempDir("", "index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	root := filepath.Join(dir, "m")
+	output := filepath.Join(dir, "dump.lsif")
+	app := New("gopls-test", root, os.Environ(), nil)
+	var stderr bytes.Buffer
+	ix := &index{app: app, Output: output, stderr: &stderr}
+	if err := ix.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("index reported errors:\n%s", stderr.String())
+	}
+
+	// Read the dump back as a graph.
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	type element struct {
+		ID         int
+		Type       string
+		Label      string
+		OutV, InV  int
+		Identifier string
+		Kind       string
+		Name       string
+		Version    string
+		Start      protocol.Position
+	}
+	vertices := make(map[int]element)
+	var edges []element
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var e element
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Type == "vertex" {
+			vertices[e.ID] = e
+		} else {
+			edges = append(edges, e)
+		}
+	}
+	// out returns the vertices reached from id by an edge with the label.
+	out := func(id int, label string) []element {
+		var result []element
+		for _, e := range edges {
+			if e.OutV == id && e.Label == label && e.InV != 0 {
+				result = append(result, vertices[e.InV])
+			}
+		}
+		return result
+	}
+
+	for _, test := range []struct {
+		identifier, kind string
+		name, version    string              // of the packageInformation vertex
+		refs             []protocol.Position // the ranges bound to the moniker
+	}{
+		{"example.com/m:F", "export", "example.com/m", "", []protocol.Position{{Line: 8, Character: 5}}},
+		{"example.com/dep:Dep", "import", "example.com/dep", "v1.2.3", []protocol.Position{{Line: 9, Character: 5}}},
+		{"example.com/dep", "import", "example.com/dep", "v1.2.3", []protocol.Position{{Line: 9, Character: 1}}},
+		{"fmt:Println", "import", "std", "", []protocol.Position{{Line: 10, Character: 5}}},
+	} {
+		var moniker *element
+		for _, v := range vertices {
+			if v.Label == "moniker" && v.Identifier == test.identifier {
+				v := v
+				moniker = &v
+			}
+		}
+		if moniker == nil {
+			t.Errorf("no moniker %s", test.identifier)
+			continue
+		}
+		if moniker.Kind != test.kind {
+			t.Errorf("moniker %s has kind %s, want %s", test.identifier, moniker.Kind, test.kind)
+		}
+		infos := out(moniker.ID, "packageInformation")
+		if len(infos) != 1 {
+			t.Errorf("moniker %s has %d packageInformation vertices, want 1", test.identifier, len(infos))
+		} else if info := infos[0]; info.Name != test.name || (test.version != "" && info.Version != test.version) {
+			t.Errorf("moniker %s has package %s %s, want %s %s", test.identifier, info.Name, info.Version, test.name, test.version)
+		} else if test.name == "std" && info.Version == "" {
+			t.Errorf("moniker %s has no Go version", test.identifier)
+		}
+		var refs []protocol.Position
+		for _, e := range edges {
+			if e.Label != "moniker" || e.InV != moniker.ID {
+				continue
+			}
+			for _, n := range edges {
+				if n.Label == "next" && n.InV == e.OutV {
+					refs = append(refs, vertices[n.OutV].Start)
+				}
+			}
+		}
+		if len(refs) != len(test.refs) {
+			t.Errorf("moniker %s is bound to ranges at %v, want %v", test.identifier, refs, test.refs)
+			continue
+		}
+		for i := range refs {
+			if refs[i] != test.refs[i] {
+				t.Errorf("moniker %s is bound to ranges at %v, want %v", test.identifier, refs, test.refs)
+				break
+			}
+		}
+	}
+	// The parameters and locals are bound to the result set of their
+	// definition, which has references.
+	resultSet := func(pos protocol.Position) int {
+		for _, v := range vertices {
+			if v.Label == "range" && v.Start == pos {
+				for _, e := range edges {
+					if e.Label == "next" && e.OutV == v.ID {
+						return e.InV
+					}
+				}
+			}
+		}
+		return 0
+	}
+	for _, test := range []struct {
+		name string
+		def  protocol.Position
+		uses []protocol.Position
+	}{
+		{"n", protocol.Position{Line: 13, Character: 7}, []protocol.Position{{Line: 14, Character: 11}}},
+		{"x", protocol.Position{Line: 14, Character: 1}, []protocol.Position{{Line: 15, Character: 11}, {Line: 16, Character: 8}}},
+		{"y", protocol.Position{Line: 15, Character: 1}, []protocol.Position{{Line: 16, Character: 12}}},
+		// The second err redeclares the first.
+		{"err", protocol.Position{Line: 14, Character: 4}, []protocol.Position{{Line: 15, Character: 4}, {Line: 15, Character: 16}}},
+	} {
+		set := resultSet(test.def)
+		if set == 0 {
+			t.Errorf("definition of %s is not bound to a result set", test.name)
+			continue
+		}
+		if len(out(set, "textDocument/references")) != 1 {
+			t.Errorf("definition of %s has no references", test.name)
+		}
+		for _, use := range test.uses {
+			if got := resultSet(use); got != set {
+				t.Errorf("use of %s at %v is bound to %d, want %d", test.name, use, got, set)
+			}
+		}
+	}
+}
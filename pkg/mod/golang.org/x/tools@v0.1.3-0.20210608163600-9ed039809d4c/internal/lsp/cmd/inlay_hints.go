@@ -0,0 +1,75 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"golang.org/x/tools/internal/lsp/diff"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+	"golang.org/x/tools/internal/tool"
+	errors "golang.org/x/xerrors"
+)
+
+// inlayHints implements the inlay_hints verb for gopls.
+type inlayHints struct {
+	app *Application
+}
+
+func (r *inlayHints) Name() string      { return "inlay_hints" }
+func (r *inlayHints) Usage() string     { return "<file>" }
+func (r *inlayHints) ShortHelp() string { return "display selected file with its inlay hints" }
+func (r *inlayHints) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Prints the file with every inlay hint inserted as a comment.
+
+Example:
+
+  $ gopls inlay_hints helper/helper.go
+`)
+	f.PrintDefaults()
+}
+
+func (r *inlayHints) Run(ctx context.Context, args ...string) error {
+	if len(args) != 1 {
+		return tool.CommandLineErrorf("inlay_hints expects 1 argument (file)")
+	}
+
+	conn, err := r.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	from := span.Parse(args[0])
+	file := conn.AddFile(ctx, from.URI())
+	if file.err != nil {
+		return file.err
+	}
+
+	p := protocol.InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.URIFromSpanURI(from.URI()),
+		},
+	}
+	proposed, ok := conn.Server.(protocol.ProposedServer)
+	if !ok {
+		return errors.Errorf("the server does not provide inlay hints")
+	}
+	hints, err := proposed.InlayHint(ctx, &p)
+	if err != nil {
+		return err
+	}
+	sedits, err := source.FromProtocolEdits(file.mapper, source.InlayHintEdits(hints))
+	if err != nil {
+		return errors.Errorf("%v: %v", from, err)
+	}
+	fmt.Print(diff.ApplyEdits(string(file.mapper.Content), sedits))
+	return nil
+}
@@ -34,9 +34,14 @@ type Serve struct {
 	Trace       bool          `flag:"rpc.trace" help:"print the full rpc trace in lsp inspector format"`
 	Debug       string        `flag:"debug" help:"serve debug information on the supplied address"`
 
+	MemoryBudget int    `flag:"memory.budget" help:"the estimated number of megabytes that type-checked packages may use before the least recently used ones are evicted; 0 means no limit"`
+	CacheDir     string `flag:"cache.dir" help:"the directory of the persistent cache of diagnostics, export data and analysis facts, with which unchanged packages are not type-checked again after a restart. If value is \"auto\", the gopls directory in the user cache directory is used; the cache is disabled if empty"`
+
 	RemoteListenTimeout time.Duration `flag:"remote.listen.timeout" help:"when used with -remote=auto, the -listen.timeout value used to start the daemon"`
 	RemoteDebug         string        `flag:"remote.debug" help:"when used with -remote=auto, the -debug value used to start the daemon"`
 	RemoteLogfile       string        `flag:"remote.logfile" help:"when used with -remote=auto, the -logfile value used to start the daemon"`
+	RemoteMemoryBudget  int           `flag:"remote.memory.budget" help:"when used with -remote=auto, the -memory.budget value used to start the daemon"`
+	RemoteCacheDir      string        `flag:"remote.cache.dir" help:"when used with -remote=auto, the -cache.dir value used to start the daemon"`
 
 	app *Application
 }
@@ -82,9 +87,25 @@ func (s *Serve) Run(ctx context.Context, args ...string) error {
 			lsprpc.RemoteDebugAddress(s.RemoteDebug),
 			lsprpc.RemoteListenTimeout(s.RemoteListenTimeout),
 			lsprpc.RemoteLogfile(s.RemoteLogfile),
+			lsprpc.RemoteMemoryBudget(s.RemoteMemoryBudget),
+			lsprpc.RemoteCacheDir(s.RemoteCacheDir),
 		)
 	} else {
-		ss = lsprpc.NewStreamServer(cache.New(s.app.options), isDaemon)
+		c := cache.New(s.app.options)
+		c.SetMemoryBudget(int64(s.MemoryBudget) << 20)
+		if s.CacheDir != "" {
+			disk, err := diskCache(s.CacheDir)
+			if err != nil {
+				return err
+			}
+			c.SetDiskCache(disk)
+			go func() {
+				if err := disk.Trim(); err != nil {
+					log.Printf("trimming the persistent cache: %v", err)
+				}
+			}()
+		}
+		ss = lsprpc.NewStreamServer(c, isDaemon)
 	}
 
 	var network, addr string
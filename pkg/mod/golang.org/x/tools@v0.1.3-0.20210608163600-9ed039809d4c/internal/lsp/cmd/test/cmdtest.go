@@ -100,6 +100,22 @@ func (r *runner) FunctionExtraction(t *testing.T, start span.Span, end span.Span
 	//TODO: function extraction not supported on command line
 }
 
+func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span) {
+	//TODO: function extraction not supported on command line
+}
+
+func (r *runner) InlineCall(t *testing.T, start span.Span, end span.Span) {
+	//TODO: inlining not supported on command line
+}
+
+func (r *runner) ChangeSignature(t *testing.T, spn span.Span, title string) {
+	//TODO: changing signatures not supported on command line
+}
+
+func (r *runner) TypeHierarchy(t *testing.T, spn span.Span, expectedTypes *tests.TypeHierarchyResult) {
+	//TODO: type hierarchy not supported on command line
+}
+
 func (r *runner) AddImport(t *testing.T, uri span.URI, expectedImport string) {
 	//TODO: import addition not supported on command line
 }
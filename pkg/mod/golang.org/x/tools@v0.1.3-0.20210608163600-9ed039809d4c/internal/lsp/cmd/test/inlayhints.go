@@ -0,0 +1,26 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmdtest
+
+import (
+	"testing"
+
+	"golang.org/x/tools/internal/span"
+)
+
+func (r *runner) InlayHints(t *testing.T, spn span.Span) {
+	uri := spn.URI()
+	filename := uri.Filename()
+	got, stderr := r.NormalizeGoplsCmd(t, "inlay_hints", filename)
+	if stderr != "" {
+		t.Fatalf("%s: %q", filename, stderr)
+	}
+	want := string(r.data.Golden("inlayHint", filename, func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if want != got {
+		t.Errorf("inlay_hints failed for %s expected:\n%s\ngot:\n%s", filename, want, got)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/tools/internal/lsp/command"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/tool"
+	errors "golang.org/x/xerrors"
+)
+
+// unused implements the unused verb for gopls.
+type unused struct {
+	JSON bool `flag:"json" help:"emit the unused symbols in JSON format"`
+
+	app *Application
+}
+
+func (u *unused) Name() string      { return "unused" }
+func (u *unused) Usage() string     { return "" }
+func (u *unused) ShortHelp() string { return "list the unused exported symbols of the workspace" }
+func (u *unused) DetailedHelp(f *flag.FlagSet) {
+	fmt.Fprint(f.Output(), `
+Unused lists the exported constants, variables, functions, types, fields and
+methods of the workspace packages that are not referred to outside of their
+package.
+
+The symbols of main packages, those declared in test or generated files, the
+fields with a struct tag and the methods that implement a method of a known
+interface are not listed, as they may be used without a reference.
+
+Example:
+
+  $ gopls unused
+
+	gopls unused flags are:
+`)
+	f.PrintDefaults()
+}
+
+func (u *unused) Run(ctx context.Context, args ...string) error {
+	if len(args) != 0 {
+		return tool.CommandLineErrorf("unused expects no arguments")
+	}
+	conn, err := u.app.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.terminate(ctx)
+
+	cmd, err := command.NewUnusedExportsCommand("")
+	if err != nil {
+		return err
+	}
+	res, err := conn.ExecuteCommand(ctx, &protocol.ExecuteCommandParams{Command: cmd.Command, Arguments: cmd.Arguments})
+	if err != nil {
+		return errors.Errorf("executing server command: %v", err)
+	}
+	// The result is decoded from JSON when gopls runs remotely.
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	var result command.UnusedExportsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	if u.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		return enc.Encode(result.Symbols)
+	}
+	for _, sym := range result.Symbols {
+		f := conn.AddFile(ctx, fileURI(sym.Location.URI))
+		if f.err != nil {
+			return f.err
+		}
+		spn, err := f.mapper.Span(sym.Location)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v: %s %s.%s is unused outside its package\n", spn, sym.Kind, sym.Package, sym.Name)
+	}
+	return nil
+}
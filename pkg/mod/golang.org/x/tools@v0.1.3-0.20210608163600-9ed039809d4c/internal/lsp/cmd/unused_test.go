@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/command"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/testenv"
+)
+
+func TestUnusedExports(t *testing.T) {
+	testenv.NeedsGo1Point(t, 14)
+
+	files := map[string]string{
+		"go.mod": "module example.com/ue\n\ngo 1.14\n",
+		"a/a.go": `package a
+
+import "fmt"
+
+type T struct {
+	Used   int
+	Unused int
+	Tagged int ` + "`json:\"tagged\"`" + `
+}
+
+func (T) String() string { return fmt.Sprint("t") }
+
+func (T) Method() {}
+
+func (*T) Called() {}
+
+func New() *T { return nil }
+
+func Unused() {}
+
+func usedInside() { Unused() }
+`,
+		"a/a_test.go": "package a\n\nfunc Helper() {}\n",
+		"b/b.go": `package b
+
+import "example.com/ue/a"
+
+func F() int {
+	t := a.New()
+	t.Called()
+	return t.Used
+}
+`,
+		"main/main.go": "package main\n\nfunc Main() {}\n\nfunc main() {}\n",
+	}
+	dir := writeFiles(t, files)
+
+	ctx := context.Background()
+	app := New("gopls-test", dir, os.Environ(), nil)
+	conn, err := app.connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.terminate(ctx)
+	got := unusedExports(t, ctx, conn)
+	want := []string{
+		"field example.com/ue/a.T.Unused",
+		"method example.com/ue/a.T.Method",
+		"func example.com/ue/a.Unused",
+		"func example.com/ue/b.F",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unused exports:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestUnusedExportsAcrossViews(t *testing.T) {
+	testenv.NeedsGo1Point(t, 14)
+
+	// Module b uses module a, which is in another view.
+	dir := writeFiles(t, map[string]string{
+		"a/go.mod": "module example.com/a\n\ngo 1.14\n",
+		"a/a.go":   "package a\n\nfunc Used() {}\n\nfunc Unused() {}\n",
+		"b/go.mod": `module example.com/b
+
+go 1.14
+
+require example.com/a v0.0.0
+
+replace example.com/a => ../a
+`,
+		"b/main.go": "package main\n\nimport \"example.com/a\"\n\nfunc main() { a.Used() }\n",
+	})
+
+	ctx := context.Background()
+	app := New("gopls-test", filepath.Join(dir, "a"), os.Environ(), nil)
+	conn, err := app.connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.terminate(ctx)
+	if err := conn.DidChangeWorkspaceFolders(ctx, &protocol.DidChangeWorkspaceFoldersParams{
+		Event: protocol.WorkspaceFoldersChangeEvent{
+			Added: []protocol.WorkspaceFolder{{
+				URI:  string(protocol.URIFromPath(filepath.Join(dir, "b"))),
+				Name: "b",
+			}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got := unusedExports(t, ctx, conn)
+	want := []string{"func example.com/a.Unused"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unused exports:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// unusedExports runs the unused exports command, and returns the symbols
+// it reports as "kind package.Name".
+func unusedExports(t *testing.T, ctx context.Context, conn *connection) []string {
+	cmd, err := command.NewUnusedExportsCommand("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := conn.ExecuteCommand(ctx, &protocol.ExecuteCommandParams{Command: cmd.Command, Arguments: cmd.Arguments})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, sym := range res.(command.UnusedExportsResult).Symbols {
+		got = append(got, sym.Kind+" "+sym.Package+"."+sym.Name)
+	}
+	return got
+}
+
+// writeFiles writes files, by slash-separated path, to a temporary
+// directory removed at the end of the test, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "gopls-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -191,6 +191,30 @@ func (s *Server) codeAction(ctx context.Context, params *protocol.CodeActionPara
 			codeActions = append(codeActions, fixes...)
 		}
 
+		if wanted[protocol.RefactorInline] {
+			fixes, err := inlineFixes(ctx, snapshot, uri, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
+		if wanted[protocol.RefactorRewrite] || wanted[protocol.QuickFix] {
+			fixes, err := changeSignatureFixes(ctx, snapshot, uri, params.Range, diagnostics)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
+		if wanted[protocol.RefactorRewrite] {
+			fixes, err := structTagFixes(ctx, snapshot, uri, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			codeActions = append(codeActions, fixes...)
+		}
+
 		if wanted[protocol.GoTest] {
 			fixes, err := goTest(ctx, snapshot, uri, params.Range)
 			if err != nil {
@@ -289,7 +313,7 @@ func extractionFixes(ctx context.Context, snapshot source.Snapshot, pkg source.P
 	}
 	puri := protocol.URIFromSpanURI(uri)
 	var commands []protocol.Command
-	if _, ok, _ := source.CanExtractFunction(snapshot.FileSet(), srng, pgf.Src, pgf.File); ok {
+	if _, ok, methodOk, _ := source.CanExtractFunction(snapshot.FileSet(), srng, pgf.Src, pgf.File); ok {
 		cmd, err := command.NewApplyFixCommand("Extract to function", command.ApplyFixArgs{
 			URI:   puri,
 			Fix:   source.ExtractFunction,
@@ -299,6 +323,17 @@ func extractionFixes(ctx context.Context, snapshot source.Snapshot, pkg source.P
 			return nil, err
 		}
 		commands = append(commands, cmd)
+		if methodOk {
+			cmd, err := command.NewApplyFixCommand("Extract to method", command.ApplyFixArgs{
+				URI:   puri,
+				Fix:   source.ExtractMethod,
+				Range: rng,
+			})
+			if err != nil {
+				return nil, err
+			}
+			commands = append(commands, cmd)
+		}
 	}
 	if _, _, ok, _ := source.CanExtractVariable(srng, pgf.File); ok {
 		cmd, err := command.NewApplyFixCommand("Extract variable", command.ApplyFixArgs{
@@ -311,17 +346,284 @@ func extractionFixes(ctx context.Context, snapshot source.Snapshot, pkg source.P
 		}
 		commands = append(commands, cmd)
 	}
+	if source.CanExtractToNewFile(pgf.File, srng) {
+		cmd, err := command.NewExtractToNewFileCommand("Extract declarations to new file", command.ExtractToNewFileArgs{
+			URI:   puri,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
 	var actions []protocol.CodeAction
-	for _, cmd := range commands {
+	for i := range commands {
 		actions = append(actions, protocol.CodeAction{
-			Title:   cmd.Title,
+			Title:   commands[i].Title,
 			Kind:    protocol.RefactorExtract,
+			Command: &commands[i],
+		})
+	}
+	return actions, nil
+}
+
+func inlineFixes(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pkg, pgf, err := source.GetParsedFile(ctx, snapshot, fh, source.NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for Identifier: %w", err)
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	if !source.CanInlineCall(snapshot.FileSet(), srng, pgf.File, pkg) {
+		return nil, nil
+	}
+	cmd, err := command.NewApplyFixCommand("Inline call", command.ApplyFixArgs{
+		URI:   protocol.URIFromSpanURI(uri),
+		Fix:   source.InlineCall,
+		Range: rng,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.CodeAction{{
+		Title:   cmd.Title,
+		Kind:    protocol.RefactorInline,
+		Command: &cmd,
+	}}, nil
+}
+
+// changeSignatureFixes returns the actions that remove or move the parameter
+// at the start of rng, and that remove the parameters reported as unused by
+// the given unusedparams diagnostics.
+func changeSignatureFixes(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range, diagnostics []protocol.Diagnostic) ([]protocol.CodeAction, error) {
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	_, pgf, err := source.GetParsedFile(ctx, snapshot, fh, source.NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for Identifier: %w", err)
+	}
+	// paramAt returns the position of the name of the function with a
+	// parameter at pos, and the old indexes of its parameters.
+	paramAt := func(pos protocol.Position) (protocol.Position, []int, int, bool, bool) {
+		srng, err := pgf.Mapper.RangeToSpanRange(protocol.Range{Start: pos, End: pos})
+		if err != nil {
+			return protocol.Position{}, nil, 0, false, false
+		}
+		name, index, count, variadic := source.SelectedParam(pgf.File, srng.Start)
+		if name == nil {
+			return protocol.Position{}, nil, 0, false, false
+		}
+		nameRng, err := source.NewMappedRange(snapshot.FileSet(), pgf.Mapper, name.Pos(), name.End()).Range()
+		if err != nil {
+			return protocol.Position{}, nil, 0, false, false
+		}
+		order := make([]int, count)
+		for i := range order {
+			order[i] = i
+		}
+		return nameRng.Start, order, index, variadic, true
+	}
+	signature := func(order []int) []command.SignatureParam {
+		params := make([]command.SignatureParam, len(order))
+		for i, j := range order {
+			params[i] = command.SignatureParam{OldIndex: j}
+		}
+		return params
+	}
+	without := func(order []int, index int) []command.SignatureParam {
+		return signature(append(order[:index:index], order[index+1:]...))
+	}
+	swap := func(order []int, i int) []command.SignatureParam {
+		order = append([]int(nil), order...)
+		order[i], order[i+1] = order[i+1], order[i]
+		return signature(order)
+	}
+	var actions []protocol.CodeAction
+	add := func(title string, kind protocol.CodeActionKind, pos protocol.Position, params []command.SignatureParam, diag *protocol.Diagnostic) error {
+		cmd, err := command.NewChangeSignatureCommand(title, command.ChangeSignatureArgs{
+			URI:      protocol.URIFromSpanURI(uri),
+			Position: pos,
+			Params:   params,
+		})
+		if err != nil {
+			return err
+		}
+		action := protocol.CodeAction{
+			Title:   cmd.Title,
+			Kind:    kind,
+			Command: &cmd,
+		}
+		if diag != nil {
+			action.Diagnostics = []protocol.Diagnostic{*diag}
+		}
+		actions = append(actions, action)
+		return nil
+	}
+	for i, d := range diagnostics {
+		if d.Source != "unusedparams" {
+			continue
+		}
+		pos, order, index, _, ok := paramAt(d.Range.Start)
+		if !ok {
+			continue
+		}
+		if err := add("Remove unused parameter", protocol.QuickFix, pos, without(order, index), &diagnostics[i]); err != nil {
+			return nil, err
+		}
+	}
+	pos, order, index, variadic, ok := paramAt(rng.Start)
+	if !ok {
+		return actions, nil
+	}
+	if err := add("Remove parameter", protocol.RefactorRewrite, pos, without(order, index), nil); err != nil {
+		return nil, err
+	}
+	// The variadic parameter must remain last.
+	lastMovable := len(order) - 1
+	if variadic {
+		lastMovable--
+	}
+	if index > 0 && index <= lastMovable {
+		if err := add("Move parameter left", protocol.RefactorRewrite, pos, swap(order, index-1), nil); err != nil {
+			return nil, err
+		}
+	}
+	if index < lastMovable {
+		if err := add("Move parameter right", protocol.RefactorRewrite, pos, swap(order, index), nil); err != nil {
+			return nil, err
+		}
+	}
+	return actions, nil
+}
+
+// structTagFixes returns the code actions adding or removing the tags of the
+// fields of the struct type at rng.
+func structTagFixes(ctx context.Context, snapshot source.Snapshot, uri span.URI, rng protocol.Range) ([]protocol.CodeAction, error) {
+	fh, err := snapshot.GetFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, source.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return nil, err
+	}
+	// missing are the known keys that a taggable field lacks, and present
+	// the keys of the tags.
+	missing := make(map[string]bool)
+	present := make(map[string]bool)
+	var malformed bool
+	for _, f := range source.StructFieldsAt(pgf.File, srng) {
+		keys := source.StructTagKeysOf(f)
+		if f.Tag != nil && len(keys) == 0 {
+			// The tag is empty or malformed.
+			malformed = true
+		}
+		has := make(map[string]bool)
+		for _, key := range keys {
+			has[key] = true
+			present[key] = true
+		}
+		if source.IsTaggableField(f) {
+			for _, key := range source.StructTagKeys {
+				if !has[key.Name] {
+					missing[key.Name] = true
+				}
+			}
+		}
+	}
+	var actions []protocol.CodeAction
+	add := func(title string, args command.ModifyTagsArgs) error {
+		args.URI = protocol.URIFromSpanURI(uri)
+		args.Range = rng
+		cmd, err := command.NewModifyTagsCommand(title, args)
+		if err != nil {
+			return err
+		}
+		actions = append(actions, protocol.CodeAction{
+			Title:   cmd.Title,
+			Kind:    protocol.RefactorRewrite,
 			Command: &cmd,
 		})
+		return nil
+	}
+	for _, key := range source.StructTagKeys {
+		// The value of the keys without a name can not be derived from
+		// the field.
+		if key.NoName || !missing[key.Name] {
+			continue
+		}
+		// The case of the key is offered first, then the other cases.
+		cases := []tagCase{caseOf(key.Case)}
+		for _, c := range tagCases {
+			if c.transform != key.Case {
+				cases = append(cases, c)
+			}
+		}
+		for _, c := range cases {
+			if err := add(fmt.Sprintf("Add %s tags (%s)", key.Name, c.name), command.ModifyTagsArgs{
+				Add:       []string{key.Name},
+				Transform: c.transform,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var known int
+	for _, key := range source.StructTagKeys {
+		if !present[key.Name] {
+			continue
+		}
+		known++
+		if err := add(fmt.Sprintf("Remove %s tags", key.Name), command.ModifyTagsArgs{Remove: []string{key.Name}}); err != nil {
+			return nil, err
+		}
+	}
+	// Removing all the tags is only offered when it differs from removing
+	// a single key.
+	if malformed || len(present) > 1 || len(present) > known {
+		if err := add("Remove all struct tags", command.ModifyTagsArgs{Clear: true}); err != nil {
+			return nil, err
+		}
 	}
 	return actions, nil
 }
 
+// A tagCase is a case transformation of the values of struct tags, with
+// its name shown in the titles of the code actions.
+type tagCase struct {
+	transform, name string
+}
+
+var tagCases = []tagCase{
+	{source.CamelCase, "camelCase"},
+	{source.PascalCase, "PascalCase"},
+	{source.SnakeCase, "snake_case"},
+	{source.LispCase, "lisp-case"},
+	{source.KeepCase, "field name"},
+}
+
+// caseOf returns the case transformation named transform.
+func caseOf(transform string) tagCase {
+	for _, c := range tagCases {
+		if c.transform == transform {
+			return c
+		}
+	}
+	return tagCase{transform, transform}
+}
+
 func documentChanges(fh source.VersionedFileHandle, edits []protocol.TextEdit) []protocol.TextDocumentEdit {
 	return []protocol.TextDocumentEdit{
 		{
@@ -0,0 +1,216 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/lsp/tests"
+	"golang.org/x/tools/internal/span"
+)
+
+func TestStructTagActions(t *testing.T) {
+	const module = `
+-- go.mod --
+module example.com
+
+go 1.16
+-- a/a.go --
+package a
+
+type T struct {
+	ID       int
+	UserName string ` + "`xml:\"user\"`" + `
+	a, b     int
+}
+
+type U struct {
+	ID int ` + "`json:\"id\" db:\"id\" yaml:\"id\" xml:\"id\"`" + `
+}
+`
+	dir := writeTestModule(t, module)
+	ctx := tests.Context(t)
+	client := &editClient{applied: true}
+	s := newTestServer(t, ctx, dir, client)
+	uri := span.URIFromPath(filepath.Join(dir, "a", "a.go"))
+	content, err := ioutil.ReadFile(uri.Filename())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The add actions of the fields of T: one per case of each missing key,
+	// the case of the key first.
+	var titlesOfT []string
+	for _, key := range []struct{ name, first string }{
+		{"json", "camelCase"},
+		{"yaml", "camelCase"},
+		{"xml", "camelCase"},
+		{"db", "snake_case"},
+	} {
+		titlesOfT = append(titlesOfT, "Add "+key.name+" tags ("+key.first+")")
+		for _, c := range []string{"camelCase", "PascalCase", "snake_case", "lisp-case", "field name"} {
+			if c != key.first {
+				titlesOfT = append(titlesOfT, "Add "+key.name+" tags ("+c+")")
+			}
+		}
+	}
+	titlesOfT = append(titlesOfT, "Remove xml tags")
+
+	for _, test := range []struct {
+		desc   string
+		line   uint32 // of the position in the struct type
+		titles []string
+		run    string // the title of the action to run
+		want   string // the struct type after running it
+	}{
+		{
+			desc:   "add db tags",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add db tags (snake_case)",
+			want: `type T struct {
+	ID       int    ` + "`db:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" db:\"user_name\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add yaml tags",
+			line:   4,
+			titles: titlesOfT,
+			run:    "Add yaml tags (camelCase)",
+			want: `type T struct {
+	ID       int    ` + "`yaml:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" yaml:\"userName\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add json tags in PascalCase",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add json tags (PascalCase)",
+			want: `type T struct {
+	ID       int    ` + "`json:\"ID\"`" + `
+	UserName string ` + "`xml:\"user\" json:\"UserName\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add json tags in snake_case",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add json tags (snake_case)",
+			want: `type T struct {
+	ID       int    ` + "`json:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" json:\"user_name\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add json tags in lisp-case",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add json tags (lisp-case)",
+			want: `type T struct {
+	ID       int    ` + "`json:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" json:\"user-name\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add json tags with the field names",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add json tags (field name)",
+			want: `type T struct {
+	ID       int    ` + "`json:\"ID\"`" + `
+	UserName string ` + "`xml:\"user\" json:\"UserName\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc:   "add db tags in camelCase",
+			line:   3,
+			titles: titlesOfT,
+			run:    "Add db tags (camelCase)",
+			want: `type T struct {
+	ID       int    ` + "`db:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" db:\"userName\"`" + `
+	a, b     int
+}`,
+		},
+		{
+			desc: "remove all tags",
+			line: 9,
+			titles: []string{
+				"Remove json tags",
+				"Remove yaml tags",
+				"Remove xml tags",
+				"Remove db tags",
+				"Remove all struct tags",
+			},
+			run: "Remove all struct tags",
+			want: `type U struct {
+	ID int
+}`,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			pos := protocol.Position{Line: test.line, Character: 1}
+			actions, err := s.codeAction(ctx, &protocol.CodeActionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromSpanURI(uri)},
+				Range:        protocol.Range{Start: pos, End: pos},
+				Context:      protocol.CodeActionContext{Only: []protocol.CodeActionKind{protocol.RefactorRewrite}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var titles []string
+			var run *protocol.Command
+			for _, a := range actions {
+				titles = append(titles, a.Title)
+				if a.Title == test.run {
+					run = a.Command
+				}
+			}
+			if strings.Join(titles, "\n") != strings.Join(test.titles, "\n") {
+				t.Errorf("got actions %q, want %q", titles, test.titles)
+			}
+			if run == nil {
+				t.Fatalf("no action %q", test.run)
+			}
+
+			client.edits = nil
+			if _, err := s.executeCommand(ctx, &protocol.ExecuteCommandParams{
+				Command:   run.Command,
+				Arguments: run.Arguments,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if len(client.edits) != 1 || len(client.edits[0].DocumentChanges) != 1 {
+				t.Fatalf("got edits %v, want one edit of a.go", client.edits)
+			}
+			m := &protocol.ColumnMapper{
+				URI:       uri,
+				Converter: span.NewContentConverter(uri.Filename(), content),
+				Content:   content,
+			}
+			edits, err := source.FromProtocolEdits(m, client.edits[0].DocumentChanges[0].Edits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := applyEdits(string(content), edits)
+			if !strings.Contains(got, test.want+"\n") {
+				t.Errorf("after %q, got:\n%s\nwant:\n%s", test.run, got, test.want)
+			}
+		})
+	}
+}
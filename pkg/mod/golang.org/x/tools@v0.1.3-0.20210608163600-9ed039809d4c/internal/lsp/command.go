@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"golang.org/x/mod/modfile"
@@ -147,6 +148,113 @@ func (c *commandHandler) ApplyFix(ctx context.Context, args command.ApplyFixArgs
 	})
 }
 
+func (c *commandHandler) ChangeSignature(ctx context.Context, args command.ChangeSignatureArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		edits, err := source.ChangeSignature(ctx, deps.snapshot, deps.fh, args.Position, args.Params)
+		if err != nil {
+			return err
+		}
+		var docChanges []protocol.TextDocumentEdit
+		for uri, e := range edits {
+			fh, err := deps.snapshot.GetVersionedFile(ctx, uri)
+			if err != nil {
+				return err
+			}
+			docChanges = append(docChanges, documentChanges(fh, e)...)
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: docChanges,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
+// ExtractToNewFile writes the new file to disk before removing the
+// declarations from the original file, since the edits of a WorkspaceEdit
+// can not create files.
+func (c *commandHandler) ExtractToNewFile(ctx context.Context, args command.ExtractToNewFileArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		uri, content, edits, err := source.ExtractToNewFile(ctx, deps.snapshot, deps.fh, args.Range)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(uri.Filename(), content, 0644); err != nil {
+			return errors.Errorf("writing %s: %w", uri.Filename(), err)
+		}
+		if err := c.s.didModifyFiles(ctx, []source.FileModification{{
+			URI:    uri,
+			Action: source.Create,
+			OnDisk: true,
+		}}, FromDidChangeWatchedFiles); err != nil {
+			return err
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: edits,
+			},
+		})
+		if err == nil && !r.Applied {
+			err = errors.New(r.FailureReason)
+		}
+		if err != nil {
+			// The declarations are still in the source file, remove their
+			// copy so that the package keeps compiling.
+			if rmErr := os.Remove(uri.Filename()); rmErr != nil {
+				event.Error(ctx, "removing extracted file", rmErr)
+			}
+			if modErr := c.s.didModifyFiles(ctx, []source.FileModification{{
+				URI:    uri,
+				Action: source.Delete,
+				OnDisk: true,
+			}}, FromDidChangeWatchedFiles); modErr != nil {
+				event.Error(ctx, "removing extracted file", modErr)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (c *commandHandler) ModifyTags(ctx context.Context, args command.ModifyTagsArgs) error {
+	return c.run(ctx, commandConfig{
+		forURI: args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		edits, err := source.ModifyTags(ctx, deps.snapshot, deps.fh, args.Range, source.TagModification{
+			Add:       args.Add,
+			Transform: args.Transform,
+			Remove:    args.Remove,
+			Clear:     args.Clear,
+		})
+		if err != nil {
+			return err
+		}
+		r, err := c.s.client.ApplyEdit(ctx, &protocol.ApplyWorkspaceEditParams{
+			Edit: protocol.WorkspaceEdit{
+				DocumentChanges: documentChanges(deps.fh, edits),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if !r.Applied {
+			return errors.New(r.FailureReason)
+		}
+		return nil
+	})
+}
+
 func (c *commandHandler) RegenerateCgo(ctx context.Context, args command.URIArg) error {
 	return c.run(ctx, commandConfig{
 		progress: "Regenerating Cgo",
@@ -364,14 +472,15 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot source.Snapshot,
 	// create output
 	buf := &bytes.Buffer{}
 	ew := &eventWriter{ctx: ctx, operation: "test"}
-	out := io.MultiWriter(ew, workDoneWriter{work}, buf)
+	out := io.MultiWriter(ew, &testResultWriter{wd: work}, buf)
 
-	// Run `go test -run Func` on each test.
+	// Run `go test -run Func` on each test, or `go test -run Func/Sub` on
+	// each subtest.
 	var failedTests int
 	for _, funcName := range tests {
 		inv := &gocommand.Invocation{
 			Verb:       "test",
-			Args:       []string{pkgPath, "-v", "-count=1", "-run", fmt.Sprintf("^%s$", funcName)},
+			Args:       []string{pkgPath, "-v", "-count=1", "-run", testRunRegexp(funcName)},
 			WorkingDir: filepath.Dir(uri.SpanURI().Filename()),
 		}
 		if err := snapshot.RunGoCommandPiped(ctx, source.Normal, inv, out, out); err != nil {
@@ -426,6 +535,50 @@ func (c *commandHandler) runTests(ctx context.Context, snapshot source.Snapshot,
 	})
 }
 
+// testRunRegexp returns the -run regexp of go test that matches exactly
+// the test or subtest name, e.g. TestFoo/bar_baz, as printed by go test.
+func testRunRegexp(name string) string {
+	// go test matches each element of the slash-separated name of a
+	// subtest against the corresponding element of the regexp. It names
+	// a subtest with an empty name #00.
+	elems := strings.Split(name, "/")
+	for i, elem := range elems {
+		if i > 0 && elem == "" {
+			elem = "#00"
+		}
+		elems[i] = "^" + regexp.QuoteMeta(elem) + "$"
+	}
+	return strings.Join(elems, "/")
+}
+
+var (
+	testResultRe = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	testResults  = map[string]string{"PASS": "passed", "FAIL": "failed", "SKIP": "skipped"}
+)
+
+// testResultWriter reports the result of each test and subtest in the
+// verbose output of go test as a progress notification.
+type testResultWriter struct {
+	wd   *workDone
+	line []byte
+}
+
+func (w *testResultWriter) Write(p []byte) (n int, err error) {
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		if m := testResultRe.FindSubmatch(w.line[:i]); m != nil {
+			w.wd.report(fmt.Sprintf("%s %s", m[2], testResults[string(m[1])]), 0)
+		}
+		w.line = w.line[i+1:]
+	}
+	// Don't fail just because of a failure to report progress.
+	return len(p), nil
+}
+
 func (c *commandHandler) Generate(ctx context.Context, args command.GenerateArgs) error {
 	title := "Running go generate ."
 	if args.Recursive {
@@ -706,6 +859,36 @@ func (c *commandHandler) WorkspaceMetadata(ctx context.Context) (command.Workspa
 	return result, nil
 }
 
+func (c *commandHandler) UnusedExports(ctx context.Context) (command.UnusedExportsResult, error) {
+	var result command.UnusedExportsResult
+	err := c.run(ctx, commandConfig{
+		progress: "Finding unused exported symbols",
+	}, func(ctx context.Context, deps commandDeps) error {
+		// Exports may be used from other views, so all views are
+		// considered at once.
+		var snapshots []source.Snapshot
+		for _, view := range c.s.session.Views() {
+			snapshot, release := view.Snapshot(ctx)
+			defer release()
+			snapshots = append(snapshots, snapshot)
+		}
+		unused, err := source.UnusedExports(ctx, snapshots)
+		if err != nil {
+			return err
+		}
+		for _, u := range unused {
+			result.Symbols = append(result.Symbols, command.UnusedExport{
+				Package:  u.Package,
+				Name:     u.Name,
+				Kind:     u.Kind,
+				Location: u.Location,
+			})
+		}
+		return nil
+	})
+	return result, err
+}
+
 func (c *commandHandler) StartDebugging(ctx context.Context, args command.DebuggingArgs) (result command.DebuggingResult, _ error) {
 	addr := args.Addr
 	if addr == "" {
@@ -22,12 +22,15 @@ const (
 	AddDependency     Command = "add_dependency"
 	AddImport         Command = "add_import"
 	ApplyFix          Command = "apply_fix"
+	ChangeSignature   Command = "change_signature"
 	CheckUpgrades     Command = "check_upgrades"
+	ExtractToNewFile  Command = "extract_to_new_file"
 	GCDetails         Command = "gc_details"
 	Generate          Command = "generate"
 	GenerateGoplsMod  Command = "generate_gopls_mod"
 	GoGetPackage      Command = "go_get_package"
 	ListKnownPackages Command = "list_known_packages"
+	ModifyTags        Command = "modify_tags"
 	RegenerateCgo     Command = "regenerate_cgo"
 	RemoveDependency  Command = "remove_dependency"
 	RunTests          Command = "run_tests"
@@ -35,6 +38,7 @@ const (
 	Test              Command = "test"
 	Tidy              Command = "tidy"
 	ToggleGCDetails   Command = "toggle_gc_details"
+	UnusedExports     Command = "unused_exports"
 	UpdateGoSum       Command = "update_go_sum"
 	UpgradeDependency Command = "upgrade_dependency"
 	Vendor            Command = "vendor"
@@ -45,12 +49,15 @@ var Commands = []Command{
 	AddDependency,
 	AddImport,
 	ApplyFix,
+	ChangeSignature,
 	CheckUpgrades,
+	ExtractToNewFile,
 	GCDetails,
 	Generate,
 	GenerateGoplsMod,
 	GoGetPackage,
 	ListKnownPackages,
+	ModifyTags,
 	RegenerateCgo,
 	RemoveDependency,
 	RunTests,
@@ -58,6 +65,7 @@ var Commands = []Command{
 	Test,
 	Tidy,
 	ToggleGCDetails,
+	UnusedExports,
 	UpdateGoSum,
 	UpgradeDependency,
 	Vendor,
@@ -84,12 +92,24 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.ApplyFix(ctx, a0)
+	case "gopls.change_signature":
+		var a0 ChangeSignatureArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ChangeSignature(ctx, a0)
 	case "gopls.check_upgrades":
 		var a0 CheckUpgradesArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
 			return nil, err
 		}
 		return nil, s.CheckUpgrades(ctx, a0)
+	case "gopls.extract_to_new_file":
+		var a0 ExtractToNewFileArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ExtractToNewFile(ctx, a0)
 	case "gopls.gc_details":
 		var a0 protocol.DocumentURI
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -120,6 +140,12 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return s.ListKnownPackages(ctx, a0)
+	case "gopls.modify_tags":
+		var a0 ModifyTagsArgs
+		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
+			return nil, err
+		}
+		return nil, s.ModifyTags(ctx, a0)
 	case "gopls.regenerate_cgo":
 		var a0 URIArg
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -164,6 +190,8 @@ func Dispatch(ctx context.Context, params *protocol.ExecuteCommandParams, s Inte
 			return nil, err
 		}
 		return nil, s.ToggleGCDetails(ctx, a0)
+	case "gopls.unused_exports":
+		return s.UnusedExports(ctx)
 	case "gopls.update_go_sum":
 		var a0 URIArgs
 		if err := UnmarshalArgs(params.Arguments, &a0); err != nil {
@@ -224,6 +252,18 @@ func NewApplyFixCommand(title string, a0 ApplyFixArgs) (protocol.Command, error)
 	}, nil
 }
 
+func NewChangeSignatureCommand(title string, a0 ChangeSignatureArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.change_signature",
+		Arguments: args,
+	}, nil
+}
+
 func NewCheckUpgradesCommand(title string, a0 CheckUpgradesArgs) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -236,6 +276,18 @@ func NewCheckUpgradesCommand(title string, a0 CheckUpgradesArgs) (protocol.Comma
 	}, nil
 }
 
+func NewExtractToNewFileCommand(title string, a0 ExtractToNewFileArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.extract_to_new_file",
+		Arguments: args,
+	}, nil
+}
+
 func NewGCDetailsCommand(title string, a0 protocol.DocumentURI) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -296,6 +348,18 @@ func NewListKnownPackagesCommand(title string, a0 URIArg) (protocol.Command, err
 	}, nil
 }
 
+func NewModifyTagsCommand(title string, a0 ModifyTagsArgs) (protocol.Command, error) {
+	args, err := MarshalArgs(a0)
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.modify_tags",
+		Arguments: args,
+	}, nil
+}
+
 func NewRegenerateCgoCommand(title string, a0 URIArg) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
@@ -380,6 +444,18 @@ func NewToggleGCDetailsCommand(title string, a0 URIArg) (protocol.Command, error
 	}, nil
 }
 
+func NewUnusedExportsCommand(title string) (protocol.Command, error) {
+	args, err := MarshalArgs()
+	if err != nil {
+		return protocol.Command{}, err
+	}
+	return protocol.Command{
+		Title:     title,
+		Command:   "gopls.unused_exports",
+		Arguments: args,
+	}, nil
+}
+
 func NewUpdateGoSumCommand(title string, a0 URIArgs) (protocol.Command, error) {
 	args, err := MarshalArgs(a0)
 	if err != nil {
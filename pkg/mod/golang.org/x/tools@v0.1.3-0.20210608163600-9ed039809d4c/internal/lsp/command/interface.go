@@ -36,16 +36,36 @@ type Interface interface {
 	//
 	// Applies a fix to a region of source code.
 	ApplyFix(context.Context, ApplyFixArgs) error
+
+	// ChangeSignature: Change function signature
+	//
+	// Removes, reorders, and adds parameters of a function or method, and
+	// updates its calls, and the interface methods and implementations that
+	// must keep the same signature.
+	ChangeSignature(context.Context, ChangeSignatureArgs) error
+
+	// ExtractToNewFile: Extract declarations to new file
+	//
+	// Moves the selected top-level declarations to a new file of the same
+	// package.
+	ExtractToNewFile(context.Context, ExtractToNewFileArgs) error
+
+	// ModifyTags: Modify struct tags
+	//
+	// Adds or removes keys of the tags of struct fields.
+	ModifyTags(context.Context, ModifyTagsArgs) error
 	// Test: Run test(s) (legacy)
 	//
-	// Runs `go test` for a specific set of test or benchmark functions.
+	// Runs `go test` for a specific set of test or benchmark functions, or
+	// subtests.
 	Test(context.Context, protocol.DocumentURI, []string, []string) error
 
 	// TODO: deprecate Test in favor of RunTests below.
 
 	// Test: Run test(s)
 	//
-	// Runs `go test` for a specific set of test or benchmark functions.
+	// Runs `go test` for a specific set of test or benchmark functions, or
+	// subtests.
 	RunTests(context.Context, RunTestsArgs) error
 
 	// Generate: Run go generate
@@ -126,6 +146,12 @@ type Interface interface {
 
 	WorkspaceMetadata(context.Context) (WorkspaceMetadataResult, error)
 
+	// UnusedExports: Find unused exported symbols
+	//
+	// Lists the exported symbols of the workspace packages that are not
+	// referred to outside of their package.
+	UnusedExports(context.Context) (UnusedExportsResult, error)
+
 	StartDebugging(context.Context, DebuggingArgs) (DebuggingResult, error)
 }
 
@@ -133,7 +159,8 @@ type RunTestsArgs struct {
 	// The test file containing the tests to run.
 	URI protocol.DocumentURI
 
-	// Specific test names to run, e.g. TestFoo.
+	// Specific test names to run, e.g. TestFoo, or subtest names, e.g.
+	// TestFoo/bar.
 	Tests []string
 
 	// Specific benchmarks to run, e.g. BenchmarkFoo.
@@ -159,6 +186,51 @@ type ApplyFixArgs struct {
 	Range protocol.Range
 }
 
+type ChangeSignatureArgs struct {
+	// The file URI containing the name of the function.
+	URI protocol.DocumentURI
+	// The position of the name of the function.
+	Position protocol.Position
+	// The new parameters of the function.
+	Params []SignatureParam
+}
+
+type SignatureParam struct {
+	// The index of the parameter in the old signature, or -1 for a new
+	// parameter.
+	OldIndex int
+	// The name of a new parameter.
+	Name string
+	// The type of a new parameter.
+	Type string
+	// The argument passed for a new parameter at each call.
+	Default string
+}
+
+type ExtractToNewFileArgs struct {
+	// The file URI containing the declarations.
+	URI protocol.DocumentURI
+	// The document range selecting the declarations.
+	Range protocol.Range
+}
+
+type ModifyTagsArgs struct {
+	// The file URI containing the struct type.
+	URI protocol.DocumentURI
+	// The document range selecting the fields, or a position in the struct
+	// type to select all its fields.
+	Range protocol.Range
+	// The keys to add to the tags of the fields that lack them.
+	Add []string
+	// The transformation of the field names into the values of the added
+	// keys: "camelcase", "snakecase", "lispcase", "pascalcase" or "keep".
+	Transform string
+	// The keys to remove from the tags.
+	Remove []string
+	// Whether to remove the tags entirely.
+	Clear bool
+}
+
 type URIArg struct {
 	// The file URI.
 	URI protocol.DocumentURI
@@ -231,6 +303,23 @@ type Workspace struct {
 	ModuleDir string
 }
 
+type UnusedExportsResult struct {
+	// The unused exported symbols, sorted by location.
+	Symbols []UnusedExport
+}
+
+type UnusedExport struct {
+	// The path of the package declaring the symbol.
+	Package string
+	// The name of the symbol, prefixed by the type name for fields and
+	// methods.
+	Name string
+	// The kind of the symbol: const, var, func, type, field or method.
+	Kind string
+	// The location of the name of the symbol in its declaration.
+	Location protocol.Location
+}
+
 type DebuggingArgs struct {
 	// Optional: the address (including port) for the debug server to listen on.
 	// If not provided, the debug server will bind to "localhost:0", and the
@@ -0,0 +1,90 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/command"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/tests"
+	"golang.org/x/tools/internal/span"
+)
+
+func TestTestRunRegexp(t *testing.T) {
+	for _, test := range []struct {
+		name, want string
+	}{
+		{"TestFoo", "^TestFoo$"},
+		{"TestFoo/bar", "^TestFoo$/^bar$"},
+		{"TestFoo/a_(b)/c.d#01", `^TestFoo$/^a_\(b\)$/^c\.d#01$`},
+		{"TestFoo/[x]+", `^TestFoo$/^\[x\]\+$`},
+		{"TestFoo/", "^TestFoo$/^#00$"},
+		{"TestFoo/#00/x", "^TestFoo$/^#00$/^x$"},
+	} {
+		if got := testRunRegexp(test.name); got != test.want {
+			t.Errorf("testRunRegexp(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+// editClient records workspace edits, and answers them with applied,
+// without applying them.
+type editClient struct {
+	testClient
+	applied bool
+	edits   []protocol.WorkspaceEdit
+}
+
+func (c *editClient) ApplyEdit(_ context.Context, params *protocol.ApplyWorkspaceEditParams) (*protocol.ApplyWorkspaceEditResponse, error) {
+	c.edits = append(c.edits, params.Edit)
+	if !c.applied {
+		return &protocol.ApplyWorkspaceEditResponse{FailureReason: "rejected"}, nil
+	}
+	return &protocol.ApplyWorkspaceEditResponse{Applied: true}, nil
+}
+
+func (c *editClient) RegisterCapability(context.Context, *protocol.RegistrationParams) error {
+	return nil
+}
+
+func TestExtractToNewFile(t *testing.T) {
+	const module = `
+-- go.mod --
+module example.com
+
+go 1.16
+-- a/a.go --
+package a
+
+func A() {}
+
+func Moved() {}
+`
+	for _, applied := range []bool{true, false} {
+		dir := writeTestModule(t, module)
+		ctx := tests.Context(t)
+		s := newTestServer(t, ctx, dir, &editClient{applied: applied})
+
+		h := &commandHandler{s: s, params: &protocol.ExecuteCommandParams{}}
+		err := h.ExtractToNewFile(ctx, command.ExtractToNewFileArgs{
+			URI: protocol.URIFromSpanURI(span.URIFromPath(filepath.Join(dir, "a", "a.go"))),
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 4, Character: 0},
+				End:   protocol.Position{Line: 4, Character: 15},
+			},
+		})
+		if applied != (err == nil) {
+			t.Errorf("ExtractToNewFile with applied=%v returned %v", applied, err)
+		}
+		_, statErr := os.Stat(filepath.Join(dir, "a", "moved.go"))
+		if exists := statErr == nil; exists != applied {
+			t.Errorf("after ExtractToNewFile with applied=%v, moved.go exists: %v", applied, exists)
+		}
+	}
+}
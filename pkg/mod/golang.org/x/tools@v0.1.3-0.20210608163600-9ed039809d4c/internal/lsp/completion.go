@@ -33,7 +33,11 @@ func (s *Server) completion(ctx context.Context, params *protocol.CompletionPara
 	case source.Mod:
 		candidates, surrounding = nil, nil
 	case source.Tmpl:
-		candidates, surrounding, err = template.Completion(ctx, snapshot, fh, params.Position, params.Context)
+		var list *protocol.CompletionList
+		list, err = template.Completion(ctx, snapshot, fh, params.Position, params.Context)
+		if err == nil && list != nil {
+			return list, nil
+		}
 	}
 	if err != nil {
 		event.Error(ctx, "no completions found", err, tag.Position.Of(params.Position))
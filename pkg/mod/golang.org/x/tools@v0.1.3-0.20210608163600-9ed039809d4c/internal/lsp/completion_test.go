@@ -22,6 +22,15 @@ func (r *runner) Completion(t *testing.T, src span.Span, test tests.Completion,
 		opts.InsertTextFormat = protocol.SnippetTextFormat
 		opts.LiteralCompletions = strings.Contains(string(src.URI()), "literal")
 		opts.ExperimentalPostfixCompletions = strings.Contains(string(src.URI()), "postfix")
+		if opts.ExperimentalPostfixCompletions {
+			source.SetOptions(opts, map[string]interface{}{
+				"postfixSnippets": []interface{}{map[string]interface{}{
+					"label":   "errwrap",
+					"details": "wrap error",
+					"body":    `{{if .IsError}}{{.Import "fmt"}}.Errorf("{{.Cursor}}: %w", {{.X}}){{end}}`,
+				}},
+			})
+		}
 	})
 	got = tests.FilterBuiltins(src, got)
 	want := expected(t, test, items)
@@ -719,6 +719,13 @@ func fuint64(v uint64) string {
 	return commas(strconv.FormatUint(v, 10))
 }
 
+func fint64(v int64) string {
+	if v < 0 {
+		return "-" + commas(strconv.FormatInt(-v, 10))
+	}
+	return commas(strconv.FormatInt(v, 10))
+}
+
 func fuint32(v uint32) string {
 	return commas(strconv.FormatUint(uint64(v), 10))
 }
@@ -770,6 +777,7 @@ Unknown page
 `)).Funcs(template.FuncMap{
 	"fuint64":  fuint64,
 	"fuint32":  fuint32,
+	"fint64":   fint64,
 	"fcontent": fcontent,
 	"localAddress": func(s string) string {
 		// Try to translate loopback addresses to localhost, both for cosmetics and
@@ -863,6 +871,10 @@ var CacheTmpl = template.Must(template.Must(BaseTemplate.Clone()).Parse(`
 {{define "body"}}
 <h2>memoize.Store entries</h2>
 <ul>{{range $k,$v := .MemStats}}<li>{{$k}} - {{$v}}</li>{{end}}</ul>
+<h2>Memory budget</h2>
+{{with .MemoryBudget}}
+Type-checked packages: {{fint64 .Size}} bytes, budget: {{if .Budget}}{{fint64 .Budget}} bytes{{else}}none{{end}}, {{.Evictions}} evicted<br>
+{{end}}
 <h2>Per-package usage - not accurate, for guidance only</h2>
 {{.PackageStats true}}
 {{end}}
@@ -918,6 +930,14 @@ From: <b>{{template "cachelink" .Cache.ID}}</b><br>
 <ul>{{range .Views}}<li>{{.Name}} is {{template "viewlink" .ID}} in {{.Folder}}</li>{{end}}</ul>
 <h2>Overlays</h2>
 <ul>{{range .Overlays}}<li>{{template "filelink" .}}</li>{{end}}</ul>
+<h2>Type-checked packages - estimated, for guidance only</h2>
+{{with .MemStats}}
+<table>
+<tr><th>View</th><th>Packages</th><th>Bytes</th></tr>
+{{range .Views}}<tr><td>{{.Name}}</td><td class="value">{{.Packages}}</td><td class="value">{{fint64 .Size}}</td></tr>{{end}}
+</table>
+Total: {{fint64 .Size}} bytes, with {{.Shared}} packages shared by several views<br>
+{{end}}
 <h2>Options</h2>
 {{range options .}}<p>{{.}}{{end}}
 {{end}}
@@ -89,6 +89,7 @@ func (s *Server) diagnoseDetached(snapshot source.Snapshot) {
 	ctx = xcontext.Detach(ctx)
 	s.diagnose(ctx, snapshot, false)
 	s.publishDiagnostics(ctx, true, snapshot)
+	s.refreshPulledDiagnostics(ctx)
 }
 
 func (s *Server) diagnoseSnapshot(snapshot source.Snapshot, changedURIs []span.URI, onDisk bool) {
@@ -110,6 +111,7 @@ func (s *Server) diagnoseSnapshot(snapshot source.Snapshot, changedURIs []span.U
 		s.debouncer.debounce(snapshot.View().Name(), snapshot.ID(), delay, func() {
 			s.diagnose(ctx, snapshot, false)
 			s.publishDiagnostics(ctx, true, snapshot)
+			s.refreshPulledDiagnostics(ctx)
 		})
 		return
 	}
@@ -117,6 +119,7 @@ func (s *Server) diagnoseSnapshot(snapshot source.Snapshot, changedURIs []span.U
 	// Ignore possible workspace configuration warnings in the normal flow.
 	s.diagnose(ctx, snapshot, false)
 	s.publishDiagnostics(ctx, true, snapshot)
+	s.refreshPulledDiagnostics(ctx)
 }
 
 func (s *Server) diagnoseChangedFiles(ctx context.Context, snapshot source.Snapshot, uris []span.URI, onDisk bool) {
@@ -197,8 +200,9 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, forceAn
 		s.storeDiagnostics(snapshot, id.URI, modSource, diags)
 	}
 
-	// Diagnose all of the packages in the workspace.
-	wsPkgs, err := snapshot.WorkspacePackages(ctx)
+	// Diagnose all of the packages in the workspace. Packages whose
+	// diagnostics are stored in the persistent cache are not type-checked.
+	wsPkgs, stored, err := snapshot.StoredWorkspacePackages(ctx)
 	if s.shouldIgnoreError(ctx, snapshot, err) {
 		return
 	}
@@ -212,13 +216,13 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, forceAn
 
 	// There may be .tmpl files.
 	for _, f := range snapshot.Templates() {
-		diags := template.Diagnose(f)
+		diags := template.Diagnose(ctx, snapshot, f)
 		s.storeDiagnostics(snapshot, f.URI(), typeCheckSource, diags)
 	}
 
 	// If there are no workspace packages, there is nothing to diagnose and
 	// there are no orphaned files.
-	if len(wsPkgs) == 0 {
+	if len(wsPkgs) == 0 && len(stored) == 0 {
 		return
 	}
 
@@ -226,6 +230,12 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, forceAn
 		wg   sync.WaitGroup
 		seen = map[span.URI]struct{}{}
 	)
+	for _, sp := range stored {
+		for _, uri := range sp.CompiledGoFiles {
+			seen[uri] = struct{}{}
+		}
+		s.diagnoseStoredPkg(ctx, snapshot, sp)
+	}
 	for _, pkg := range wsPkgs {
 		wg.Add(1)
 
@@ -274,6 +284,8 @@ func (s *Server) diagnosePkg(ctx context.Context, snapshot source.Snapshot, pkg
 		event.Error(ctx, "warning: diagnosing package", err, tag.Snapshot.Of(snapshot.ID()), tag.Package.Of(pkg.ID()))
 		return
 	}
+	// Keep the diagnostics for the next restart, see diagnoseStoredPkg.
+	snapshot.PersistDiagnostics(ctx, pkg, pkgDiagnostics)
 	for _, cgf := range pkg.CompiledGoFiles() {
 		s.storeDiagnostics(snapshot, cgf.URI, typeCheckSource, pkgDiagnostics[cgf.URI])
 	}
@@ -320,6 +332,40 @@ func (s *Server) diagnosePkg(ctx context.Context, snapshot source.Snapshot, pkg
 	}
 }
 
+// diagnoseStoredPkg reports the diagnostics of a package that were read from
+// the persistent cache. If gc optimization details are requested for the
+// package, it is type-checked and diagnosed as usual.
+func (s *Server) diagnoseStoredPkg(ctx context.Context, snapshot source.Snapshot, sp *source.StoredPackage) {
+	s.gcOptimizationDetailsMu.Lock()
+	_, enableGCDetails := s.gcOptimizationDetails[sp.ID]
+	s.gcOptimizationDetailsMu.Unlock()
+	if enableGCDetails && len(sp.CompiledGoFiles) > 0 {
+		pkgs, err := snapshot.PackagesForFile(ctx, sp.CompiledGoFiles[0], source.TypecheckWorkspace)
+		if err != nil {
+			event.Error(ctx, "warning: diagnosing package", err, tag.Snapshot.Of(snapshot.ID()), tag.Package.Of(sp.ID))
+			return
+		}
+		for _, pkg := range pkgs {
+			if pkg.ID() == sp.ID {
+				s.diagnosePkg(ctx, snapshot, pkg, false)
+				return
+			}
+		}
+	}
+
+	enableDiagnostics := false
+	for _, uri := range sp.CompiledGoFiles {
+		enableDiagnostics = enableDiagnostics || !snapshot.IgnoredFile(uri)
+	}
+	// Don't show any diagnostics on ignored files.
+	if !enableDiagnostics {
+		return
+	}
+	for _, uri := range sp.CompiledGoFiles {
+		s.storeDiagnostics(snapshot, uri, typeCheckSource, sp.Diagnostics[uri])
+	}
+}
+
 // storeDiagnostics stores results from a single diagnostic source. If merge is
 // true, it merges results into any existing results for this snapshot.
 func (s *Server) storeDiagnostics(snapshot source.Snapshot, uri span.URI, dsource diagnosticSource, diags []*source.Diagnostic) {
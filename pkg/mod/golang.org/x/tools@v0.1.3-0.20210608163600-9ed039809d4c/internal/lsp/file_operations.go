@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/span"
+)
+
+// fileOperationFilters selects the file operations gopls is interested in:
+// operations on Go files and on directories, which may contain packages.
+var fileOperationFilters = []protocol.FileOperationFilter{
+	{Scheme: "file", Pattern: protocol.FileOperationPattern{Glob: "**/*.go", Matches: protocol.FileOp}},
+	{Scheme: "file", Pattern: protocol.FileOperationPattern{Glob: "**", Matches: protocol.FolderOp}},
+}
+
+// fileOperationOptions returns the file operations capabilities of the server.
+func fileOperationOptions() *protocol.FileOperationOptions {
+	none := protocol.FileOperationRegistrationOptions{Filters: []protocol.FileOperationFilter{}}
+	return &protocol.FileOperationOptions{
+		WillRename: protocol.FileOperationRegistrationOptions{Filters: fileOperationFilters},
+		DidRename:  protocol.FileOperationRegistrationOptions{Filters: fileOperationFilters},
+		DidCreate:  none,
+		WillCreate: none,
+		DidDelete:  none,
+		WillDelete: none,
+	}
+}
+
+func (s *Server) willRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
+	// Group the renames by view, each view computes its own edits.
+	renames := map[source.View][]source.FileRename{}
+	for _, f := range params.Files {
+		oldURI, newURI := span.URIFromURI(f.OldURI), span.URIFromURI(f.NewURI)
+		if !oldURI.IsFile() || !newURI.IsFile() {
+			continue
+		}
+		view, err := s.session.ViewOf(oldURI)
+		if err != nil {
+			continue
+		}
+		renames[view] = append(renames[view], source.FileRename{OldURI: oldURI, NewURI: newURI})
+	}
+
+	var docChanges []protocol.TextDocumentEdit
+	for view, r := range renames {
+		snapshot, release := view.Snapshot(ctx)
+		edits, err := source.RenameFiles(ctx, snapshot, r)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		for uri, e := range edits {
+			fh, err := snapshot.GetVersionedFile(ctx, uri)
+			if err != nil {
+				release()
+				return nil, err
+			}
+			docChanges = append(docChanges, documentChanges(fh, e)...)
+		}
+		release()
+	}
+	if len(docChanges) == 0 {
+		return nil, nil
+	}
+	return &protocol.WorkspaceEdit{
+		DocumentChanges: docChanges,
+	}, nil
+}
+
+func (s *Server) didRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) error {
+	var modifications []source.FileModification
+	for _, f := range params.Files {
+		oldURI, newURI := span.URIFromURI(f.OldURI), span.URIFromURI(f.NewURI)
+		if !oldURI.IsFile() || !newURI.IsFile() {
+			continue
+		}
+		// Directories are expanded to the files they contain by
+		// didModifyFiles, but only if they are known: the new directory
+		// isn't, so its files are listed here.
+		modifications = append(modifications, source.FileModification{URI: oldURI, Action: source.Delete, OnDisk: true})
+		for _, uri := range goFilesAt(newURI.Filename()) {
+			modifications = append(modifications, source.FileModification{URI: uri, Action: source.Create, OnDisk: true})
+		}
+	}
+	return s.didModifyFiles(ctx, modifications, FromDidChangeWatchedFiles)
+}
+
+// goFilesAt returns path, if it is a Go file, or the Go files in the tree
+// rooted at path, if it is a directory.
+func goFilesAt(path string) []span.URI {
+	var uris []span.URI
+	filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+			uris = append(uris, span.URIFromPath(path))
+		}
+		return nil
+	})
+	return uris
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/cache"
+	"golang.org/x/tools/internal/lsp/fake"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/lsp/tests"
+	"golang.org/x/tools/internal/span"
+)
+
+const fileOperationsModule = `
+-- go.mod --
+module example.com
+
+go 1.16
+-- a/a.go --
+package a
+
+func A() {}
+-- a/moved.go --
+package a
+
+func Moved() {}
+-- b/b.go --
+package b
+
+import "example.com/a"
+
+func B() { a.A() }
+-- c/c.go --
+package c
+
+const C = 1
+`
+
+func TestWillRenameFiles(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		old, new string
+		want     map[string]string // the edited files, by path relative to the module
+	}{
+		{
+			name: "package directory",
+			old:  "a",
+			new:  "renamed",
+			want: map[string]string{
+				"a/a.go":     "package renamed\n\nfunc A() {}\n",
+				"a/moved.go": "package renamed\n\nfunc Moved() {}\n",
+				"b/b.go":     "package b\n\nimport \"example.com/renamed\"\n\nfunc B() { renamed.A() }\n",
+			},
+		},
+		{
+			name: "file to other package",
+			old:  "a/moved.go",
+			new:  "c/moved.go",
+			want: map[string]string{
+				"a/moved.go": "package c\n\nfunc Moved() {}\n",
+			},
+		},
+		{
+			name: "file in same directory",
+			old:  "a/moved.go",
+			new:  "a/other.go",
+			want: map[string]string{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeTestModule(t, fileOperationsModule)
+			ctx := tests.Context(t)
+			s := newTestServer(t, ctx, dir, testClient{})
+
+			edit, err := s.willRenameFiles(ctx, &protocol.RenameFilesParams{
+				Files: []protocol.FileRename{{
+					OldURI: string(span.URIFromPath(filepath.Join(dir, test.old))),
+					NewURI: string(span.URIFromPath(filepath.Join(dir, test.new))),
+				}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]string{}
+			if edit != nil {
+				for _, docEdit := range edit.DocumentChanges {
+					uri := docEdit.TextDocument.URI.SpanURI()
+					content, err := ioutil.ReadFile(uri.Filename())
+					if err != nil {
+						t.Fatal(err)
+					}
+					m := &protocol.ColumnMapper{
+						URI:       uri,
+						Converter: span.NewContentConverter(uri.Filename(), content),
+						Content:   content,
+					}
+					edits, err := source.FromProtocolEdits(m, docEdit.Edits)
+					if err != nil {
+						t.Fatal(err)
+					}
+					rel, err := filepath.Rel(dir, uri.Filename())
+					if err != nil {
+						t.Fatal(err)
+					}
+					got[filepath.ToSlash(rel)] = applyEdits(string(content), edits)
+				}
+			}
+			for file, want := range test.want {
+				if got[file] != want {
+					t.Errorf("%s after renaming %s to %s:\n%s\nwant:\n%s", file, test.old, test.new, got[file], want)
+				}
+			}
+			for file := range got {
+				if _, ok := test.want[file]; !ok {
+					t.Errorf("unexpected edit of %s:\n%s", file, got[file])
+				}
+			}
+		})
+	}
+}
+
+// writeTestModule writes the files of the txtar archive to a temporary
+// directory and returns it.
+func writeTestModule(t *testing.T, archive string) string {
+	dir, err := ioutil.TempDir("", "gopls-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range fake.UnpackTxt(archive) {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// newTestServer returns a server with a view of dir, shut down at the end
+// of the test.
+func newTestServer(t *testing.T, ctx context.Context, dir string, client protocol.ClientCloser) *Server {
+	session := cache.New(nil).NewSession(ctx)
+	options := source.DefaultOptions().Clone()
+	tests.DefaultOptions(options)
+	session.SetOptions(options)
+	view, _, release, err := session.NewView(ctx, "test", span.URIFromPath(dir), "", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	t.Cleanup(func() { view.Shutdown(ctx) })
+	return NewServer(session, client)
+}
@@ -0,0 +1,279 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package filecache provides a persistent cache of values computed by gopls,
+// stored in files under a directory so that they survive restarts and are
+// shared by gopls processes.
+//
+// Values are addressed by a kind, such as "diagnostics", and a key that is a
+// hash of all the inputs of the value. A stored value is therefore never
+// stale, and is never invalidated: entries that have not been used for a
+// while are removed by Trim.
+package filecache
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is the version of the layout of the cache and of the encoding of
+// its values. It must be incremented whenever either changes, so that values
+// written by other versions of gopls are ignored.
+const version = 1
+
+const (
+	// mtimeInterval is the precision of the last use times of entries.
+	mtimeInterval = 1 * time.Hour
+
+	// trimInterval is the minimum time between two trims of the cache.
+	trimInterval = 24 * time.Hour
+
+	// trimLimit is the time after which unused entries are removed.
+	trimLimit = 5 * 24 * time.Hour
+)
+
+// ErrNotFound is returned by Get for values that are not in the cache.
+var ErrNotFound = errors.New("not found in the file cache")
+
+// A Cache is a persistent cache in a directory.
+type Cache struct {
+	dir string
+}
+
+// New returns the cache in dir. The directory is created when the first
+// value is stored.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns the directory of the cache shared by gopls processes:
+// gopls in the user cache directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gopls"), nil
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// root returns the directory of the entries of the current version.
+func (c *Cache) root() string {
+	return filepath.Join(c.dir, "v"+strconv.Itoa(version))
+}
+
+func (c *Cache) path(kind, key string) (string, error) {
+	if kind == "" || strings.ContainsAny(kind, `/\.`) {
+		return "", fmt.Errorf("invalid kind %q", kind)
+	}
+	if len(key) < 2 || strings.ContainsAny(key, `/\.`) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(c.root(), kind, key[:2], key), nil
+}
+
+// Get returns the value of kind stored under key, or ErrNotFound.
+func (c *Cache) Get(kind, key string) ([]byte, error) {
+	path, err := c.path(kind, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.used(path)
+	return data, nil
+}
+
+// Has reports whether a value of kind is stored under key, and records the
+// use of the value, which a caller need not compute again.
+func (c *Cache) Has(kind, key string) bool {
+	path, err := c.path(kind, key)
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	c.used(path)
+	return true
+}
+
+// Set stores the value of kind under key. As the value is determined by its
+// key, an existing value is kept.
+func (c *Cache) Set(kind, key string, value []byte) error {
+	path, err := c.path(kind, key)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		c.used(path)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	// Write to a temporary file that is renamed, so that readers, including
+	// other processes, never observe a partial value.
+	f, err := ioutil.TempFile(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(value)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// used records that the entry at path was just used, so that it is not
+// trimmed. The time is only updated once per mtimeInterval to save writes.
+func (c *Cache) used(path string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if now := time.Now(); now.Sub(fi.ModTime()) >= mtimeInterval {
+		os.Chtimes(path, now, now)
+	}
+}
+
+// Trim removes the entries that have not been used for five days. It does
+// nothing if the cache was trimmed in the last day.
+func (c *Cache) Trim() error {
+	stamp := filepath.Join(c.root(), "trim.txt")
+	now := time.Now()
+	if data, err := ioutil.ReadFile(stamp); err == nil {
+		if t, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil && now.Sub(time.Unix(t, 0)) < trimInterval {
+			return nil
+		}
+	}
+	err := filepath.Walk(c.root(), func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !fi.IsDir() && path != stamp && now.Sub(fi.ModTime()) > trimLimit {
+			os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.root(), 0777); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(stamp, []byte(fmt.Sprintf("%d\n", now.Unix())), 0666)
+}
+
+// Clean removes all the entries of the cache, including those stored by
+// other versions of gopls. Files in the directory that do not belong to the
+// cache are left alone.
+func (c *Cache) Clean() error {
+	entries, err := ioutil.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, fi := range entries {
+		if !fi.IsDir() || !isVersion(fi.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.dir, fi.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isVersion(name string) bool {
+	if !strings.HasPrefix(name, "v") {
+		return false
+	}
+	_, err := strconv.Atoi(name[1:])
+	return err == nil
+}
+
+// KindStats describes the entries of one kind in a cache.
+type KindStats struct {
+	Kind    string
+	Entries int
+	Bytes   int64
+}
+
+// Stats returns statistics about the entries of the cache, by kind. The
+// entries stored by other versions of gopls are reported as the "obsolete"
+// kind.
+func (c *Cache) Stats() ([]KindStats, error) {
+	byKind := map[string]*KindStats{}
+	err := filepath.Walk(c.dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if fi.IsDir() || filepath.Base(path) == "trim.txt" {
+			return nil
+		}
+		rel, err := filepath.Rel(c.dir, path)
+		if err != nil {
+			return err
+		}
+		// Entries are stored at v<version>/<kind>/<prefix>/<key>.
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 4 || !isVersion(parts[0]) {
+			return nil
+		}
+		kind := parts[1]
+		if parts[0] != "v"+strconv.Itoa(version) {
+			kind = "obsolete"
+		}
+		s := byKind[kind]
+		if s == nil {
+			s = &KindStats{Kind: kind}
+			byKind[kind] = s
+		}
+		s.Entries++
+		s.Bytes += fi.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var stats []KindStats
+	for _, s := range byKind {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Kind < stats[j].Kind
+	})
+	return stats, nil
+}
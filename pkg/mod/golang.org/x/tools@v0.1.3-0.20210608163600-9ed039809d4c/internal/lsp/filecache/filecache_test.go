@@ -0,0 +1,78 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package filecache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/filecache"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "filecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c := filecache.New(dir)
+
+	if _, err := c.Get("kind", "abcd"); err != filecache.ErrNotFound {
+		t.Fatalf("Get() of missing value: got %v, want ErrNotFound", err)
+	}
+	if c.Has("kind", "abcd") {
+		t.Fatal("Has() of missing value = true")
+	}
+	if err := c.Set("kind", "abcd", []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Has("kind", "abcd") {
+		t.Fatal("Has() of stored value = false")
+	}
+	// The value is determined by the key, so it is not replaced.
+	if err := c.Set("kind", "abcd", []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("other", "abcd", []byte("other value")); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Get("kind", "abcd")
+	if err != nil || string(got) != "value" {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, "value")
+	}
+	if err := c.Set("kind", "../up", nil); err == nil {
+		t.Errorf("Set() of an invalid key succeeded")
+	}
+
+	// A file of an unrelated tool is left alone.
+	unrelated := filepath.Join(dir, "unrelated")
+	if err := ioutil.WriteFile(unrelated, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []filecache.KindStats{
+		{Kind: "kind", Entries: 1, Bytes: 5},
+		{Kind: "other", Entries: 1, Bytes: 11},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	if err := c.Clean(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("kind", "abcd"); err != filecache.ErrNotFound {
+		t.Errorf("Get() after Clean(): got %v, want ErrNotFound", err)
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("Clean() removed an unrelated file: %v", err)
+	}
+}
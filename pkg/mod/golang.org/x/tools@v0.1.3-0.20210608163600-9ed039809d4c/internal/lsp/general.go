@@ -126,6 +126,7 @@ See https://github.com/golang/go/issues/45732 for more information.`,
 			},
 			DefinitionProvider:         true,
 			TypeDefinitionProvider:     true,
+			MonikerProvider:            true,
 			ImplementationProvider:     true,
 			DocumentFormattingProvider: true,
 			DocumentSymbolProvider:     true,
@@ -133,12 +134,17 @@ See https://github.com/golang/go/issues/45732 for more information.`,
 			ExecuteCommandProvider: protocol.ExecuteCommandOptions{
 				Commands: options.SupportedCommands,
 			},
-			FoldingRangeProvider:      true,
-			HoverProvider:             true,
-			DocumentHighlightProvider: true,
-			DocumentLinkProvider:      protocol.DocumentLinkOptions{},
-			ReferencesProvider:        true,
-			RenameProvider:            renameOpts,
+			FoldingRangeProvider:            true,
+			HoverProvider:                   true,
+			DocumentHighlightProvider:       true,
+			DocumentLinkProvider:            protocol.DocumentLinkOptions{},
+			ReferencesProvider:              true,
+			RenameProvider:                  renameOpts,
+			SelectionRangeProvider:          true,
+			DocumentRangeFormattingProvider: true,
+			DocumentOnTypeFormattingProvider: protocol.DocumentOnTypeFormattingOptions{
+				FirstTriggerCharacter: "}",
+			},
 			SignatureHelpProvider: protocol.SignatureHelpOptions{
 				TriggerCharacters: []string{"(", ","},
 			},
@@ -150,6 +156,7 @@ See https://github.com/golang/go/issues/45732 for more information.`,
 				},
 			},
 			Workspace: protocol.Workspace5Gn{
+				FileOperations: fileOperationOptions(),
 				WorkspaceFolders: protocol.WorkspaceFolders4Gn{
 					Supported:           true,
 					ChangeNotifications: "workspace/didChangeWorkspaceFolders",
@@ -202,6 +209,10 @@ func (s *Server) initialized(ctx context.Context, params *protocol.InitializedPa
 		if options.SemanticTokens {
 			registrations = append(registrations, semanticTokenRegistration(options.SemanticTypes, options.SemanticMods))
 		}
+		// The pull diagnostics, the type hierarchy and the inlay hints of LSP
+		// 3.17 are missing from the generated ServerCapabilities, and are
+		// registered instead.
+		registrations = append(registrations, diagnosticRegistration(), typeHierarchyRegistration(), inlayHintRegistration())
 		if err := s.client.RegisterCapability(ctx, &protocol.RegistrationParams{
 			Registrations: registrations,
 		}); err != nil {
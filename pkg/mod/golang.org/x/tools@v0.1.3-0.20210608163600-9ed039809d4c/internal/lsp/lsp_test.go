@@ -116,6 +116,10 @@ func (c testClient) ShowMessage(context.Context, *protocol.ShowMessageParams) er
 	return nil
 }
 
+func (c testClient) DiagnosticRefresh(context.Context) error {
+	return nil
+}
+
 func (c testClient) ApplyEdit(ctx context.Context, params *protocol.ApplyWorkspaceEditParams) (*protocol.ApplyWorkspaceEditResponse, error) {
 	res, err := applyTextDocumentEdits(c.runner, params.Edit.DocumentChanges)
 	if err != nil {
@@ -185,6 +189,56 @@ func (r *runner) CallHierarchy(t *testing.T, spn span.Span, expectedCalls *tests
 	}
 }
 
+func (r *runner) TypeHierarchy(t *testing.T, spn span.Span, expectedTypes *tests.TypeHierarchyResult) {
+	mapper, err := r.data.Mapper(spn.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := mapper.Location(spn)
+	if err != nil {
+		t.Fatalf("failed for %v: %v", spn, err)
+	}
+
+	params := &protocol.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: loc.URI},
+			Position:     loc.Range.Start,
+		},
+	}
+
+	items, err := r.server.PrepareTypeHierarchy(r.ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 {
+		t.Fatalf("expected type hierarchy item to be returned for identifier at %v\n", loc.Range)
+	}
+
+	typeLocation := protocol.Location{
+		URI:   items[0].URI,
+		Range: items[0].Range,
+	}
+	if typeLocation != loc {
+		t.Fatalf("expected server.PrepareTypeHierarchy to return identifier at %v but got %v\n", loc, typeLocation)
+	}
+
+	supertypes, err := r.server.Supertypes(r.ctx, &protocol.TypeHierarchySupertypesParams{Item: items[0]})
+	if err != nil {
+		t.Error(err)
+	}
+	if msg := tests.DiffTypeHierarchyItems(supertypes, expectedTypes.Supertypes); msg != "" {
+		t.Error(fmt.Sprintf("supertypes: %s", msg))
+	}
+
+	subtypes, err := r.server.Subtypes(r.ctx, &protocol.TypeHierarchySubtypesParams{Item: items[0]})
+	if err != nil {
+		t.Error(err)
+	}
+	if msg := tests.DiffTypeHierarchyItems(subtypes, expectedTypes.Subtypes); msg != "" {
+		t.Error(fmt.Sprintf("subtypes: %s", msg))
+	}
+}
+
 func (r *runner) CodeLens(t *testing.T, uri span.URI, want []protocol.CodeLens) {
 	if source.DetectLanguage("", uri.Filename()) != source.Mod {
 		return
@@ -219,6 +273,48 @@ func (r *runner) Diagnostics(t *testing.T, uri span.URI, want []*source.Diagnost
 	if diff := tests.DiffDiagnostics(uri, want, got); diff != "" {
 		t.Error(diff)
 	}
+	r.pullDiagnostics(t, uri, got)
+}
+
+// pullDiagnostics checks that the diagnostics pulled for uri match the
+// pushed diagnostics, and that pulling them again reports them unchanged.
+func (r *runner) pullDiagnostics(t *testing.T, uri span.URI, pushed []*source.Diagnostic) {
+	if source.DetectLanguage("", uri.Filename()) != source.Go {
+		return
+	}
+	params := &protocol.DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromSpanURI(uri)},
+	}
+	report, err := r.server.DocumentDiagnostic(r.ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, ok := report.(*protocol.RelatedFullDocumentDiagnosticReport)
+	if !ok {
+		t.Fatalf("%s: got %T, want a full report", uri, report)
+	}
+	want := toProtocolDiagnostics(pushed)
+	var got, wantMsgs []string
+	for _, d := range full.Items {
+		got = append(got, fmt.Sprintf("%v: %s", d.Range, d.Message))
+	}
+	for _, d := range want {
+		wantMsgs = append(wantMsgs, fmt.Sprintf("%v: %s", d.Range, d.Message))
+	}
+	sort.Strings(got)
+	sort.Strings(wantMsgs)
+	if strings.Join(got, "\n") != strings.Join(wantMsgs, "\n") {
+		t.Errorf("%s: pulled diagnostics differ from pushed diagnostics:\ngot:\n%s\nwant:\n%s", uri, strings.Join(got, "\n"), strings.Join(wantMsgs, "\n"))
+	}
+
+	params.PreviousResultID = full.ResultID
+	report, err = r.server.DocumentDiagnostic(r.ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := report.(*protocol.RelatedUnchangedDocumentDiagnosticReport); !ok {
+		t.Errorf("%s: got %T, want an unchanged report", uri, report)
+	}
 }
 
 func (r *runner) FoldingRanges(t *testing.T, spn span.Span) {
@@ -414,6 +510,37 @@ func (r *runner) Format(t *testing.T, spn span.Span) {
 	}
 }
 
+func (r *runner) InlayHints(t *testing.T, spn span.Span) {
+	uri := spn.URI()
+	filename := uri.Filename()
+
+	hints, err := r.server.InlayHint(r.ctx, &protocol.InlayHintParams{
+		TextDocument: protocol.TextDocumentIdentifier{
+			URI: protocol.URIFromSpanURI(uri),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sedits, err := source.FromProtocolEdits(m, source.InlayHintEdits(hints))
+	if err != nil {
+		t.Error(err)
+	}
+	got := applyEdits(string(m.Content), sedits)
+
+	withinlayHints := string(r.data.Golden("inlayHint", filename, func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+
+	if withinlayHints != got {
+		t.Errorf("inlay hints failed for %s, expected:\n%v\ngot:\n%v", filename, withinlayHints, got)
+	}
+}
+
 func (r *runner) SemanticTokens(t *testing.T, spn span.Span) {
 	uri := spn.URI()
 	filename := uri.Filename()
@@ -572,6 +699,24 @@ func (r *runner) SuggestedFix(t *testing.T, spn span.Span, actionKinds []string,
 }
 
 func (r *runner) FunctionExtraction(t *testing.T, start span.Span, end span.Span) {
+	r.refactoring(t, start, end, protocol.RefactorExtract, "Extract to function", "functionextraction_")
+}
+
+func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span) {
+	r.refactoring(t, start, end, protocol.RefactorExtract, "Extract to method", "methodextraction_")
+}
+
+func (r *runner) InlineCall(t *testing.T, start span.Span, end span.Span) {
+	r.refactoring(t, start, end, protocol.RefactorInline, "Inline call", "inline_")
+}
+
+func (r *runner) ChangeSignature(t *testing.T, spn span.Span, title string) {
+	r.refactoring(t, spn, spn, protocol.RefactorRewrite, title, "changesignature_")
+}
+
+// refactoring applies the code action of the given kind and title to the
+// range from start to end, and compares the result with the golden file.
+func (r *runner) refactoring(t *testing.T, start span.Span, end span.Span, kind protocol.CodeActionKind, title, goldenPrefix string) {
 	uri := start.URI()
 	m, err := r.data.Mapper(uri)
 	if err != nil {
@@ -588,31 +733,36 @@ func (r *runner) FunctionExtraction(t *testing.T, start span.Span, end span.Span
 		},
 		Range: rng,
 		Context: protocol.CodeActionContext{
-			Only: []protocol.CodeActionKind{"refactor.extract"},
+			Only: []protocol.CodeActionKind{kind},
 		},
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
-	// Hack: We assume that we only get one code action per range.
-	// TODO(rstambler): Support multiple code actions per test.
-	if len(actions) == 0 || len(actions) > 1 {
-		t.Fatalf("unexpected number of code actions, want 1, got %v", len(actions))
+	var action *protocol.CodeAction
+	for i := range actions {
+		if actions[i].Title == title {
+			action = &actions[i]
+			break
+		}
+	}
+	if action == nil {
+		t.Fatalf("no %q code action, got %v", title, actions)
 	}
 	_, err = r.server.ExecuteCommand(r.ctx, &protocol.ExecuteCommandParams{
-		Command:   actions[0].Command.Command,
-		Arguments: actions[0].Command.Arguments,
+		Command:   action.Command.Command,
+		Arguments: action.Command.Arguments,
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 	res := <-r.editRecv
 	for u, got := range res {
-		want := string(r.data.Golden("functionextraction_"+tests.SpanName(spn), u.Filename(), func() ([]byte, error) {
+		want := string(r.data.Golden(goldenPrefix+tests.SpanName(spn), u.Filename(), func() ([]byte, error) {
 			return []byte(got), nil
 		}))
 		if want != got {
-			t.Errorf("function extraction failed for %s:\n%s", u.Filename(), tests.Diff(t, want, got))
+			t.Errorf("%s failed for %s:\n%s", title, u.Filename(), tests.Diff(t, want, got))
 		}
 	}
 }
@@ -622,6 +772,19 @@ func (r *runner) Definition(t *testing.T, spn span.Span, d tests.Definition) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if d.FullDocumentation {
+		view, err := r.server.session.ViewOf(d.Src.URI())
+		if err != nil {
+			t.Fatal(err)
+		}
+		original := view.Options()
+		modified := original.Clone()
+		modified.HoverKind = source.FullDocumentation
+		if _, err := view.SetOptions(r.ctx, modified); err != nil {
+			t.Fatal(err)
+		}
+		defer view.SetOptions(r.ctx, original)
+	}
 	loc, err := sm.Location(d.Src)
 	if err != nil {
 		t.Fatalf("failed for %v: %v", d.Src, err)
@@ -133,6 +133,8 @@ type remoteConfig struct {
 	debug         string
 	listenTimeout time.Duration
 	logfile       string
+	memoryBudget  int
+	cacheDir      string
 }
 
 // A RemoteOption configures the behavior of the auto-started remote.
@@ -164,6 +166,22 @@ func (l RemoteLogfile) set(cfg *remoteConfig) {
 	cfg.logfile = string(l)
 }
 
+// RemoteMemoryBudget configures the number of megabytes that type-checked
+// packages may use in the cache of the auto-started gopls daemon.
+type RemoteMemoryBudget int
+
+func (b RemoteMemoryBudget) set(cfg *remoteConfig) {
+	cfg.memoryBudget = int(b)
+}
+
+// RemoteCacheDir configures the directory of the persistent cache of the
+// auto-started gopls daemon.
+type RemoteCacheDir string
+
+func (d RemoteCacheDir) set(cfg *remoteConfig) {
+	cfg.cacheDir = string(d)
+}
+
 func defaultRemoteConfig() remoteConfig {
 	return remoteConfig{
 		listenTimeout: 1 * time.Minute,
@@ -411,6 +429,12 @@ func connectToRemote(ctx context.Context, inNetwork, inAddr, goplsPath string, r
 		if rcfg.debug != "" {
 			args = append(args, "-debug", rcfg.debug)
 		}
+		if rcfg.memoryBudget != 0 {
+			args = append(args, "-memory.budget", strconv.Itoa(rcfg.memoryBudget))
+		}
+		if rcfg.cacheDir != "" {
+			args = append(args, "-cache.dir", rcfg.cacheDir)
+		}
 		if err := startRemote(goplsPath, args...); err != nil {
 			return nil, errors.Errorf("startRemote(%q, %v): %w", goplsPath, args, err)
 		}
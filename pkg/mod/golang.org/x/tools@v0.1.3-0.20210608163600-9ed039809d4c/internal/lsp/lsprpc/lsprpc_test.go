@@ -7,6 +7,9 @@ package lsprpc
 import (
 	"context"
 	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -18,6 +21,7 @@ import (
 	"golang.org/x/tools/internal/lsp/cache"
 	"golang.org/x/tools/internal/lsp/debug"
 	"golang.org/x/tools/internal/lsp/fake"
+	"golang.org/x/tools/internal/lsp/filecache"
 	"golang.org/x/tools/internal/lsp/protocol"
 	"golang.org/x/tools/internal/testenv"
 )
@@ -321,6 +325,80 @@ func TestEnvForwarding(t *testing.T) {
 	}
 }
 
+const storedProgram = `
+-- main.go --
+package main
+
+import "lib"
+
+func main() { lib.F() }
+-- lib/lib.go --
+package lib
+
+func F() { var x int = "s"; _ = x }
+`
+
+func TestStoredDiagnostics(t *testing.T) {
+	sb, err := fake.NewSandbox(&fake.SandboxConfig{Files: fake.UnpackTxt(storedProgram), InGoPath: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+	dir, err := ioutil.TempDir("", "gopls-filecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	disk := filecache.New(dir)
+
+	// diagnose runs a new server on the workspace and returns the first
+	// non-empty diagnostics that it publishes, and whether any package was
+	// type-checked before.
+	diagnose := func() ([]protocol.Diagnostic, bool) {
+		ctx, cancel := context.WithTimeout(debug.WithInstance(context.Background(), "", ""), 30*time.Second)
+		defer cancel()
+		c := cache.New(nil)
+		c.SetDiskCache(disk)
+		ts := servertest.NewPipeServer(ctx, NewStreamServer(c, false), nil)
+		published := make(chan []protocol.Diagnostic)
+		hooks := fake.ClientHooks{
+			OnDiagnostics: func(ctx context.Context, params *protocol.PublishDiagnosticsParams) error {
+				if len(params.Diagnostics) > 0 {
+					select {
+					case published <- params.Diagnostics:
+					case <-ctx.Done():
+					}
+				}
+				return nil
+			},
+		}
+		config := fake.EditorConfig{Env: map[string]string{"GO111MODULE": "off"}}
+		ed, err := fake.NewEditor(sb, config).Connect(ctx, ts.Connect(ctx), hooks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ed.Close(ctx)
+		select {
+		case diags := <-published:
+			return diags, c.MemoryBudget().Size > 0
+		case <-ctx.Done():
+			t.Fatal("no diagnostics were published")
+		}
+		return nil, false
+	}
+
+	want, _ := diagnose()
+	// The diagnostics are stored by the first server, and reported by the
+	// second without type-checking the packages.
+	got, checked := diagnose()
+	if checked {
+		t.Errorf("packages were type-checked after a restart")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics after a restart = %v, want %v", got, want)
+	}
+}
+
 func TestListenParsing(t *testing.T) {
 	tests := []struct {
 		input, wantNetwork, wantAddr string
@@ -85,6 +85,19 @@ func DiagnosticsForMod(ctx context.Context, snapshot source.Snapshot, fh source.
 		})
 	}
 
+	// Report retracted versions and deprecated modules, as recorded in the
+	// module cache, and known vulnerabilities of the required versions.
+	statusDiags, err := statusDiagnostics(snapshot, fh, pm)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics = append(diagnostics, statusDiags...)
+	vulnDiags, err := vulnDiagnostics(ctx, snapshot, fh, pm)
+	if err != nil {
+		event.Error(ctx, "diagnosing vulnerabilities", err)
+	}
+	diagnostics = append(diagnostics, vulnDiags...)
+
 	// Packages in the workspace can contribute diagnostics to go.mod files.
 	wspkgs, err := snapshot.WorkspacePackages(ctx)
 	if err != nil && !source.IsNonFatalGoModError(err) {
@@ -80,7 +80,8 @@ func Hover(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle,
 		return nil, err
 	}
 	explanation, ok := why[req.Mod.Path]
-	if !ok {
+	status := statusHover(ctx, snapshot, req)
+	if !ok && status == "" {
 		return nil, nil
 	}
 
@@ -104,7 +105,15 @@ func Hover(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle,
 	}
 	options := snapshot.View().Options()
 	isPrivate := snapshot.View().IsGoPrivatePath(req.Mod.Path)
-	explanation = formatExplanation(explanation, req, options, isPrivate)
+	if ok {
+		explanation = formatExplanation(explanation, req, options, isPrivate)
+	}
+	if status != "" {
+		if explanation != "" {
+			explanation += "\n\n"
+		}
+		explanation += status
+	}
 	return &protocol.Hover{
 		Contents: protocol.MarkupContent{
 			Kind:  options.PreferredContentFormat,
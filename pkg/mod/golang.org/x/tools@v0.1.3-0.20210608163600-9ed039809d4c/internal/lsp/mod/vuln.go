@@ -0,0 +1,430 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/command"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+// usage records what the workspace depends on, to tell vulnerabilities
+// that matter from those that don't. Only the uses in workspace packages
+// are known, since dependencies are type-checked without function bodies:
+// an affected symbol may still be reached through a dependency.
+type usage struct {
+	imported map[string]bool // the paths of the packages in the build
+	used     map[string]bool // the objects used directly by workspace packages, as "path.Name" or "path.Type.Method"
+}
+
+// workspaceUsage returns the usage of the workspace packages. The packages
+// in the build are the workspace packages and their dependencies.
+func workspaceUsage(ctx context.Context, snapshot source.Snapshot) (*usage, error) {
+	wspkgs, err := snapshot.WorkspacePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	u := &usage{imported: make(map[string]bool), used: make(map[string]bool)}
+	var add func(pkg *types.Package)
+	add = func(pkg *types.Package) {
+		if u.imported[pkg.Path()] {
+			return
+		}
+		u.imported[pkg.Path()] = true
+		for _, imp := range pkg.Imports() {
+			add(imp)
+		}
+	}
+	for _, pkg := range wspkgs {
+		add(pkg.GetTypes())
+		u.addUses(pkg.GetTypesInfo())
+	}
+	return u, nil
+}
+
+// addUses records the package-level objects used in info.
+func (u *usage) addUses(info *types.Info) {
+	for _, obj := range info.Uses {
+		if name := symbolName(obj); name != "" {
+			u.used[obj.Pkg().Path()+"."+name] = true
+		}
+	}
+}
+
+// symbolName returns the name of a package-level object as vulnerability
+// reports spell it: "Name" for functions, types, variables and constants,
+// and "Type.Method" for methods.
+func symbolName(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return ""
+	}
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			T := recv.Type()
+			if ptr, ok := T.(*types.Pointer); ok {
+				T = ptr.Elem()
+			}
+			if named, ok := T.(*types.Named); ok {
+				return named.Obj().Name() + "." + fn.Name()
+			}
+			return ""
+		}
+	}
+	if obj.Parent() != obj.Pkg().Scope() {
+		return ""
+	}
+	return obj.Name()
+}
+
+// A finding is a vulnerability of a required module version that affects
+// packages in the build.
+type finding struct {
+	entry *source.OSVEntry
+	fixed string // the first version that fixes it, or ""
+
+	// used lists the affected symbols that the workspace uses, as
+	// "pkg.Symbol", or "pkg" when the whole package is affected.
+	used []string
+}
+
+// affected reports whether some of the entries affect version v of module
+// modPath, so that the usage of the workspace is only computed when needed.
+func affected(entries []*source.OSVEntry, modPath, v string) bool {
+	for _, e := range entries {
+		for i := range e.Affected {
+			if ok, _ := e.Affected[i].Affects(modPath, v); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findings returns the vulnerabilities in entries of version v of module
+// modPath whose affected packages are in the build.
+func findings(entries []*source.OSVEntry, u *usage, modPath, v string) []*finding {
+	var result []*finding
+	for _, e := range entries {
+		var f *finding
+		for i := range e.Affected {
+			a := &e.Affected[i]
+			ok, fixed := a.Affects(modPath, v)
+			if !ok {
+				continue
+			}
+			for _, imp := range a.Imports() {
+				if !u.imported[imp.Path] {
+					continue
+				}
+				if f == nil {
+					f = &finding{entry: e, fixed: fixed}
+				}
+				name := path.Base(imp.Path)
+				if len(imp.Symbols) == 0 {
+					f.used = append(f.used, name)
+				}
+				for _, sym := range imp.Symbols {
+					if u.used[imp.Path+"."+sym] {
+						f.used = append(f.used, name+"."+sym)
+					}
+				}
+			}
+		}
+		if f != nil {
+			result = append(result, f)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].entry.ID < result[j].entry.ID
+	})
+	return result
+}
+
+// vulnDiagnostics returns a diagnostic for each vulnerability of a
+// requirement of the go.mod file that affects the build. Vulnerabilities
+// in symbols that the workspace calls directly are warnings, the others are
+// informational.
+func vulnDiagnostics(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, pm *source.ParsedModule) ([]*source.Diagnostic, error) {
+	entries, err := snapshot.VulnDatabase(ctx)
+	if err != nil {
+		event.Error(ctx, "loading vulnerability database", err)
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	var (
+		u           *usage
+		diagnostics []*source.Diagnostic
+	)
+	for _, req := range pm.File.Require {
+		if !affected(entries, req.Mod.Path, req.Mod.Version) {
+			continue
+		}
+		if u == nil {
+			if u, err = workspaceUsage(ctx, snapshot); err != nil {
+				return nil, err
+			}
+		}
+		fs := findings(entries, u, req.Mod.Path, req.Mod.Version)
+		if len(fs) == 0 {
+			continue
+		}
+		rng, err := lineToRange(pm.Mapper, fh.URI(), req.Syntax.Start, req.Syntax.End)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fs {
+			d := &source.Diagnostic{
+				URI:      fh.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityInformation,
+				Source:   source.Vulnerability,
+				Code:     f.entry.ID,
+				CodeHref: f.entry.URL(),
+				Message:  fmt.Sprintf("%v has vulnerability %s, but the workspace does not directly use the affected symbols", req.Mod, f.entry.ID),
+			}
+			if len(f.used) > 0 {
+				d.Severity = protocol.SeverityWarning
+				d.Message = fmt.Sprintf("%v has vulnerability %s, used by the workspace through %s", req.Mod, f.entry.ID, strings.Join(f.used, ", "))
+			}
+			if f.entry.Summary != "" {
+				d.Message += ": " + f.entry.Summary
+			}
+			if f.fixed != "" {
+				title := fmt.Sprintf("Upgrade to %v", f.fixed)
+				cmd, err := command.NewUpgradeDependencyCommand(title, command.DependencyArgs{
+					URI:        protocol.URIFromSpanURI(fh.URI()),
+					AddRequire: false,
+					GoCmdArgs:  []string{req.Mod.Path + "@" + f.fixed},
+				})
+				if err != nil {
+					return nil, err
+				}
+				d.SuggestedFixes = []source.SuggestedFix{source.SuggestedFixFromCommand(cmd, protocol.QuickFix)}
+			}
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics, nil
+}
+
+// moduleStatus is what the latest version of a module in the module cache
+// says about the module and its earlier versions.
+type moduleStatus struct {
+	latest     string
+	deprecated string             // the deprecation message, if any
+	retracted  []*modfile.Retract // the retracted versions
+}
+
+// cachedModuleStatus reads the status of module modPath from the go.mod
+// file of its latest version in the module cache, so that it works
+// offline. It returns nil if the module is not in the cache.
+func cachedModuleStatus(modcache, modPath string) *moduleStatus {
+	if modcache == "" {
+		return nil
+	}
+	escaped, err := module.EscapePath(modPath)
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Join(modcache, "cache", "download", filepath.FromSlash(escaped), "@v")
+	mods, err := filepath.Glob(filepath.Join(dir, "*.mod"))
+	if err != nil || len(mods) == 0 {
+		return nil
+	}
+	var latest string
+	for _, mod := range mods {
+		v, err := module.UnescapeVersion(strings.TrimSuffix(filepath.Base(mod), ".mod"))
+		if err != nil || !semver.IsValid(v) {
+			continue
+		}
+		// Prefer releases, as the go command does.
+		if latest == "" || isRelease(v) && !isRelease(latest) || isRelease(v) == isRelease(latest) && semver.Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return nil
+	}
+	escapedVersion, err := module.EscapeVersion(latest)
+	if err != nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, escapedVersion+".mod"))
+	if err != nil {
+		return nil
+	}
+	return parseModuleStatus(latest, data)
+}
+
+func isRelease(v string) bool {
+	return semver.Prerelease(v) == ""
+}
+
+func parseModuleStatus(latest string, data []byte) *moduleStatus {
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return nil
+	}
+	status := &moduleStatus{latest: latest, retracted: f.Retract}
+	if f.Module != nil && f.Module.Syntax != nil {
+		status.deprecated = deprecation(f.Module.Syntax.Comments)
+	}
+	return status
+}
+
+// deprecation returns the message of a paragraph starting with
+// "Deprecated:" in the comments of a module directive.
+func deprecation(comments modfile.Comments) string {
+	var lines []string
+	for _, c := range append(comments.Before, comments.Suffix...) {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(c.Token, "//")))
+	}
+	for _, para := range strings.Split(strings.Join(lines, "\n"), "\n\n") {
+		if strings.HasPrefix(para, "Deprecated:") {
+			return strings.TrimSpace(strings.TrimPrefix(para, "Deprecated:"))
+		}
+	}
+	return ""
+}
+
+// retraction returns the retraction of version v, if any.
+func (s *moduleStatus) retraction(v string) *modfile.Retract {
+	for _, r := range s.retracted {
+		if semver.Compare(r.Low, v) <= 0 && semver.Compare(v, r.High) <= 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+// statusDiagnostics returns diagnostics for the requirements of the go.mod
+// file on retracted versions and deprecated modules.
+func statusDiagnostics(snapshot source.Snapshot, fh source.FileHandle, pm *source.ParsedModule) ([]*source.Diagnostic, error) {
+	modcache := snapshot.View().GoModCache()
+	var diagnostics []*source.Diagnostic
+	for _, req := range pm.File.Require {
+		status := cachedModuleStatus(modcache, req.Mod.Path)
+		if status == nil {
+			continue
+		}
+		r := status.retraction(req.Mod.Version)
+		if r == nil && status.deprecated == "" {
+			continue
+		}
+		rng, err := lineToRange(pm.Mapper, fh.URI(), req.Syntax.Start, req.Syntax.End)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			msg := fmt.Sprintf("%v is retracted", req.Mod)
+			if r.Rationale != "" {
+				msg += ": " + r.Rationale
+			}
+			d := &source.Diagnostic{
+				URI:      fh.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityWarning,
+				Source:   source.RetractedVersion,
+				Message:  msg,
+			}
+			if status.retraction(status.latest) == nil && semver.Compare(status.latest, req.Mod.Version) > 0 {
+				title := fmt.Sprintf("Upgrade to %v", status.latest)
+				cmd, err := command.NewUpgradeDependencyCommand(title, command.DependencyArgs{
+					URI:        protocol.URIFromSpanURI(fh.URI()),
+					AddRequire: false,
+					GoCmdArgs:  []string{req.Mod.Path + "@" + status.latest},
+				})
+				if err != nil {
+					return nil, err
+				}
+				d.SuggestedFixes = []source.SuggestedFix{source.SuggestedFixFromCommand(cmd, protocol.QuickFix)}
+			}
+			diagnostics = append(diagnostics, d)
+		}
+		if status.deprecated != "" {
+			diagnostics = append(diagnostics, &source.Diagnostic{
+				URI:      fh.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityInformation,
+				Source:   source.DeprecatedModule,
+				Message:  fmt.Sprintf("%s is deprecated: %s", req.Mod.Path, status.deprecated),
+				Tags:     []protocol.DiagnosticTag{protocol.Deprecated},
+			})
+		}
+	}
+	return diagnostics, nil
+}
+
+// statusHover returns the markdown describing the vulnerabilities,
+// retraction and deprecation of a requirement, or "" if there are none.
+func statusHover(ctx context.Context, snapshot source.Snapshot, req *modfile.Require) string {
+	var b strings.Builder
+	if status := cachedModuleStatus(snapshot.View().GoModCache(), req.Mod.Path); status != nil {
+		if r := status.retraction(req.Mod.Version); r != nil {
+			fmt.Fprintf(&b, "**%v is retracted**", req.Mod)
+			if r.Rationale != "" {
+				b.WriteString(": " + r.Rationale)
+			}
+			b.WriteString("\n\n")
+		}
+		if status.deprecated != "" {
+			fmt.Fprintf(&b, "**%s is deprecated**: %s\n\n", req.Mod.Path, status.deprecated)
+		}
+	}
+	if snapshot.View().Options().VulnDatabase != "" {
+		entries, err := snapshot.VulnDatabase(ctx)
+		if err != nil {
+			event.Error(ctx, "loading vulnerability database", err)
+		}
+		var fs []*finding
+		if affected(entries, req.Mod.Path, req.Mod.Version) {
+			u, err := workspaceUsage(ctx, snapshot)
+			if err != nil {
+				event.Error(ctx, "computing workspace usage", err)
+			} else {
+				fs = findings(entries, u, req.Mod.Path, req.Mod.Version)
+			}
+		}
+		for _, f := range fs {
+			id := f.entry.ID
+			if url := f.entry.URL(); url != "" {
+				id = fmt.Sprintf("[%s](%s)", id, url)
+			}
+			fmt.Fprintf(&b, "**Vulnerability %s**", id)
+			if f.entry.Summary != "" {
+				b.WriteString(": " + f.entry.Summary)
+			}
+			b.WriteString("\n\n")
+			if details := strings.TrimSpace(f.entry.Details); details != "" {
+				b.WriteString(details + "\n\n")
+			}
+			if len(f.used) > 0 {
+				fmt.Fprintf(&b, "Used by the workspace through `%s`.", strings.Join(f.used, "`, `"))
+			} else {
+				b.WriteString("The workspace does not directly use the affected symbols.")
+			}
+			if f.fixed != "" {
+				fmt.Fprintf(&b, " Fixed in %s.", f.fixed)
+			}
+			b.WriteString("\n\n")
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+const textVuln = `{
+	"id": "GO-2021-0113",
+	"summary": "Out-of-bounds read in language.ParseAcceptLanguage",
+	"affected": [{
+		"package": {"name": "golang.org/x/text", "ecosystem": "Go"},
+		"ranges": [{"type": "SEMVER", "events": [
+			{"introduced": "0"}, {"fixed": "0.3.7"},
+			{"introduced": "0.4.0"}, {"fixed": "0.4.2"}
+		]}],
+		"ecosystem_specific": {"imports": [
+			{"path": "golang.org/x/text/language", "symbols": ["Parse", "MatchStrings"]},
+			{"path": "golang.org/x/text/internal/tag"}
+		]}
+	}],
+	"database_specific": {"url": "https://pkg.go.dev/vuln/GO-2021-0113"}
+}`
+
+func TestFindings(t *testing.T) {
+	entries, err := source.ParseOSV([]byte(textVuln))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name     string
+		imported []string
+		used     []string
+		want     string // the uses of the finding, or "none" for no finding
+	}{
+		{"not imported", nil, nil, "none"},
+		{"not called", []string{"golang.org/x/text/language"}, []string{"golang.org/x/text/language.Make"}, ""},
+		{"called", []string{"golang.org/x/text/language"}, []string{"golang.org/x/text/language.Parse"}, "language.Parse"},
+		{"whole package", []string{"golang.org/x/text/internal/tag"}, nil, "tag"},
+	} {
+		u := &usage{imported: make(map[string]bool), used: make(map[string]bool)}
+		for _, path := range tt.imported {
+			u.imported[path] = true
+		}
+		for _, sym := range tt.used {
+			u.used[sym] = true
+		}
+		got := "none"
+		if fs := findings(entries, u, "golang.org/x/text", "v0.3.5"); len(fs) > 0 {
+			got = strings.Join(fs[0].used, ",")
+			if fs[0].fixed != "v0.3.7" {
+				t.Errorf("%s: fixed = %q, want v0.3.7", tt.name, fs[0].fixed)
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s: uses = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	// The language package declares a function, a type with value and
+	// pointer methods, and a variable.
+	lang := types.NewPackage("golang.org/x/text/language", "language")
+	tag := types.NewNamed(types.NewTypeName(token.NoPos, lang, "Tag", nil), types.NewStruct(nil, nil), nil)
+	lang.Scope().Insert(tag.Obj())
+	sig := types.NewSignature(nil, nil, types.NewTuple(types.NewVar(token.NoPos, lang, "", tag)), false)
+	lang.Scope().Insert(types.NewFunc(token.NoPos, lang, "Parse", sig))
+	for _, recv := range []types.Type{tag, types.NewPointer(tag)} {
+		name := "String"
+		if _, ok := recv.(*types.Pointer); ok {
+			name = "Set"
+		}
+		recvSig := types.NewSignature(types.NewVar(token.NoPos, lang, "", recv), nil, nil, false)
+		tag.AddMethod(types.NewFunc(token.NoPos, lang, name, recvSig))
+	}
+	lang.Scope().Insert(types.NewVar(token.NoPos, lang, "Und", tag))
+	lang.MarkComplete()
+
+	const src = `package p
+
+import "golang.org/x/text/language"
+
+func F() {
+	t := language.Parse()
+	t.String()
+	t.Set()
+	var Parse int
+	_ = Parse
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Uses: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{Importer: importerFunc(func(string) (*types.Package, error) { return lang, nil })}
+	if _, err := conf.Check("example.com/p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+	u := &usage{imported: make(map[string]bool), used: make(map[string]bool)}
+	u.addUses(info)
+	for sym, want := range map[string]bool{
+		"golang.org/x/text/language.Parse":      true,
+		"golang.org/x/text/language.Tag.String": true,
+		"golang.org/x/text/language.Tag.Set":    true,
+		"golang.org/x/text/language.Und":        false,
+		"golang.org/x/text/language.Tag":        false,
+		"example.com/p.Parse":                   false, // a local variable
+		"example.com/p.t":                       false,
+	} {
+		if u.used[sym] != want {
+			t.Errorf("used[%s] = %v, want %v", sym, u.used[sym], want)
+		}
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func TestModuleStatus(t *testing.T) {
+	const gomod = `// Deprecated: use example.com/new instead.
+module example.com/old
+
+go 1.16
+
+retract (
+	v1.0.1 // Published too early.
+	[v1.1.0, v1.1.3]
+)
+`
+	modcache, err := ioutil.TempDir("", "modcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(modcache)
+	dir := filepath.Join(modcache, "cache", "download", "example.com", "old", "@v")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for v, content := range map[string]string{
+		"v1.0.0":     "module example.com/old\n",
+		"v1.2.0":     gomod,
+		"v1.3.0-pre": "module example.com/old\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, v+".mod"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status := cachedModuleStatus(modcache, "example.com/old")
+	if status == nil {
+		t.Fatal("no status for example.com/old")
+	}
+	if status.latest != "v1.2.0" {
+		t.Errorf("latest = %s, want v1.2.0", status.latest)
+	}
+	if want := "use example.com/new instead."; status.deprecated != want {
+		t.Errorf("deprecated = %q, want %q", status.deprecated, want)
+	}
+	for v, want := range map[string]string{
+		"v1.0.0": "-",
+		"v1.0.1": "Published too early.",
+		"v1.1.2": "",
+		"v1.2.0": "-",
+	} {
+		got := "-"
+		if r := status.retraction(v); r != nil {
+			got = r.Rationale
+		}
+		if got != want {
+			t.Errorf("retraction(%s) = %q, want %q", v, got, want)
+		}
+	}
+	if cachedModuleStatus(modcache, "example.com/missing") != nil {
+		t.Errorf("found a status for a module that is not in the cache")
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+// The types of the pull diagnostics of LSP 3.17 that are missing from the
+// generated code.
+
+// DiagnosticOptions are the options of the diagnostic provider of the
+// server.
+type DiagnosticOptions struct {
+	// An optional identifier under which the diagnostics are managed by the
+	// client.
+	Identifier string `json:"identifier,omitempty"`
+	// Whether the language has inter file dependencies meaning that editing
+	// code in one file can result in a different diagnostic set in another
+	// file.
+	InterFileDependencies bool `json:"interFileDependencies"`
+	// The server provides support for workspace diagnostics as well.
+	WorkspaceDiagnostics bool `json:"workspaceDiagnostics"`
+	WorkDoneProgressOptions
+}
+
+// The kinds of document diagnostic reports.
+const (
+	// A diagnostic report with a full set of problems.
+	DiagnosticFull = "full"
+	// A report indicating that the last returned report is still accurate.
+	DiagnosticUnchanged = "unchanged"
+)
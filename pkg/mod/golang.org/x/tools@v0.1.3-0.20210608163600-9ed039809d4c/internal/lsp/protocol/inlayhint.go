@@ -0,0 +1,66 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+// The inlay hint request of LSP 3.17, which is still a proposal, and is
+// missing from the generated code. The names are those the generator will
+// use, so that this file can be removed once it knows about inlay hints.
+
+// InlayHint is a hint displayed inline in the document, such as the name of
+// a parameter or the type of a variable.
+type InlayHint struct {
+	// Position is where the hint is displayed.
+	Position Position `json:"position"`
+	// Label is the text of the hint. The protocol also allows a plain string,
+	// which gopls doesn't send.
+	Label []InlayHintLabelPart `json:"label"`
+	// Kind is the kind of the hint, or zero if it has none.
+	Kind InlayHintKind `json:"kind,omitempty"`
+	// Tooltip is displayed when hovering the hint.
+	Tooltip *MarkupContent `json:"tooltip,omitempty"`
+	// PaddingLeft and PaddingRight request padding before and after the
+	// hint, in the background color of the editor.
+	PaddingLeft  bool `json:"paddingLeft,omitempty"`
+	PaddingRight bool `json:"paddingRight,omitempty"`
+}
+
+// InlayHintLabelPart is a part of the label of an inlay hint.
+type InlayHintLabelPart struct {
+	// Value is the text of the part.
+	Value string `json:"value"`
+	// Tooltip is displayed when hovering the part.
+	Tooltip *MarkupContent `json:"tooltip,omitempty"`
+	// Location is the source code the part refers to, if any.
+	Location *Location `json:"location,omitempty"`
+	// Command is run when the part is clicked, if any.
+	Command *Command `json:"command,omitempty"`
+}
+
+// InlayHintKind is the kind of an inlay hint.
+type InlayHintKind float64
+
+const (
+	// Type is the kind of the hints that annotate a type.
+	Type InlayHintKind = 1
+	// Parameter is the kind of the hints that name a parameter.
+	Parameter InlayHintKind = 2
+)
+
+// InlayHintOptions are the options of the inlay hint capability.
+type InlayHintOptions struct {
+	// ResolveProvider reports whether the server resolves additional
+	// information for an inlay hint.
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+	WorkDoneProgressOptions
+}
+
+// InlayHintParams are the parameters of a textDocument/inlayHint request.
+type InlayHintParams struct {
+	// TextDocument is the document to compute the hints of.
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	// Range is the visible range of the document.
+	Range Range `json:"range"`
+	WorkDoneProgressParams
+}
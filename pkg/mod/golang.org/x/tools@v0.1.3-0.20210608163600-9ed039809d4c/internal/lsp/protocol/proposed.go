@@ -0,0 +1,149 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+// The requests of LSP 3.17 that are missing from the generated code, or that
+// are generated with placeholder types. ServerHandler and ClientHandler
+// dispatch them before the generated requests, to the servers implementing
+// ProposedServer and the clients implementing ProposedClient.
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang.org/x/tools/internal/jsonrpc2"
+	errors "golang.org/x/xerrors"
+)
+
+// ProposedServer is implemented by the servers handling the requests of LSP
+// 3.17 that are missing from Server.
+type ProposedServer interface {
+	// DocumentDiagnostic handles textDocument/diagnostic, which Server
+	// declares as Diagnostic with placeholder types.
+	DocumentDiagnostic(context.Context, *DocumentDiagnosticParams) (DocumentDiagnosticReport, error)
+	// PrepareTypeHierarchy handles textDocument/prepareTypeHierarchy.
+	PrepareTypeHierarchy(context.Context, *TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error)
+	// Supertypes handles typeHierarchy/supertypes.
+	Supertypes(context.Context, *TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error)
+	// Subtypes handles typeHierarchy/subtypes.
+	Subtypes(context.Context, *TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error)
+	// InlayHint handles textDocument/inlayHint.
+	InlayHint(context.Context, *InlayHintParams) ([]InlayHint, error)
+}
+
+// ProposedClient has the requests of LSP 3.17 sent to the client that are
+// missing from Client.
+type ProposedClient interface {
+	// DiagnosticRefresh asks the client to pull the diagnostics of its
+	// documents again.
+	DiagnosticRefresh(context.Context) error
+}
+
+func proposedServerDispatch(ctx context.Context, server Server, reply jsonrpc2.Replier, r jsonrpc2.Request) (bool, error) {
+	proposed, ok := server.(ProposedServer)
+	if !ok {
+		return false, nil
+	}
+	switch r.Method() {
+	case "textDocument/diagnostic": // req
+		var params DocumentDiagnosticParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := proposed.DocumentDiagnostic(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "textDocument/prepareTypeHierarchy": // req
+		var params TypeHierarchyPrepareParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := proposed.PrepareTypeHierarchy(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "typeHierarchy/supertypes": // req
+		var params TypeHierarchySupertypesParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := proposed.Supertypes(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "typeHierarchy/subtypes": // req
+		var params TypeHierarchySubtypesParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := proposed.Subtypes(ctx, &params)
+		return true, reply(ctx, resp, err)
+	case "textDocument/inlayHint": // req
+		var params InlayHintParams
+		if err := json.Unmarshal(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := proposed.InlayHint(ctx, &params)
+		return true, reply(ctx, resp, err)
+	default:
+		return false, nil
+	}
+}
+
+func proposedClientDispatch(ctx context.Context, client Client, reply jsonrpc2.Replier, r jsonrpc2.Request) (bool, error) {
+	proposed, ok := client.(ProposedClient)
+	if !ok {
+		return false, nil
+	}
+	switch r.Method() {
+	case "workspace/diagnostic/refresh": // req
+		if len(r.Params()) > 0 {
+			return true, reply(ctx, nil, errors.Errorf("%w: expected no params", jsonrpc2.ErrInvalidParams))
+		}
+		err := proposed.DiagnosticRefresh(ctx)
+		return true, reply(ctx, nil, err)
+	default:
+		return false, nil
+	}
+}
+
+func (s *serverDispatcher) DocumentDiagnostic(ctx context.Context, params *DocumentDiagnosticParams) (DocumentDiagnosticReport, error) {
+	var result DocumentDiagnosticReport
+	if err := s.sender.Call(ctx, "textDocument/diagnostic", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) PrepareTypeHierarchy(ctx context.Context, params *TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	if err := s.sender.Call(ctx, "textDocument/prepareTypeHierarchy", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) Supertypes(ctx context.Context, params *TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	if err := s.sender.Call(ctx, "typeHierarchy/supertypes", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) Subtypes(ctx context.Context, params *TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error) {
+	var result []TypeHierarchyItem
+	if err := s.sender.Call(ctx, "typeHierarchy/subtypes", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *serverDispatcher) InlayHint(ctx context.Context, params *InlayHintParams) ([]InlayHint, error) {
+	var result []InlayHint
+	if err := s.sender.Call(ctx, "textDocument/inlayHint", params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *clientDispatcher) DiagnosticRefresh(ctx context.Context) error {
+	return c.sender.Call(ctx, "workspace/diagnostic/refresh", nil, nil)
+}
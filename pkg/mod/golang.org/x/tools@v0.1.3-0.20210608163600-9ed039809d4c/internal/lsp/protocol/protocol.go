@@ -25,6 +25,7 @@ var (
 
 type ClientCloser interface {
 	Client
+	ProposedClient
 	io.Closer
 }
 
@@ -115,7 +116,10 @@ func ClientHandler(client Client, handler jsonrpc2.Handler) jsonrpc2.Handler {
 			ctx := xcontext.Detach(ctx)
 			return reply(ctx, nil, RequestCancelledError)
 		}
-		handled, err := clientDispatch(ctx, client, reply, req)
+		handled, err := proposedClientDispatch(ctx, client, reply, req)
+		if !handled && err == nil {
+			handled, err = clientDispatch(ctx, client, reply, req)
+		}
 		if handled || err != nil {
 			return err
 		}
@@ -137,7 +141,10 @@ func ClientHandlerV2(client Client) jsonrpc2_v2.Handler {
 			result, resErr = res, err
 			return nil
 		}
-		_, err := clientDispatch(ctx, client, replier, req1)
+		handled, err := proposedClientDispatch(ctx, client, replier, req1)
+		if !handled && err == nil {
+			_, err = clientDispatch(ctx, client, replier, req1)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -151,7 +158,10 @@ func ServerHandler(server Server, handler jsonrpc2.Handler) jsonrpc2.Handler {
 			ctx := xcontext.Detach(ctx)
 			return reply(ctx, nil, RequestCancelledError)
 		}
-		handled, err := serverDispatch(ctx, server, reply, req)
+		handled, err := proposedServerDispatch(ctx, server, reply, req)
+		if !handled && err == nil {
+			handled, err = serverDispatch(ctx, server, reply, req)
+		}
 		if handled || err != nil {
 			return err
 		}
@@ -182,7 +192,10 @@ func ServerHandlerV2(server Server) jsonrpc2_v2.Handler {
 			result, resErr = res, err
 			return nil
 		}
-		_, err := serverDispatch(ctx, server, replier, req1)
+		handled, err := proposedServerDispatch(ctx, server, replier, req1)
+		if !handled && err == nil {
+			_, err = serverDispatch(ctx, server, replier, req1)
+		}
 		if err != nil {
 			return nil, err
 		}
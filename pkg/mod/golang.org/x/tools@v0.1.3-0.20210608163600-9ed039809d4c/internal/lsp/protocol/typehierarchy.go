@@ -0,0 +1,69 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+// The type hierarchy requests of LSP 3.17, which are still a proposal, and
+// are missing from the generated code.
+
+// TypeHierarchyItem is a type of the hierarchy, as returned by
+// textDocument/prepareTypeHierarchy, typeHierarchy/supertypes and
+// typeHierarchy/subtypes.
+type TypeHierarchyItem struct {
+	// Name is the name of the type.
+	Name string `json:"name"`
+	// Kind is the kind of the type.
+	Kind SymbolKind `json:"kind"`
+	// Tags are the tags of the type.
+	Tags []SymbolTag `json:"tags,omitempty"`
+	// Detail is more detail about the type, e.g. its package.
+	Detail string `json:"detail,omitempty"`
+	// URI is the document of the type declaration.
+	URI DocumentURI `json:"uri"`
+	// Range encloses the type declaration, without leading or trailing
+	// whitespace.
+	Range Range `json:"range"`
+	// SelectionRange is the range to select when the type is picked, e.g. its
+	// name. It is contained in Range.
+	SelectionRange Range `json:"selectionRange"`
+	// Data is preserved from the prepare request to the supertypes and
+	// subtypes requests.
+	Data interface{} `json:"data,omitempty"`
+}
+
+// TypeHierarchyOptions are the options of the type hierarchy capability.
+type TypeHierarchyOptions struct {
+	WorkDoneProgressOptions
+}
+
+// TypeHierarchyRegistrationOptions are the options of the dynamic
+// registration of the type hierarchy capability.
+type TypeHierarchyRegistrationOptions struct {
+	TextDocumentRegistrationOptions
+	TypeHierarchyOptions
+	StaticRegistrationOptions
+}
+
+// TypeHierarchyPrepareParams are the parameters of a
+// textDocument/prepareTypeHierarchy request.
+type TypeHierarchyPrepareParams struct {
+	TextDocumentPositionParams
+	WorkDoneProgressParams
+}
+
+// TypeHierarchySupertypesParams are the parameters of a
+// typeHierarchy/supertypes request.
+type TypeHierarchySupertypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+	WorkDoneProgressParams
+	PartialResultParams
+}
+
+// TypeHierarchySubtypesParams are the parameters of a typeHierarchy/subtypes
+// request.
+type TypeHierarchySubtypesParams struct {
+	Item TypeHierarchyItem `json:"item"`
+	WorkDoneProgressParams
+	PartialResultParams
+}
@@ -0,0 +1,237 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/debug/tag"
+	"golang.org/x/tools/internal/lsp/mod"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+	"golang.org/x/tools/internal/lsp/template"
+	"golang.org/x/tools/internal/span"
+)
+
+// Pull diagnostics are computed by the same code that computes the pushed
+// diagnostics, and read from the same cache of diagnostic reports. The result
+// ID of a report is the hash of its diagnostics, so that a client holding the
+// result of an earlier request, possibly for an earlier snapshot, is told
+// that its diagnostics are unchanged.
+//
+// Once a client has pulled diagnostics, it is asked to pull them again after
+// each diagnostics pass, as the diagnostics of a file change with the files
+// it depends on.
+
+// DocumentDiagnostic implements protocol.ProposedServer.
+func (s *Server) DocumentDiagnostic(ctx context.Context, params *protocol.DocumentDiagnosticParams) (protocol.DocumentDiagnosticReport, error) {
+	return s.diagnostic(ctx, params)
+}
+
+func (s *Server) diagnostic(ctx context.Context, params *protocol.DocumentDiagnosticParams) (protocol.DocumentDiagnosticReport, error) {
+	s.setPulledDiagnostics()
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.UnknownKind)
+	defer release()
+	if !ok {
+		if err != nil {
+			return nil, err
+		}
+		return &protocol.RelatedFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+				Kind:  protocol.DiagnosticFull,
+				Items: []protocol.Diagnostic{},
+			},
+		}, nil
+	}
+	s.diagnoseFile(ctx, snapshot, fh)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	diags := s.storedDiagnostics(snapshot, fh.URI())
+	resultID := hashDiagnostics(diags...)
+	if resultID == params.PreviousResultID {
+		return &protocol.RelatedUnchangedDocumentDiagnosticReport{
+			UnchangedDocumentDiagnosticReport: protocol.UnchangedDocumentDiagnosticReport{
+				Kind:     protocol.DiagnosticUnchanged,
+				ResultID: resultID,
+			},
+		}, nil
+	}
+	return &protocol.RelatedFullDocumentDiagnosticReport{
+		FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+			Kind:     protocol.DiagnosticFull,
+			ResultID: resultID,
+			Items:    toProtocolDiagnostics(diags),
+		},
+	}, nil
+}
+
+func (s *Server) diagnosticWorkspace(ctx context.Context, params *protocol.WorkspaceDiagnosticParams) (*protocol.WorkspaceDiagnosticReport, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.diagnosticWorkspace")
+	defer done()
+
+	s.setPulledDiagnostics()
+
+	previous := make(map[span.URI]string)
+	for _, prev := range params.PreviousResultIds {
+		previous[prev.URI.SpanURI()] = prev.Value
+	}
+	report := &protocol.WorkspaceDiagnosticReport{
+		Items: []protocol.WorkspaceDocumentDiagnosticReport{},
+	}
+	seen := make(map[span.URI]bool)
+	for _, view := range s.session.Views() {
+		snapshot, release := view.Snapshot(ctx)
+		s.diagnose(ctx, snapshot, false)
+		if ctx.Err() != nil {
+			release()
+			return nil, ctx.Err()
+		}
+		for _, uri := range s.diagnosedFiles() {
+			if seen[uri] {
+				continue
+			}
+			fh := snapshot.FindFile(uri)
+			if fh == nil {
+				continue
+			}
+			if v, err := s.session.ViewOf(uri); err != nil || v != view {
+				continue
+			}
+			seen[uri] = true
+			diags := s.storedDiagnostics(snapshot, uri)
+			prevID, hasPrev := previous[uri]
+			if len(diags) == 0 && !hasPrev {
+				continue
+			}
+			resultID := hashDiagnostics(diags...)
+			if resultID == prevID {
+				report.Items = append(report.Items, &protocol.WorkspaceUnchangedDocumentDiagnosticReport{
+					URI:     protocol.URIFromSpanURI(uri),
+					Version: fh.Version(),
+					UnchangedDocumentDiagnosticReport: protocol.UnchangedDocumentDiagnosticReport{
+						Kind:     protocol.DiagnosticUnchanged,
+						ResultID: resultID,
+					},
+				})
+				continue
+			}
+			report.Items = append(report.Items, &protocol.WorkspaceFullDocumentDiagnosticReport{
+				URI:     protocol.URIFromSpanURI(uri),
+				Version: fh.Version(),
+				FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+					Kind:     protocol.DiagnosticFull,
+					ResultID: resultID,
+					Items:    toProtocolDiagnostics(diags),
+				},
+			})
+		}
+		release()
+	}
+	return report, nil
+}
+
+func diagnosticRegistration() protocol.Registration {
+	return protocol.Registration{
+		ID:     "textDocument/diagnostic",
+		Method: "textDocument/diagnostic",
+		RegisterOptions: &protocol.DiagnosticOptions{
+			InterFileDependencies: true,
+			WorkspaceDiagnostics:  true,
+		},
+	}
+}
+
+func (s *Server) setPulledDiagnostics() {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	s.pulledDiagnostics = true
+}
+
+// refreshPulledDiagnostics asks the client to pull its diagnostics again, if
+// it has pulled diagnostics before.
+func (s *Server) refreshPulledDiagnostics(ctx context.Context) {
+	s.diagnosticsMu.Lock()
+	pulled := s.pulledDiagnostics
+	s.diagnosticsMu.Unlock()
+	if !pulled {
+		return
+	}
+	if err := s.client.DiagnosticRefresh(ctx); err != nil && ctx.Err() == nil {
+		event.Error(ctx, "refreshing pulled diagnostics", err)
+	}
+}
+
+// diagnoseFile computes and stores the diagnostics of a single file. Unlike
+// the workspace-wide diagnostics, the analyzers are run on the packages of
+// the file even if it isn't open, since the client asked for them.
+func (s *Server) diagnoseFile(ctx context.Context, snapshot source.Snapshot, fh source.VersionedFileHandle) {
+	ctx, done := event.Start(ctx, "lsp.Server.diagnoseFile", tag.Snapshot.Of(snapshot.ID()), tag.URI.Of(fh.URI()))
+	defer done()
+
+	switch fh.Kind() {
+	case source.Mod:
+		modReports, err := mod.Diagnostics(ctx, snapshot)
+		if err != nil {
+			event.Error(ctx, "warning: diagnose go.mod", err, tag.URI.Of(fh.URI()), tag.Snapshot.Of(snapshot.ID()))
+			return
+		}
+		for id, diags := range modReports {
+			if id.URI != "" {
+				s.storeDiagnostics(snapshot, id.URI, modSource, diags)
+			}
+		}
+	case source.Tmpl:
+		s.storeDiagnostics(snapshot, fh.URI(), typeCheckSource, template.Diagnose(ctx, snapshot, fh))
+	case source.Go:
+		if snapshot.IsBuiltin(ctx, fh.URI()) || snapshot.IgnoredFile(fh.URI()) {
+			return
+		}
+		pkgs, err := snapshot.PackagesForFile(ctx, fh.URI(), source.TypecheckFull)
+		if err != nil || len(pkgs) == 0 {
+			if diagnostic := s.checkForOrphanedFile(ctx, snapshot, fh); diagnostic != nil {
+				s.storeDiagnostics(snapshot, fh.URI(), orphanedSource, []*source.Diagnostic{diagnostic})
+			}
+			return
+		}
+		for _, pkg := range pkgs {
+			s.diagnosePkg(ctx, snapshot, pkg, true)
+		}
+	}
+}
+
+// storedDiagnostics returns the diagnostics stored for uri that are at least
+// as recent as snapshot.
+func (s *Server) storedDiagnostics(snapshot source.Snapshot, uri span.URI) []*source.Diagnostic {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	r := s.diagnostics[uri]
+	if r == nil {
+		return nil
+	}
+	var diags []*source.Diagnostic
+	for _, report := range r.reports {
+		if report.snapshotID < snapshot.ID() {
+			continue
+		}
+		for _, d := range report.diags {
+			diags = append(diags, d)
+		}
+	}
+	source.SortDiagnostics(diags)
+	return diags
+}
+
+// diagnosedFiles returns the files for which diagnostics have been stored.
+func (s *Server) diagnosedFiles() []span.URI {
+	s.diagnosticsMu.Lock()
+	defer s.diagnosticsMu.Unlock()
+	uris := make([]span.URI, 0, len(s.diagnostics))
+	for uri := range s.diagnostics {
+		uris = append(uris, uri)
+	}
+	return uris
+}
@@ -0,0 +1,62 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/tests"
+	"golang.org/x/tools/internal/span"
+)
+
+// refreshClient counts the requests to refresh the pulled diagnostics.
+type refreshClient struct {
+	testClient
+	refreshes int
+}
+
+func (c *refreshClient) DiagnosticRefresh(context.Context) error {
+	c.refreshes++
+	return nil
+}
+
+func TestDiagnosticRefresh(t *testing.T) {
+	const module = `
+-- go.mod --
+module example.com
+
+go 1.16
+-- a/a.go --
+package a
+
+func A() {}
+`
+	dir := writeTestModule(t, module)
+	ctx := tests.Context(t)
+	client := &refreshClient{}
+	s := newTestServer(t, ctx, dir, client)
+	snapshot, release := s.session.Views()[0].Snapshot(ctx)
+	defer release()
+
+	// A client that only uses pushed diagnostics is not asked to pull them.
+	s.diagnoseSnapshot(snapshot, nil, false)
+	if client.refreshes != 0 {
+		t.Fatalf("got %d refreshes before pulling diagnostics, want 0", client.refreshes)
+	}
+
+	uri := span.URIFromPath(filepath.Join(dir, "a", "a.go"))
+	if _, err := s.DocumentDiagnostic(ctx, &protocol.DocumentDiagnosticParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromSpanURI(uri)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.diagnoseSnapshot(snapshot, nil, false)
+	if client.refreshes != 1 {
+		t.Errorf("got %d refreshes after pulling diagnostics, want 1", client.refreshes)
+	}
+}
@@ -88,6 +88,8 @@ type Server struct {
 
 	diagnosticsMu sync.Mutex
 	diagnostics   map[span.URI]*fileReports
+	// pulledDiagnostics is set once the client has pulled diagnostics.
+	pulledDiagnostics bool
 
 	// gcOptimizationDetails describes the packages for which we want
 	// optimization details to be included in the diagnostics. The key is the
@@ -269,6 +269,19 @@ var GeneratedAPIJSON = &APIJSON{
 				Status:     "experimental",
 				Hierarchy:  "ui.completion",
 			},
+			{
+				Name: "postfixSnippets",
+				Type: "[]source.PostfixSnippet",
+				Doc:  "postfixSnippets adds postfix snippets to the built-in ones, when\nexperimentalPostfixCompletions is enabled. The body of a snippet is a\ntext/template executed on the operand of the completion; a snippet\nwhose body produces no text does not apply. The dot of the body has\nthe following fields and methods:\n\n* `X`, `Obj`, `Type`: the operand as text, its object and its type.\n* `Kind`, `ElemType`, `KeyType`, `Tuple`, `IsError`: predicates and\ntypes derived from the type of the operand.\n* `Fun`, `Args`: the function and the arguments of an operand that\nis a call.\n* `StmtOK`: whether the snippet may expand to a statement.\n* `Import`, `VarName`, `TypeName`, `EscapeQuotes`, `Cursor`: helpers\nto import a package, choose a variable name, print a type, quote the\noperand and place the cursor.\n\nExample Usage:\n\n```json5\n\"gopls\": {\n...\n  \"postfixSnippets\": [{\n    \"label\": \"errwrap\",\n    \"details\": \"wrap error\",\n    \"body\": \"{{if .IsError}}{{.Import \\\"fmt\\\"}}.Errorf(\\\"{{.Cursor}}: %w\\\", {{.X}}){{end}}\"\n  }]\n...\n}\n```\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: nil,
+				Default:    "[]",
+				Status:     "experimental",
+				Hierarchy:  "ui.completion",
+			},
 			{
 				Name: "importShortcut",
 				Type: "enum",
@@ -347,6 +360,28 @@ var GeneratedAPIJSON = &APIJSON{
 				Status:    "advanced",
 				Hierarchy: "ui.navigation",
 			},
+			{
+				Name: "typeHierarchyScope",
+				Type: "enum",
+				Doc:  "typeHierarchyScope controls which packages are searched for the\nsupertypes and subtypes of a type in the type hierarchy.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: []EnumValue{
+					{
+						Value: "\"All\"",
+						Doc:   "`\"All\"` also searches the dependencies of the workspace.\n",
+					},
+					{
+						Value: "\"Workspace\"",
+						Doc:   "`\"Workspace\"` searches the packages of the workspace.\n",
+					},
+				},
+				Default:   "\"Workspace\"",
+				Status:    "advanced",
+				Hierarchy: "ui.navigation",
+			},
 			{
 				Name: "analyses",
 				Type: "map[string]bool",
@@ -414,6 +449,11 @@ var GeneratedAPIJSON = &APIJSON{
 							Doc:     "find structs that would use less memory if their fields were sorted\n\nThis analyzer find structs that can be rearranged to use less memory, and provides\na suggested edit with the optimal order.\n\nNote that there are two different diagnostics reported. One checks struct size,\nand the other reports \"pointer bytes\" used. Pointer bytes is how many bytes of the\nobject that the garbage collector has to potentially scan for pointers, for example:\n\n\tstruct { uint32; string }\n\nhave 16 pointer bytes because the garbage collector has to scan up through the string's\ninner pointer.\n\n\tstruct { string; *uint32 }\n\nhas 24 pointer bytes because it has to scan further through the *uint32.\n\n\tstruct { string; uint32 }\n\nhas 8 because it can stop immediately after the string pointer.\n",
 							Default: "false",
 						},
+						{
+							Name:    "\"fieldtag\"",
+							Doc:     "check the names and options of struct field tags\n\nThis analyzer complements structtag. It reports the unknown options of\nthe json, xml and yaml keys of struct field tags, such as the misspelled\noption of\n\tName string `json:\"name,omitemty\"`\nand the JSON names that encoding/json ignores because they are not valid.\n\nIt also reports the fields that encoding/json silently ignores because\nanother field of the struct, or of the structs it embeds, has the same\nJSON name at the same depth, for example:\n\ttype T struct {\n\t\tID    string\n\t\tOther string `json:\"ID\"`\n\t}\nwhere the ID field is not encoded.",
+							Default: "true",
+						},
 						{
 							Name:    "\"httpresponse\"",
 							Doc:     "check for mistakes using HTTP responses\n\nA common mistake when using the net/http package is to defer a function\ncall to close the http.Response Body before checking the error that\ndetermines whether the response is valid:\n\n\tresp, err := http.Head(url)\n\tdefer resp.Body.Close()\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\t// (defer statement belongs here)\n\nThis checker helps uncover latent nil dereference bugs by reporting a\ndiagnostic for such mistakes.",
@@ -549,6 +589,11 @@ var GeneratedAPIJSON = &APIJSON{
 							Doc:     "suggested fixes for \"no result values expected\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no result values expected\". For example:\n\tfunc z() { return nil }\nwill turn into\n\tfunc z() { return }\n",
 							Default: "true",
 						},
+						{
+							Name:    "\"stubmethods\"",
+							Doc:     "stub methods analyzer\n\nThis analyzer notes type errors where a value of a concrete type is\nassigned, returned, passed or converted to an interface type that the\nconcrete type does not implement, for example:\n\tvar _ io.Reader = T{}\nBecause the suggested fix, which declares the missing methods of T after\nits declaration, can edit other files of the package, callers should\ncompute it separately.",
+							Default: "true",
+						},
 						{
 							Name:    "\"undeclaredname\"",
 							Doc:     "suggested fixes for \"undeclared name: <>\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"undeclared name: <>\". It will insert a new statement:\n\"<> := \".",
@@ -626,6 +671,53 @@ var GeneratedAPIJSON = &APIJSON{
 				Status:     "experimental",
 				Hierarchy:  "ui.diagnostic",
 			},
+			{
+				Name: "vulnDatabase",
+				Type: "string",
+				Doc:  "vulnDatabase is the path of a local directory holding a vulnerability\ndatabase in the OSV format, such as a checkout of\nhttps://github.com/golang/vulndb. When set, the requirements of go.mod\nfiles are checked against it, and vulnerable modules are reported\nalong with whether the workspace calls the affected symbols directly.\n",
+				EnumKeys: EnumKeys{
+					ValueType: "",
+					Keys:      nil,
+				},
+				EnumValues: nil,
+				Default:    "\"\"",
+				Status:     "experimental",
+				Hierarchy:  "ui.diagnostic",
+			},
+			{
+				Name: "hints",
+				Type: "map[string]bool",
+				Doc:  "hints specify the inlay hints that users want to see. All hints are\ndisabled by default.\n\nExample Usage:\n\n```json5\n\"gopls\": {\n...\n  \"hints\": {\n    \"parameterNames\": true,  // Show the parameter names of call arguments.\n    \"constantValues\": true   // Show the values of iota constants.\n  }\n...\n}\n```\n",
+				EnumKeys: EnumKeys{
+					ValueType: "bool",
+					Keys: []EnumKey{
+						{
+							Name:    "\"assignVariableTypes\"",
+							Doc:     "Enable/disable inlay hints for variable types in assign statements:\n\n\ti/* int*/, j/* int*/ := 0, len(r)-1",
+							Default: "false",
+						},
+						{
+							Name:    "\"compositeLiteralFields\"",
+							Doc:     "Enable/disable inlay hints for composite literal field names:\n\n\t{in: \"Hello, world\", want: \"dlrow ,olleH\"}",
+							Default: "false",
+						},
+						{
+							Name:    "\"constantValues\"",
+							Doc:     "Enable/disable inlay hints for constant values:\n\n\tconst (\n\t\tKindNone   Kind = iota/* = 0*/\n\t\tKindPrint/*  = 1*/\n\t\tKindPrintf/* = 2*/\n\t)",
+							Default: "false",
+						},
+						{
+							Name:    "\"parameterNames\"",
+							Doc:     "Enable/disable inlay hints for parameter names:\n\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)",
+							Default: "false",
+						},
+					},
+				},
+				EnumValues: nil,
+				Default:    "{}",
+				Status:     "experimental",
+				Hierarchy:  "ui.inlayhint",
+			},
 			{
 				Name: "codelenses",
 				Type: "map[string]bool",
@@ -650,7 +742,7 @@ var GeneratedAPIJSON = &APIJSON{
 						},
 						{
 							Name:    "\"test\"",
-							Doc:     "Runs `go test` for a specific set of test or benchmark functions.",
+							Doc:     "Runs `go test` for a specific set of test or benchmark functions, or\nsubtests.",
 							Default: "false",
 						},
 						{
@@ -748,12 +840,24 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "Applies a fix to a region of source code.",
 			ArgDoc:  "{\n\t// The fix to apply.\n\t\"Fix\": string,\n\t// The file URI for the document to fix.\n\t\"URI\": string,\n\t// The document range to scan for fixes.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n}",
 		},
+		{
+			Command: "gopls.change_signature",
+			Title:   "Change function signature",
+			Doc:     "Removes, reorders, and adds parameters of a function or method, and\nupdates its calls, and the interface methods and implementations that\nmust keep the same signature.",
+			ArgDoc:  "{\n\t// The file URI containing the name of the function.\n\t\"URI\": string,\n\t// The position of the name of the function.\n\t\"Position\": {\n\t\t\"line\": uint32,\n\t\t\"character\": uint32,\n\t},\n\t// The new parameters of the function.\n\t\"Params\": []struct{OldIndex int; Name string; Type string; Default string},\n}",
+		},
 		{
 			Command: "gopls.check_upgrades",
 			Title:   "Check for upgrades",
 			Doc:     "Checks for module upgrades.",
 			ArgDoc:  "{\n\t// The go.mod file URI.\n\t\"URI\": string,\n\t// The modules to check.\n\t\"Modules\": []string,\n}",
 		},
+		{
+			Command: "gopls.extract_to_new_file",
+			Title:   "Extract declarations to new file",
+			Doc:     "Moves the selected top-level declarations to a new file of the same\npackage.",
+			ArgDoc:  "{\n\t// The file URI containing the declarations.\n\t\"URI\": string,\n\t// The document range selecting the declarations.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n}",
+		},
 		{
 			Command: "gopls.gc_details",
 			Title:   "Toggle gc_details",
@@ -784,6 +888,12 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "that are importable from the given URI.",
 			ArgDoc:  "{\n\t// The file URI.\n\t\"URI\": string,\n}",
 		},
+		{
+			Command: "gopls.modify_tags",
+			Title:   "Modify struct tags",
+			Doc:     "Adds or removes keys of the tags of struct fields.",
+			ArgDoc:  "{\n\t// The file URI containing the struct type.\n\t\"URI\": string,\n\t// The document range selecting the fields, or a position in the struct\n\t// type to select all its fields.\n\t\"Range\": {\n\t\t\"start\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t\t\"end\": {\n\t\t\t\"line\": uint32,\n\t\t\t\"character\": uint32,\n\t\t},\n\t},\n\t// The keys to add to the tags of the fields that lack them.\n\t\"Add\": []string,\n\t// The transformation of the field names into the values of the added\n\t// keys: \"camelcase\", \"snakecase\", \"lispcase\", \"pascalcase\" or \"keep\".\n\t\"Transform\": string,\n\t// The keys to remove from the tags.\n\t\"Remove\": []string,\n\t// Whether to remove the tags entirely.\n\t\"Clear\": bool,\n}",
+		},
 		{
 			Command: "gopls.regenerate_cgo",
 			Title:   "Regenerate cgo",
@@ -799,8 +909,8 @@ var GeneratedAPIJSON = &APIJSON{
 		{
 			Command: "gopls.run_tests",
 			Title:   "Run test(s)",
-			Doc:     "Runs `go test` for a specific set of test or benchmark functions.",
-			ArgDoc:  "{\n\t// The test file containing the tests to run.\n\t\"URI\": string,\n\t// Specific test names to run, e.g. TestFoo.\n\t\"Tests\": []string,\n\t// Specific benchmarks to run, e.g. BenchmarkFoo.\n\t\"Benchmarks\": []string,\n}",
+			Doc:     "Runs `go test` for a specific set of test or benchmark functions, or\nsubtests.",
+			ArgDoc:  "{\n\t// The test file containing the tests to run.\n\t\"URI\": string,\n\t// Specific test names to run, e.g. TestFoo, or subtest names, e.g.\n\t// TestFoo/bar.\n\t\"Tests\": []string,\n\t// Specific benchmarks to run, e.g. BenchmarkFoo.\n\t\"Benchmarks\": []string,\n}",
 		},
 		{
 			Command: "gopls.start_debugging",
@@ -811,7 +921,7 @@ var GeneratedAPIJSON = &APIJSON{
 		{
 			Command: "gopls.test",
 			Title:   "Run test(s) (legacy)",
-			Doc:     "Runs `go test` for a specific set of test or benchmark functions.",
+			Doc:     "Runs `go test` for a specific set of test or benchmark functions, or\nsubtests.",
 			ArgDoc:  "string,\n[]string,\n[]string",
 		},
 		{
@@ -826,6 +936,12 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "Toggle the calculation of gc annotations.",
 			ArgDoc:  "{\n\t// The file URI.\n\t\"URI\": string,\n}",
 		},
+		{
+			Command: "gopls.unused_exports",
+			Title:   "Find unused exported symbols",
+			Doc:     "Lists the exported symbols of the workspace packages that are not\nreferred to outside of their package.",
+			ArgDoc:  "",
+		},
 		{
 			Command: "gopls.update_go_sum",
 			Title:   "Update go.sum",
@@ -870,7 +986,7 @@ var GeneratedAPIJSON = &APIJSON{
 		{
 			Lens:  "test",
 			Title: "Run test(s) (legacy)",
-			Doc:   "Runs `go test` for a specific set of test or benchmark functions.",
+			Doc:   "Runs `go test` for a specific set of test or benchmark functions, or\nsubtests.",
 		},
 		{
 			Lens:  "tidy",
@@ -949,6 +1065,11 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "find structs that would use less memory if their fields were sorted\n\nThis analyzer find structs that can be rearranged to use less memory, and provides\na suggested edit with the optimal order.\n\nNote that there are two different diagnostics reported. One checks struct size,\nand the other reports \"pointer bytes\" used. Pointer bytes is how many bytes of the\nobject that the garbage collector has to potentially scan for pointers, for example:\n\n\tstruct { uint32; string }\n\nhave 16 pointer bytes because the garbage collector has to scan up through the string's\ninner pointer.\n\n\tstruct { string; *uint32 }\n\nhas 24 pointer bytes because it has to scan further through the *uint32.\n\n\tstruct { string; uint32 }\n\nhas 8 because it can stop immediately after the string pointer.\n",
 			Default: false,
 		},
+		{
+			Name:    "fieldtag",
+			Doc:     "check the names and options of struct field tags\n\nThis analyzer complements structtag. It reports the unknown options of\nthe json, xml and yaml keys of struct field tags, such as the misspelled\noption of\n\tName string `json:\"name,omitemty\"`\nand the JSON names that encoding/json ignores because they are not valid.\n\nIt also reports the fields that encoding/json silently ignores because\nanother field of the struct, or of the structs it embeds, has the same\nJSON name at the same depth, for example:\n\ttype T struct {\n\t\tID    string\n\t\tOther string `json:\"ID\"`\n\t}\nwhere the ID field is not encoded.",
+			Default: true,
+		},
 		{
 			Name:    "httpresponse",
 			Doc:     "check for mistakes using HTTP responses\n\nA common mistake when using the net/http package is to defer a function\ncall to close the http.Response Body before checking the error that\ndetermines whether the response is valid:\n\n\tresp, err := http.Head(url)\n\tdefer resp.Body.Close()\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\t// (defer statement belongs here)\n\nThis checker helps uncover latent nil dereference bugs by reporting a\ndiagnostic for such mistakes.",
@@ -1084,6 +1205,11 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "suggested fixes for \"no result values expected\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no result values expected\". For example:\n\tfunc z() { return nil }\nwill turn into\n\tfunc z() { return }\n",
 			Default: true,
 		},
+		{
+			Name:    "stubmethods",
+			Doc:     "stub methods analyzer\n\nThis analyzer notes type errors where a value of a concrete type is\nassigned, returned, passed or converted to an interface type that the\nconcrete type does not implement, for example:\n\tvar _ io.Reader = T{}\nBecause the suggested fix, which declares the missing methods of T after\nits declaration, can edit other files of the package, callers should\ncompute it separately.",
+			Default: true,
+		},
 		{
 			Name:    "undeclaredname",
 			Doc:     "suggested fixes for \"undeclared name: <>\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"undeclared name: <>\". It will insert a new statement:\n\"<> := \".",
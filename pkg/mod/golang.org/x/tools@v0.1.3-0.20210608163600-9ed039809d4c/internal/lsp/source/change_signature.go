@@ -0,0 +1,586 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/command"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// SelectedParam returns the name of the function or interface method whose
+// declaration has a parameter at pos, along with the index of that parameter,
+// the number of parameters, and whether the function is variadic. It returns
+// a nil name if pos is not in such a parameter.
+func SelectedParam(file *ast.File, pos token.Pos) (name *ast.Ident, index, count int, variadic bool) {
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for i := 2; i < len(path); i++ {
+		// The FuncType of a FuncDecl is not in the path, its parameters are
+		// directly under the FuncDecl.
+		var (
+			ftype *ast.FuncType
+			fname *ast.Ident
+		)
+		switch n := path[i].(type) {
+		case *ast.FuncDecl:
+			ftype, fname = n.Type, n.Name
+		case *ast.FuncType:
+			ftype = n
+			if i+3 < len(path) {
+				_, inInterface := path[i+3].(*ast.InterfaceType)
+				if parent, ok := path[i+1].(*ast.Field); ok && inInterface && len(parent.Names) == 1 {
+					fname = parent.Names[0]
+				}
+			}
+		}
+		if ftype == nil || path[i-1] != ftype.Params {
+			continue
+		}
+		field, ok := path[i-2].(*ast.Field)
+		if !ok || fname == nil {
+			// A parameter of a function type or literal, which may itself
+			// be a parameter.
+			continue
+		}
+		for _, f := range ftype.Params.List {
+			if f == field {
+				index = count
+				for j, id := range f.Names {
+					if id.Pos() <= pos && pos <= id.End() {
+						index += j
+					}
+				}
+			}
+			if len(f.Names) == 0 {
+				count++
+			}
+			count += len(f.Names)
+		}
+		last := ftype.Params.List[len(ftype.Params.List)-1]
+		_, variadic = last.Type.(*ast.Ellipsis)
+		return fname, index, count, variadic
+	}
+	return nil, 0, 0, false
+}
+
+// ChangeSignature returns the edits that change the parameters of the
+// function or method named at pp to params, and update its calls throughout
+// the workspace. The interface methods that a method implements, and the
+// methods that implement an interface method, are changed along with their
+// calls, so that the types keep implementing the same interfaces.
+//
+// The types and default values of new parameters are inserted as they are
+// given: any import they need must be added separately.
+func ChangeSignature(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position, params []command.SignatureParam) (map[span.URI][]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.ChangeSignature")
+	defer done()
+
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh, pp)
+	if err != nil {
+		return nil, err
+	}
+	fn, ok := qos[0].obj.(*types.Func)
+	if !ok {
+		return nil, errors.Errorf("%s is not a function", qos[0].obj.Name())
+	}
+	sig := fn.Type().(*types.Signature)
+	if err := checkSignatureParams(sig, params); err != nil {
+		return nil, errors.Errorf("cannot change signature of %s: %w", fn.Name(), err)
+	}
+	related, err := relatedMethods(ctx, snapshot, fh, pp)
+	if err != nil {
+		return nil, err
+	}
+	wsPkgs, err := snapshot.WorkspacePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspace := make(map[string]bool)
+	for _, pkg := range wsPkgs {
+		workspace[pkg.PkgPath()] = true
+	}
+
+	c := &signatureChanger{
+		fset:     snapshot.FileSet(),
+		name:     fn.Name(),
+		params:   params,
+		n:        sig.Params().Len(),
+		variadic: sig.Variadic(),
+		calls:    make(map[*ParsedGoFile]*fileCalls),
+		seen:     make(map[signatureEdit]bool),
+		edits:    make(map[span.URI][]protocol.TextEdit),
+	}
+	for _, qo := range append(qos, related...) {
+		if qo.pkg == nil || qo.obj.Pkg() == nil || !workspace[qo.obj.Pkg().Path()] {
+			return nil, errors.Errorf("cannot change signature of %s: it must match %s, which is declared outside the workspace", fn.Name(), qo.obj)
+		}
+		if err := c.changeDecl(qo); err != nil {
+			return nil, err
+		}
+		refs, err := references(ctx, snapshot, []qualifiedObject{qo}, false, false, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range refs {
+			if err := c.addCall(ref); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for pgf, fc := range c.calls {
+		if err := c.changeCalls(pgf, fc); err != nil {
+			return nil, err
+		}
+	}
+	for _, edits := range c.edits {
+		sort.Slice(edits, func(i, j int) bool {
+			return protocol.CompareRange(edits[i].Range, edits[j].Range) < 0
+		})
+	}
+	return c.edits, nil
+}
+
+// checkSignatureParams checks that params describe a valid new signature for
+// a function of signature sig.
+func checkSignatureParams(sig *types.Signature, params []command.SignatureParam) error {
+	n := sig.Params().Len()
+	seen := make(map[int]bool)
+	for i, p := range params {
+		last := i == len(params)-1
+		switch {
+		case p.OldIndex < -1 || p.OldIndex >= n:
+			return errors.Errorf("no parameter at index %d", p.OldIndex)
+		case p.OldIndex == -1:
+			if p.Name != "" && p.Name != "_" && !isValidIdentifier(p.Name) {
+				return errors.Errorf("invalid parameter name %q", p.Name)
+			}
+			typ := strings.TrimPrefix(p.Type, "...")
+			if _, err := parser.ParseExpr(typ); typ == "" || err != nil {
+				return errors.Errorf("invalid type %q for new parameter %s", p.Type, p.Name)
+			}
+			if typ != p.Type && !last {
+				return errors.Errorf("only the last parameter may be variadic")
+			}
+			if p.Default != "" {
+				if _, err := parser.ParseExpr(p.Default); err != nil {
+					return errors.Errorf("invalid default value %q for new parameter %s", p.Default, p.Name)
+				}
+			}
+		default:
+			if seen[p.OldIndex] {
+				return errors.Errorf("parameter %d appears more than once", p.OldIndex)
+			}
+			seen[p.OldIndex] = true
+			if sig.Variadic() && p.OldIndex == n-1 && !last {
+				return errors.Errorf("the variadic parameter must remain last")
+			}
+		}
+	}
+	return nil
+}
+
+// relatedMethods returns the methods whose signature must change along with
+// that of the function or method at pp: the interface methods it implements,
+// the other implementations of those, and the implementations of an interface
+// method.
+func relatedMethods(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) ([]qualifiedObject, error) {
+	impls, err := implementations(ctx, snapshot, fh, pp)
+	if errors.Is(err, ErrNotAType) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	related := impls
+	for _, impl := range impls {
+		recv := impl.obj.Type().(*types.Signature).Recv()
+		if impl.pkg == nil || recv == nil || !IsInterface(recv.Type()) {
+			continue
+		}
+		rng, err := objToMappedRange(snapshot, impl.pkg, impl.obj)
+		if err != nil {
+			return nil, err
+		}
+		pRng, err := rng.Range()
+		if err != nil {
+			return nil, err
+		}
+		ifh, err := snapshot.GetFile(ctx, rng.URI())
+		if err != nil {
+			return nil, err
+		}
+		others, err := implementations(ctx, snapshot, ifh, pRng.Start)
+		if err != nil {
+			return nil, err
+		}
+		related = append(related, others...)
+	}
+	return related, nil
+}
+
+// A signatureChanger collects the edits of a signature change.
+type signatureChanger struct {
+	fset     *token.FileSet
+	name     string
+	params   []command.SignatureParam
+	n        int // number of parameters of the old signature
+	variadic bool
+
+	calls map[*ParsedGoFile]*fileCalls
+	seen  map[signatureEdit]bool
+	edits map[span.URI][]protocol.TextEdit
+}
+
+// fileCalls holds the calls to change in a file.
+type fileCalls struct {
+	info  *types.Info
+	calls map[*ast.CallExpr]int // the number of arguments before the parameters
+}
+
+// signatureEdit identifies an edit, since the same file may be seen through
+// several packages.
+type signatureEdit struct {
+	uri span.URI
+	rng protocol.Range
+}
+
+func (c *signatureChanger) addEdit(pgf *ParsedGoFile, start, end token.Pos, text string) error {
+	rng, err := NewMappedRange(c.fset, pgf.Mapper, start, end).Range()
+	if err != nil {
+		return err
+	}
+	key := signatureEdit{pgf.URI, rng}
+	if c.seen[key] {
+		return nil
+	}
+	c.seen[key] = true
+	c.edits[pgf.URI] = append(c.edits[pgf.URI], protocol.TextEdit{
+		Range:   rng,
+		NewText: text,
+	})
+	return nil
+}
+
+// changeDecl changes the parameter list of the declaration of qo.
+func (c *signatureChanger) changeDecl(qo qualifiedObject) error {
+	pgf, err := qo.pkg.File(span.URIFromPath(c.fset.Position(qo.obj.Pos()).Filename))
+	if err != nil {
+		return err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, qo.obj.Pos(), qo.obj.Pos())
+	if len(path) < 2 {
+		return errors.Errorf("no declaration found for %s", qo.obj.Name())
+	}
+	var (
+		ftype *ast.FuncType
+		body  *ast.BlockStmt
+	)
+	switch n := path[1].(type) {
+	case *ast.FuncDecl:
+		ftype, body = n.Type, n.Body
+	case *ast.Field:
+		ftype, _ = n.Type.(*ast.FuncType)
+	}
+	if ftype == nil {
+		return errors.Errorf("no declaration found for %s", qo.obj.Name())
+	}
+	if body != nil {
+		if err := c.checkRemovedUnused(qo.pkg.GetTypesInfo(), ftype, body); err != nil {
+			return err
+		}
+	}
+	text, err := changeParams(c.fset, pgf.Src, ftype, c.params)
+	if err != nil {
+		return err
+	}
+	return c.addEdit(pgf, ftype.Params.Opening+1, ftype.Params.Closing, text)
+}
+
+// checkRemovedUnused checks that the removed parameters are not used in body.
+func (c *signatureChanger) checkRemovedUnused(info *types.Info, ftype *ast.FuncType, body *ast.BlockStmt) error {
+	kept := make(map[int]bool)
+	for _, p := range c.params {
+		kept[p.OldIndex] = true
+	}
+	removed := make(map[types.Object]bool)
+	i := 0
+	for _, f := range ftype.Params.List {
+		for _, id := range f.Names {
+			if obj := info.Defs[id]; obj != nil && !kept[i] {
+				removed[obj] = true
+			}
+			i++
+		}
+	}
+	var used *ast.Ident
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && removed[info.Uses[id]] {
+			used = id
+		}
+		return used == nil
+	})
+	if used != nil {
+		return errors.Errorf("cannot remove parameter %s of %s: it is used at %s", used.Name, c.name, c.fset.Position(used.Pos()))
+	}
+	return nil
+}
+
+// addCall records the call of ref, which must be the function of a call.
+func (c *signatureChanger) addCall(ref *ReferenceInfo) error {
+	pgf, err := ref.pkg.File(ref.URI())
+	if err != nil {
+		return err
+	}
+	info := ref.pkg.GetTypesInfo()
+	path, _ := astutil.PathEnclosingInterval(pgf.File, ref.ident.Pos(), ref.ident.End())
+	var fun ast.Node = ref.ident
+	i, recv := 1, 0
+	if i < len(path) {
+		if sel, ok := path[i].(*ast.SelectorExpr); ok && sel.Sel == ref.ident {
+			if s := info.Selections[sel]; s != nil && s.Kind() == types.MethodExpr {
+				recv = 1
+			}
+			fun = sel
+			i++
+		}
+	}
+	for ; i < len(path); i++ {
+		if _, ok := path[i].(*ast.ParenExpr); !ok {
+			break
+		}
+		fun = path[i]
+	}
+	var call *ast.CallExpr
+	if i < len(path) {
+		call, _ = path[i].(*ast.CallExpr)
+	}
+	if call == nil || call.Fun != fun {
+		return errors.Errorf("cannot change signature of %s: it is used as a value at %s", c.name, c.fset.Position(ref.ident.Pos()))
+	}
+	fc := c.calls[pgf]
+	if fc == nil {
+		fc = &fileCalls{info: info, calls: make(map[*ast.CallExpr]int)}
+		c.calls[pgf] = fc
+	}
+	fc.calls[call] = recv
+	return nil
+}
+
+// changeCalls changes the arguments of the calls of a file. The calls nested
+// in the arguments of another are changed along with it, so that the edits
+// don't overlap.
+func (c *signatureChanger) changeCalls(pgf *ParsedGoFile, fc *fileCalls) error {
+	for call := range fc.calls {
+		nested := false
+		for other := range fc.calls {
+			if other != call && other.Lparen < call.Pos() && call.End() <= other.Rparen {
+				nested = true
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+		text, err := c.argsText(pgf, fc, call)
+		if err != nil {
+			return err
+		}
+		if err := c.addEdit(pgf, call.Lparen+1, call.Rparen, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// argsText returns the new text of the arguments of call.
+func (c *signatureChanger) argsText(pgf *ParsedGoFile, fc *fileCalls, call *ast.CallExpr) (string, error) {
+	exprText := func(expr ast.Expr) (string, error) {
+		return c.exprText(pgf, fc, expr)
+	}
+	return changeArgs(c.fset, fc.info, call, fc.calls[call], c.n, c.variadic, c.params, exprText)
+}
+
+// exprText returns the text of expr, with the arguments of the calls it
+// contains changed.
+func (c *signatureChanger) exprText(pgf *ParsedGoFile, fc *fileCalls, expr ast.Expr) (string, error) {
+	tok := c.fset.File(expr.Pos())
+	var (
+		b    strings.Builder
+		last = expr.Pos()
+		err  error
+	)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if err != nil || !ok {
+			return err == nil
+		}
+		if _, ok := fc.calls[call]; !ok {
+			return true
+		}
+		var args string
+		args, err = c.argsText(pgf, fc, call)
+		b.Write(pgf.Src[tok.Offset(last) : tok.Offset(call.Lparen)+1])
+		b.WriteString(args)
+		last = call.Rparen
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+	b.Write(pgf.Src[tok.Offset(last):tok.Offset(expr.End())])
+	return b.String(), nil
+}
+
+// changeParams returns the new text of the parameter list of ftype, between
+// its parentheses. Parameters declared together stay together, as long as
+// they remain adjacent.
+func changeParams(fset *token.FileSet, src []byte, ftype *ast.FuncType, params []command.SignatureParam) (string, error) {
+	type param struct {
+		name, typ string
+		field     int // the index of the declaring field, or -1
+	}
+	tok := fset.File(ftype.Pos())
+	var old []param
+	named := false
+	for i, f := range ftype.Params.List {
+		typ := string(src[tok.Offset(f.Type.Pos()):tok.Offset(f.Type.End())])
+		if len(f.Names) == 0 {
+			old = append(old, param{"", typ, i})
+		}
+		for _, id := range f.Names {
+			old = append(old, param{id.Name, typ, i})
+			named = true
+		}
+	}
+	var list []param
+	for _, p := range params {
+		if p.OldIndex >= len(old) {
+			return "", errors.Errorf("no parameter at index %d", p.OldIndex)
+		}
+		if p.OldIndex >= 0 {
+			list = append(list, old[p.OldIndex])
+			continue
+		}
+		list = append(list, param{p.Name, p.Type, -1})
+		if p.Name != "" {
+			named = true
+		}
+	}
+	var b strings.Builder
+	for i, p := range list {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if named {
+			if p.name == "" {
+				p.name = "_"
+			}
+			b.WriteString(p.name)
+			if i+1 < len(list) && p.field >= 0 && list[i+1].field == p.field {
+				continue
+			}
+			b.WriteString(" ")
+		}
+		b.WriteString(p.typ)
+	}
+	return b.String(), nil
+}
+
+// changeArgs returns the new text of the arguments of call, between its
+// parentheses. The first recv arguments, of a method expression, are kept in
+// place. n is the number of parameters of the old signature, and exprText
+// returns the text of an argument.
+func changeArgs(fset *token.FileSet, info *types.Info, call *ast.CallExpr, recv, n int, variadic bool, params []command.SignatureParam, exprText func(ast.Expr) (string, error)) (string, error) {
+	pos := fset.Position(call.Pos())
+	if len(call.Args) < recv {
+		return "", errors.Errorf("missing receiver in the call at %s", pos)
+	}
+	args := call.Args[recv:]
+	if len(args) == 1 && n > 1 {
+		if _, ok := info.TypeOf(args[0]).(*types.Tuple); ok {
+			return "", errors.Errorf("the arguments of the call at %s are the results of a call", pos)
+		}
+	}
+	if len(args) < n && !(variadic && len(args) == n-1) || len(args) > n && !variadic {
+		return "", errors.Errorf("wrong number of arguments in the call at %s", pos)
+	}
+
+	// Group the arguments by parameter.
+	old := make([][]ast.Expr, n)
+	for i, arg := range args {
+		j := i
+		if j >= n {
+			j = n - 1
+		}
+		old[j] = append(old[j], arg)
+	}
+	impure := func(exprs []ast.Expr) bool {
+		for _, e := range exprs {
+			if !isPure(info, e) {
+				return true
+			}
+		}
+		return false
+	}
+	kept := make(map[int]bool)
+	lastImpure := -1
+	for _, p := range params {
+		if p.OldIndex < 0 {
+			continue
+		}
+		kept[p.OldIndex] = true
+		if impure(old[p.OldIndex]) {
+			if p.OldIndex < lastImpure {
+				return "", errors.Errorf("reordering the arguments of the call at %s would change the order of their side effects", pos)
+			}
+			lastImpure = p.OldIndex
+		}
+	}
+	for i, exprs := range old {
+		if !kept[i] && impure(exprs) {
+			return "", errors.Errorf("the removed argument of the call at %s has side effects", pos)
+		}
+	}
+
+	var out []string
+	for _, arg := range call.Args[:recv] {
+		text, err := exprText(arg)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, text)
+	}
+	for _, p := range params {
+		if p.OldIndex < 0 {
+			if p.Default == "" {
+				return "", errors.Errorf("no default value for the new parameter %s of the call at %s", p.Name, pos)
+			}
+			out = append(out, p.Default)
+			continue
+		}
+		for _, arg := range old[p.OldIndex] {
+			text, err := exprText(arg)
+			if err != nil {
+				return "", err
+			}
+			out = append(out, text)
+		}
+		if variadic && p.OldIndex == n-1 && call.Ellipsis.IsValid() {
+			out[len(out)-1] += "..."
+		}
+	}
+	return strings.Join(out, ", "), nil
+}
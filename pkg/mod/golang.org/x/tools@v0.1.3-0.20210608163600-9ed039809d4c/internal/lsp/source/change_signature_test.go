@@ -0,0 +1,185 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/command"
+)
+
+func TestChangeSignature(t *testing.T) {
+	const src = `package p
+
+func f(a, b int, s string) {}
+
+func g(format string, args ...interface{}) {}
+
+func h(int, string) {}
+
+func next() int { return 0 }
+
+func _() {
+	f(1, 2, "x")
+	f(next(), 2, "y")
+	g("z", 1, 2)
+	g("z")
+	h(1, "w")
+}
+`
+	keep := func(indexes ...int) []command.SignatureParam {
+		var params []command.SignatureParam
+		for _, i := range indexes {
+			params = append(params, command.SignatureParam{OldIndex: i})
+		}
+		return params
+	}
+	for _, tt := range []struct {
+		fn        string
+		params    []command.SignatureParam
+		wantDecl  string
+		wantCalls []string // the new arguments, or the error, of each call
+	}{
+		{"f", keep(1, 0, 2), "b, a int, s string", []string{`2, 1, "x"`, `2, next(), "y"`}},
+		{"f", keep(2, 0, 1), "s string, a, b int", []string{`"x", 1, 2`, `"y", next(), 2`}},
+		{"f", keep(1, 2), "b int, s string", []string{`2, "x"`, "the removed argument of the call at p.go:13:2 has side effects"}},
+		{"f", append(keep(0, 1, 2), command.SignatureParam{OldIndex: -1, Name: "ok", Type: "bool", Default: "true"}),
+			"a, b int, s string, ok bool", []string{`1, 2, "x", true`, `next(), 2, "y", true`}},
+		{"g", keep(1), "args ...interface{}", []string{"1, 2", ""}},
+		{"g", append(keep(0), command.SignatureParam{OldIndex: -1, Name: "n", Type: "int", Default: "0"}), "format string, n int", []string{`"z", 0`, `"z", 0`}},
+		{"h", keep(1, 0), "string, int", []string{`"w", 1`}},
+		{"h", append(keep(0), command.SignatureParam{OldIndex: -1, Name: "x", Type: "float64", Default: "1.5"}), "_ int, x float64", []string{"1, 1.5"}},
+	} {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		}
+		conf := types.Config{}
+		if _, err := conf.Check("p", fset, []*ast.File{file}, info); err != nil {
+			t.Fatal(err)
+		}
+		var decl *ast.FuncDecl
+		for _, d := range file.Decls {
+			if d, ok := d.(*ast.FuncDecl); ok && d.Name.Name == tt.fn {
+				decl = d
+			}
+		}
+		sig := info.Defs[decl.Name].Type().(*types.Signature)
+		if err := checkSignatureParams(sig, tt.params); err != nil {
+			t.Fatalf("checkSignatureParams(%s): %v", tt.fn, err)
+		}
+		got, err := changeParams(fset, []byte(src), decl.Type, tt.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.wantDecl {
+			t.Errorf("changeParams(%s) = %q, want %q", tt.fn, got, tt.wantDecl)
+		}
+
+		tok := fset.File(file.Pos())
+		exprText := func(expr ast.Expr) (string, error) {
+			return src[tok.Offset(expr.Pos()):tok.Offset(expr.End())], nil
+		}
+		var calls []string
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != tt.fn {
+				return true
+			}
+			got, err := changeArgs(fset, info, call, 0, sig.Params().Len(), sig.Variadic(), tt.params, exprText)
+			if err != nil {
+				got = err.Error()
+			}
+			calls = append(calls, got)
+			return true
+		})
+		if len(calls) != len(tt.wantCalls) {
+			t.Fatalf("%s: got %d calls, want %d", tt.fn, len(calls), len(tt.wantCalls))
+		}
+		for i, want := range tt.wantCalls {
+			if got := calls[i]; got != want {
+				t.Errorf("changeArgs(%s) #%d = %q, want %q", tt.fn, i, got, want)
+			}
+		}
+	}
+}
+
+func TestCheckSignatureParams(t *testing.T) {
+	sig := types.NewSignature(nil, types.NewTuple(
+		types.NewVar(token.NoPos, nil, "a", types.Typ[types.Int]),
+		types.NewVar(token.NoPos, nil, "rest", types.NewSlice(types.Typ[types.Int])),
+	), nil, true)
+	for _, tt := range []struct {
+		params []command.SignatureParam
+		want   string
+	}{
+		{[]command.SignatureParam{{OldIndex: 2}}, "no parameter at index 2"},
+		{[]command.SignatureParam{{OldIndex: 0}, {OldIndex: 0}}, "appears more than once"},
+		{[]command.SignatureParam{{OldIndex: 1}, {OldIndex: 0}}, "must remain last"},
+		{[]command.SignatureParam{{OldIndex: -1, Name: "x", Type: "[]"}}, "invalid type"},
+		{[]command.SignatureParam{{OldIndex: -1, Name: "x", Type: "...int"}, {OldIndex: 0}}, "only the last parameter may be variadic"},
+		{[]command.SignatureParam{{OldIndex: -1, Name: "func", Type: "int"}}, "invalid parameter name"},
+	} {
+		err := checkSignatureParams(sig, tt.params)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("checkSignatureParams(%v) = %v, want %q", tt.params, err, tt.want)
+		}
+	}
+}
+
+func TestSelectedParam(t *testing.T) {
+	const src = `package p
+
+func f(a, b int, cb func(x int), rest ...string) {}
+
+type I interface {
+	m(x int, y string)
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok := fset.File(file.Pos())
+	for _, tt := range []struct {
+		at       string
+		name     string
+		index    int
+		count    int
+		variadic bool
+	}{
+		{"a, b", "f", 0, 4, true},
+		{"b int", "f", 1, 4, true},
+		{"x int)", "f", 2, 4, true},
+		{"rest", "f", 3, 4, true},
+		{"y string", "m", 1, 2, false},
+		{"p\n", "", 0, 0, false},
+	} {
+		pos := tok.Pos(strings.Index(src, tt.at))
+		name, index, count, variadic := SelectedParam(file, pos)
+		var gotName string
+		if name != nil {
+			gotName = name.Name
+		}
+		if gotName != tt.name || index != tt.index || count != tt.count || variadic != tt.variadic {
+			t.Errorf("SelectedParam(%q) = %s, %d, %d, %t, want %s, %d, %d, %t", tt.at, gotName, index, count, variadic, tt.name, tt.index, tt.count, tt.variadic)
+		}
+	}
+}
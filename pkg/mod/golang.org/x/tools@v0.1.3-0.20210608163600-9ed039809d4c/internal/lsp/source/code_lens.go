@@ -6,11 +6,14 @@ package source
 
 import (
 	"context"
+	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/token"
 	"go/types"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"golang.org/x/tools/internal/lsp/command"
@@ -52,6 +55,15 @@ func runTestCodeLens(ctx context.Context, snapshot Snapshot, fh FileHandle) ([]p
 		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
 	}
 
+	for _, fn := range fns.Subtests {
+		cmd, err := command.NewTestCommand("run subtest", puri, []string{fn.Name}, nil)
+		if err != nil {
+			return nil, err
+		}
+		rng := protocol.Range{Start: fn.Rng.Start, End: fn.Rng.Start}
+		codeLens = append(codeLens, protocol.CodeLens{Range: rng, Command: cmd})
+	}
+
 	for _, fn := range fns.Benchmarks {
 		cmd, err := command.NewTestCommand("run benchmark", puri, nil, []string{fn.Name})
 		if err != nil {
@@ -92,6 +104,10 @@ type testFn struct {
 type testFns struct {
 	Tests      []testFn
 	Benchmarks []testFn
+
+	// Subtests are the subtests of Tests whose names are constants, named
+	// as by go test, e.g. TestFoo/bar.
+	Subtests []testFn
 }
 
 func TestsAndBenchmarks(ctx context.Context, snapshot Snapshot, fh FileHandle) (testFns, error) {
@@ -118,6 +134,13 @@ func TestsAndBenchmarks(ctx context.Context, snapshot Snapshot, fh FileHandle) (
 
 		if matchTestFunc(fn, pkg, testRe, "T") {
 			out.Tests = append(out.Tests, testFn{fn.Name.Name, rng})
+			for _, st := range findSubtests(pkg.GetTypesInfo(), pgf.File, fn) {
+				rng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, st.pos, st.end).Range()
+				if err != nil {
+					return out, err
+				}
+				out.Subtests = append(out.Subtests, testFn{st.name, rng})
+			}
 		}
 
 		if matchTestFunc(fn, pkg, benchmarkRe, "B") {
@@ -166,6 +189,340 @@ func matchTestFunc(fn *ast.FuncDecl, pkg Package, nameRe *regexp.Regexp, paramID
 	return namedObj.Id() == paramID
 }
 
+// A subtest is a call to (*testing.T).Run, or an element of the table of a
+// table-driven test, that runs a subtest with a constant name.
+type subtest struct {
+	name     string // the full name of the subtest, as printed by go test
+	pos, end token.Pos
+}
+
+// findSubtests returns the subtests of the test function fn of file, in
+// order. Subtests are found in the calls to t.Run with a constant name, in
+// the subtests that they run, and in the table-driven tests that call
+// t.Run(tt.name, ...) for each element tt of a slice of structs with a
+// name field. Subtests whose names go test may number differently, for
+// example after a subtest with a name that is not constant, are left out.
+func findSubtests(info *types.Info, file *ast.File, fn *ast.FuncDecl) []subtest {
+	if fn.Body == nil {
+		return nil
+	}
+	var out []subtest
+	var visit func(parent string, body *ast.BlockStmt)
+	visit = func(parent string, body *ast.BlockStmt) {
+		names := newSubtestNames(parent)
+		var stack []ast.Node // the enclosing nodes in body
+		ast.Inspect(body, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return false
+			}
+			call, ok := n.(*ast.CallExpr)
+			if !ok || !isSubtestRun(info, call) {
+				stack = append(stack, n)
+				return true
+			}
+			if name, ok := constantString(info, call.Args[0]); ok {
+				once, ordered := runsOnce(stack, nil)
+				full, known := names.add(name, once, ordered)
+				if known {
+					out = append(out, subtest{full, call.Pos(), call.End()})
+					if lit, ok := call.Args[1].(*ast.FuncLit); ok {
+						visit(full, lit.Body)
+					}
+				}
+				return false
+			}
+			cases, loop := tableSubtests(info, file, fn, call.Args[0])
+			if loop == nil {
+				names.unknown = true
+				return false
+			}
+			once, ordered := runsOnce(stack, loop)
+			for _, c := range cases {
+				if !c.known {
+					names.unknown = true
+					continue
+				}
+				if full, known := names.add(c.name, once, ordered); known {
+					out = append(out, subtest{full, c.pos, c.end})
+				}
+			}
+			// The names of the subtests of a table-driven subtest depend
+			// on the element that runs it.
+			return false
+		})
+	}
+	visit(fn.Name.Name, fn.Body)
+	return out
+}
+
+// runsOnce reports whether a call to t.Run enclosed by the nodes of stack,
+// other than loop, runs exactly once, and whether it runs in the order of
+// the statements of the test.
+func runsOnce(stack []ast.Node, loop ast.Node) (once, ordered bool) {
+	once, ordered = true, true
+	for _, n := range stack {
+		switch n.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.IfStmt, *ast.CaseClause, *ast.CommClause:
+			if n != loop {
+				once = false
+			}
+		case *ast.FuncLit, *ast.DeferStmt, *ast.GoStmt:
+			ordered = false
+		}
+	}
+	return once, ordered
+}
+
+// subtestNames names the subtests of a test as go test does, which makes
+// them unique by adding a #NN suffix to the repeated ones (see
+// testing.matcher.unique), for as long as it can tell how many times each
+// name was used.
+type subtestNames struct {
+	parent  string
+	used    map[string]int  // full name to the next suffix
+	unsure  map[string]bool // full names that may have been used more times
+	unknown bool            // a subtest with an unknown name has run
+}
+
+func newSubtestNames(parent string) *subtestNames {
+	return &subtestNames{parent: parent, used: make(map[string]int), unsure: make(map[string]bool)}
+}
+
+// add returns the full name of the next subtest run with name, and whether
+// the name is known. A subtest that may run more or less than once, or out
+// of order, leaves the number of uses of its name unknown.
+func (s *subtestNames) add(name string, once, ordered bool) (string, bool) {
+	name = rewriteSubtestName(name)
+	base := s.parent + "/" + name
+	full := base
+	known := !s.unknown && ordered
+	for empty := name == ""; ; empty = false {
+		next, exists := s.used[full]
+		if !empty && !exists {
+			s.used[full] = 1
+			break
+		}
+		if s.unsure[full] {
+			known = false
+		}
+		s.used[full] = next + 1
+		full = fmt.Sprintf("%s#%02d", full, next)
+	}
+	if !once || !ordered {
+		s.unsure[base] = true
+		s.unsure[full] = true
+	}
+	return full, known
+}
+
+// isSubtestRun reports whether call is a call to (*testing.T).Run.
+func isSubtestRun(info *types.Info, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 2 {
+		return false
+	}
+	fn, ok := info.ObjectOf(sel.Sel).(*types.Func)
+	if !ok || fn.Name() != "Run" || fn.Pkg() == nil || fn.Pkg().Path() != "testing" {
+		return false
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return false
+	}
+	ptr, ok := recv.Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	return ok && named.Obj().Name() == "T"
+}
+
+// A tableCase is an element of the table of a table-driven test. Its name
+// is known if it is constant, and is not yet rewritten.
+type tableCase struct {
+	name     string
+	known    bool
+	pos, end token.Pos
+}
+
+// tableSubtests returns the cases of a table-driven test run by a call to
+// t.Run whose name argument is tt.name, where tt ranges over the elements
+// of a slice or array composite literal, declared in file or ranged over
+// directly, along with the range statement. It returns a nil statement if
+// the subtests run by the call are not a table-driven test.
+func tableSubtests(info *types.Info, file *ast.File, fn *ast.FuncDecl, arg ast.Expr) ([]tableCase, *ast.RangeStmt) {
+	sel, ok := arg.(*ast.SelectorExpr)
+	if !ok {
+		return nil, nil
+	}
+	selection, ok := info.Selections[sel]
+	if !ok || selection.Kind() != types.FieldVal || len(selection.Index()) != 1 || !strings.EqualFold(sel.Sel.Name, "name") {
+		return nil, nil
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, nil
+	}
+	elem := info.Uses[x]
+	if elem == nil {
+		return nil, nil
+	}
+
+	// Find the range statement that declares the element variable, and the
+	// table it ranges over.
+	var (
+		table *ast.CompositeLit
+		loop  *ast.RangeStmt
+	)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if loop != nil {
+			return false
+		}
+		rs, ok := n.(*ast.RangeStmt)
+		if !ok {
+			return true
+		}
+		if v, ok := rs.Value.(*ast.Ident); !ok || rs.Tok != token.DEFINE || info.Defs[v] != elem {
+			return true
+		}
+		loop = rs
+		switch x := rs.X.(type) {
+		case *ast.CompositeLit:
+			table = x
+		case *ast.Ident:
+			table = declaredCompositeLit(info, file, info.Uses[x])
+		}
+		return false
+	})
+	if table == nil {
+		return nil, nil
+	}
+	T := info.TypeOf(table)
+	if T == nil {
+		return nil, nil
+	}
+	if _, ok := T.Underlying().(*types.Map); ok {
+		return nil, nil // the subtests run in a random order
+	}
+
+	field := selection.Index()[0]
+	var out []tableCase
+	for _, elt := range table.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		lit := elt
+		if u, ok := lit.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			lit = u.X
+		}
+		cl, ok := lit.(*ast.CompositeLit)
+		if !ok {
+			out = append(out, tableCase{pos: elt.Pos(), end: elt.End()})
+			continue
+		}
+		// An element without the field has an empty name.
+		c := tableCase{known: true, pos: elt.Pos(), end: elt.End()}
+		var value ast.Expr
+		for i, e := range cl.Elts {
+			if kv, ok := e.(*ast.KeyValueExpr); ok {
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == sel.Sel.Name {
+					value = kv.Value
+				}
+			} else if i == field {
+				value = e
+			}
+		}
+		if value != nil {
+			c.name, c.known = constantString(info, value)
+		}
+		out = append(out, c)
+	}
+	return out, loop
+}
+
+// declaredCompositeLit returns the composite literal that the variable obj
+// is declared with in file, if any.
+func declaredCompositeLit(info *types.Info, file *ast.File, obj types.Object) *ast.CompositeLit {
+	if obj == nil {
+		return nil
+	}
+	var lit *ast.CompositeLit
+	find := func(lhs []ast.Expr, rhs []ast.Expr) {
+		if len(lhs) != len(rhs) {
+			return
+		}
+		for i, e := range lhs {
+			if id, ok := e.(*ast.Ident); ok && info.Defs[id] == obj {
+				lit, _ = rhs[i].(*ast.CompositeLit)
+			}
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				find(n.Lhs, n.Rhs)
+			}
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				names[i] = name
+			}
+			find(names, n.Values)
+		}
+		return lit == nil
+	})
+	return lit
+}
+
+func constantString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// rewriteSubtestName rewrites the name of a subtest as go test does (see
+// testing.rewrite): spaces are replaced by underscores and unprintable
+// characters are escaped.
+func rewriteSubtestName(s string) string {
+	b := []byte{}
+	for _, r := range s {
+		switch {
+		case isTestSpace(r):
+			b = append(b, '_')
+		case !strconv.IsPrint(r):
+			s := strconv.QuoteRune(r)
+			b = append(b, s[1:len(s)-1]...)
+		default:
+			b = append(b, string(r)...)
+		}
+	}
+	return string(b)
+}
+
+// isTestSpace reports whether go test rewrites r as a space (see
+// testing.isSpace), which is not the same as the Unicode Z class.
+func isTestSpace(r rune) bool {
+	if r < 0x2000 {
+		switch r {
+		case '\t', '\n', '\v', '\f', '\r', ' ', 0x85, 0xA0, 0x1680:
+			return true
+		}
+		return false
+	}
+	if r <= 0x200a {
+		return true
+	}
+	switch r {
+	case 0x2028, 0x2029, 0x202f, 0x205f, 0x3000:
+		return true
+	}
+	return false
+}
+
 func goGenerateCodeLens(ctx context.Context, snapshot Snapshot, fh FileHandle) ([]protocol.CodeLens, error) {
 	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
 	if err != nil {
@@ -0,0 +1,178 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestFindSubtests(t *testing.T) {
+	const src = `package p
+
+import "testing"
+
+var cases = []struct {
+	name string
+	in   int
+}{
+	{"zero", 0},
+	{name: "one", in: 1},
+}
+
+func TestConst(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		t.Run("nested sub", func(t *testing.T) {})
+	})
+	t.Run("simple", func(t *testing.T) {})
+	const name = "a/b"
+	t.Run(name, nil)
+	t.Run("tab\t(x)", nil)
+}
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		Name string
+		want bool
+	}{
+		{Name: "first", want: true},
+		{Name: "second"},
+		{want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Run("inner", nil)
+		})
+	}
+}
+
+func TestGlobalTable(t *testing.T) {
+	for _, c := range cases {
+		t.Run(c.name, nil)
+	}
+	for _, c := range []struct{ name string }{{"literal"}} {
+		t.Run(c.name, nil)
+	}
+}
+
+func TestDynamic(t *testing.T) {
+	t.Run("before", nil)
+	for _, s := range []string{"x"} {
+		t.Run(s, nil)
+	}
+	t.Run("after", nil)
+}
+
+func TestEmpty(t *testing.T) {
+	t.Run("", nil)
+	t.Run("", nil)
+	t.Run("#00", nil)
+}
+
+func TestRepeated(t *testing.T) {
+	t.Run("once", nil)
+	for i := 0; i < 2; i++ {
+		t.Run("loop", nil)
+	}
+	if testing.Short() {
+		t.Run("once", nil)
+	}
+	t.Run("loop", nil)
+	t.Run("once", nil)
+	t.Run("other", nil)
+	defer t.Run("deferred", nil)
+	run := func() { t.Run("closure", nil) }
+	run()
+	t.Run("closure", nil)
+}
+
+func TestUnknownCase(t *testing.T) {
+	name := "x"
+	for _, tt := range []struct{ name string }{{"known"}, {name}, {"next"}} {
+		t.Run(tt.name, nil)
+	}
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p_test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"TestConst": {
+			`TestConst/simple at t.Run("simple"`,
+			`TestConst/simple/nested_sub at t.Run("nested sub"`,
+			`TestConst/simple#01 at t.Run("simple"`,
+			`TestConst/a/b at t.Run(name`,
+			`TestConst/tab_(x) at t.Run("tab\t(x)"`,
+		},
+		"TestTable": {
+			`TestTable/first at {Name: "first"`,
+			`TestTable/second at {Name: "second"`,
+			`TestTable/#00 at {want: false}`,
+		},
+		"TestGlobalTable": {
+			`TestGlobalTable/zero at {"zero"`,
+			`TestGlobalTable/one at {name: "one"`,
+			`TestGlobalTable/literal at {"literal"`,
+		},
+		"TestDynamic": {
+			`TestDynamic/before at t.Run("before"`,
+		},
+		"TestEmpty": {
+			`TestEmpty/#00 at t.Run(""`,
+			`TestEmpty/#01 at t.Run(""`,
+			`TestEmpty/#00#01 at t.Run("#00"`,
+		},
+		// The repeated and conditional subtests are named by their first
+		// run, and the later subtests of the same name are left out.
+		"TestRepeated": {
+			`TestRepeated/once at t.Run("once"`,
+			`TestRepeated/loop at t.Run("loop"`,
+			`TestRepeated/once#01 at t.Run("once"`,
+			`TestRepeated/other at t.Run("other"`,
+		},
+		"TestUnknownCase": {
+			`TestUnknownCase/known at {"known"}`,
+		},
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		var got []string
+		for _, st := range findSubtests(info, file, fn) {
+			text := src[fset.Position(st.pos).Offset:fset.Position(st.end).Offset]
+			got = append(got, st.name+" at "+text)
+		}
+		w := want[fn.Name.Name]
+		if len(got) != len(w) {
+			t.Errorf("%s: got subtests\n%s\nwant\n%s", fn.Name.Name, strings.Join(got, "\n"), strings.Join(w, "\n"))
+			continue
+		}
+		for i := range got {
+			if !strings.HasPrefix(got[i], w[i]) {
+				t.Errorf("%s: subtest %d is %q, want prefix %q", fn.Name.Name, i, got[i], w[i])
+			}
+		}
+	}
+}
@@ -28,7 +28,22 @@ import (
 // URLs in the comment text are converted into links.
 func CommentToMarkdown(text string) string {
 	buf := &bytes.Buffer{}
-	commentToMarkdown(buf, text)
+	commentToMarkdown(buf, text, nil)
+	return buf.String()
+}
+
+// A docLinker returns the URL of the declaration or package that a name in
+// a doc comment refers to, or "" if there is none. The name is either the
+// text of a doc link, such as "io.Reader" in [io.Reader], or a bare
+// identifier of the text if bare is set.
+type docLinker func(name string, bare bool) string
+
+// commentToMarkdownWithLinks is like CommentToMarkdown, but also converts
+// the doc links and the identifiers of the comment text into links, as
+// resolved by link.
+func commentToMarkdownWithLinks(text string, link docLinker) string {
+	buf := &bytes.Buffer{}
+	commentToMarkdown(buf, text, link)
 	return buf.String()
 }
 
@@ -41,13 +56,13 @@ var (
 	mdLinkEnd   = []byte(")")
 )
 
-func commentToMarkdown(w io.Writer, text string) {
+func commentToMarkdown(w io.Writer, text string, link docLinker) {
 	blocks := blocks(text)
 	for i, b := range blocks {
 		switch b.op {
 		case opPara:
 			for _, line := range b.lines {
-				emphasize(w, line, true)
+				emphasize(w, line, true, link)
 			}
 		case opHead:
 			// The header block can consist of only one line.
@@ -108,7 +123,69 @@ func escapeRegex(text string) string {
 	return markdownEscape.ReplaceAllString(text, `\$1`)
 }
 
-func emphasize(w io.Writer, line string, nice bool) {
+// docLinkRx matches the doc links of comments, such as [Name], [*Name],
+// [pkg.Name.Method] or [encoding/json]. The name is the first submatch.
+var docLinkRx = regexp.MustCompile(`\[\*?([\pL_][\pL_0-9]*(?:[./][\pL_0-9\-]+)*)\]`)
+
+// emphasize writes line, converting URLs into links. If link is set, the doc
+// links and identifiers that it resolves are converted into links too.
+func emphasize(w io.Writer, line string, nice bool, link docLinker) {
+	for link != nil {
+		// Look for the first doc link that resolves; the others, such as
+		// brackets in URLs, are left to emphasizeText.
+		var m []int
+		var url string
+		for pos := 0; pos < len(line); {
+			m = docLinkRx.FindStringSubmatchIndex(line[pos:])
+			if m == nil {
+				break
+			}
+			for i := range m {
+				m[i] += pos
+			}
+			if isDocLinkBoundary(line[:m[0]], false) && isDocLinkBoundary(line[m[1]:], true) {
+				if url = link(line[m[2]:m[3]], false); url != "" {
+					break
+				}
+			}
+			pos = m[0] + 1
+		}
+		if url == "" {
+			break
+		}
+		emphasizeText(w, line[:m[0]], nice, link)
+		// The brackets are part of the link syntax, not of its text.
+		w.Write(mdLinkStart)
+		commentEscape(w, line[m[0]+1:m[1]-1], nice)
+		w.Write(mdLinkDiv)
+		w.Write([]byte(urlReplacer.Replace(url)))
+		w.Write(mdLinkEnd)
+		line = line[m[1]:]
+	}
+	emphasizeText(w, line, nice, link)
+}
+
+// isDocLinkBoundary reports whether text may precede a doc link, or follow
+// it if after is set: the adjacent rune is a space or punctuation, other
+// than a slash, as in URLs. A doc link is also not followed by "(" or ":", as
+// in the links and link definitions of markdown.
+func isDocLinkBoundary(text string, after bool) bool {
+	var r rune
+	if after {
+		r, _ = utf8.DecodeRuneInString(text)
+	} else {
+		r, _ = utf8.DecodeLastRuneInString(text)
+	}
+	switch {
+	case text == "":
+		return true
+	case r == '/' || after && (r == '(' || r == ':'):
+		return false
+	}
+	return unicode.IsSpace(r) || unicode.IsPunct(r)
+}
+
+func emphasizeText(w io.Writer, line string, nice bool, link docLinker) {
 	for {
 		m := matchRx.FindStringSubmatchIndex(line)
 		if m == nil {
@@ -149,6 +226,9 @@ func emphasize(w io.Writer, line string, nice bool) {
 		url := ""
 		if m[2] >= 0 {
 			url = match
+		} else if link != nil && (m[0] == 0 || line[m[0]-1] != '.') {
+			// Identifiers that follow a dot are selectors of other names.
+			url = link(match, true)
 		}
 
 		// write match
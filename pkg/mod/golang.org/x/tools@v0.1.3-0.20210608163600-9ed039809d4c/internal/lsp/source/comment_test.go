@@ -204,7 +204,38 @@ var emphasizeTests = []struct {
 func TestEmphasize(t *testing.T) {
 	for i, tt := range emphasizeTests {
 		var buf bytes.Buffer
-		emphasize(&buf, tt.in, true)
+		emphasize(&buf, tt.in, true, nil)
+		out := buf.String()
+		if out != tt.out {
+			t.Errorf("#%d: mismatch\nhave: %v\nwant: %v", i, out, tt.out)
+		}
+	}
+}
+
+func TestEmphasizeLinks(t *testing.T) {
+	link := func(name string, bare bool) string {
+		switch {
+		case name == "Reader" || !bare && name == "io.Reader":
+			return "https://pkg.go.dev/io#Reader"
+		case !bare && name == "encoding/json":
+			return "https://pkg.go.dev/encoding/json"
+		}
+		return ""
+	}
+	tests := []struct {
+		in, out string
+	}{
+		{"See [io.Reader].", `See [io\.Reader](https://pkg.go.dev/io#Reader)\.`},
+		{"A [*io.Reader] value", `A [\*io\.Reader](https://pkg.go.dev/io#Reader) value`},
+		{"Uses [encoding/json] (package)", `Uses [encoding\/json](https://pkg.go.dev/encoding/json) \(package\)`},
+		{"An [unknown] name", `An \[unknown\] name`},
+		{"Wraps a Reader, not an io.Reader", `Wraps a [Reader](https://pkg.go.dev/io#Reader), not an io\.Reader`},
+		{"Not [io.Reader](http://x) or [io.Reader]: def", `Not \[io\.Reader\]\([http\:\/\/x](http://x)\) or \[io\.Reader\]\: def`},
+		{"See https://example.com/[io.Reader]", `See [https\:\/\/example\.com\/\[io\.Reader\]](https://example.com/[io.Reader])`},
+	}
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		emphasize(&buf, tt.in, true, link)
 		out := buf.String()
 		if out != tt.out {
 			t.Errorf("#%d: mismatch\nhave: %v\nwant: %v", i, out, tt.out)
@@ -99,6 +99,7 @@ type completionOptions struct {
 	literal           bool
 	snippets          bool
 	postfix           bool
+	postfixSnippets   []source.PostfixSnippet
 	matcher           source.Matcher
 	budget            time.Duration
 }
@@ -466,11 +467,15 @@ func Completion(ctx context.Context, snapshot source.Snapshot, fh source.FileHan
 	// Check if completion at this position is valid. If not, return early.
 	switch n := path[0].(type) {
 	case *ast.BasicLit:
-		// Skip completion inside literals except for ImportSpec
+		// Skip completion inside literals except for ImportSpec and struct
+		// tags.
 		if len(path) > 1 {
 			if _, ok := path[1].(*ast.ImportSpec); ok {
 				break
 			}
+			if field, ok := path[1].(*ast.Field); ok && field.Tag == n {
+				return structTagCompletions(snapshot, pgf, field, rng.Start)
+			}
 		}
 		return nil, nil, nil
 	case *ast.CallExpr:
@@ -528,6 +533,7 @@ func Completion(ctx context.Context, snapshot source.Snapshot, fh source.FileHan
 			budget:            opts.CompletionBudget,
 			snippets:          opts.InsertTextFormat == protocol.SnippetTextFormat,
 			postfix:           opts.ExperimentalPostfixCompletions,
+			postfixSnippets:   opts.PostfixSnippets,
 		},
 		// default to a matcher that always matches
 		matcher:        prefixMatcher(""),
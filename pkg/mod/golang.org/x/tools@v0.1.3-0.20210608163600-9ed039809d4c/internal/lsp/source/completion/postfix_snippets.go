@@ -67,6 +67,10 @@ type postfixTmplArgs struct {
 	// Type is the type of "foo.bar" in "foo.bar.print!".
 	Type types.Type
 
+	// call is SelectorExpr.X, if it is a call.
+	call *ast.CallExpr
+	fset *token.FileSet
+
 	scope          *types.Scope
 	snip           snippet.Builder
 	importIfNeeded func(pkgPath string, scope *types.Scope) (name string, edits []protocol.TextEdit, err error)
@@ -213,6 +217,37 @@ func (a *postfixTmplArgs) Kind() string {
 	return strings.ToLower(strings.TrimPrefix(t.String(), "*types."))
 }
 
+// IsError reports whether X is an error.
+func (a *postfixTmplArgs) IsError() bool {
+	return types.Implements(a.Type, errorIntf)
+}
+
+// Fun returns the textual function of X, or "" if X is not a call. For
+// example, when completing "foo.bar(x, y).ctx!", "Fun" is "foo.bar".
+func (a *postfixTmplArgs) Fun() string {
+	if a.call == nil {
+		return ""
+	}
+	return source.FormatNode(a.fset, a.call.Fun)
+}
+
+// Args returns the textual arguments of X, or "" if X is not a call or has
+// no arguments. For example, when completing "foo.bar(x, y).ctx!", "Args"
+// is "x, y".
+func (a *postfixTmplArgs) Args() string {
+	if a.call == nil {
+		return ""
+	}
+	var args []string
+	for _, arg := range a.call.Args {
+		args = append(args, source.FormatNode(a.fset, arg))
+	}
+	if a.call.Ellipsis.IsValid() {
+		args[len(args)-1] += "..."
+	}
+	return strings.Join(args, ", ")
+}
+
 // KeyType returns the type of X's key. KeyType panics if X is not a
 // map.
 func (a *postfixTmplArgs) KeyType() types.Type {
@@ -349,7 +384,18 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 		afterDot = c.pos
 	}
 
-	for _, rule := range postfixTmpls {
+	rules := postfixTmpls
+	for _, snippet := range c.opts.postfixSnippets {
+		rules = append(rules[:len(rules):len(rules)], postfixTmpl{
+			label:   snippet.Label,
+			details: snippet.Details,
+			body:    snippet.Body,
+			tmpl:    snippet.Template(),
+		})
+	}
+
+	call, _ := sel.X.(*ast.CallExpr)
+	for _, rule := range rules {
 		// When completing foo.print<>, "print" is naturally overwritten,
 		// but we need to also remove "foo." so the snippet has a clean
 		// slate.
@@ -364,6 +410,8 @@ func (c *completer) addPostfixSnippetCandidates(ctx context.Context, sel *ast.Se
 			StmtOK:         stmtOK,
 			Obj:            exprObj(c.pkg.GetTypesInfo(), sel.X),
 			Type:           selType,
+			call:           call,
+			fset:           c.snapshot.FileSet(),
 			qf:             c.qf,
 			importIfNeeded: c.importIfNeeded,
 			scope:          scope,
@@ -0,0 +1,33 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+// TestPostfixSnippetFields checks that the configuration checks the
+// user-defined snippets against the fields and methods they get.
+func TestPostfixSnippetFields(t *testing.T) {
+	var got []string
+	typ := reflect.TypeOf(&postfixTmplArgs{})
+	for i := 0; i < typ.NumMethod(); i++ {
+		got = append(got, typ.Method(i).Name)
+	}
+	for i := 0; i < typ.Elem().NumField(); i++ {
+		if f := typ.Elem().Field(i); f.PkgPath == "" {
+			got = append(got, f.Name)
+		}
+	}
+	sort.Strings(got)
+	if want := source.PostfixSnippetFields; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("postfixTmplArgs has %v, source.PostfixSnippetFields is %v", got, want)
+	}
+}
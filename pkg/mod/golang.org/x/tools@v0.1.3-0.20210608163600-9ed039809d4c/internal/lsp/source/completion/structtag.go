@@ -0,0 +1,199 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package completion
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+
+	"golang.org/x/tools/internal/lsp/fuzzy"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/snippet"
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+// tagKeyRx matches the keys of a struct tag.
+var tagKeyRx = regexp.MustCompile(`([^\s:"]+):"`)
+
+// structTagCompletions offers completions inside the raw string tag of a
+// struct field: the known keys, with the transformed field name as value,
+// and the names and options of their values.
+func structTagCompletions(snapshot source.Snapshot, pgf *source.ParsedGoFile, field *ast.Field, pos token.Pos) ([]CompletionItem, *Selection, error) {
+	lit := field.Tag
+	if len(lit.Value) < 2 || lit.Value[0] != '`' || pos <= lit.Pos() || pos >= lit.End() {
+		return nil, nil, nil
+	}
+	content := lit.Value[1 : len(lit.Value)-1]
+	offset := int(pos-lit.Pos()) - 1
+	var fieldName string
+	if len(field.Names) == 1 {
+		fieldName = field.Names[0].Name
+	}
+
+	// selection returns the surrounding of the word of content that starts
+	// at start and extends past the cursor up to the first byte of stop.
+	selection := func(start int, stop string) *Selection {
+		end := offset
+		for end < len(content) && !strings.ContainsRune(stop, rune(content[end])) {
+			end++
+		}
+		return &Selection{
+			content:     content[start:end],
+			cursor:      pos,
+			MappedRange: source.NewMappedRange(snapshot.FileSet(), pgf.Mapper, lit.Pos()+token.Pos(start+1), lit.Pos()+token.Pos(end+1)),
+		}
+	}
+
+	// Find the key or the value that encloses the cursor.
+	var key string
+	i := 0
+	for {
+		for i < offset && content[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < offset && content[i] != ':' && content[i] != ' ' && content[i] != '"' {
+			i++
+		}
+		if i == offset {
+			return structTagKeyCompletions(content, fieldName, selection(start, ` :"`), snapshot.View().Options().UsePlaceholders)
+		}
+		if content[i] != ':' || i+1 >= offset || content[i+1] != '"' {
+			return nil, nil, nil
+		}
+		key = content[start:i]
+		i += 2
+		valueStart := i
+		for i < offset && content[i] != '"' {
+			if content[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= offset {
+			i = valueStart
+			break
+		}
+		i++
+	}
+	var known *source.StructTagKey
+	for j := range source.StructTagKeys {
+		if source.StructTagKeys[j].Name == key {
+			known = &source.StructTagKeys[j]
+		}
+	}
+	if known == nil {
+		return nil, nil, nil
+	}
+
+	value := content[i:offset]
+	comma := strings.LastIndexByte(value, ',')
+	if comma < 0 && !known.NoName {
+		// Complete the name, with the transformations of the field name.
+		if fieldName == "" {
+			return nil, nil, nil
+		}
+		surrounding := selection(i, `,"`)
+		matcher := fuzzy.NewMatcher(surrounding.Prefix())
+		var items []CompletionItem
+		seen := make(map[string]bool)
+		for _, transform := range []string{known.Case, source.CamelCase, source.SnakeCase, source.PascalCase, source.LispCase, source.KeepCase} {
+			name := source.TransformName(fieldName, transform)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if score := matcher.Score(name); score > 0 {
+				items = append(items, CompletionItem{
+					Label:      name,
+					Detail:     "name of " + fieldName,
+					InsertText: name,
+					Kind:       protocol.ValueCompletion,
+					// Prefer the conventional case of the key.
+					Score: float64(score) * (1 - 0.01*float64(len(items))),
+				})
+			}
+		}
+		return items, surrounding, nil
+	}
+
+	// Complete an option that is not already present.
+	start := i + comma + 1
+	present := make(map[string]bool)
+	end := strings.IndexByte(content[i:], '"')
+	if end < 0 {
+		end = len(content) - i
+	}
+	for _, opt := range strings.Split(content[i:i+end], ",") {
+		present[opt] = true
+	}
+	surrounding := selection(start, `,"`)
+	matcher := fuzzy.NewMatcher(surrounding.Prefix())
+	var items []CompletionItem
+	for _, opt := range known.Options {
+		if present[opt.Name] && opt.Name != surrounding.Content() {
+			continue
+		}
+		if score := matcher.Score(opt.Name); score > 0 {
+			items = append(items, CompletionItem{
+				Label:         opt.Name,
+				Detail:        key + " option",
+				InsertText:    opt.Name,
+				Kind:          protocol.EnumMemberCompletion,
+				Documentation: opt.Doc,
+				Score:         float64(score),
+			})
+		}
+	}
+	return items, surrounding, nil
+}
+
+// structTagKeyCompletions offers the known keys of struct tags that are not
+// already in the tag content.
+func structTagKeyCompletions(content, fieldName string, surrounding *Selection, placeholders bool) ([]CompletionItem, *Selection, error) {
+	present := make(map[string]bool)
+	for _, m := range tagKeyRx.FindAllStringSubmatch(content, -1) {
+		present[m[1]] = true
+	}
+	matcher := fuzzy.NewMatcher(surrounding.Prefix())
+	var items []CompletionItem
+	for _, key := range source.StructTagKeys {
+		if present[key.Name] {
+			continue
+		}
+		score := matcher.Score(key.Name)
+		if score <= 0 {
+			continue
+		}
+		var name string
+		if !key.NoName && fieldName != "" {
+			name = source.TransformName(fieldName, key.Case)
+		}
+		insert := key.Name + `:"` + name + `"`
+		snip := &snippet.Builder{}
+		snip.WriteText(key.Name + `:"`)
+		if placeholders && name != "" {
+			snip.WritePlaceholder(func(b *snippet.Builder) {
+				b.WriteText(name)
+			})
+		} else {
+			snip.WriteText(name)
+			snip.WriteFinalTabstop()
+		}
+		snip.WriteText(`"`)
+		items = append(items, CompletionItem{
+			Label:         key.Name,
+			Detail:        insert,
+			InsertText:    insert,
+			Kind:          protocol.PropertyCompletion,
+			Documentation: key.Doc,
+			Score:         float64(score),
+			snippet:       snip,
+		})
+	}
+	return items, surrounding, nil
+}
@@ -171,28 +171,59 @@ type returnVariable struct {
 	zeroVal ast.Expr
 }
 
+// extractMethod refactors the selected block of code into a new method.
+func extractMethod(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, pkg *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	return extractFunctionMethod(fset, rng, src, file, pkg, info, true)
+}
+
 // extractFunction refactors the selected block of code into a new function.
+func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, pkg *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
+	return extractFunctionMethod(fset, rng, src, file, pkg, info, false)
+}
+
+// extractFunctionMethod refactors the selected block of code into a new function/method.
 // It also replaces the selected block of code with a call to the extracted
 // function. First, we manually adjust the selection range. We remove trailing
 // and leading whitespace characters to ensure the range is precisely bounded
 // by AST nodes. Next, we determine the variables that will be the parameters
-// and return values of the extracted function. Lastly, we construct the call
-// of the function and insert this call as well as the extracted function into
+// and return values of the extracted function/method. Lastly, we construct the call
+// of the function/method and insert this call as well as the extracted function/method into
 // their proper locations.
-func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, pkg *types.Package, info *types.Info) (*analysis.SuggestedFix, error) {
-	p, ok, err := CanExtractFunction(fset, rng, src, file)
-	if !ok {
-		return nil, fmt.Errorf("extractFunction: cannot extract %s: %v",
+func extractFunctionMethod(fset *token.FileSet, rng span.Range, src []byte, file *ast.File, pkg *types.Package, info *types.Info, isMethod bool) (*analysis.SuggestedFix, error) {
+	errorPrefix := "extractFunction"
+	if isMethod {
+		errorPrefix = "extractMethod"
+	}
+	p, ok, methodOk, err := CanExtractFunction(fset, rng, src, file)
+	if (!ok && !isMethod) || (!methodOk && isMethod) {
+		return nil, fmt.Errorf("%s: cannot extract %s: %v", errorPrefix,
 			fset.Position(rng.Start), err)
 	}
 	tok, path, rng, outer, start := p.tok, p.path, p.rng, p.outer, p.start
 	fileScope := info.Scopes[file]
 	if fileScope == nil {
-		return nil, fmt.Errorf("extractFunction: file scope is empty")
+		return nil, fmt.Errorf("%s: file scope is empty", errorPrefix)
 	}
 	pkgScope := fileScope.Parent()
 	if pkgScope == nil {
-		return nil, fmt.Errorf("extractFunction: package scope is empty")
+		return nil, fmt.Errorf("%s: package scope is empty", errorPrefix)
+	}
+
+	// A method receiver is not a parameter of the extracted method: it is
+	// the receiver of the new method too.
+	var receiverObj types.Object
+	var receiverName string
+	if isMethod {
+		receiverName = outer.Recv.List[0].Names[0].Name
+		receiverObj = info.Defs[outer.Recv.List[0].Names[0]]
+		if receiverObj == nil {
+			return nil, fmt.Errorf("%s: no type information for the receiver", errorPrefix)
+		}
+		// Changes to a value receiver would be made to the copy received by
+		// the extracted method.
+		if _, ok := receiverObj.Type().Underlying().(*types.Pointer); !ok && modifiesObj(info, rng, path[0], receiverObj) {
+			return nil, fmt.Errorf("%s: the selection modifies the value receiver %s", errorPrefix, receiverName)
+		}
 	}
 
 	// A return statement is non-nested if its parent node is equal to the parent node
@@ -208,6 +239,11 @@ func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.
 		if n.Pos() < rng.Start || n.End() > rng.End {
 			return n.Pos() <= rng.End
 		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			// Returns in function literals don't return from the enclosing
+			// function.
+			return false
+		}
 		ret, ok := n.(*ast.ReturnStmt)
 		if !ok {
 			return true
@@ -274,6 +310,12 @@ func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.
 			// The blank identifier is always a local variable
 			continue
 		}
+		if isMethod && v.obj == receiverObj {
+			if v.assigned {
+				return nil, fmt.Errorf("%s: the receiver is assigned in the selection", errorPrefix)
+			}
+			continue
+		}
 		typ := analysisinternal.TypeExpr(fset, file, pkg, v.obj.Type())
 		if typ == nil {
 			return nil, fmt.Errorf("nil AST expression for type: %v", v.obj.Name())
@@ -420,9 +462,51 @@ func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.
 	//     return b
 	// }
 
+	// If the enclosing function returns an error, and every return statement
+	// in the selection returns a provably non-nil error along with zero
+	// values, the error alone reports whether the enclosing function must
+	// return. An error that may be nil, such as a variable or the result of a
+	// call, needs the bool flag above:
+	//
+	// Before:
+	//
+	// func _(a int) (int, error) {
+	//     **if a < 0 {
+	//         return 0, errors.New("negative")
+	//     }
+	//     b := a + 1**
+	//     return b, nil
+	// }
+	//
+	// After:
+	//
+	// func _(a int) (int, error) {
+	//     b, err := fn0(a)
+	//     if err != nil {
+	//         return 0, err
+	//     }
+	//     return b, nil
+	// }
+	//
+	// func fn0(a int) (int, error) {
+	//     if a < 0 {
+	//         return 0, errors.New("negative")
+	//     }
+	//     b := a + 1
+	//     return b, nil
+	// }
 	var retVars []*returnVariable
 	var ifReturn *ast.IfStmt
-	if containsReturnStatement {
+	if containsReturnStatement && !hasNonNestedReturn && returnsOnlyErrors(fset, file, pkg, info, enclosing, retStmts) {
+		if err := adjustErrorReturnStatements(returnTypes, seenVars, fset, file,
+			pkg, extractedBlock); err != nil {
+			return nil, err
+		}
+		retVars, ifReturn, err = generateErrorReturnInfo(enclosing, pkg, path, file, info, fset, rng.Start)
+		if err != nil {
+			return nil, err
+		}
+	} else if containsReturnStatement {
 		if !hasNonNestedReturn {
 			// The selected block contained return statements, so we have to modify the
 			// signature of the extracted function as described above. Adjust all of
@@ -465,9 +549,14 @@ func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.
 	if canDefine {
 		sym = token.DEFINE
 	}
-	funName := generateAvailableIdentifier(rng.Start, file, path, info, "fn", 0)
+	var funName string
+	if isMethod {
+		funName = generateAvailableMethodName(rng.Start, file, path, info, pkg, receiverObj.Type(), "fn")
+	} else {
+		funName = generateAvailableIdentifier(rng.Start, file, path, info, "fn", 0)
+	}
 	extractedFunCall := generateFuncCall(hasNonNestedReturn, hasReturnValues, params,
-		append(returns, getNames(retVars)...), funName, sym)
+		append(returns, getNames(retVars)...), funName, sym, receiverName)
 
 	// Build the extracted function.
 	newFunc := &ast.FuncDecl{
@@ -478,6 +567,16 @@ func extractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.
 		},
 		Body: extractedBlock,
 	}
+	if isMethod {
+		recvType := analysisinternal.TypeExpr(fset, file, pkg, receiverObj.Type())
+		if recvType == nil {
+			return nil, fmt.Errorf("%s: nil AST expression for the receiver type", errorPrefix)
+		}
+		newFunc.Recv = &ast.FieldList{List: []*ast.Field{{
+			Names: []*ast.Ident{ast.NewIdent(receiverName)},
+			Type:  recvType,
+		}}}
+	}
 
 	// Create variable declarations for any identifiers that need to be initialized prior to
 	// calling the extracted function. We do not manually initialize variables if every return
@@ -828,6 +927,62 @@ func referencesObj(info *types.Info, expr ast.Expr, obj types.Object) bool {
 	return hasObj
 }
 
+// modifiesObj reports whether the statements of node in rng assign to obj,
+// to one of its fields or to one of its elements.
+func modifiesObj(info *types.Info, rng span.Range, node ast.Node, obj types.Object) bool {
+	modifies := false
+	isObj := func(expr ast.Expr) bool {
+		for {
+			switch e := expr.(type) {
+			case *ast.Ident:
+				return info.ObjectOf(e) == obj
+			case *ast.SelectorExpr:
+				expr = e.X
+			case *ast.IndexExpr:
+				expr = e.X
+			case *ast.ParenExpr:
+				expr = e.X
+			default:
+				return false
+			}
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil || modifies {
+			return false
+		}
+		if n.Pos() < rng.Start || n.End() > rng.End {
+			return n.Pos() <= rng.End
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				modifies = modifies || isObj(lhs)
+			}
+		case *ast.IncDecStmt:
+			modifies = isObj(n.X)
+		case *ast.UnaryExpr:
+			// Taking the address of the receiver may lead to its
+			// modification.
+			modifies = n.Op == token.AND && isObj(n.X)
+		}
+		return true
+	})
+	return modifies
+}
+
+// generateAvailableMethodName returns a name for a new method of recv that
+// neither collides with the identifiers in scope at pos nor with the fields
+// and methods of recv.
+func generateAvailableMethodName(pos token.Pos, file *ast.File, path []ast.Node, info *types.Info, pkg *types.Package, recv types.Type, prefix string) string {
+	for idx := 0; ; idx++ {
+		name := generateAvailableIdentifier(pos, file, path, info, prefix, idx)
+		if obj, _, _ := types.LookupFieldOrMethod(recv, true, pkg, name); obj == nil {
+			return name
+		}
+	}
+}
+
 type fnExtractParams struct {
 	tok   *token.File
 	path  []ast.Node
@@ -837,25 +992,26 @@ type fnExtractParams struct {
 }
 
 // CanExtractFunction reports whether the code in the given range can be
-// extracted to a function.
-func CanExtractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.File) (*fnExtractParams, bool, error) {
+// extracted to a function, and whether it can be extracted to a method of
+// the receiver of the enclosing method.
+func CanExtractFunction(fset *token.FileSet, rng span.Range, src []byte, file *ast.File) (*fnExtractParams, bool, bool, error) {
 	if rng.Start == rng.End {
-		return nil, false, fmt.Errorf("start and end are equal")
+		return nil, false, false, fmt.Errorf("start and end are equal")
 	}
 	tok := fset.File(file.Pos())
 	if tok == nil {
-		return nil, false, fmt.Errorf("no file for pos %v", fset.Position(file.Pos()))
+		return nil, false, false, fmt.Errorf("no file for pos %v", fset.Position(file.Pos()))
 	}
 	rng = adjustRangeForWhitespace(rng, tok, src)
 	path, _ := astutil.PathEnclosingInterval(file, rng.Start, rng.End)
 	if len(path) == 0 {
-		return nil, false, fmt.Errorf("no path enclosing interval")
+		return nil, false, false, fmt.Errorf("no path enclosing interval")
 	}
 	// Node that encloses the selection must be a statement.
 	// TODO: Support function extraction for an expression.
 	_, ok := path[0].(ast.Stmt)
 	if !ok {
-		return nil, false, fmt.Errorf("node is not a statement")
+		return nil, false, false, fmt.Errorf("node is not a statement")
 	}
 
 	// Find the function declaration that encloses the selection.
@@ -867,9 +1023,14 @@ func CanExtractFunction(fset *token.FileSet, rng span.Range, src []byte, file *a
 		}
 	}
 	if outer == nil {
-		return nil, false, fmt.Errorf("no enclosing function")
+		return nil, false, false, fmt.Errorf("no enclosing function")
 	}
 
+	// The selection can be extracted to a method if the receiver of the
+	// enclosing method is named.
+	method := outer.Recv != nil && len(outer.Recv.List) == 1 &&
+		len(outer.Recv.List[0].Names) == 1 && outer.Recv.List[0].Names[0].Name != "_"
+
 	// Find the nodes at the start and end of the selection.
 	var start, end ast.Node
 	ast.Inspect(outer, func(n ast.Node) bool {
@@ -887,7 +1048,7 @@ func CanExtractFunction(fset *token.FileSet, rng span.Range, src []byte, file *a
 		return n.Pos() <= rng.End
 	})
 	if start == nil || end == nil {
-		return nil, false, fmt.Errorf("range does not map to AST nodes")
+		return nil, false, false, fmt.Errorf("range does not map to AST nodes")
 	}
 	return &fnExtractParams{
 		tok:   tok,
@@ -895,7 +1056,7 @@ func CanExtractFunction(fset *token.FileSet, rng span.Range, src []byte, file *a
 		rng:   rng,
 		outer: outer,
 		start: start,
-	}, true, nil
+	}, true, method, nil
 }
 
 // objUsed checks if the object is used within the range. It returns the first
@@ -1068,6 +1229,9 @@ func adjustReturnStatements(returnTypes []*ast.Field, seenVars map[types.Object]
 		if n == nil {
 			return false
 		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
 		if n, ok := n.(*ast.ReturnStmt); ok {
 			n.Results = append(zeroVals, n.Results...)
 			return false
@@ -1077,13 +1241,188 @@ func adjustReturnStatements(returnTypes []*ast.Field, seenVars map[types.Object]
 	return nil
 }
 
+// returnsOnlyErrors reports whether the last result of the enclosing function
+// is an error and every return statement in rets returns a non-nil error and
+// the zero value of the other results, in which case the error is enough to
+// report whether the enclosing function must return after calling the
+// extracted function.
+func returnsOnlyErrors(fset *token.FileSet, file *ast.File, pkg *types.Package, info *types.Info, enclosing *ast.FuncType, rets []*ast.ReturnStmt) bool {
+	if enclosing.Results == nil {
+		return false
+	}
+	var results []types.Type
+	for _, field := range enclosing.Results.List {
+		typ := info.TypeOf(field.Type)
+		if typ == nil {
+			return false
+		}
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			results = append(results, typ)
+		}
+	}
+	errorType := types.Universe.Lookup("error").Type()
+	if len(results) == 0 || !types.Identical(results[len(results)-1], errorType) {
+		return false
+	}
+	for _, ret := range rets {
+		if len(ret.Results) != len(results) {
+			return false
+		}
+		if !isNonNilError(info, ret.Results[len(ret.Results)-1]) {
+			return false
+		}
+		for i, expr := range ret.Results[:len(ret.Results)-1] {
+			if !isZeroValue(fset, file, pkg, expr, results[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isNonNilError reports whether the error expr is provably not nil: it
+// converts a value of a concrete type to error, which gives a non-nil
+// interface even for a nil pointer, or it calls errors.New or fmt.Errorf.
+// Error variables and the results of other calls may be nil.
+func isNonNilError(info *types.Info, expr ast.Expr) bool {
+	typ := info.TypeOf(expr)
+	if typ == nil {
+		return false
+	}
+	if !types.IsInterface(typ) {
+		return typ != types.Typ[types.UntypedNil]
+	}
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := astutil.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	switch fn.Pkg().Path() + "." + fn.Name() {
+	case "errors.New", "fmt.Errorf":
+		return true
+	}
+	return false
+}
+
+// isZeroValue reports whether expr is the zero value of typ, as it would be
+// written by analysisinternal.ZeroValue.
+func isZeroValue(fset *token.FileSet, file *ast.File, pkg *types.Package, expr ast.Expr, typ types.Type) bool {
+	zero := analysisinternal.ZeroValue(fset, file, pkg, typ)
+	if zero == nil {
+		return false
+	}
+	var got, want bytes.Buffer
+	if err := format.Node(&got, fset, expr); err != nil {
+		return false
+	}
+	if err := format.Node(&want, token.NewFileSet(), zero); err != nil {
+		return false
+	}
+	return got.String() == want.String()
+}
+
+// adjustErrorReturnStatements rewrites each return statement in the given
+// AST node to return the "zero values" of the given types and the error it
+// returned.
+func adjustErrorReturnStatements(returnTypes []*ast.Field, seenVars map[types.Object]ast.Expr, fset *token.FileSet, file *ast.File, pkg *types.Package, extractedBlock *ast.BlockStmt) error {
+	var zeroVals []ast.Expr
+	for _, returnType := range returnTypes {
+		var val ast.Expr
+		for obj, typ := range seenVars {
+			if typ != returnType.Type {
+				continue
+			}
+			val = analysisinternal.ZeroValue(fset, file, pkg, obj.Type())
+			break
+		}
+		if val == nil {
+			return fmt.Errorf(
+				"could not find matching AST expression for %T", returnType.Type)
+		}
+		zeroVals = append(zeroVals, val)
+	}
+	ast.Inspect(extractedBlock, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if n, ok := n.(*ast.ReturnStmt); ok {
+			n.Results = append(zeroVals[:len(zeroVals):len(zeroVals)], n.Results[len(n.Results)-1])
+			return false
+		}
+		return true
+	})
+	return nil
+}
+
+// generateErrorReturnInfo is like generateReturnInfo, for extracted functions
+// that report whether the enclosing function must return with an error: it
+// generates the error variable and the if statement that returns it.
+func generateErrorReturnInfo(enclosing *ast.FuncType, pkg *types.Package, path []ast.Node, file *ast.File, info *types.Info, fset *token.FileSet, pos token.Pos) ([]*returnVariable, *ast.IfStmt, error) {
+	name := "err"
+	if file.Scope.Lookup(name) != nil || !isValidName(name, CollectScopes(info, path, pos)) {
+		name = generateAvailableIdentifier(pos, file, path, info, "err", 0)
+	}
+	errVar := ast.NewIdent(name)
+	retVars := []*returnVariable{{
+		name:    errVar,
+		decl:    &ast.Field{Type: ast.NewIdent("error")},
+		zeroVal: ast.NewIdent("nil"),
+	}}
+	var results []ast.Expr
+	for _, field := range enclosing.Results.List {
+		typ := info.TypeOf(field.Type)
+		if typ == nil {
+			return nil, nil, fmt.Errorf(
+				"failed type conversion, AST expression: %T", field.Type)
+		}
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			zero := analysisinternal.ZeroValue(fset, file, pkg, typ)
+			if zero == nil {
+				return nil, nil, fmt.Errorf("nil AST expression")
+			}
+			results = append(results, zero)
+		}
+	}
+	results[len(results)-1] = errVar
+	ifReturn := &ast.IfStmt{
+		Cond: &ast.BinaryExpr{X: errVar, Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: &ast.BlockStmt{
+			List: []ast.Stmt{&ast.ReturnStmt{Results: results}},
+		},
+	}
+	return retVars, ifReturn, nil
+}
+
 // generateFuncCall constructs a call expression for the extracted function, described by the
-// given parameters and return variables.
-func generateFuncCall(hasNonNestedReturn, hasReturnVals bool, params, returns []ast.Expr, name string, token token.Token) ast.Node {
+// given parameters and return variables. If selector is not empty, the extracted function is
+// a method of selector.
+func generateFuncCall(hasNonNestedReturn, hasReturnVals bool, params, returns []ast.Expr, name string, token token.Token, selector string) ast.Node {
+	var fun ast.Expr = ast.NewIdent(name)
+	if selector != "" {
+		fun = &ast.SelectorExpr{X: ast.NewIdent(selector), Sel: ast.NewIdent(name)}
+	}
 	var replace ast.Node
 	if hasReturnVals {
 		callExpr := &ast.CallExpr{
-			Fun:  ast.NewIdent(name),
+			Fun:  fun,
 			Args: params,
 		}
 		if hasNonNestedReturn {
@@ -1102,7 +1441,7 @@ func generateFuncCall(hasNonNestedReturn, hasReturnVals bool, params, returns []
 		}
 	} else {
 		replace = &ast.CallExpr{
-			Fun:  ast.NewIdent(name),
+			Fun:  fun,
 			Args: params,
 		}
 	}
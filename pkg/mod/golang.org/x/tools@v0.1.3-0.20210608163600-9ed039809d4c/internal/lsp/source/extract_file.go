@@ -0,0 +1,265 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// CanExtractToNewFile reports whether rng selects top-level declarations,
+// other than imports, that can be moved to a new file.
+func CanExtractToNewFile(file *ast.File, rng span.Range) bool {
+	return len(selectedDecls(file, rng)) > 0
+}
+
+// ExtractToNewFile moves the top-level declarations selected by rng to a new
+// file of the same package, in the same directory. The new file is named
+// after the first declaration and contains the imports the declarations
+// need. It returns the URI and content of the new file, and the edits that
+// remove the declarations, and the imports that become unused, from the
+// original file.
+func ExtractToNewFile(ctx context.Context, snapshot Snapshot, fh VersionedFileHandle, rng protocol.Range) (span.URI, []byte, []protocol.TextDocumentEdit, error) {
+	ctx, done := event.Start(ctx, "source.ExtractToNewFile")
+	defer done()
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return "", nil, nil, errors.Errorf("getting file for extraction: %w", err)
+	}
+	srng, err := pgf.Mapper.RangeToSpanRange(rng)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	decls := selectedDecls(pgf.File, srng)
+	if len(decls) == 0 {
+		return "", nil, nil, fmt.Errorf("no declarations selected")
+	}
+	filename := filepath.Join(filepath.Dir(fh.URI().Filename()), newFileName(fh.URI().Filename(), decls[0]))
+	if _, err := os.Stat(filename); err == nil {
+		return "", nil, nil, fmt.Errorf("cannot extract to %s: file already exists", filename)
+	}
+	newSrc, oldSrc, err := extractToNewFile(snapshot.FileSet(), pgf.File, pgf.Src, pkg.GetTypesInfo(), decls)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	edits, err := computeTextEdits(ctx, snapshot, pgf, string(oldSrc))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return span.URIFromPath(filename), newSrc, []protocol.TextDocumentEdit{{
+		TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+			Version: fh.Version(),
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+				URI: protocol.URIFromSpanURI(fh.URI()),
+			},
+		},
+		Edits: edits,
+	}}, nil
+}
+
+// selectedDecls returns the top-level declarations, other than imports, that
+// start within rng. It returns nil if rng starts in the middle of a
+// declaration, since the selection is then more likely to be meant for
+// another refactoring.
+func selectedDecls(file *ast.File, rng span.Range) []ast.Decl {
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			continue
+		}
+		if rng.Start > decl.Pos() && rng.Start < decl.End() {
+			return nil
+		}
+		start := declStart(decl)
+		if rng.Start <= decl.Pos() && start < rng.End {
+			decls = append(decls, decl)
+		}
+	}
+	return decls
+}
+
+// declStart returns the start of decl, including its doc comment.
+func declStart(decl ast.Decl) token.Pos {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Doc != nil {
+			return decl.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if decl.Doc != nil {
+			return decl.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+// newFileName returns the base name of the file that decl is moved to from
+// the file named from: the lower-cased name of the declared function or type,
+// or of the receiver type of a method.
+func newFileName(from string, decl ast.Decl) string {
+	name := "extracted"
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		name = decl.Name.Name
+		if decl.Recv != nil && len(decl.Recv.List) > 0 {
+			if recv := receiverTypeName(decl.Recv.List[0].Type); recv != "" {
+				name = recv
+			}
+		}
+	case *ast.GenDecl:
+		if len(decl.Specs) > 0 {
+			switch spec := decl.Specs[0].(type) {
+			case *ast.TypeSpec:
+				name = spec.Name.Name
+			case *ast.ValueSpec:
+				name = spec.Names[0].Name
+			}
+		}
+	}
+	name = strings.ToLower(name)
+	if name == "_" {
+		name = "extracted"
+	}
+	if strings.HasSuffix(from, "_test.go") {
+		name += "_test"
+	}
+	return name + ".go"
+}
+
+// receiverTypeName returns the name of the base type of a receiver.
+func receiverTypeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(expr.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
+
+// extractToNewFile returns the content of a new file containing decls, and
+// the content of file once decls, and the imports only they use, are removed.
+func extractToNewFile(fset *token.FileSet, file *ast.File, src []byte, info *types.Info, decls []ast.Decl) ([]byte, []byte, error) {
+	tok := fset.File(file.Pos())
+	if tok == nil {
+		return nil, nil, fmt.Errorf("no file for %s", file.Name.Name)
+	}
+	moved := make(map[ast.Decl]bool)
+	for _, decl := range decls {
+		moved[decl] = true
+	}
+	// Find the imports used by the moved and by the remaining declarations.
+	movedUses := make(map[*types.PkgName]bool)
+	remainingUses := make(map[*types.PkgName]bool)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT {
+			continue
+		}
+		uses := remainingUses
+		if moved[decl] {
+			uses = movedUses
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if pkgName, ok := info.Uses[id].(*types.PkgName); ok {
+					uses[pkgName] = true
+				}
+			}
+			return true
+		})
+	}
+
+	var newBuf bytes.Buffer
+	fmt.Fprintf(&newBuf, "package %s\n\n", file.Name.Name)
+	type deletion struct{ name, path string }
+	var deletions []deletion
+	var importBuf bytes.Buffer
+	for _, imp := range file.Imports {
+		var obj types.Object
+		if imp.Name != nil {
+			obj = info.Defs[imp.Name]
+		} else {
+			obj = info.Implicits[imp]
+		}
+		pkgName, ok := obj.(*types.PkgName)
+		if !ok || !movedUses[pkgName] {
+			continue
+		}
+		fmt.Fprintf(&importBuf, "\t%s\n", src[tok.Offset(imp.Pos()):tok.Offset(imp.End())])
+		if !remainingUses[pkgName] {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			var name string
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			deletions = append(deletions, deletion{name, path})
+		}
+	}
+	if importBuf.Len() > 0 {
+		fmt.Fprintf(&newBuf, "import (\n%s)\n\n", importBuf.Bytes())
+	}
+
+	// Remove the declarations from the original file, along with the
+	// comments that follow them on their last line.
+	var oldBuf bytes.Buffer
+	last := 0
+	for _, decl := range decls {
+		start, end := tok.Offset(declStart(decl)), tok.Offset(decl.End())
+		if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+			if rest := bytes.TrimSpace(src[end : end+i]); len(rest) == 0 || bytes.HasPrefix(rest, []byte("//")) {
+				end += i
+			}
+		}
+		newBuf.Write(src[start:end])
+		newBuf.WriteString("\n\n")
+		oldBuf.Write(src[last:start])
+		last = end
+	}
+	oldBuf.Write(src[last:])
+
+	newSrc, err := format.Source(newBuf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(deletions) == 0 {
+		oldSrc, err := format.Source(oldBuf.Bytes())
+		return newSrc, oldSrc, err
+	}
+	oldFset := token.NewFileSet()
+	oldFile, err := parser.ParseFile(oldFset, tok.Name(), oldBuf.Bytes(), parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, d := range deletions {
+		astutil.DeleteNamedImport(oldFset, oldFile, d.name, d.path)
+	}
+	var out bytes.Buffer
+	if err := format.Node(&out, oldFset, oldFile); err != nil {
+		return nil, nil, err
+	}
+	return newSrc, out.Bytes(), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/span"
+)
+
+func TestExtractToNewFile(t *testing.T) {
+	const src = `package p
+
+import (
+	"fmt"
+	"strings"
+)
+
+// T is moved.
+type T struct{}
+
+func (T) String() string { return strings.ToUpper("t") } // moved too
+
+func f() { fmt.Println(strings.TrimSpace(" f ")) }
+`
+	const wantNew = `package p
+
+import (
+	"strings"
+)
+
+// T is moved.
+type T struct{}
+
+func (T) String() string { return strings.ToUpper("t") } // moved too
+`
+	const wantOld = `package p
+
+import (
+	"fmt"
+	"strings"
+)
+
+func f() { fmt.Println(strings.TrimSpace(" f ")) }
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Uses:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+	tok := fset.File(file.Pos())
+	rangeOf := func(start, end string) span.Range {
+		return span.NewRange(fset, tok.Pos(strings.Index(src, start)), tok.Pos(strings.Index(src, end)+len(end)))
+	}
+
+	// Selecting from the middle of a declaration selects nothing.
+	if decls := selectedDecls(file, rangeOf("struct", "moved too")); len(decls) != 0 {
+		t.Errorf("got %d declarations selected from within a declaration, want 0", len(decls))
+	}
+
+	decls := selectedDecls(file, rangeOf("// T is", "moved too"))
+	if len(decls) != 2 {
+		t.Fatalf("got %d declarations selected, want 2", len(decls))
+	}
+	if got := newFileName("p.go", decls[0]); got != "t.go" {
+		t.Errorf("newFileName = %q, want %q", got, "t.go")
+	}
+	newSrc, oldSrc, err := extractToNewFile(fset, file, []byte(src), info, decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(newSrc) != wantNew {
+		t.Errorf("new file:\n%s", diffStr(t, wantNew, string(newSrc)))
+	}
+	if string(oldSrc) != wantOld {
+		t.Errorf("original file:\n%s", diffStr(t, wantOld, string(oldSrc)))
+	}
+
+	// Moving f removes the fmt import, which only f uses.
+	decls = selectedDecls(file, rangeOf("func f", "\" f \")) }"))
+	if len(decls) != 1 {
+		t.Fatalf("got %d declarations selected, want 1", len(decls))
+	}
+	if got := newFileName("p_test.go", decls[0]); got != "f_test.go" {
+		t.Errorf("newFileName = %q, want %q", got, "f_test.go")
+	}
+	_, oldSrc, err = extractToNewFile(fset, file, []byte(src), info, decls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(oldSrc), "package p\n\nimport (\n\t\"strings\"\n)\n") {
+		t.Errorf("original file after moving f:\n%s", oldSrc)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"sort"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/internal/lsp/analysis/fillstruct"
@@ -32,6 +33,9 @@ const (
 	UndeclaredName  = "undeclared_name"
 	ExtractVariable = "extract_variable"
 	ExtractFunction = "extract_function"
+	ExtractMethod   = "extract_method"
+	InlineCall      = "inline_call"
+	StubMethods     = "stub_methods"
 )
 
 // suggestedFixes maps a suggested fix command id to its handler.
@@ -40,6 +44,19 @@ var suggestedFixes = map[string]SuggestedFixFunc{
 	UndeclaredName:  undeclaredname.SuggestedFix,
 	ExtractVariable: extractVariable,
 	ExtractFunction: extractFunction,
+	ExtractMethod:   extractMethod,
+}
+
+// packageFixFunc is like a SuggestedFixFunc, for fixes that need the syntax
+// of the other files of the package. The edits may apply to any file of the
+// package.
+type packageFixFunc func(fset *token.FileSet, rng span.Range, pgf *ParsedGoFile, pkg Package) (*analysis.SuggestedFix, error)
+
+// packageFixes maps a suggested fix command id to its handler, for the fixes
+// that need the whole package.
+var packageFixes = map[string]packageFixFunc{
+	InlineCall:  inlineCall,
+	StubMethods: stubMethods,
 }
 
 func SuggestedFixFromCommand(cmd protocol.Command, kind protocol.CodeActionKind) SuggestedFix {
@@ -53,15 +70,23 @@ func SuggestedFixFromCommand(cmd protocol.Command, kind protocol.CodeActionKind)
 // ApplyFix applies the command's suggested fix to the given file and
 // range, returning the resulting edits.
 func ApplyFix(ctx context.Context, fix string, snapshot Snapshot, fh VersionedFileHandle, pRng protocol.Range) ([]protocol.TextDocumentEdit, error) {
-	handler, ok := suggestedFixes[fix]
-	if !ok {
-		return nil, fmt.Errorf("no suggested fix function for %s", fix)
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for Identifier: %w", err)
 	}
-	fset, rng, src, file, m, pkg, info, err := getAllSuggestedFixInputs(ctx, snapshot, fh, pRng)
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
 	if err != nil {
 		return nil, err
 	}
-	suggestion, err := handler(fset, rng, src, file, pkg, info)
+	fset := snapshot.FileSet()
+	var suggestion *analysis.SuggestedFix
+	if handler, ok := packageFixes[fix]; ok {
+		suggestion, err = handler(fset, rng, pgf, pkg)
+	} else if handler, ok := suggestedFixes[fix]; ok {
+		suggestion, err = handler(fset, rng, pgf.Src, pgf.File, pkg.GetTypes(), pkg.GetTypesInfo())
+	} else {
+		return nil, fmt.Errorf("no suggested fix function for %s", fix)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -69,45 +94,53 @@ func ApplyFix(ctx context.Context, fix string, snapshot Snapshot, fh VersionedFi
 		return nil, nil
 	}
 
+	// The edits of a file are all relative to its original content, so they
+	// go in a single TextDocumentEdit.
 	var edits []protocol.TextDocumentEdit
+	index := make(map[span.URI]int)
 	for _, edit := range suggestion.TextEdits {
 		rng := span.NewRange(fset, edit.Pos, edit.End)
 		spn, err := rng.Span()
 		if err != nil {
 			return nil, err
 		}
+		editFh, m := fh, pgf.Mapper
+		if spn.URI() != fh.URI() {
+			other, err := pkg.File(spn.URI())
+			if err != nil {
+				return nil, err
+			}
+			if editFh, err = snapshot.GetVersionedFile(ctx, spn.URI()); err != nil {
+				return nil, err
+			}
+			m = other.Mapper
+		}
 		clRng, err := m.Range(spn)
 		if err != nil {
 			return nil, err
 		}
-		edits = append(edits, protocol.TextDocumentEdit{
-			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
-				Version: fh.Version(),
-				TextDocumentIdentifier: protocol.TextDocumentIdentifier{
-					URI: protocol.URIFromSpanURI(fh.URI()),
-				},
-			},
-			Edits: []protocol.TextEdit{
-				{
-					Range:   clRng,
-					NewText: string(edit.NewText),
+		i, ok := index[spn.URI()]
+		if !ok {
+			i = len(edits)
+			index[spn.URI()] = i
+			edits = append(edits, protocol.TextDocumentEdit{
+				TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+					Version: editFh.Version(),
+					TextDocumentIdentifier: protocol.TextDocumentIdentifier{
+						URI: protocol.URIFromSpanURI(editFh.URI()),
+					},
 				},
-			},
+			})
+		}
+		edits[i].Edits = append(edits[i].Edits, protocol.TextEdit{
+			Range:   clRng,
+			NewText: string(edit.NewText),
 		})
 	}
-	return edits, nil
-}
-
-// getAllSuggestedFixInputs is a helper function to collect all possible needed
-// inputs for an AppliesFunc or SuggestedFixFunc.
-func getAllSuggestedFixInputs(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range) (*token.FileSet, span.Range, []byte, *ast.File, *protocol.ColumnMapper, *types.Package, *types.Info, error) {
-	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
-	if err != nil {
-		return nil, span.Range{}, nil, nil, nil, nil, nil, errors.Errorf("getting file for Identifier: %w", err)
-	}
-	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
-	if err != nil {
-		return nil, span.Range{}, nil, nil, nil, nil, nil, err
+	for _, edit := range edits {
+		sort.SliceStable(edit.Edits, func(i, j int) bool {
+			return protocol.ComparePosition(edit.Edits[i].Range.Start, edit.Edits[j].Range.Start) < 0
+		})
 	}
-	return snapshot.FileSet(), rng, pgf.Src, pgf.File, pgf.Mapper, pkg.GetTypes(), pkg.GetTypesInfo(), nil
+	return edits, nil
 }
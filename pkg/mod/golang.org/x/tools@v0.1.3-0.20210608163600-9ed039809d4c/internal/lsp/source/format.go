@@ -16,10 +16,12 @@ import (
 	"strings"
 	"text/scanner"
 
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/imports"
 	"golang.org/x/tools/internal/lsp/diff"
 	"golang.org/x/tools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
 )
 
 // Format formats a file with a given range.
@@ -77,6 +79,137 @@ func formatSource(ctx context.Context, fh FileHandle) ([]byte, error) {
 	return format.Source(data)
 }
 
+// FormatRange formats the statements or declarations that overlap rng,
+// leaving the rest of the file untouched. The formatted code keeps the
+// indentation of the first line of the statements.
+func FormatRange(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.FormatRange")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	if pgf.ParseErr != nil {
+		return nil, errors.Errorf("cannot format a range of a file with parse errors: %v", pgf.ParseErr)
+	}
+	spn, err := pgf.Mapper.RangeSpan(rng)
+	if err != nil {
+		return nil, err
+	}
+	r, err := spn.Range(pgf.Mapper.Converter)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := formatRange(pgf.File, pgf.Tok, pgf.Src, r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	return computeTextEdits(ctx, snapshot, pgf, formatted)
+}
+
+// FormatOnType re-formats the statement or declaration closed by the
+// character ch, typed just before pos. Only '}' triggers formatting.
+func FormatOnType(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position, ch string) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.FormatOnType")
+	defer done()
+
+	if ch != "}" {
+		return nil, nil
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	// The code is likely incomplete while it is being typed: don't format
+	// anything until it parses.
+	if pgf.ParseErr != nil {
+		return nil, nil
+	}
+	spn, err := pgf.Mapper.PointSpan(pos)
+	if err != nil {
+		return nil, err
+	}
+	r, err := spn.Range(pgf.Mapper.Converter)
+	if err != nil {
+		return nil, err
+	}
+	brace := r.Start - 1
+	if offset := pgf.Tok.Offset(r.Start) - 1; offset < 0 || pgf.Src[offset] != '}' {
+		return nil, nil
+	}
+	formatted, err := formatRange(pgf.File, pgf.Tok, pgf.Src, brace, brace)
+	if err != nil {
+		// The statement can't be formatted on its own, e.g. because it
+		// contains a label; there is nothing to report while typing.
+		return nil, nil
+	}
+	return computeTextEdits(ctx, snapshot, pgf, formatted)
+}
+
+// formatRange returns the content of the file src, with the statements or
+// declarations overlapping [start, end] formatted.
+//
+// The formatted nodes are the elements of the innermost statement list
+// (or list of declarations) that overlap the range. They are formatted as
+// a partial source file by go/format, which preserves the indentation of
+// their first line.
+func formatRange(file *ast.File, tok *token.File, src []byte, start, end token.Pos) (string, error) {
+	path, _ := astutil.PathEnclosingInterval(file, start, end)
+	var nodes []ast.Node
+	for _, n := range path {
+		for _, elem := range nodeList(n) {
+			if elem.End() >= start && elem.Pos() <= end {
+				nodes = append(nodes, elem)
+			}
+		}
+		if len(nodes) > 0 {
+			break
+		}
+	}
+	if len(nodes) == 0 {
+		return string(src), nil
+	}
+
+	startOffset := tok.Offset(nodes[0].Pos())
+	endOffset := tok.Offset(nodes[len(nodes)-1].End())
+	// Include the indentation of the first line, go/format uses it to indent
+	// the result.
+	lineStart := tok.Offset(tok.LineStart(tok.Line(nodes[0].Pos())))
+	if len(bytes.TrimSpace(src[lineStart:startOffset])) == 0 {
+		startOffset = lineStart
+	}
+	formatted, err := format.Source(src[startOffset:endOffset])
+	if err != nil {
+		return "", err
+	}
+	return string(src[:startOffset]) + string(formatted) + string(src[endOffset:]), nil
+}
+
+// nodeList returns the statements or declarations listed in n.
+func nodeList(n ast.Node) []ast.Node {
+	var list []ast.Node
+	switch n := n.(type) {
+	case *ast.File:
+		for _, decl := range n.Decls {
+			list = append(list, decl)
+		}
+	case *ast.BlockStmt:
+		for _, stmt := range n.List {
+			list = append(list, stmt)
+		}
+	case *ast.CaseClause:
+		for _, stmt := range n.Body {
+			list = append(list, stmt)
+		}
+	case *ast.CommClause:
+		for _, stmt := range n.Body {
+			list = append(list, stmt)
+		}
+	}
+	return list
+}
+
 type ImportFix struct {
 	Fix   *imports.ImportFix
 	Edits []protocol.TextEdit
@@ -6,6 +6,8 @@ package source
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
 	"strings"
 	"testing"
 
@@ -83,3 +85,119 @@ func diffStr(t *testing.T, want, got string) string {
 	}
 	return fmt.Sprintf("%q", diff.ToUnified("want", "got", want, d))
 }
+
+func TestFormatRange(t *testing.T) {
+	const src = `package p
+
+func f() {
+	if true {
+	x   :=   1
+	_ = x
+	}
+	y :=   2
+	_ = []int{1,
+	2}
+	_ = y
+}
+
+var  z   =  3
+`
+	for _, tt := range []struct {
+		start, end string // text at the start and end of the range, unique in src
+		want       string
+	}{
+		{
+			start: "x   :=",
+			end:   "x   :=",
+			want: `package p
+
+func f() {
+	if true {
+	x := 1
+	_ = x
+	}
+	y :=   2
+	_ = []int{1,
+	2}
+	_ = y
+}
+
+var  z   =  3
+`,
+		},
+		{
+			// At a closing brace, the whole statement is formatted.
+			start: "}\n\ty",
+			end:   "}\n\ty",
+			want: `package p
+
+func f() {
+	if true {
+		x := 1
+		_ = x
+	}
+	y :=   2
+	_ = []int{1,
+	2}
+	_ = y
+}
+
+var  z   =  3
+`,
+		},
+		{
+			start: "y :=",
+			end:   "2}",
+			want: `package p
+
+func f() {
+	if true {
+	x   :=   1
+	_ = x
+	}
+	y := 2
+	_ = []int{1,
+		2}
+	_ = y
+}
+
+var  z   =  3
+`,
+		},
+		{
+			start: "var",
+			end:   "var",
+			want: `package p
+
+func f() {
+	if true {
+	x   :=   1
+	_ = x
+	}
+	y :=   2
+	_ = []int{1,
+	2}
+	_ = y
+}
+
+var z = 3
+`,
+		},
+	} {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok := fset.File(file.Pos())
+		start := tok.Pos(strings.Index(src, tt.start))
+		end := tok.Pos(strings.Index(src, tt.end))
+		got, err := formatRange(file, tok, []byte(src), start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("formatting %q-%q:\n%s", tt.start, tt.end, diffStr(t, tt.want, got))
+		}
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"go/types"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/lsp/protocol"
@@ -54,6 +56,14 @@ type HoverInformation struct {
 	source  interface{}
 	comment *ast.CommentGroup
 
+	// docLink resolves the names of the documentation that are converted
+	// into links: doc links such as [io.Reader], and bare identifiers if bare
+	// is set.
+	docLink func(name string, bare bool) (path, anchor string, ok bool)
+
+	// examples are the example functions of the symbol.
+	examples []*hoverExample
+
 	// typeName contains the identifier name when the identifier is a type declaration.
 	// If it is not empty, the hover will have the prefix "type <typeName> ".
 	typeName string
@@ -134,6 +144,14 @@ func HoverIdentifier(ctx context.Context, i *IdentifierInfo) (*HoverInformation,
 	if obj == nil {
 		return h, nil
 	}
+	h.docLink = docLinkResolver(i, obj)
+	if i.Snapshot.View().Options().HoverKind == FullDocumentation {
+		examples, err := findExamples(ctx, i, obj)
+		if err != nil {
+			event.Error(ctx, "finding examples", err)
+		}
+		h.examples = examples
+	}
 	switch obj := obj.(type) {
 	case *types.PkgName:
 		h.importPath = obj.Imported().Path()
@@ -217,6 +235,157 @@ func HoverIdentifier(ctx context.Context, i *IdentifierInfo) (*HoverInformation,
 	return h, nil
 }
 
+// docLinkResolver returns the resolver of the names in the documentation of
+// obj, which are looked up in the scope of the package that declares it, or
+// of the imported package for package names.
+func docLinkResolver(i *IdentifierInfo, obj types.Object) func(string, bool) (string, string, bool) {
+	pkg := obj.Pkg()
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		pkg = pkgName.Imported()
+	}
+	if pkg == nil {
+		return nil
+	}
+	return func(name string, bare bool) (string, string, bool) {
+		var path, anchor string
+		if bare {
+			// Mentions of the symbol itself are not worth a link, and
+			// single letters, such as I or A, are more likely words.
+			if name == obj.Name() || len(name) == 1 {
+				return "", "", false
+			}
+			if o := pkg.Scope().Lookup(name); o == nil || !o.Exported() {
+				return "", "", false
+			}
+			path, anchor = pkg.Path(), name
+		} else {
+			var ok bool
+			if path, anchor, ok = resolveDocLink(pkg, name); !ok {
+				return "", "", false
+			}
+		}
+		// See golang/go#36998: don't link to modules matching GOPRIVATE.
+		if i.Snapshot.View().IsGoPrivatePath(path) {
+			return "", "", false
+		}
+		if mod, version, ok := moduleAtVersion(path, i); ok {
+			path = strings.Replace(path, mod, mod+"@"+version, 1)
+		}
+		return path, anchor, true
+	}
+}
+
+// resolveDocLink returns the package path and the anchor of the link to the
+// declaration or package named by a doc link in the documentation of pkg.
+// The name has the form Name, Name.Method, pkg, pkg.Name or pkg.Name.Method,
+// where pkg is the name or the path of a package imported by pkg.
+func resolveDocLink(pkg *types.Package, name string) (string, string, bool) {
+	if anchor, ok := resolveInScope(pkg, name); ok {
+		return pkg.Path(), anchor, true
+	}
+	if imp := importedPackage(pkg, name); imp != nil {
+		return imp.Path(), "", true
+	}
+	// The package path may contain dots, so try each prefix.
+	for i := strings.Index(name, "."); i >= 0; {
+		if imp := importedPackage(pkg, name[:i]); imp != nil {
+			if anchor, ok := resolveInScope(imp, name[i+1:]); ok {
+				return imp.Path(), anchor, true
+			}
+		}
+		j := strings.Index(name[i+1:], ".")
+		if j < 0 {
+			break
+		}
+		i += j + 1
+	}
+	return "", "", false
+}
+
+// resolveInScope returns the anchor of the exported declaration of pkg named
+// Name or Name.Method, where Method is a method or a field.
+func resolveInScope(pkg *types.Package, name string) (string, bool) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", false
+	}
+	obj := pkg.Scope().Lookup(parts[0])
+	if obj == nil || !obj.Exported() {
+		return "", false
+	}
+	if len(parts) == 1 {
+		return name, true
+	}
+	if _, ok := obj.(*types.TypeName); !ok || !token.IsExported(parts[1]) {
+		return "", false
+	}
+	if sel, _, _ := types.LookupFieldOrMethod(obj.Type(), true, pkg, parts[1]); sel == nil {
+		return "", false
+	}
+	return name, true
+}
+
+// importedPackage returns the package imported by pkg with the given path or
+// name, or nil.
+func importedPackage(pkg *types.Package, name string) *types.Package {
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == name || imp.Name() == name {
+			return imp
+		}
+	}
+	return nil
+}
+
+// A hoverExample is an example function of a hovered symbol.
+type hoverExample struct {
+	*Example
+	suffix string
+}
+
+// findExamples returns the example functions of obj, an exported function,
+// method or type, declared in the _test.go files of its package.
+func findExamples(ctx context.Context, i *IdentifierInfo, obj types.Object) ([]*hoverExample, error) {
+	if !obj.Exported() || obj.Pkg() == nil {
+		return nil, nil
+	}
+	// An example of a function F is named ExampleF, of a type T ExampleT and
+	// of a method T.M ExampleT_M, optionally followed by a lowercase suffix.
+	name := obj.Name()
+	switch obj := obj.(type) {
+	case *types.TypeName:
+	case *types.Func:
+		if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+			named, ok := Deref(recv.Type()).(*types.Named)
+			if !ok || !named.Obj().Exported() {
+				return nil, nil
+			}
+			name = named.Obj().Name() + "_" + name
+		}
+	default:
+		return nil, nil
+	}
+
+	all, err := i.Snapshot.PackageExamples(ctx, obj.Pkg().Path())
+	if err != nil {
+		return nil, err
+	}
+	var examples []*hoverExample
+	for _, ex := range all {
+		var suffix string
+		if ex.Name != name {
+			if !strings.HasPrefix(ex.Name, name+"_") {
+				continue
+			}
+			suffix = ex.Name[len(name)+1:]
+			if r, _ := utf8.DecodeRuneInString(suffix); !unicode.IsLower(r) {
+				continue
+			}
+		}
+		examples = append(examples, &hoverExample{Example: ex, suffix: suffix})
+	}
+	return examples, nil
+}
+
 func moduleAtVersion(path string, i *IdentifierInfo) (string, string, bool) {
 	if strings.ToLower(i.Snapshot.View().Options().LinkTarget) != "pkg.go.dev" {
 		return "", "", false
@@ -513,11 +682,17 @@ func FormatHover(h *HoverInformation, options *Options) (string, error) {
 	link := formatLink(h, options)
 	switch options.HoverKind {
 	case SynopsisDocumentation:
-		doc := formatDoc(h.Synopsis, options)
+		doc := formatDoc(h, h.Synopsis, options)
 		return formatHover(options, signature, link, doc), nil
 	case FullDocumentation:
-		doc := formatDoc(h.FullDocumentation, options)
-		return formatHover(options, signature, link, doc), nil
+		doc := formatDoc(h, h.FullDocumentation, options)
+		examples := formatExamples(h, options)
+		if examples != "" {
+			// Separate the examples from the documentation by a single
+			// blank line.
+			doc = strings.TrimRight(doc, "\n")
+		}
+		return formatHover(options, signature, link, doc, examples), nil
 	}
 	return "", errors.Errorf("no hover for %v", h.source)
 }
@@ -549,11 +724,48 @@ func BuildLink(target, path, anchor string) string {
 	return link + "#" + anchor
 }
 
-func formatDoc(doc string, options *Options) string {
-	if options.PreferredContentFormat == protocol.Markdown {
+func formatDoc(h *HoverInformation, doc string, options *Options) string {
+	if options.PreferredContentFormat != protocol.Markdown {
+		return doc
+	}
+	if h.docLink == nil || !options.LinksInHover || options.LinkTarget == "" {
 		return CommentToMarkdown(doc)
 	}
-	return doc
+	return commentToMarkdownWithLinks(doc, func(name string, bare bool) string {
+		path, anchor, ok := h.docLink(name, bare)
+		if !ok {
+			return ""
+		}
+		return BuildLink(options.LinkTarget, path, anchor)
+	})
+}
+
+// formatExamples formats the examples of the hovered symbol. In markdown,
+// each example is collapsed.
+func formatExamples(h *HoverInformation, options *Options) string {
+	var b strings.Builder
+	for _, ex := range h.examples {
+		title := "Example"
+		if ex.suffix != "" {
+			title += " (" + ex.suffix + ")"
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		if options.PreferredContentFormat != protocol.Markdown {
+			fmt.Fprintf(&b, "%s:\n\t%s", title, strings.Replace(ex.Code, "\n", "\n\t", -1))
+			if ex.Output != "" {
+				fmt.Fprintf(&b, "\nOutput:\n\t%s", strings.Replace(ex.Output, "\n", "\n\t", -1))
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "<details><summary>%s</summary>\n\n```go\n%s\n```\n", title, ex.Code)
+		if ex.Output != "" {
+			fmt.Fprintf(&b, "\nOutput:\n\n```\n%s\n```\n", ex.Output)
+		}
+		b.WriteString("</details>")
+	}
+	return b.String()
 }
 
 func formatHover(options *Options, x ...string) string {
@@ -0,0 +1,343 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+// The names of the inlay hints, as used in the "hints" setting.
+const (
+	AssignVariableTypes    = "assignVariableTypes"
+	CompositeLiteralFields = "compositeLiteralFields"
+	ConstantValues         = "constantValues"
+	ParameterNames         = "parameterNames"
+)
+
+// Hint describes a kind of inlay hint that can be enabled in the "hints"
+// setting.
+type Hint struct {
+	Name string
+	Doc  string
+	run  inlayHintFunc
+}
+
+// inlayHint is an inlay hint that has not yet been mapped to a protocol
+// position.
+type inlayHint struct {
+	pos   token.Pos
+	label string
+	kind  protocol.InlayHintKind
+}
+
+type inlayHintFunc func(n ast.Node, info *types.Info, q types.Qualifier) []inlayHint
+
+// AllInlayHints returns the supported inlay hints, keyed by name.
+func AllInlayHints() map[string]*Hint {
+	return map[string]*Hint{
+		AssignVariableTypes: {
+			Name: AssignVariableTypes,
+			Doc:  "Enable/disable inlay hints for variable types in assign statements:\n\n\ti/* int*/, j/* int*/ := 0, len(r)-1",
+			run:  assignVariableTypes,
+		},
+		CompositeLiteralFields: {
+			Name: CompositeLiteralFields,
+			Doc:  "Enable/disable inlay hints for composite literal field names:\n\n\t{in: \"Hello, world\", want: \"dlrow ,olleH\"}",
+			run:  compositeLiteralFields,
+		},
+		ConstantValues: {
+			Name: ConstantValues,
+			Doc:  "Enable/disable inlay hints for constant values:\n\n\tconst (\n\t\tKindNone   Kind = iota/* = 0*/\n\t\tKindPrint/*  = 1*/\n\t\tKindPrintf/* = 2*/\n\t)",
+			run:  constantValues,
+		},
+		ParameterNames: {
+			Name: ParameterNames,
+			Doc:  "Enable/disable inlay hints for parameter names:\n\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)",
+			run:  parameterNames,
+		},
+	}
+}
+
+// InlayHint returns the enabled inlay hints for the given range of the file.
+// An empty range requests the hints of the whole file.
+func InlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range) ([]protocol.InlayHint, error) {
+	ctx, done := event.Start(ctx, "source.InlayHint")
+	defer done()
+
+	var names []string
+	for name, on := range snapshot.View().Options().Hints {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var enabled []inlayHintFunc
+	hints := AllInlayHints()
+	for _, name := range names {
+		if hint, ok := hints[name]; ok {
+			enabled = append(enabled, hint.run)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	pkg, pgf, err := GetParsedFile(ctx, snapshot, fh, NarrowestPackage)
+	if err != nil {
+		return nil, errors.Errorf("getting file for InlayHint: %w", err)
+	}
+	start, end := pgf.File.Pos(), pgf.File.End()
+	if rng.Start != rng.End {
+		spn, err := pgf.Mapper.RangeSpan(rng)
+		if err != nil {
+			return nil, err
+		}
+		r, err := spn.Range(pgf.Mapper.Converter)
+		if err != nil {
+			return nil, err
+		}
+		start, end = r.Start, r.End
+	}
+
+	info := pkg.GetTypesInfo()
+	q := Qualifier(pgf.File, pkg.GetTypes(), info)
+	var found []inlayHint
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		if n == nil || n.End() < start || n.Pos() > end {
+			return false
+		}
+		for _, f := range enabled {
+			found = append(found, f(n, info, q)...)
+		}
+		return true
+	})
+
+	var result []protocol.InlayHint
+	for _, h := range found {
+		if h.pos < start || h.pos > end {
+			continue
+		}
+		rng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, h.pos, h.pos).Range()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, protocol.InlayHint{
+			Position:     rng.Start,
+			Label:        []protocol.InlayHintLabelPart{{Value: h.label}},
+			Kind:         h.kind,
+			PaddingLeft:  h.kind != protocol.Parameter,
+			PaddingRight: h.kind == protocol.Parameter,
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return protocol.ComparePosition(result[i].Position, result[j].Position) < 0
+	})
+	return result, nil
+}
+
+// InlayHintEdits returns the edits that insert each of the hints as a
+// comment, padded as requested by the hint. They show the hints to the users
+// of the command line and to the tests.
+func InlayHintEdits(hints []protocol.InlayHint) []protocol.TextEdit {
+	var edits []protocol.TextEdit
+	for _, h := range hints {
+		var label strings.Builder
+		for _, part := range h.Label {
+			label.WriteString(part.Value)
+		}
+		text := label.String()
+		if h.PaddingLeft {
+			text = " " + text
+		}
+		if h.PaddingRight {
+			text += " "
+		}
+		edits = append(edits, protocol.TextEdit{
+			Range:   protocol.Range{Start: h.Position, End: h.Position},
+			NewText: "/*" + text + "*/",
+		})
+	}
+	return edits
+}
+
+// parameterNames labels the arguments of a call with the names of the
+// parameters they are passed to.
+func parameterNames(n ast.Node, info *types.Info, _ types.Qualifier) []inlayHint {
+	call, ok := n.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil
+	}
+	if tv, ok := info.Types[call.Fun]; !ok || tv.IsType() {
+		// Conversions have no parameters.
+		return nil
+	}
+	sig, ok := info.TypeOf(call.Fun).Underlying().(*types.Signature)
+	if !ok {
+		return nil
+	}
+	params := sig.Params()
+	if params.Len() == 0 {
+		return nil
+	}
+	if _, ok := info.TypeOf(call.Args[0]).(*types.Tuple); ok {
+		// f(g()) passes all results of g at once.
+		return nil
+	}
+	var hints []inlayHint
+	for i, arg := range call.Args {
+		if i >= params.Len() {
+			// Only the first of the variadic arguments is labelled.
+			break
+		}
+		param := params.At(i)
+		name := param.Name()
+		if name == "" || name == "_" {
+			continue
+		}
+		if id, ok := arg.(*ast.Ident); ok && id.Name == name {
+			// The argument already says it all.
+			continue
+		}
+		if sig.Variadic() && i == params.Len()-1 && !call.Ellipsis.IsValid() {
+			name += "..."
+		}
+		hints = append(hints, inlayHint{
+			pos:   arg.Pos(),
+			label: name + ":",
+			kind:  protocol.Parameter,
+		})
+	}
+	return hints
+}
+
+// assignVariableTypes labels the variables declared by short variable
+// declarations with their types.
+func assignVariableTypes(n ast.Node, info *types.Info, q types.Qualifier) []inlayHint {
+	var lhs []ast.Expr
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		if n.Tok != token.DEFINE {
+			return nil
+		}
+		lhs = n.Lhs
+	case *ast.RangeStmt:
+		if n.Tok != token.DEFINE {
+			return nil
+		}
+		lhs = []ast.Expr{n.Key, n.Value}
+	default:
+		return nil
+	}
+	var hints []inlayHint
+	for _, expr := range lhs {
+		id, ok := expr.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		// Redeclared variables are not in Defs.
+		obj := info.Defs[id]
+		if obj == nil || !isValid(obj.Type()) {
+			continue
+		}
+		hints = append(hints, inlayHint{
+			pos:   id.End(),
+			label: types.TypeString(obj.Type(), q),
+			kind:  protocol.Type,
+		})
+	}
+	return hints
+}
+
+// compositeLiteralFields labels the elements of unkeyed struct literals with
+// the names of their fields.
+func compositeLiteralFields(n ast.Node, info *types.Info, _ types.Qualifier) []inlayHint {
+	lit, ok := n.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) == 0 {
+		return nil
+	}
+	if _, ok := lit.Elts[0].(*ast.KeyValueExpr); ok {
+		return nil
+	}
+	typ := info.TypeOf(lit)
+	if typ == nil {
+		return nil
+	}
+	strct, ok := Deref(typ).Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var hints []inlayHint
+	for i, elt := range lit.Elts {
+		if i >= strct.NumFields() {
+			break
+		}
+		hints = append(hints, inlayHint{
+			pos:   elt.Pos(),
+			label: strct.Field(i).Name() + ":",
+			kind:  protocol.Parameter,
+		})
+	}
+	return hints
+}
+
+// constantValues labels the constants of a declaration whose values are
+// implicit, or computed from iota, with their values.
+func constantValues(n ast.Node, info *types.Info, _ types.Qualifier) []inlayHint {
+	decl, ok := n.(*ast.GenDecl)
+	if !ok || decl.Tok != token.CONST {
+		return nil
+	}
+	var hints []inlayHint
+	for _, spec := range decl.Specs {
+		spec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if len(spec.Values) > 0 && !usesIota(info, spec.Values) {
+			continue
+		}
+		var values []string
+		for _, name := range spec.Names {
+			obj, ok := info.Defs[name].(*types.Const)
+			if !ok {
+				values = nil
+				break
+			}
+			values = append(values, obj.Val().String())
+		}
+		if len(values) == 0 {
+			continue
+		}
+		hints = append(hints, inlayHint{
+			pos:   spec.End(),
+			label: "= " + strings.Join(values, ", "),
+		})
+	}
+	return hints
+}
+
+// usesIota reports whether any of exprs refers to the predeclared iota.
+func usesIota(info *types.Info, exprs []ast.Expr) bool {
+	iota := types.Universe.Lookup("iota")
+	found := false
+	for _, expr := range exprs {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && info.Uses[id] == iota {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
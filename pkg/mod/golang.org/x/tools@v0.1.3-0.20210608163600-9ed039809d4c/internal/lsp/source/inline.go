@@ -0,0 +1,971 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+	"golang.org/x/tools/internal/span"
+)
+
+// CanInlineCall reports whether rng selects a call to a function or method
+// declared, with a body, in pkg. Whether the call can actually be inlined is
+// only determined by inlineCall, which explains why it can't.
+func CanInlineCall(fset *token.FileSet, rng span.Range, file *ast.File, pkg Package) bool {
+	call, callee := selectedCall(rng, file, pkg.GetTypesInfo())
+	if call == nil || callee.Pkg() != pkg.GetTypes() {
+		return false
+	}
+	decl, _ := findFuncDecl(pkg, callee)
+	return decl != nil && decl.Body != nil
+}
+
+// inlineCall replaces the selected call by the body of the callee.
+func inlineCall(fset *token.FileSet, rng span.Range, pgf *ParsedGoFile, pkg Package) (*analysis.SuggestedFix, error) {
+	info := pkg.GetTypesInfo()
+	call, callee := selectedCall(rng, pgf.File, info)
+	if call == nil {
+		return nil, fmt.Errorf("no call selected")
+	}
+	if callee.Pkg() != pkg.GetTypes() {
+		return nil, fmt.Errorf("cannot inline call to %s: it is declared in another package", callee.Name())
+	}
+	decl, declFile := findFuncDecl(pkg, callee)
+	if decl == nil || decl.Body == nil {
+		return nil, fmt.Errorf("cannot inline call to %s: its body is not available", callee.Name())
+	}
+	in := &inliner{
+		fset:     fset,
+		file:     pgf.File,
+		src:      pgf.Src,
+		pkg:      pkg.GetTypes(),
+		info:     info,
+		call:     call,
+		callee:   callee,
+		decl:     decl,
+		declFile: declFile.File,
+		declSrc:  declFile.Src,
+	}
+	return in.inline()
+}
+
+// selectedCall returns the innermost call whose function, or whole
+// expression, contains rng, along with the function or method it statically
+// calls.
+func selectedCall(rng span.Range, file *ast.File, info *types.Info) (*ast.CallExpr, *types.Func) {
+	path, _ := astutil.PathEnclosingInterval(file, rng.Start, rng.End)
+	for _, n := range path {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		inFun := rng.Start >= call.Fun.Pos() && rng.End <= call.Fun.End()
+		whole := rng.Start <= call.Pos() && rng.End >= call.End()
+		if !inFun && !whole {
+			return nil, nil
+		}
+		callee := typeutil.StaticCallee(info, call)
+		if callee == nil {
+			return nil, nil
+		}
+		return call, callee
+	}
+	return nil, nil
+}
+
+// findFuncDecl returns the declaration of fn among the files of pkg, along
+// with the file that contains it.
+func findFuncDecl(pkg Package, fn *types.Func) (*ast.FuncDecl, *ParsedGoFile) {
+	for _, pgf := range pkg.CompiledGoFiles() {
+		if pgf.Tok == nil || !(pgf.File.Pos() <= fn.Pos() && fn.Pos() <= pgf.File.End()) {
+			continue
+		}
+		for _, decl := range pgf.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Name.Pos() == fn.Pos() {
+				return decl, pgf
+			}
+		}
+	}
+	return nil, nil
+}
+
+// An inliner holds the state of the inlining of a call.
+type inliner struct {
+	fset     *token.FileSet
+	file     *ast.File // file of the call
+	src      []byte
+	pkg      *types.Package
+	info     *types.Info
+	call     *ast.CallExpr
+	callee   *types.Func
+	decl     *ast.FuncDecl
+	declFile *ast.File // file of the callee
+	declSrc  []byte
+
+	// avoid holds the names that the locals of the callee and the
+	// temporaries must not use.
+	avoid map[string]bool
+}
+
+// binding describes how a parameter of the callee is replaced at the call
+// site: either by the expression of its argument, or by a temporary.
+type binding struct {
+	param *types.Var
+	arg   ast.Expr
+	// recv is set for the receiver of a method.
+	recv bool
+	// temp is the name of the temporary bound to arg, if any.
+	temp string
+}
+
+func (in *inliner) inline() (*analysis.SuggestedFix, error) {
+	name := in.callee.Name()
+	fail := func(format string, args ...interface{}) error {
+		return fmt.Errorf("cannot inline call to %s: %s", name, fmt.Sprintf(format, args...))
+	}
+	sig := in.callee.Type().(*types.Signature)
+	if sig.Variadic() {
+		return nil, fail("it is variadic")
+	}
+	if sig.Results().Len() > 0 && sig.Results().At(0).Name() != "" {
+		return nil, fail("it has named results")
+	}
+	tok := in.fset.File(in.call.Pos())
+	if tok == nil {
+		return nil, fmt.Errorf("no file for call to %s", name)
+	}
+	declTok := in.fset.File(in.decl.Pos())
+	if declTok == nil {
+		return nil, fmt.Errorf("no file for %s", name)
+	}
+
+	// Check that the body can be moved to the call site.
+	body := in.decl.Body
+	var returns []*ast.ReturnStmt
+	var bodyErr error
+	ast.Inspect(body, func(n ast.Node) bool {
+		if bodyErr != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			returns = append(returns, n)
+		case *ast.DeferStmt:
+			bodyErr = fail("it defers calls")
+		case *ast.LabeledStmt:
+			bodyErr = fail("it uses labels")
+		case *ast.Ident:
+			if in.info.Uses[n] == in.callee {
+				bodyErr = fail("it is recursive")
+			}
+		}
+		return true
+	})
+	if bodyErr != nil {
+		return nil, bodyErr
+	}
+	var ret *ast.ReturnStmt
+	stmts := body.List
+	if len(stmts) > 0 {
+		ret, _ = stmts[len(stmts)-1].(*ast.ReturnStmt)
+	}
+	switch {
+	case len(returns) > 1:
+		return nil, fail("it has multiple return statements")
+	case len(returns) == 1 && returns[0] != ret:
+		return nil, fail("it returns before the end of its body")
+	case sig.Results().Len() > 0 && ret == nil:
+		return nil, fail("it does not end with a return statement")
+	}
+	if ret != nil {
+		stmts = stmts[:len(stmts)-1]
+	}
+
+	// Find the context of the call.
+	path, _ := astutil.PathEnclosingInterval(in.file, in.call.Pos(), in.call.End())
+	var parent ast.Node
+	for _, n := range path[1:] {
+		if _, ok := n.(*ast.ParenExpr); !ok {
+			parent = n
+			break
+		}
+	}
+	var stmt ast.Stmt
+	var stmtParent ast.Node
+	var caller ast.Node = in.file
+	for i, n := range path {
+		if s, ok := n.(ast.Stmt); ok && stmt == nil {
+			stmt = s
+			if i+1 < len(path) {
+				stmtParent = path[i+1]
+			}
+		}
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			if stmt != nil && caller == in.file {
+				caller = n
+			}
+		}
+	}
+	isSole := func(exprs []ast.Expr) bool {
+		return len(exprs) == 1 && astutil.Unparen(exprs[0]) == in.call
+	}
+	var exprStmt, assign, retStmt bool
+	switch parent := parent.(type) {
+	case *ast.DeferStmt, *ast.GoStmt:
+		return nil, fail("the call is in a %s statement", strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(fmt.Sprintf("%T", parent), "*ast."), "Stmt")))
+	case *ast.ExprStmt:
+		exprStmt = true
+	case *ast.AssignStmt:
+		assign = isSole(parent.Rhs) && (parent.Tok == token.DEFINE || parent.Tok == token.ASSIGN)
+	case *ast.ReturnStmt:
+		retStmt = isSole(parent.Results)
+	}
+	if sig.Results().Len() > 1 && !exprStmt && !assign && !retStmt {
+		return nil, fail("its results can only be assigned or returned")
+	}
+
+	// Find the names the callee refers to, and check that they refer to the
+	// same objects at the call site.
+	in.avoid = make(map[string]bool)
+	scope := in.pkg.Scope().Innermost(in.call.Pos())
+	ast.Inspect(caller, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			in.avoid[id.Name] = true
+		}
+		return true
+	})
+	locals := make(map[types.Object]bool)
+	qualified := make(map[*ast.Ident]bool)
+	var refErr error
+	ast.Inspect(body, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			qualified[sel.Sel] = true
+		}
+		id, ok := n.(*ast.Ident)
+		if !ok || refErr != nil {
+			return refErr == nil
+		}
+		in.avoid[id.Name] = true
+		if obj := in.info.Defs[id]; obj != nil {
+			if v, ok := obj.(*types.Var); ok && !v.IsField() && id.Name != "_" {
+				locals[obj] = true
+			}
+			return true
+		}
+		if qualified[id] {
+			return true
+		}
+		obj := in.info.Uses[id]
+		if obj == nil || obj.Parent() == nil || obj.Pos() >= in.decl.Pos() && obj.Pos() < in.decl.End() {
+			return true
+		}
+		// obj is declared outside of the callee.
+		if _, found := scope.LookupParent(id.Name, in.call.Pos()); !sameObj(found, obj) {
+			if pkgName, ok := obj.(*types.PkgName); ok {
+				refErr = fail("package %s is not imported as %s", pkgName.Imported().Path(), id.Name)
+			} else {
+				refErr = fail("%s is shadowed at the call site", id.Name)
+			}
+		}
+		return true
+	})
+	if refErr != nil {
+		return nil, refErr
+	}
+	for s := scope; s != nil && s != types.Universe; s = s.Parent() {
+		for _, name := range s.Names() {
+			if _, obj := s.LookupParent(name, in.call.Pos()); obj != nil {
+				in.avoid[name] = true
+			}
+		}
+	}
+
+	// Bind the parameters to their arguments.
+	var bindings []*binding
+	if recv := sig.Recv(); recv != nil {
+		sel, ok := in.call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil, fail("it is called through a method expression")
+		}
+		if s := in.info.Selections[sel]; s == nil || s.Kind() != types.MethodVal {
+			return nil, fail("it is called through a method expression")
+		} else if len(s.Index()) > 1 {
+			return nil, fail("it is promoted from an embedded field")
+		}
+		bindings = append(bindings, &binding{param: recv, arg: sel.X, recv: true})
+	}
+	if len(in.call.Args) != sig.Params().Len() {
+		return nil, fail("its arguments are the results of a call")
+	}
+	for i, arg := range in.call.Args {
+		bindings = append(bindings, &binding{param: sig.Params().At(i), arg: arg})
+	}
+	// Arguments are bound to temporaries when they have side effects, when
+	// the callee modifies the parameter, or when they are used more than once
+	// and are not trivial. All the arguments that precede an argument with
+	// side effects are bound too, so that they are evaluated in order. When
+	// the body has statements before the return, they may change what an
+	// argument reads, even if it is pure, so all the used arguments are
+	// bound. Constants, and the locals of the caller that the callee can't
+	// reach, have the same value anyway.
+	uses := make(map[types.Object]int)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			uses[in.info.Uses[id]]++
+		}
+		return true
+	})
+	lastImpure := -1
+	for i, b := range bindings {
+		if !in.pure(b.arg) {
+			lastImpure = i
+		}
+	}
+	var temps []string
+	for i, b := range bindings {
+		n := uses[b.param]
+		bind := (i <= lastImpure || n > 0 && len(stmts) > 0) && !in.isConst(b.arg) && !in.unreachable(b.arg)
+		bind = bind || in.modifies(b)
+		bind = bind || n > 1 && !in.trivial(b.arg)
+		if !bind {
+			continue
+		}
+		argText := in.text(tok, in.src, b.arg)
+		if n == 0 || b.param.Name() == "_" || b.param.Name() == "" {
+			temps = append(temps, "_ = "+argText)
+			continue
+		}
+		b.temp = in.freshName(b.param.Name())
+		if b.recv {
+			temps = append(temps, b.temp+" := "+in.recvText(b, argText, false))
+			continue
+		}
+		if !in.needsConversion(b.arg, b.param.Type()) {
+			temps = append(temps, b.temp+" := "+argText)
+			continue
+		}
+		typ, err := in.typeString(b.param.Type())
+		if err != nil {
+			return nil, fail("%v", err)
+		}
+		temps = append(temps, fmt.Sprintf("var %s %s = %s", b.temp, typ, argText))
+	}
+
+	// Rename the locals of the callee that are in use at the call site.
+	var localObjs []types.Object
+	for obj := range locals {
+		localObjs = append(localObjs, obj)
+	}
+	sort.Slice(localObjs, func(i, j int) bool { return localObjs[i].Pos() < localObjs[j].Pos() })
+	renames := make(map[types.Object]string)
+	for _, obj := range localObjs {
+		if _, found := scope.LookupParent(obj.Name(), in.call.Pos()); found != nil || in.callerDeclares(caller, obj.Name()) {
+			renames[obj] = in.freshName(obj.Name())
+		}
+	}
+
+	// Substitute the parameters and renamed locals of the body.
+	params := make(map[types.Object]*binding)
+	for _, b := range bindings {
+		params[b.param] = b
+	}
+	var substErr error
+	replace := func(id *ast.Ident, parent ast.Node) (string, bool) {
+		obj := in.info.ObjectOf(id)
+		if name, ok := renames[obj]; ok {
+			return name, true
+		}
+		b, ok := params[obj]
+		if !ok {
+			return "", false
+		}
+		if b.temp != "" {
+			return b.temp, true
+		}
+		text, err := in.argText(tok, b, id, parent)
+		if err != nil {
+			substErr = fail("%v", err)
+		}
+		return text, true
+	}
+	rewrite := func(n ast.Node) string {
+		return in.rewrite(declTok, n, replace)
+	}
+
+	// Assemble the replacement.
+	indent := lineIndent(in.src, tok, in.call.Pos())
+	if stmt != nil {
+		indent = lineIndent(in.src, tok, stmt.Pos())
+	}
+	var results []string
+	resultPrimary := true
+	if ret != nil {
+		for i, r := range ret.Results {
+			text := rewrite(r)
+			if len(ret.Results) == sig.Results().Len() && in.needsConversion(r, sig.Results().At(i).Type()) {
+				typ, err := in.typeString(sig.Results().At(i).Type())
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				text = convert(typ, text)
+			} else if !isPrimary(r) {
+				resultPrimary = false
+			}
+			results = append(results, text)
+		}
+	}
+	var lines []string
+	lines = append(lines, temps...)
+	for _, s := range stmts {
+		lines = append(lines, reindent(rewrite(s), lineIndent(in.declSrc, declTok, s.Pos()), indent))
+	}
+	if substErr != nil {
+		return nil, substErr
+	}
+	resultText := strings.Join(results, ", ")
+
+	var start, end token.Pos
+	inExpr := false // whether only the call is replaced
+	switch {
+	case exprStmt:
+		start, end = stmt.Pos(), stmt.End()
+		if ret != nil && len(ret.Results) > 0 && !in.allPure(ret.Results) {
+			lines = append(lines, strings.Repeat("_, ", sig.Results().Len()-1)+"_ = "+resultText)
+		}
+	case assign && len(stmts) > 0, retStmt && len(stmts) > 0:
+		start, end = stmt.Pos(), stmt.End()
+		if assign {
+			a := stmt.(*ast.AssignStmt)
+			lhs := in.src[tok.Offset(a.Lhs[0].Pos()):tok.Offset(a.Lhs[len(a.Lhs)-1].End())]
+			lines = append(lines, fmt.Sprintf("%s %s %s", lhs, a.Tok, resultText))
+		} else {
+			lines = append(lines, "return "+resultText)
+		}
+	default:
+		if len(stmts) > 0 {
+			return nil, fail("its body has statements other than a return, which can't be inlined in an expression")
+		}
+		inExpr = true
+		start, end = in.call.Pos(), in.call.End()
+		if len(results) == 1 && !resultPrimary && (needsParens(parent) || isCallFun(parent, in.call)) {
+			resultText = "(" + resultText + ")"
+		}
+	}
+	if len(lines) > 0 && inExpr {
+		// The temporaries precede the statement containing the call.
+		if err := in.checkTemporaries(path, stmt, stmtParent); err != nil {
+			return nil, fail("%v", err)
+		}
+	} else if len(lines) > 0 {
+		if !isBlockList(stmtParent) {
+			return nil, fail("the call is not in a statement list")
+		}
+	}
+
+	// The fix is a single edit, as the edits of a fix are applied in turn.
+	var newText string
+	if inExpr {
+		if len(lines) > 0 {
+			lines = append(lines, in.text(tok, in.src, &ast.BadExpr{From: stmt.Pos(), To: start})+resultText)
+			start, newText = stmt.Pos(), strings.Join(lines, "\n"+indent)
+		} else {
+			newText = resultText
+		}
+	} else {
+		newText = strings.Join(lines, "\n"+indent)
+	}
+	return &analysis.SuggestedFix{
+		TextEdits: []analysis.TextEdit{{Pos: start, End: end, NewText: []byte(newText)}},
+	}, nil
+}
+
+// checkTemporaries returns an error if the temporaries of a call in an
+// expression can't be declared before stmt, its enclosing statement.
+func (in *inliner) checkTemporaries(path []ast.Node, stmt ast.Stmt, stmtParent ast.Node) error {
+	if stmt == nil || !isBlockList(stmtParent) {
+		return fmt.Errorf("the call is not in a statement list")
+	}
+	switch stmt.(type) {
+	case *ast.ExprStmt, *ast.AssignStmt, *ast.ReturnStmt, *ast.DeclStmt, *ast.SendStmt, *ast.IncDecStmt, *ast.GoStmt:
+	default:
+		return fmt.Errorf("its arguments would be evaluated before the %s", strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast.")))
+	}
+	for i, n := range path {
+		if n == stmt {
+			break
+		}
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if (n.Op == token.LAND || n.Op == token.LOR) && i > 0 && path[i-1] == n.Y {
+				return fmt.Errorf("its arguments would be evaluated unconditionally")
+			}
+		case *ast.FuncLit:
+			return fmt.Errorf("the call is in a function literal")
+		}
+	}
+	return nil
+}
+
+// isBlockList reports whether n holds a list of statements.
+func isBlockList(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
+
+// callerDeclares reports whether a variable named name is declared in the
+// caller, possibly after the call.
+func (in *inliner) callerDeclares(caller ast.Node, name string) bool {
+	found := false
+	ast.Inspect(caller, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && in.info.Defs[id] != nil {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// freshName returns a name based on name that is not used by the caller, by
+// the callee, or by an earlier temporary or renamed local.
+func (in *inliner) freshName(name string) string {
+	fresh := name
+	for i := 1; in.avoid[fresh]; i++ {
+		fresh = fmt.Sprintf("%s%d", name, i)
+	}
+	in.avoid[fresh] = true
+	return fresh
+}
+
+// argText returns the text that replaces id, a use of a parameter whose
+// parent node is parent, with its argument.
+func (in *inliner) argText(tok *token.File, b *binding, id *ast.Ident, parent ast.Node) (string, error) {
+	text := in.text(tok, in.src, b.arg)
+	if b.recv {
+		sel, ok := parent.(*ast.SelectorExpr)
+		return in.recvText(b, text, ok && sel.X == id), nil
+	}
+	if in.needsConversion(b.arg, b.param.Type()) {
+		typ, err := in.typeString(b.param.Type())
+		if err != nil {
+			return "", err
+		}
+		return convert(typ, text), nil
+	}
+	if needsParens(parent) || isCallFun(parent, id) {
+		text = parenthesize(b.arg, text)
+	}
+	return text, nil
+}
+
+// recvText returns the text that replaces a use of the receiver with text,
+// the text of its argument. Selectors automatically take the address of, or
+// dereference, the receiver; other uses need it explicitly.
+func (in *inliner) recvText(b *binding, text string, isSelector bool) string {
+	_, paramPtr := b.param.Type().Underlying().(*types.Pointer)
+	_, argPtr := in.info.TypeOf(b.arg).Underlying().(*types.Pointer)
+	switch {
+	case isSelector || paramPtr == argPtr:
+	case paramPtr:
+		text = "&" + parenthesize(b.arg, text)
+	default:
+		text = "*" + parenthesize(b.arg, text)
+	}
+	if isSelector && !isPrimary(b.arg) {
+		text = "(" + text + ")"
+	}
+	return text
+}
+
+// modifies reports whether the callee modifies the parameter of b, so that
+// it can't be replaced by its argument. Modifications through a pointer
+// parameter apply to its argument too.
+func (in *inliner) modifies(b *binding) bool {
+	body := in.decl.Body
+	if _, ok := b.param.Type().Underlying().(*types.Pointer); !ok {
+		return modifiesObj(in.info, span.Range{Start: body.Pos(), End: body.End()}, body, b.param)
+	}
+	modifies := false
+	isParam := func(expr ast.Expr) bool {
+		id, ok := astutil.Unparen(expr).(*ast.Ident)
+		return ok && in.info.Uses[id] == b.param
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				modifies = modifies || isParam(lhs)
+			}
+		case *ast.IncDecStmt:
+			modifies = modifies || isParam(n.X)
+		case *ast.UnaryExpr:
+			modifies = modifies || n.Op == token.AND && isParam(n.X)
+		}
+		return !modifies
+	})
+	return modifies
+}
+
+// isCallFun reports whether n is the function called by parent.
+func isCallFun(parent ast.Node, n ast.Node) bool {
+	call, ok := parent.(*ast.CallExpr)
+	return ok && astutil.Unparen(call.Fun) == n
+}
+
+// rewrite returns the text of n, a node of the callee, with the identifiers
+// for which replace returns true replaced.
+func (in *inliner) rewrite(tok *token.File, n ast.Node, replace func(*ast.Ident, ast.Node) (string, bool)) string {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	var stack []ast.Node
+	ast.Inspect(n, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		if id, ok := n.(*ast.Ident); ok {
+			var parent ast.Node
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if sel, ok := parent.(*ast.SelectorExpr); ok && sel.Sel == id {
+				// A field or method name.
+			} else if text, ok := replace(id, parent); ok {
+				edits = append(edits, edit{tok.Offset(id.Pos()), tok.Offset(id.End()), text})
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+	start, end := tok.Offset(n.Pos()), tok.Offset(n.End())
+	var buf bytes.Buffer
+	last := start
+	for _, e := range edits {
+		buf.Write(in.declSrc[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(in.declSrc[last:end])
+	return buf.String()
+}
+
+// lineIndent returns the white space at the start of the line of pos.
+func lineIndent(src []byte, tok *token.File, pos token.Pos) string {
+	start := tok.Offset(tok.LineStart(tok.Line(pos)))
+	line := src[start:tok.Offset(pos)]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// reindent replaces the indentation from of the lines of text after the first
+// with the indentation to.
+func reindent(text, from, to string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		lines[i] = to + strings.TrimPrefix(lines[i], from)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// text returns the source text of n.
+func (in *inliner) text(tok *token.File, src []byte, n ast.Node) string {
+	return string(src[tok.Offset(n.Pos()):tok.Offset(n.End())])
+}
+
+// typeString returns the representation of typ at the call site, or an error
+// if a package it refers to is not imported there.
+func (in *inliner) typeString(typ types.Type) (string, error) {
+	qf := Qualifier(in.file, in.pkg, in.info)
+	var missing *types.Package
+	s := types.TypeString(typ, func(p *types.Package) string {
+		if p == in.pkg {
+			return ""
+		}
+		for _, imp := range in.file.Imports {
+			if strings.Trim(imp.Path.Value, `"`) == p.Path() {
+				return qf(p)
+			}
+		}
+		missing = p
+		return p.Name()
+	})
+	if missing != nil {
+		return "", fmt.Errorf("package %s is not imported", missing.Path())
+	}
+	return s, nil
+}
+
+// needsConversion reports whether expr must be converted to typ to keep its
+// type when it is moved: either it is untyped, or its type differs from typ.
+func (in *inliner) needsConversion(expr ast.Expr, typ types.Type) bool {
+	if isUntyped(in.info, expr) {
+		return !types.Identical(defaultType(in.info, expr), typ)
+	}
+	t := in.info.TypeOf(expr)
+	return t != nil && !types.Identical(t, typ)
+}
+
+// isUntyped reports whether expr is an untyped constant or nil. The type
+// checker records the type untyped expressions are converted to, so this
+// is determined from the syntax.
+func isUntyped(info *types.Info, expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isUntyped(info, expr.X)
+	case *ast.UnaryExpr:
+		return expr.Op != token.AND && expr.Op != token.ARROW && isUntyped(info, expr.X)
+	case *ast.BinaryExpr:
+		if expr.Op == token.SHL || expr.Op == token.SHR {
+			return isUntyped(info, expr.X)
+		}
+		return isUntyped(info, expr.X) && isUntyped(info, expr.Y)
+	case *ast.Ident, *ast.SelectorExpr:
+		var id *ast.Ident
+		if sel, ok := expr.(*ast.SelectorExpr); ok {
+			id = sel.Sel
+		} else {
+			id = expr.(*ast.Ident)
+		}
+		switch obj := info.ObjectOf(id).(type) {
+		case *types.Nil:
+			return true
+		case *types.Const:
+			if b, ok := obj.Type().(*types.Basic); ok {
+				return b.Info()&types.IsUntyped != 0
+			}
+		}
+	}
+	return false
+}
+
+// defaultType returns the type an untyped expression takes when it is
+// assigned to a new variable, or nil if it has none or it can't be
+// determined.
+func defaultType(info *types.Info, expr ast.Expr) types.Type {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		switch expr.Kind {
+		case token.INT:
+			return types.Typ[types.Int]
+		case token.FLOAT:
+			return types.Typ[types.Float64]
+		case token.IMAG:
+			return types.Typ[types.Complex128]
+		case token.CHAR:
+			return types.Universe.Lookup("rune").Type()
+		case token.STRING:
+			return types.Typ[types.String]
+		}
+	case *ast.Ident:
+		if obj, ok := info.ObjectOf(expr).(*types.Const); ok {
+			return types.Default(obj.Type())
+		}
+	}
+	return nil
+}
+
+// unreachable reports whether expr is a local variable of the caller that
+// the callee can't change: its address is never taken, explicitly or to call
+// a method with a pointer receiver, and no function literal refers to it.
+func (in *inliner) unreachable(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v, ok := in.info.Uses[id].(*types.Var)
+	if !ok || v.Parent() == nil || v.Parent() == in.pkg.Scope() {
+		return false
+	}
+	var decl ast.Decl
+	for _, d := range in.file.Decls {
+		if d.Pos() <= v.Pos() && v.Pos() < d.End() {
+			decl = d
+			break
+		}
+	}
+	if decl == nil {
+		return false
+	}
+	reached := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if reached {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.UnaryExpr:
+			reached = n.Op == token.AND && in.root(n.X) == v
+		case *ast.SelectorExpr:
+			sel, ok := in.info.Selections[n]
+			if ok && sel.Kind() == types.MethodVal && in.root(n.X) == v {
+				_, ptrRecv := sel.Obj().Type().(*types.Signature).Recv().Type().Underlying().(*types.Pointer)
+				_, ptrX := sel.Recv().Underlying().(*types.Pointer)
+				reached = ptrRecv && !ptrX
+			}
+		case *ast.FuncLit:
+			ast.Inspect(n.Body, func(n ast.Node) bool {
+				if id, ok := n.(*ast.Ident); ok && in.info.Uses[id] == v {
+					reached = true
+				}
+				return !reached
+			})
+		}
+		return !reached
+	})
+	return !reached
+}
+
+// root returns the variable whose storage holds the value of expr, a chain
+// of field selections, array indexes and parentheses, or nil.
+func (in *inliner) root(expr ast.Expr) *types.Var {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			if sel, ok := in.info.Selections[e]; !ok || sel.Kind() != types.FieldVal {
+				return nil
+			}
+			expr = e.X
+		case *ast.IndexExpr:
+			if _, ok := in.info.TypeOf(e.X).Underlying().(*types.Array); !ok {
+				return nil
+			}
+			expr = e.X
+		case *ast.Ident:
+			v, _ := in.info.Uses[e].(*types.Var)
+			return v
+		default:
+			return nil
+		}
+	}
+}
+
+// isConst reports whether expr is a constant.
+func (in *inliner) isConst(expr ast.Expr) bool {
+	tv, ok := in.info.Types[expr]
+	return ok && tv.Value != nil
+}
+
+// trivial reports whether expr is cheap, and always has the same value, so
+// that it may be duplicated.
+func (in *inliner) trivial(expr ast.Expr) bool {
+	if in.isConst(expr) {
+		return true
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.ParenExpr:
+		return in.trivial(expr.X)
+	case *ast.SelectorExpr:
+		return in.trivial(expr.X)
+	}
+	return false
+}
+
+// pure reports whether the evaluation of expr has no side effects.
+func (in *inliner) pure(expr ast.Expr) bool {
+	return isPure(in.info, expr)
+}
+
+// isPure reports whether the evaluation of expr has no side effects: whether
+// it involves no calls, other than conversions, and no receive operations.
+func isPure(info *types.Info, expr ast.Expr) bool {
+	pure := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if tv, ok := info.Types[n.Fun]; !ok || !tv.IsType() {
+				pure = false
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				pure = false
+			}
+		}
+		return pure
+	})
+	return pure
+}
+
+func (in *inliner) allPure(exprs []ast.Expr) bool {
+	for _, e := range exprs {
+		if !in.pure(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameObj reports whether x and y are the same object, or the same imported
+// package.
+func sameObj(x, y types.Object) bool {
+	if x == y {
+		return true
+	}
+	xp, ok1 := x.(*types.PkgName)
+	yp, ok2 := y.(*types.PkgName)
+	return ok1 && ok2 && xp.Name() == yp.Name() && xp.Imported() == yp.Imported()
+}
+
+// isPrimary reports whether expr is a primary expression, which may be used
+// as an operand without parentheses.
+func isPrimary(expr ast.Expr) bool {
+	switch expr.(type) {
+	case *ast.Ident, *ast.BasicLit, *ast.SelectorExpr, *ast.CallExpr, *ast.IndexExpr,
+		*ast.SliceExpr, *ast.TypeAssertExpr, *ast.ParenExpr, *ast.CompositeLit:
+		return true
+	}
+	return false
+}
+
+// needsParens reports whether a non-primary expression whose parent is parent
+// must be parenthesized.
+func needsParens(parent ast.Node) bool {
+	switch parent.(type) {
+	case *ast.CallExpr, *ast.CompositeLit, *ast.KeyValueExpr, *ast.ReturnStmt,
+		*ast.AssignStmt, *ast.ValueSpec, *ast.ExprStmt, *ast.SendStmt, *ast.IfStmt,
+		*ast.SwitchStmt, *ast.RangeStmt, *ast.IncDecStmt, *ast.ForStmt:
+		return false
+	}
+	return true
+}
+
+// parenthesize returns text, the text of expr, in parentheses unless expr is
+// a primary expression.
+func parenthesize(expr ast.Expr, text string) string {
+	if isPrimary(expr) {
+		return text
+	}
+	return "(" + text + ")"
+}
+
+// convert returns the conversion of text to the type typ.
+func convert(typ, text string) string {
+	if strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "<-") || strings.HasPrefix(typ, "func") {
+		typ = "(" + typ + ")"
+	}
+	return typ + "(" + text + ")"
+}
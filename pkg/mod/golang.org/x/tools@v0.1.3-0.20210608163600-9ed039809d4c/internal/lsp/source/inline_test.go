@@ -0,0 +1,141 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/span"
+)
+
+func TestInlineCallErrors(t *testing.T) {
+	const src = `package p
+
+func sign(x int) int {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
+
+func early(x int) {
+	if x < 0 {
+		return
+	}
+	println(x)
+}
+
+func fact(n int) int {
+	return n * fact(n-1)
+}
+
+func sum(xs ...int) int {
+	return len(xs)
+}
+
+func named() (n int) {
+	return 1
+}
+
+func pair() (int, int) {
+	return 1, 2
+}
+
+func closing() {
+	defer println()
+}
+
+func incr(x int) int {
+	y := x
+	y++
+	return y
+}
+
+var global = 1
+
+func useGlobal() int {
+	return global
+}
+
+func _() {
+	_ = sign(1)
+	early(1)
+	_ = fact(3)
+	_ = sum(1, 2)
+	_ = named()
+	println(pair())
+	closing()
+	_ = 1 + incr(2)
+	global := 2
+	_ = useGlobal() + global
+}
+`
+	for _, tt := range []struct {
+		call, want string
+	}{
+		{"sign(1)", "multiple return statements"},
+		{"early(1)", "returns before the end of its body"},
+		{"fact(3)", "recursive"},
+		{"sum(1, 2)", "variadic"},
+		{"named()", "named results"},
+		{"pair()", "its results can only be assigned or returned"},
+		{"closing()", "defers calls"},
+		{"incr(2)", "statements other than a return"},
+		{"useGlobal()", "global is shadowed at the call site"},
+	} {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Implicits:  make(map[ast.Node]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Scopes:     make(map[ast.Node]*types.Scope),
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		pkg, err := conf.Check("p", fset, []*ast.File{file}, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok := fset.File(file.Pos())
+		start := tok.Pos(strings.LastIndex(src, tt.call))
+		call, callee := selectedCall(span.NewRange(fset, start, start), file, info)
+		if call == nil {
+			t.Fatalf("no call found at %q", tt.call)
+		}
+		var decl *ast.FuncDecl
+		for _, d := range file.Decls {
+			if d, ok := d.(*ast.FuncDecl); ok && d.Name.Pos() == callee.Pos() {
+				decl = d
+			}
+		}
+		in := &inliner{
+			fset:     fset,
+			file:     file,
+			src:      []byte(src),
+			pkg:      pkg,
+			info:     info,
+			call:     call,
+			callee:   callee,
+			decl:     decl,
+			declFile: file,
+			declSrc:  []byte(src),
+		}
+		_, err = in.inline()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("inlining %s: got error %v, want %q", tt.call, err, tt.want)
+		}
+	}
+}
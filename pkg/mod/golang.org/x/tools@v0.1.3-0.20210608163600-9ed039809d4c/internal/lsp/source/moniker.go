@@ -0,0 +1,76 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/types"
+
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+// MonikerScheme is the scheme of the monikers computed by gopls.
+const MonikerScheme = "go"
+
+// Moniker returns the monikers of the object referenced at the given
+// position. A moniker identifies an object by its package path followed by
+// its object path within the package, so that it is stable across
+// repositories. Objects that are not reachable from their package scope,
+// such as local variables, have no moniker.
+func Moniker(ctx context.Context, snapshot Snapshot, fh FileHandle, pp protocol.Position) ([]protocol.Moniker, error) {
+	ctx, done := event.Start(ctx, "source.Moniker")
+	defer done()
+
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh, pp)
+	if err != nil {
+		if errors.Is(err, ErrNoIdentFound) || errors.Is(err, errNoObjectFound) || errors.Is(err, errBuiltin) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var (
+		monikers []protocol.Moniker
+		seen     = make(map[protocol.Moniker]bool)
+	)
+	for _, qo := range qos {
+		identifier, ok := monikerIdentifier(qo.obj)
+		if !ok {
+			continue
+		}
+		// Imported package names are declared by the importing file, but
+		// denote another package.
+		kind := protocol.Import
+		if _, ok := qo.obj.(*types.PkgName); !ok && qo.sourcePkg != nil && qo.obj.Pkg() == qo.sourcePkg.GetTypes() {
+			kind = protocol.Export
+		}
+		m := protocol.Moniker{
+			Scheme:     MonikerScheme,
+			Identifier: identifier,
+			Unique:     protocol.Scheme,
+			Kind:       kind,
+		}
+		if !seen[m] {
+			seen[m] = true
+			monikers = append(monikers, m)
+		}
+	}
+	return monikers, nil
+}
+
+// monikerIdentifier returns the identifier of the moniker of obj, and
+// reports whether it has one.
+func monikerIdentifier(obj types.Object) (string, bool) {
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		return pkgName.Imported().Path(), true
+	}
+	path, err := objectpath.For(obj)
+	if err != nil {
+		return "", false
+	}
+	return obj.Pkg().Path() + ":" + string(path), true
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestMonikerIdentifier(t *testing.T) {
+	const src = `package p
+
+import "fmt"
+
+type T struct{ F int }
+
+func (T) M() {}
+
+func Exported(x int) {
+	local := x
+	_ = local
+	fmt.Println()
+}
+
+func unexported() {}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs:      make(map[*ast.Ident]types.Object),
+		Implicits: make(map[ast.Node]types.Object),
+	}
+	conf := types.Config{Importer: fakeImporter{}}
+	if _, err := conf.Check("example.com/p", fset, []*ast.File{file}, info); err != nil {
+		t.Fatal(err)
+	}
+	objs := make(map[string]types.Object)
+	for id, obj := range info.Defs {
+		if obj != nil {
+			objs[id.Name] = obj
+		}
+	}
+	for _, obj := range info.Implicits {
+		objs[obj.Name()] = obj
+	}
+	for _, tt := range []struct {
+		name string
+		want string // empty if the object has no moniker
+	}{
+		{"T", "example.com/p:T"},
+		{"F", "example.com/p:T.UF0"},
+		{"M", "example.com/p:T.M0"},
+		{"Exported", "example.com/p:Exported"},
+		{"x", "example.com/p:Exported.PA0"},
+		{"fmt", "fmt"},
+		{"local", ""},
+		{"unexported", ""},
+	} {
+		got, _ := monikerIdentifier(objs[tt.name])
+		if got != tt.want {
+			t.Errorf("monikerIdentifier(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fakeImporter imports packages that declare only a Println function.
+type fakeImporter struct{}
+
+func (fakeImporter) Import(path string) (*types.Package, error) {
+	pkg := types.NewPackage(path, path)
+	sig := types.NewSignature(nil, nil, nil, false)
+	pkg.Scope().Insert(types.NewFunc(token.NoPos, pkg, "Println", sig))
+	pkg.MarkComplete()
+	return pkg, nil
+}
@@ -7,10 +7,14 @@ package source
 import (
 	"context"
 	"fmt"
+	"go/token"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
+	"text/template"
+	"text/template/parse"
 	"time"
 
 	"golang.org/x/tools/go/analysis"
@@ -46,6 +50,7 @@ import (
 	"golang.org/x/tools/go/analysis/passes/unsafeptr"
 	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"golang.org/x/tools/internal/lsp/analysis/fieldtag"
 	"golang.org/x/tools/internal/lsp/analysis/fillreturns"
 	"golang.org/x/tools/internal/lsp/analysis/fillstruct"
 	"golang.org/x/tools/internal/lsp/analysis/nonewvars"
@@ -53,6 +58,7 @@ import (
 	"golang.org/x/tools/internal/lsp/analysis/simplifycompositelit"
 	"golang.org/x/tools/internal/lsp/analysis/simplifyrange"
 	"golang.org/x/tools/internal/lsp/analysis/simplifyslice"
+	"golang.org/x/tools/internal/lsp/analysis/stubmethods"
 	"golang.org/x/tools/internal/lsp/analysis/undeclaredname"
 	"golang.org/x/tools/internal/lsp/analysis/unusedparams"
 	"golang.org/x/tools/internal/lsp/command"
@@ -94,6 +100,7 @@ func DefaultOptions() *Options {
 						protocol.QuickFix:              true,
 						protocol.RefactorRewrite:       true,
 						protocol.RefactorExtract:       true,
+						protocol.RefactorInline:        true,
 					},
 					Mod: {
 						protocol.SourceOrganizeImports: true,
@@ -126,9 +133,10 @@ func DefaultOptions() *Options {
 						LinksInHover: true,
 					},
 					NavigationOptions: NavigationOptions{
-						ImportShortcut: Both,
-						SymbolMatcher:  SymbolFuzzy,
-						SymbolStyle:    DynamicSymbols,
+						ImportShortcut:     Both,
+						SymbolMatcher:      SymbolFuzzy,
+						SymbolStyle:        DynamicSymbols,
+						TypeHierarchyScope: WorkspaceTypeHierarchy,
 					},
 					CompletionOptions: CompletionOptions{
 						Matcher:                        Fuzzy,
@@ -271,6 +279,7 @@ type UIOptions struct {
 	CompletionOptions
 	NavigationOptions
 	DiagnosticOptions
+	InlayHintOptions
 
 	// Codelenses overrides the enabled/disabled state of code lenses. See the
 	// "Code Lenses" section of the
@@ -315,8 +324,62 @@ type CompletionOptions struct {
 	// ExperimentalPostfixCompletions enables artifical method snippets
 	// such as "someSlice.sort!".
 	ExperimentalPostfixCompletions bool `status:"experimental"`
+
+	// PostfixSnippets adds postfix snippets to the built-in ones, when
+	// experimentalPostfixCompletions is enabled. The body of a snippet is a
+	// text/template executed on the operand of the completion; a snippet
+	// whose body produces no text does not apply. The dot of the body has
+	// the following fields and methods:
+	//
+	// * `X`, `Obj`, `Type`: the operand as text, its object and its type.
+	// * `Kind`, `ElemType`, `KeyType`, `Tuple`, `IsError`: predicates and
+	// types derived from the type of the operand.
+	// * `Fun`, `Args`: the function and the arguments of an operand that
+	// is a call.
+	// * `StmtOK`: whether the snippet may expand to a statement.
+	// * `Import`, `VarName`, `TypeName`, `EscapeQuotes`, `Cursor`: helpers
+	// to import a package, choose a variable name, print a type, quote the
+	// operand and place the cursor.
+	//
+	// Example Usage:
+	//
+	// ```json5
+	// "gopls": {
+	// ...
+	//   "postfixSnippets": [{
+	//     "label": "errwrap",
+	//     "details": "wrap error",
+	//     "body": "{{if .IsError}}{{.Import \"fmt\"}}.Errorf(\"{{.Cursor}}: %w\", {{.X}}){{end}}"
+	//   }]
+	// ...
+	// }
+	// ```
+	PostfixSnippets []PostfixSnippet `status:"experimental"`
 }
 
+// PostfixSnippet is a user-defined postfix snippet.
+type PostfixSnippet struct {
+	// Label is the name of the snippet, completed as "x.label!".
+	Label string
+	// Details is shown alongside the completion candidate.
+	Details string
+	// Body is the text/template source of the snippet.
+	Body string
+
+	tmpl *template.Template
+}
+
+// Template returns the parsed body of the snippet.
+func (s PostfixSnippet) Template() *template.Template {
+	return s.tmpl
+}
+
+// PostfixSnippetFields are the fields and methods of the dot of the body
+// of a postfix snippet.
+var PostfixSnippetFields = []string{"Args", "Cursor", "ElemType", "EscapeQuotes", "Fun",
+	"Import", "IsError", "KeyType", "Kind", "Obj", "StmtOK", "Tuple", "Type", "TypeName",
+	"VarName", "X"}
+
 type DocumentationOptions struct {
 	// HoverKind controls the information that appears in the hover text.
 	// SingleLine and Structured are intended for use only by authors of editor plugins.
@@ -378,6 +441,32 @@ type DiagnosticOptions struct {
 	//
 	// This option must be set to a valid duration string, for example `"250ms"`.
 	ExperimentalDiagnosticsDelay time.Duration `status:"experimental"`
+
+	// VulnDatabase is the path of a local directory holding a vulnerability
+	// database in the OSV format, such as a checkout of
+	// https://github.com/golang/vulndb. When set, the requirements of go.mod
+	// files are checked against it, and vulnerable modules are reported
+	// along with whether the workspace calls the affected symbols directly.
+	VulnDatabase string `status:"experimental"`
+}
+
+type InlayHintOptions struct {
+	// Hints specify the inlay hints that users want to see. All hints are
+	// disabled by default.
+	//
+	// Example Usage:
+	//
+	// ```json5
+	// "gopls": {
+	// ...
+	//   "hints": {
+	//     "parameterNames": true,  // Show the parameter names of call arguments.
+	//     "constantValues": true   // Show the values of iota constants.
+	//   }
+	// ...
+	// }
+	// ```
+	Hints map[string]bool `status:"experimental"`
 }
 
 type NavigationOptions struct {
@@ -400,6 +489,10 @@ type NavigationOptions struct {
 	// }
 	// ```
 	SymbolStyle SymbolStyle `status:"advanced"`
+
+	// TypeHierarchyScope controls which packages are searched for the
+	// supertypes and subtypes of a type in the type hierarchy.
+	TypeHierarchyScope TypeHierarchyScope `status:"advanced"`
 }
 
 // UserOptions holds custom Gopls configuration (not part of the LSP) that is
@@ -548,6 +641,15 @@ const (
 	DynamicSymbols SymbolStyle = "Dynamic"
 )
 
+type TypeHierarchyScope string
+
+const (
+	// WorkspaceTypeHierarchy searches the packages of the workspace.
+	WorkspaceTypeHierarchy TypeHierarchyScope = "Workspace"
+	// AllTypeHierarchy also searches the dependencies of the workspace.
+	AllTypeHierarchy TypeHierarchyScope = "All"
+)
+
 type HoverKind string
 
 const (
@@ -686,6 +788,7 @@ func (o *Options) Clone() *Options {
 	}
 	result.Analyses = copyStringMap(o.Analyses)
 	result.Codelenses = copyStringMap(o.Codelenses)
+	result.Hints = copyStringMap(o.Hints)
 
 	copySlice := func(src []string) []string {
 		dst := make([]string, len(src))
@@ -695,6 +798,7 @@ func (o *Options) Clone() *Options {
 	result.SetEnvSlice(o.EnvSlice())
 	result.BuildFlags = copySlice(o.BuildFlags)
 	result.DirectoryFilters = copySlice(o.DirectoryFilters)
+	result.PostfixSnippets = append([]PostfixSnippet(nil), o.PostfixSnippets...)
 
 	copyAnalyzerMap := func(src map[string]*Analyzer) map[string]*Analyzer {
 		dst := make(map[string]*Analyzer)
@@ -832,6 +936,14 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 			o.SymbolStyle = SymbolStyle(s)
 		}
 
+	case "typeHierarchyScope":
+		if s, ok := result.asOneOf(
+			string(WorkspaceTypeHierarchy),
+			string(AllTypeHierarchy),
+		); ok {
+			o.TypeHierarchyScope = TypeHierarchyScope(s)
+		}
+
 	case "hoverKind":
 		if s, ok := result.asOneOf(
 			string(NoDocumentation),
@@ -900,12 +1012,32 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 	case "semanticTokens":
 		result.setBool(&o.SemanticTokens)
 
+	case "hints":
+		result.setBoolMap(&o.Hints)
+
 	case "expandWorkspaceToModule":
 		result.setBool(&o.ExpandWorkspaceToModule)
 
 	case "experimentalPostfixCompletions":
 		result.setBool(&o.ExperimentalPostfixCompletions)
 
+	case "postfixSnippets":
+		isnippets, ok := value.([]interface{})
+		if !ok {
+			result.errorf("invalid type %T, expect list", value)
+			break
+		}
+		var snippets []PostfixSnippet
+		for i, isnippet := range isnippets {
+			snippet, err := parsePostfixSnippet(isnippet)
+			if err != nil {
+				result.errorf("snippet %d: %v", i, err)
+				return result
+			}
+			snippets = append(snippets, snippet)
+		}
+		o.PostfixSnippets = snippets
+
 	case "experimentalWorkspaceModule":
 		result.setBool(&o.ExperimentalWorkspaceModule)
 
@@ -915,6 +1047,9 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 	case "experimentalDiagnosticsDelay":
 		result.setDuration(&o.ExperimentalDiagnosticsDelay)
 
+	case "vulnDatabase":
+		result.setString(&o.VulnDatabase)
+
 	case "experimentalPackageCacheKey":
 		result.setBool(&o.ExperimentalPackageCacheKey)
 
@@ -976,6 +1111,129 @@ func (o *Options) set(name string, value interface{}, seen map[string]struct{})
 	return result
 }
 
+// parsePostfixSnippet parses and checks the postfix snippet value, an
+// object with a label, details and a body.
+func parsePostfixSnippet(value interface{}) (PostfixSnippet, error) {
+	var snippet PostfixSnippet
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return snippet, errors.Errorf("invalid type %T, expect object", value)
+	}
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, ok := m[k].(string)
+		if !ok {
+			return snippet, errors.Errorf("invalid type %T for %q, expect string", m[k], k)
+		}
+		switch k {
+		case "label":
+			snippet.Label = v
+		case "details":
+			snippet.Details = v
+		case "body":
+			snippet.Body = v
+		default:
+			return snippet, errors.Errorf("unknown field %q, expect label, details or body", k)
+		}
+	}
+	if !token.IsIdentifier(snippet.Label) {
+		return snippet, errors.Errorf("label %q is not an identifier", snippet.Label)
+	}
+	if strings.TrimSpace(snippet.Body) == "" {
+		return snippet, errors.Errorf("%s has no body", snippet.Label)
+	}
+	tmpl, err := template.New(snippet.Label).Parse(snippet.Body)
+	if err != nil {
+		return snippet, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := checkPostfixFields(t.Tree, t.Root, true); err != nil {
+			return snippet, err
+		}
+	}
+	snippet.tmpl = tmpl
+	return snippet, nil
+}
+
+// checkPostfixFields reports the fields of the dot of a postfix snippet
+// that are not in PostfixSnippetFields. dotIsArgs reports whether dot is
+// still the dot of the snippet, rather than that of a range or with.
+func checkPostfixFields(tree *parse.Tree, n parse.Node, dotIsArgs bool) error {
+	check := func(n parse.Node, name string) error {
+		for _, f := range PostfixSnippetFields {
+			if f == name {
+				return nil
+			}
+		}
+		loc, _ := tree.ErrorContext(n)
+		return errors.Errorf("template: %s: unknown field %s, expect one of %s", loc, name, strings.Join(PostfixSnippetFields, ", "))
+	}
+	branch := func(b *parse.BranchNode, scoped bool) error {
+		if err := checkPostfixFields(tree, b.Pipe, dotIsArgs); err != nil {
+			return err
+		}
+		if err := checkPostfixFields(tree, b.List, dotIsArgs && !scoped); err != nil {
+			return err
+		}
+		return checkPostfixFields(tree, b.ElseList, dotIsArgs)
+	}
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil { // nil ElseList
+			return nil
+		}
+		for _, n := range n.Nodes {
+			if err := checkPostfixFields(tree, n, dotIsArgs); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkPostfixFields(tree, n.Pipe, dotIsArgs)
+	case *parse.IfNode:
+		return branch(&n.BranchNode, false)
+	case *parse.RangeNode:
+		return branch(&n.BranchNode, true)
+	case *parse.WithNode:
+		return branch(&n.BranchNode, true)
+	case *parse.TemplateNode:
+		return checkPostfixFields(tree, n.Pipe, dotIsArgs)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkPostfixFields(tree, cmd, dotIsArgs); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkPostfixFields(tree, arg, dotIsArgs); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return checkPostfixFields(tree, n.Node, dotIsArgs)
+	case *parse.FieldNode:
+		if dotIsArgs {
+			return check(n, n.Ident[0])
+		}
+	case *parse.VariableNode:
+		// $ is always the dot of the snippet.
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			return check(n, n.Ident[1])
+		}
+	}
+	return nil
+}
+
 func (r *OptionResult) errorf(msg string, values ...interface{}) {
 	prefix := fmt.Sprintf("parsing setting %q: ", r.Name)
 	r.Error = errors.Errorf(prefix+msg, values...)
@@ -1153,6 +1411,11 @@ func typeErrorAnalyzers() map[string]*Analyzer {
 			Fix:      UndeclaredName,
 			Enabled:  true,
 		},
+		stubmethods.Analyzer.Name: {
+			Analyzer: stubmethods.Analyzer,
+			Fix:      StubMethods,
+			Enabled:  true,
+		},
 	}
 }
 
@@ -1199,6 +1462,7 @@ func defaultAnalyzers() map[string]*Analyzer {
 		atomicalign.Analyzer.Name:      {Analyzer: atomicalign.Analyzer, Enabled: true},
 		deepequalerrors.Analyzer.Name:  {Analyzer: deepequalerrors.Analyzer, Enabled: true},
 		fieldalignment.Analyzer.Name:   {Analyzer: fieldalignment.Analyzer, Enabled: false},
+		fieldtag.Analyzer.Name:         {Analyzer: fieldtag.Analyzer, Enabled: true},
 		nilness.Analyzer.Name:          {Analyzer: nilness.Analyzer, Enabled: false},
 		shadow.Analyzer.Name:           {Analyzer: shadow.Analyzer, Enabled: false},
 		sortslice.Analyzer.Name:        {Analyzer: sortslice.Analyzer, Enabled: true},
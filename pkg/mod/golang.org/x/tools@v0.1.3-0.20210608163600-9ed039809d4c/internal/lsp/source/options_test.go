@@ -5,6 +5,7 @@
 package source
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -155,6 +156,24 @@ func TestSetOption(t *testing.T) {
 				return len(o.DirectoryFilters) == 0
 			},
 		},
+		{
+			name: "postfixSnippets",
+			value: []interface{}{map[string]interface{}{
+				"label": "errwrap",
+				"body":  `{{if .IsError}}fmt.Errorf("%w", {{.X}}){{end}}`,
+			}},
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 1 && o.PostfixSnippets[0].Template() != nil
+			},
+		},
+		{
+			name:      "postfixSnippets",
+			value:     []interface{}{map[string]interface{}{"label": "x", "body": "{{.Y}}"}},
+			wantError: true,
+			check: func(o Options) bool {
+				return len(o.PostfixSnippets) == 0
+			},
+		},
 		{
 			name: "annotations",
 			value: map[string]interface{}{
@@ -181,3 +200,26 @@ func TestSetOption(t *testing.T) {
 		}
 	}
 }
+
+func TestPostfixSnippetErrors(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		want  string
+	}{
+		{"sort", "snippet 0: invalid type string, expect object"},
+		{map[string]interface{}{"label": "a", "body": 1}, `snippet 0: invalid type int for "body", expect string`},
+		{map[string]interface{}{"label": "a", "body": "x", "doc": "d"}, `snippet 0: unknown field "doc", expect label, details or body`},
+		{map[string]interface{}{"label": "a.b", "body": "x"}, `snippet 0: label "a.b" is not an identifier`},
+		{map[string]interface{}{"label": "a", "body": " "}, "snippet 0: a has no body"},
+		{map[string]interface{}{"label": "a", "body": "{{if .X}}"}, "snippet 0: template: a:1: unexpected EOF"},
+		{map[string]interface{}{"label": "a", "body": "{{wrap .X}}"}, `snippet 0: template: a:1: function "wrap" not defined`},
+		{map[string]interface{}{"label": "a", "body": "{{.X}}\n{{.Kind | printf \"%s\" | .Name}}"}, "snippet 0: template: a:2:24: unknown field Name"},
+		{map[string]interface{}{"label": "a", "body": "{{range .Tuple}}{{.Name}}{{$.Nam}}{{end}}"}, "snippet 0: template: a:1:28: unknown field Nam"},
+	} {
+		var opts Options
+		result := opts.set("postfixSnippets", []interface{}{test.value}, map[string]struct{}{})
+		if result.Error == nil || !strings.Contains(result.Error.Error(), test.want) {
+			t.Errorf("postfixSnippets %v: got error %v, want %q", test.value, result.Error, test.want)
+		}
+	}
+}
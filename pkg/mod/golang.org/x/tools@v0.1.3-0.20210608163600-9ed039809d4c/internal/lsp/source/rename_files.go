@@ -0,0 +1,317 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// FileRename describes a file or directory that is about to be moved from
+// OldURI to NewURI.
+type FileRename struct {
+	OldURI, NewURI span.URI
+}
+
+// packageMove describes the new import path and name of a package whose
+// directory is being moved.
+type packageMove struct {
+	oldPath, newPath string
+	oldName, newName string
+}
+
+// RenameFiles returns the edits that keep the workspace building once the
+// files and directories in renames are moved: when a directory is moved the
+// package clause of the packages it contains is updated to match the new
+// directory name and every import of those packages is rewritten to the
+// new import path, similar to what gomvpkg does. When a Go file is moved
+// to a different directory its package clause is changed to match the
+// destination package.
+//
+// RenameFiles must be called before the files are moved, all edits refer
+// to the files at their old location.
+func RenameFiles(ctx context.Context, snapshot Snapshot, renames []FileRename) (map[span.URI][]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.RenameFiles")
+	defer done()
+
+	pkgs, err := snapshot.WorkspacePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := newEditSet()
+	moves := map[string]packageMove{}
+	for _, r := range renames {
+		oldPath, newPath := r.OldURI.Filename(), r.NewURI.Filename()
+		if fi, err := os.Stat(oldPath); (err == nil && !fi.IsDir()) || (err != nil && strings.HasSuffix(oldPath, ".go")) {
+			if !strings.HasSuffix(oldPath, ".go") || filepath.Dir(oldPath) == filepath.Dir(newPath) {
+				continue
+			}
+			if err := moveFileEdits(snapshot, pkgs, r, edits); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		for _, pkg := range pkgs {
+			dir := packageDir(pkg)
+			if dir == "" || pkg.ForTest() != "" || strings.HasSuffix(pkg.PkgPath(), "_test") {
+				continue
+			}
+			if dir != oldPath && !InDir(oldPath, dir) {
+				continue
+			}
+			newDir := filepath.Join(newPath, strings.TrimPrefix(dir, oldPath))
+			newPkgPath, err := newImportPath(ctx, snapshot, pkg, dir, newDir)
+			if err != nil {
+				return nil, err
+			}
+			m := packageMove{
+				oldPath: pkg.PkgPath(),
+				newPath: newPkgPath,
+				oldName: pkg.Name(),
+				newName: pkg.Name(),
+			}
+			if dir == oldPath && pkg.Name() == filepath.Base(oldPath) && pkg.Name() != "main" {
+				if name := filepath.Base(newPath); token.IsIdentifier(name) && name != "_" {
+					m.newName = name
+				}
+			}
+			if m.oldPath != m.newPath || m.oldName != m.newName {
+				moves[m.oldPath] = m
+			}
+		}
+	}
+
+	for _, pkg := range pkgs {
+		for _, pgf := range pkg.CompiledGoFiles() {
+			if err := packageClauseEdits(snapshot, pkg, pgf, moves, edits); err != nil {
+				return nil, err
+			}
+			if err := importEdits(snapshot, pkg, pgf, moves, edits); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return edits.result(), nil
+}
+
+// moveFileEdits updates the package clause of a Go file moved to a
+// different directory to match the package of the destination directory.
+func moveFileEdits(snapshot Snapshot, pkgs []Package, r FileRename, edits *editSet) error {
+	newName := packageNameForDir(pkgs, filepath.Dir(r.NewURI.Filename()))
+	if newName == "" {
+		return nil
+	}
+	for _, pkg := range pkgs {
+		pgf, err := pkg.File(r.OldURI)
+		if err != nil {
+			continue
+		}
+		name := pgf.File.Name
+		if strings.HasSuffix(name.Name, "_test") && strings.HasSuffix(r.NewURI.Filename(), "_test.go") {
+			newName += "_test"
+		}
+		if name.Name == newName {
+			return nil
+		}
+		return edits.add(snapshot, pgf, name.Pos(), name.End(), newName)
+	}
+	return nil
+}
+
+// packageNameForDir returns the name of the package in dir or, if there
+// is no known package there, a name derived from the name of the
+// directory.
+func packageNameForDir(pkgs []Package, dir string) string {
+	for _, pkg := range pkgs {
+		if packageDir(pkg) == dir && !strings.HasSuffix(pkg.Name(), "_test") {
+			return pkg.Name()
+		}
+	}
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, filepath.Base(dir))
+	if !token.IsIdentifier(name) || name == "_" {
+		return ""
+	}
+	return name
+}
+
+// packageClauseEdits renames the package clause of pgf if its package is
+// being moved to a directory with a different name. The external test
+// package of a renamed package is renamed too.
+func packageClauseEdits(snapshot Snapshot, pkg Package, pgf *ParsedGoFile, moves map[string]packageMove, edits *editSet) error {
+	m, ok := moves[strings.TrimSuffix(pkg.PkgPath(), "_test")]
+	if !ok || m.oldName == m.newName {
+		return nil
+	}
+	name := pgf.File.Name
+	var newName string
+	switch name.Name {
+	case m.oldName:
+		newName = m.newName
+	case m.oldName + "_test":
+		newName = m.newName + "_test"
+	default:
+		return nil
+	}
+	return edits.add(snapshot, pgf, name.Pos(), name.End(), newName)
+}
+
+// importEdits rewrites the imports of moved packages in pgf. If the name of
+// an imported package changes its qualified identifiers are renamed, unless
+// the new name conflicts with other declarations, in which case the old
+// name is kept as an explicit import name.
+func importEdits(snapshot Snapshot, pkg Package, pgf *ParsedGoFile, moves map[string]packageMove, edits *editSet) error {
+	info := pkg.GetTypesInfo()
+	for _, imp := range pgf.File.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		m, ok := moves[importPath]
+		if !ok {
+			continue
+		}
+		if err := edits.add(snapshot, pgf, imp.Path.Pos(), imp.Path.End(), strconv.Quote(m.newPath)); err != nil {
+			return err
+		}
+		if imp.Name != nil || m.oldName == m.newName || info == nil {
+			continue
+		}
+		pkgName, _ := info.Implicits[imp].(*types.PkgName)
+		if pkgName == nil {
+			continue
+		}
+		var refs []*ast.Ident
+		conflict := false
+		scope := info.Scopes[pgf.File]
+		for id, obj := range info.Uses {
+			if obj != pkgName {
+				continue
+			}
+			refs = append(refs, id)
+			if scope == nil {
+				conflict = true
+				continue
+			}
+			if inner := scope.Innermost(id.Pos()); inner != nil {
+				if _, obj := inner.LookupParent(m.newName, id.Pos()); obj != nil {
+					conflict = true
+				}
+			}
+		}
+		if scope != nil && scope.Lookup(m.newName) != nil {
+			conflict = true
+		}
+		if conflict {
+			if err := edits.add(snapshot, pgf, imp.Path.Pos(), imp.Path.Pos(), m.oldName+" "); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, id := range refs {
+			if err := edits.add(snapshot, pgf, id.Pos(), id.End(), m.newName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newImportPath returns the import path of pkg once its directory is moved
+// from dir to newDir.
+func newImportPath(ctx context.Context, snapshot Snapshot, pkg Package, dir, newDir string) (string, error) {
+	if modURI := snapshot.GoModForFile(span.URIFromPath(filepath.Join(newDir, "x.go"))); modURI != "" {
+		fh, err := snapshot.GetFile(ctx, modURI)
+		if err != nil {
+			return "", err
+		}
+		pm, err := snapshot.ParseMod(ctx, fh)
+		if err != nil {
+			return "", err
+		}
+		if pm.File == nil || pm.File.Module == nil {
+			return "", errors.Errorf("%s has no module statement", modURI.Filename())
+		}
+		rel, err := filepath.Rel(filepath.Dir(modURI.Filename()), newDir)
+		if err != nil {
+			return "", err
+		}
+		return path.Join(pm.File.Module.Mod.Path, filepath.ToSlash(rel)), nil
+	}
+	// GOPATH mode: the import path is the path of the directory relative to
+	// the src directory of its GOPATH entry.
+	slashDir := filepath.ToSlash(dir)
+	if !strings.HasSuffix(slashDir, "/"+pkg.PkgPath()) {
+		return "", errors.Errorf("could not determine the new import path of %s", pkg.PkgPath())
+	}
+	srcDir := filepath.FromSlash(strings.TrimSuffix(slashDir, "/"+pkg.PkgPath()))
+	rel, err := filepath.Rel(srcDir, newDir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", errors.Errorf("could not determine the new import path of %s", pkg.PkgPath())
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// packageDir returns the directory containing the files of pkg.
+func packageDir(pkg Package) string {
+	for _, pgf := range pkg.CompiledGoFiles() {
+		return filepath.Dir(pgf.URI.Filename())
+	}
+	return ""
+}
+
+// editSet accumulates the text edits of RenameFiles, discarding duplicates
+// caused by files that belong to more than one package (e.g. test
+// variants).
+type editSet struct {
+	edits map[span.URI]map[protocol.TextEdit]bool
+}
+
+func newEditSet() *editSet {
+	return &editSet{edits: make(map[span.URI]map[protocol.TextEdit]bool)}
+}
+
+func (s *editSet) add(snapshot Snapshot, pgf *ParsedGoFile, start, end token.Pos, newText string) error {
+	rng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, start, end).Range()
+	if err != nil {
+		return err
+	}
+	if s.edits[pgf.URI] == nil {
+		s.edits[pgf.URI] = make(map[protocol.TextEdit]bool)
+	}
+	s.edits[pgf.URI][protocol.TextEdit{Range: rng, NewText: newText}] = true
+	return nil
+}
+
+func (s *editSet) result() map[span.URI][]protocol.TextEdit {
+	result := make(map[span.URI][]protocol.TextEdit, len(s.edits))
+	for uri, edits := range s.edits {
+		for edit := range edits {
+			result[uri] = append(result[uri], edit)
+		}
+		sort.Slice(result[uri], func(i, j int) bool {
+			return protocol.CompareRange(result[uri][i].Range, result[uri][j].Range) < 0
+		})
+	}
+	return result
+}
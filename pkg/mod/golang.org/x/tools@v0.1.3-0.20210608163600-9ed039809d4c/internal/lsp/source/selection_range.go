@@ -0,0 +1,126 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+)
+
+// SelectionRange returns, for each position, the chain of syntactic ranges
+// enclosing it, used by editors to expand or shrink the selection. Each
+// range is contained in its parent: from an identifier to the expressions,
+// statements, blocks and declarations enclosing it, up to the whole file.
+func SelectionRange(ctx context.Context, snapshot Snapshot, fh FileHandle, positions []protocol.Position) ([]protocol.SelectionRange, error) {
+	ctx, done := event.Start(ctx, "source.SelectionRange")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]protocol.SelectionRange, 0, len(positions))
+	for _, pos := range positions {
+		spn, err := pgf.Mapper.PointSpan(pos)
+		if err != nil {
+			return nil, err
+		}
+		rng, err := spn.Range(pgf.Mapper.Converter)
+		if err != nil {
+			return nil, err
+		}
+		var parent *protocol.SelectionRange
+		ranges := selectionRanges(pgf.File, pgf.Tok, rng.Start)
+		for i := len(ranges) - 1; i >= 0; i-- {
+			prng, err := NewMappedRange(snapshot.FileSet(), pgf.Mapper, ranges[i].start, ranges[i].end).Range()
+			if err != nil {
+				return nil, err
+			}
+			parent = &protocol.SelectionRange{Range: prng, Parent: parent}
+		}
+		if parent == nil {
+			parent = &protocol.SelectionRange{Range: protocol.Range{Start: pos, End: pos}}
+		}
+		result = append(result, *parent)
+	}
+	return result, nil
+}
+
+// selectionRanges returns the ranges enclosing pos, innermost first. In
+// addition to the ranges of the enclosing nodes, it includes the range of
+// the list of arguments, composite literal elements or statements that
+// contains pos. The last range spans the whole file.
+func selectionRanges(file *ast.File, tok *token.File, pos token.Pos) []posRange {
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	var ranges []posRange
+	add := func(start, end token.Pos) {
+		if !start.IsValid() || !end.IsValid() || start > pos || end < pos {
+			return
+		}
+		if n := len(ranges); n > 0 {
+			last := ranges[n-1]
+			if start == last.start && end == last.end {
+				return
+			}
+			// Each range must contain the previous one.
+			if start > last.start || end < last.end {
+				return
+			}
+		}
+		ranges = append(ranges, posRange{start, end})
+	}
+	for i, n := range path {
+		if _, ok := n.(*ast.File); ok {
+			break
+		}
+		add(n.Pos(), n.End())
+		if i+1 < len(path) {
+			if list := enclosingList(path[i+1], n); len(list) > 0 {
+				add(list[0].Pos(), list[len(list)-1].End())
+			}
+		}
+	}
+	add(token.Pos(tok.Base()), token.Pos(tok.Base()+tok.Size()))
+	return ranges
+}
+
+// enclosingList returns the list of nodes of parent that child belongs to,
+// if it is an argument, a composite literal element, or a statement.
+func enclosingList(parent, child ast.Node) []ast.Node {
+	var list []ast.Node
+	switch parent := parent.(type) {
+	case *ast.CallExpr:
+		for _, arg := range parent.Args {
+			list = append(list, arg)
+		}
+	case *ast.CompositeLit:
+		for _, elt := range parent.Elts {
+			list = append(list, elt)
+		}
+	case *ast.BlockStmt:
+		for _, stmt := range parent.List {
+			list = append(list, stmt)
+		}
+	case *ast.CaseClause:
+		for _, stmt := range parent.Body {
+			list = append(list, stmt)
+		}
+	case *ast.CommClause:
+		for _, stmt := range parent.Body {
+			list = append(list, stmt)
+		}
+	}
+	for _, n := range list {
+		if n == child {
+			return list
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestSelectionRanges(t *testing.T) {
+	const src = `package p
+
+func f(a, b int) int {
+	x := g(a, []int{1, b + 2}, 3)
+	return x
+}
+`
+	for _, tt := range []struct {
+		at   string // text following the position, unique in src
+		want []string
+	}{
+		{
+			at: "b + 2",
+			want: []string{
+				"b",
+				"b + 2",
+				"1, b + 2",
+				"[]int{1, b + 2}",
+				"a, []int{1, b + 2}, 3",
+				"g(a, []int{1, b + 2}, 3)",
+				"x := g(a, []int{1, b + 2}, 3)",
+				"x := g(a, []int{1, b + 2}, 3)\n\treturn x",
+				"{\n\tx := g(a, []int{1, b + 2}, 3)\n\treturn x\n}",
+				"func f(a, b int) int {\n\tx := g(a, []int{1, b + 2}, 3)\n\treturn x\n}",
+				src,
+			},
+		},
+		{
+			at: "b int)",
+			want: []string{
+				"b",
+				"a, b int",
+				"(a, b int)",
+				"func f(a, b int) int {\n\tx := g(a, []int{1, b + 2}, 3)\n\treturn x\n}",
+				src,
+			},
+		},
+	} {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "p.go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tok := fset.File(file.Pos())
+		offset := strings.Index(src, tt.at)
+		ranges := selectionRanges(file, tok, tok.Pos(offset))
+		var got []string
+		for _, r := range ranges {
+			got = append(got, src[tok.Offset(r.start):tok.Offset(r.end)])
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("selection ranges at %q:\ngot  %q\nwant %q", tt.at, got, tt.want)
+		}
+	}
+}
@@ -150,6 +150,53 @@ func (r *runner) CallHierarchy(t *testing.T, spn span.Span, expectedCalls *tests
 	}
 }
 
+func (r *runner) TypeHierarchy(t *testing.T, spn span.Span, expectedTypes *tests.TypeHierarchyResult) {
+	mapper, err := r.data.Mapper(spn.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := mapper.Location(spn)
+	if err != nil {
+		t.Fatalf("failed for %v: %v", spn, err)
+	}
+	fh, err := r.snapshot.GetFile(r.ctx, spn.URI())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := source.PrepareTypeHierarchy(r.ctx, r.snapshot, fh, loc.Range.Start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) == 0 {
+		t.Fatalf("expected type hierarchy item to be returned for identifier at %v\n", loc.Range)
+	}
+
+	typeLocation := protocol.Location{
+		URI:   items[0].URI,
+		Range: items[0].Range,
+	}
+	if typeLocation != loc {
+		t.Fatalf("expected source.PrepareTypeHierarchy to return identifier at %v but got %v\n", loc, typeLocation)
+	}
+
+	supertypes, err := source.Supertypes(r.ctx, r.snapshot, fh, loc.Range.Start)
+	if err != nil {
+		t.Error(err)
+	}
+	if msg := tests.DiffTypeHierarchyItems(supertypes, expectedTypes.Supertypes); msg != "" {
+		t.Error(fmt.Sprintf("supertypes differ: %s", msg))
+	}
+
+	subtypes, err := source.Subtypes(r.ctx, r.snapshot, fh, loc.Range.Start)
+	if err != nil {
+		t.Error(err)
+	}
+	if msg := tests.DiffTypeHierarchyItems(subtypes, expectedTypes.Subtypes); msg != "" {
+		t.Error(fmt.Sprintf("subtypes differ: %s", msg))
+	}
+}
+
 func (r *runner) Diagnostics(t *testing.T, uri span.URI, want []*source.Diagnostic) {
 	fileID, got, err := source.FileDiagnostics(r.ctx, r.snapshot, uri)
 	if err != nil {
@@ -503,6 +550,10 @@ func (r *runner) Format(t *testing.T, spn span.Span) {
 	}
 }
 
+func (r *runner) InlayHints(t *testing.T, spn span.Span) {
+	t.Skip("nothing to test in source")
+}
+
 func (r *runner) SemanticTokens(t *testing.T, spn span.Span) {
 	t.Skip("nothing to test in source")
 }
@@ -546,6 +597,15 @@ func (r *runner) Definition(t *testing.T, spn span.Span, d tests.Definition) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if d.FullDocumentation {
+		original := r.view.Options()
+		modified := original.Clone()
+		modified.HoverKind = source.FullDocumentation
+		if _, err := r.view.SetOptions(r.ctx, modified); err != nil {
+			t.Fatal(err)
+		}
+		defer r.view.SetOptions(r.ctx, original)
+	}
 	fh, err := r.snapshot.GetFile(r.ctx, spn.URI())
 	if err != nil {
 		t.Fatal(err)
@@ -935,6 +995,9 @@ func (r *runner) Link(t *testing.T, uri span.URI, wantLinks []tests.Link) {}
 func (r *runner) SuggestedFix(t *testing.T, spn span.Span, actionKinds []string, expectedActions int) {
 }
 func (r *runner) FunctionExtraction(t *testing.T, start span.Span, end span.Span) {}
+func (r *runner) MethodExtraction(t *testing.T, start span.Span, end span.Span)   {}
+func (r *runner) InlineCall(t *testing.T, start span.Span, end span.Span)         {}
+func (r *runner) ChangeSignature(t *testing.T, spn span.Span, title string)       {}
 func (r *runner) CodeLens(t *testing.T, uri span.URI, want []protocol.CodeLens)   {}
 func (r *runner) AddImport(t *testing.T, uri span.URI, expectedImport string)     {}
 
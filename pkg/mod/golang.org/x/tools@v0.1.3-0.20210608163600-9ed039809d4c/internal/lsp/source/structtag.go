@@ -0,0 +1,464 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// A StructTagKey is a struct tag key known to gopls, which is offered in
+// completions.
+type StructTagKey struct {
+	Name string
+	Doc  string
+
+	// Case is the transformation of the field name that is suggested as the
+	// name in the value of the key.
+	Case string
+
+	// NoName is set for the keys whose value is only a list of options, such
+	// as the rules of validate.
+	NoName bool
+
+	// Options are the options that may follow the name, separated by commas.
+	Options []StructTagOption
+}
+
+// A StructTagOption is an option of the value of a struct tag key.
+type StructTagOption struct {
+	Name string
+	Doc  string
+}
+
+// StructTagKeys are the struct tag keys known to gopls.
+var StructTagKeys = []StructTagKey{
+	{
+		Name: "json",
+		Doc:  "name of the field in the JSON encoding of encoding/json",
+		Case: CamelCase,
+		Options: []StructTagOption{
+			{"omitempty", "omit the field if it has an empty value"},
+			{"omitzero", "omit the field if it has a zero value"},
+			{"string", "encode a number or bool field inside a JSON string"},
+		},
+	},
+	{
+		Name: "yaml",
+		Doc:  "name of the field in the YAML encoding of gopkg.in/yaml",
+		Case: CamelCase,
+		Options: []StructTagOption{
+			{"omitempty", "omit the field if it has an empty value"},
+			{"flow", "use the flow style for the field"},
+			{"inline", "inline the fields of the struct or map"},
+		},
+	},
+	{
+		Name: "xml",
+		Doc:  "name of the element or attribute of the field in the XML encoding of encoding/xml",
+		Case: CamelCase,
+		Options: []StructTagOption{
+			{"attr", "encode the field as an attribute"},
+			{"chardata", "encode the field as character data"},
+			{"cdata", "encode the field as a CDATA section"},
+			{"innerxml", "encode the field verbatim"},
+			{"comment", "encode the field as a comment"},
+			{"omitempty", "omit the field if it has an empty value"},
+			{"any", "accumulate the unmatched elements or attributes"},
+		},
+	},
+	{
+		Name: "db",
+		Doc:  "name of the column of the field in database libraries such as sqlx",
+		Case: SnakeCase,
+	},
+	{
+		Name:   "validate",
+		Doc:    "validation rules of the field for github.com/go-playground/validator",
+		NoName: true,
+		Options: []StructTagOption{
+			{"required", "the field must not have a zero value"},
+			{"omitempty", "skip the rules if the field has a zero value"},
+			{"dive", "apply the following rules to the elements"},
+			{"email", "the field must be an email address"},
+			{"url", "the field must be a URL"},
+			{"uuid", "the field must be a UUID"},
+			{"len=", "the field must have the given length"},
+			{"min=", "the field must have at least the given value or length"},
+			{"max=", "the field must have at most the given value or length"},
+			{"eq=", "the field must be equal to the given value"},
+			{"ne=", "the field must not be equal to the given value"},
+			{"gt=", "the field must be greater than the given value"},
+			{"gte=", "the field must be greater than or equal to the given value"},
+			{"lt=", "the field must be less than the given value"},
+			{"lte=", "the field must be less than or equal to the given value"},
+			{"oneof=", "the field must be one of the given space-separated values"},
+		},
+	},
+}
+
+// The transformations of field names into the names of struct tags.
+const (
+	CamelCase  = "camelcase"  // fieldName
+	PascalCase = "pascalcase" // FieldName
+	SnakeCase  = "snakecase"  // field_name
+	LispCase   = "lispcase"   // field-name
+	KeepCase   = "keep"       // the field name, unchanged
+)
+
+// TransformName transforms the name of a field into the name of a struct tag
+// with one of the case transformations, such as CamelCase. Acronyms are
+// kept in camel case and Pascal case: UserID becomes userID and
+// HTTPServer becomes httpServer.
+func TransformName(name, transform string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	switch transform {
+	case CamelCase, PascalCase:
+		var b strings.Builder
+		for i, w := range words {
+			if i == 0 && transform == CamelCase {
+				b.WriteString(strings.ToLower(w))
+				continue
+			}
+			// Only change the first letter, so that acronyms are kept.
+			r := []rune(w)
+			b.WriteRune(unicode.ToUpper(r[0]))
+			b.WriteString(string(r[1:]))
+		}
+		return b.String()
+	case SnakeCase, LispCase:
+		sep := "_"
+		if transform == LispCase {
+			sep = "-"
+		}
+		for i, w := range words {
+			words[i] = strings.ToLower(w)
+		}
+		return strings.Join(words, sep)
+	}
+	return name
+}
+
+// splitWords splits an identifier into words, at underscores and at the
+// changes of case: HTTPServerID is split into HTTP, Server and ID.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		var boundary bool
+		switch {
+		case i == len(runes) || runes[i] == '_':
+			boundary = true
+		case i > start && unicode.IsUpper(runes[i]):
+			prev := runes[i-1]
+			// An acronym ends before the last upper case letter that starts
+			// a new word.
+			boundary = unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				i+1 < len(runes) && unicode.IsUpper(prev) && unicode.IsLower(runes[i+1])
+		}
+		if !boundary {
+			continue
+		}
+		if i > start {
+			words = append(words, string(runes[start:i]))
+		}
+		start = i
+		if i < len(runes) && runes[i] == '_' {
+			start++
+		}
+	}
+	return words
+}
+
+// A tagPair is a key and its value in a struct tag.
+type tagPair struct {
+	key, value string
+}
+
+// parseStructTag splits a struct tag into its key and value pairs, following
+// the conventional syntax of reflect.StructTag. It reports false if the tag
+// does not follow it.
+func parseStructTag(tag string) ([]tagPair, bool) {
+	var pairs []tagPair
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return pairs, true
+		}
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, false
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// Scan the quoted value, as reflect.StructTag.Lookup does.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, false
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, false
+		}
+		pairs = append(pairs, tagPair{key, value})
+		tag = tag[i+1:]
+	}
+}
+
+// formatStructTag formats the pairs of a struct tag as a string literal.
+func formatStructTag(pairs []tagPair) string {
+	var parts []string
+	for _, p := range pairs {
+		parts = append(parts, p.key+":"+strconv.Quote(p.value))
+	}
+	tag := strings.Join(parts, " ")
+	if strings.ContainsRune(tag, '`') {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// StructFieldsAt returns the fields of the innermost struct type enclosing
+// rng whose tags may be modified: all of its fields if rng is empty, or the
+// fields that intersect rng.
+func StructFieldsAt(file *ast.File, rng span.Range) []*ast.Field {
+	path, _ := astutil.PathEnclosingInterval(file, rng.Start, rng.End)
+	var st *ast.StructType
+	for _, n := range path {
+		if n, ok := n.(*ast.StructType); ok {
+			st = n
+			break
+		}
+	}
+	if st == nil || st.Fields == nil {
+		return nil
+	}
+	var fields []*ast.Field
+	for _, f := range st.Fields.List {
+		if rng.Start == rng.End || f.Pos() < rng.End && rng.Start < f.End() {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// IsTaggableField reports whether tags are added to f by ModifyTags: it is
+// an exported field with a single name, as the fields of a list would share
+// their name in the tag.
+func IsTaggableField(f *ast.Field) bool {
+	return len(f.Names) == 1 && f.Names[0].IsExported()
+}
+
+// StructTagKeysOf returns the keys of the tag of f, or nil if it has no tag
+// or if the tag is malformed.
+func StructTagKeysOf(f *ast.Field) []string {
+	if f.Tag == nil {
+		return nil
+	}
+	tag, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return nil
+	}
+	pairs, _ := parseStructTag(tag)
+	var keys []string
+	for _, p := range pairs {
+		keys = append(keys, p.key)
+	}
+	return keys
+}
+
+// A TagModification describes the changes that ModifyTags makes to the tags
+// of struct fields.
+type TagModification struct {
+	// Add are the keys added to the tags of the fields that lack them, with
+	// the name of the field transformed by Transform as value.
+	Add       []string
+	Transform string
+
+	// Remove are the keys removed from the tags, or all the keys if Clear is
+	// set.
+	Remove []string
+	Clear  bool
+}
+
+// ModifyTags adds and removes keys of the tags of the struct fields selected
+// by pRng, as returned by StructFieldsAt, and returns the edits of the file.
+// Fields with malformed tags are left unchanged, unless all their keys are
+// removed.
+func ModifyTags(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range, mod TagModification) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "source.ModifyTags")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, ParseFull)
+	if err != nil {
+		return nil, errors.Errorf("parsing file: %w", err)
+	}
+	rng, err := pgf.Mapper.RangeToSpanRange(pRng)
+	if err != nil {
+		return nil, err
+	}
+	fields := StructFieldsAt(pgf.File, rng)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no struct fields at the selection")
+	}
+	newSrc, ok := modifyTags(pgf.Tok, pgf.Src, pgf.File, fields, mod)
+	if !ok {
+		return nil, nil
+	}
+	edits, err := snapshot.View().Options().ComputeEdits(fh.URI(), string(pgf.Src), string(newSrc))
+	if err != nil {
+		return nil, err
+	}
+	return ToProtocolEdits(pgf.Mapper, edits)
+}
+
+// modifyTags returns the source of the file with the tags of fields
+// modified, or false if they are unchanged.
+func modifyTags(tok *token.File, src []byte, file *ast.File, fields []*ast.Field, mod TagModification) ([]byte, bool) {
+	removed := make(map[string]bool)
+	for _, key := range mod.Remove {
+		removed[key] = true
+	}
+
+	// Splice the new tags into the source, and reformat the struct type,
+	// whose alignment changes.
+	type replacement struct {
+		start, end int
+		text       string
+	}
+	var replacements []replacement
+	for _, f := range fields {
+		var pairs []tagPair
+		if f.Tag != nil && !mod.Clear {
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				continue
+			}
+			var ok bool
+			if pairs, ok = parseStructTag(tag); !ok {
+				continue
+			}
+		}
+		var kept []tagPair
+		changed := mod.Clear
+		present := make(map[string]bool)
+		for _, p := range pairs {
+			if removed[p.key] {
+				changed = true
+				continue
+			}
+			kept = append(kept, p)
+			present[p.key] = true
+		}
+		if IsTaggableField(f) {
+			for _, key := range mod.Add {
+				if !present[key] {
+					kept = append(kept, tagPair{key, TransformName(f.Names[0].Name, mod.Transform)})
+					present[key] = true
+					changed = true
+				}
+			}
+		}
+		switch {
+		case !changed:
+		case f.Tag != nil && len(kept) == 0:
+			// Remove the tag, and the space before it.
+			start := tok.Offset(f.Type.End())
+			replacements = append(replacements, replacement{start, tok.Offset(f.Tag.End()), ""})
+		case f.Tag != nil:
+			replacements = append(replacements, replacement{tok.Offset(f.Tag.Pos()), tok.Offset(f.Tag.End()), formatStructTag(kept)})
+		case len(kept) > 0:
+			end := tok.Offset(f.Type.End())
+			replacements = append(replacements, replacement{end, end, " " + formatStructTag(kept)})
+		}
+	}
+	if len(replacements) == 0 {
+		return nil, false
+	}
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start < replacements[j].start
+	})
+
+	st := enclosingStructType(file, fields[0])
+	stStart, stEnd := tok.Offset(st.Pos()), tok.Offset(st.End())
+	var buf bytes.Buffer
+	last := stStart
+	for _, r := range replacements {
+		buf.Write(src[last:r.start])
+		buf.WriteString(r.text)
+		last = r.end
+	}
+	buf.Write(src[last:stEnd])
+	newStruct := formatStructType(buf.Bytes(), lineIndent(src, tok, st.Pos()))
+
+	var newSrc bytes.Buffer
+	newSrc.Write(src[:stStart])
+	newSrc.WriteString(newStruct)
+	newSrc.Write(src[stEnd:])
+	return newSrc.Bytes(), true
+}
+
+// enclosingStructType returns the struct type declaring field.
+func enclosingStructType(file *ast.File, field *ast.Field) *ast.StructType {
+	path, _ := astutil.PathEnclosingInterval(file, field.Pos(), field.End())
+	for _, n := range path {
+		if st, ok := n.(*ast.StructType); ok && st.Fields != nil {
+			for _, f := range st.Fields.List {
+				if f == field {
+					return st
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// formatStructType formats the source of a struct type, whose first line is
+// indented by indent, or returns it unchanged if it can not be formatted.
+func formatStructType(src []byte, indent string) string {
+	fset := token.NewFileSet()
+	// Parse the struct type in a declaration, to keep its comments.
+	const prefix = "package p\nvar _ "
+	file, err := parser.ParseFile(fset, "", prefix+string(src), parser.ParseComments)
+	if err != nil {
+		return string(src)
+	}
+	spec := file.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, &printer.CommentedNode{Node: spec.Type, Comments: file.Comments}); err != nil {
+		return string(src)
+	}
+	return reindent(buf.String(), "", indent)
+}
@@ -0,0 +1,136 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/internal/span"
+)
+
+func TestTransformName(t *testing.T) {
+	for _, test := range []struct {
+		name, transform, want string
+	}{
+		{"UserID", CamelCase, "userID"},
+		{"UserID", SnakeCase, "user_id"},
+		{"UserID", PascalCase, "UserID"},
+		{"UserID", LispCase, "user-id"},
+		{"UserID", KeepCase, "UserID"},
+		{"HTTPServer", CamelCase, "httpServer"},
+		{"HTTPServer", SnakeCase, "http_server"},
+		{"Name", CamelCase, "name"},
+		{"X", SnakeCase, "x"},
+		{"Version2", SnakeCase, "version2"},
+	} {
+		if got := TransformName(test.name, test.transform); got != test.want {
+			t.Errorf("TransformName(%q, %q) = %q, want %q", test.name, test.transform, got, test.want)
+		}
+	}
+}
+
+func TestModifyTags(t *testing.T) {
+	const src = `package p
+
+type T struct {
+	ID       int
+	UserName string ` + "`xml:\"user\"`" + `
+	Email    string ` + "`json:\"mail,omitempty\"`" + `
+	a, b     int
+	Bad      bool ` + "`json:bad`" + `
+}
+`
+	for _, test := range []struct {
+		desc string
+		sel  string // field selected, or the whole struct
+		mod  TagModification
+		want string // the struct type, or "" if unchanged
+	}{
+		{
+			desc: "add json camelcase",
+			mod:  TagModification{Add: []string{"json"}, Transform: CamelCase},
+			want: `type T struct {
+	ID       int    ` + "`json:\"id\"`" + `
+	UserName string ` + "`xml:\"user\" json:\"userName\"`" + `
+	Email    string ` + "`json:\"mail,omitempty\"`" + `
+	a, b     int
+	Bad      bool ` + "`json:bad`" + `
+}`,
+		},
+		{
+			desc: "add selected field",
+			sel:  "UserName",
+			mod:  TagModification{Add: []string{"yaml"}, Transform: SnakeCase},
+			want: `type T struct {
+	ID       int
+	UserName string ` + "`xml:\"user\" yaml:\"user_name\"`" + `
+	Email    string ` + "`json:\"mail,omitempty\"`" + `
+	a, b     int
+	Bad      bool ` + "`json:bad`" + `
+}`,
+		},
+		{
+			desc: "remove json",
+			mod:  TagModification{Remove: []string{"json"}},
+			want: `type T struct {
+	ID       int
+	UserName string ` + "`xml:\"user\"`" + `
+	Email    string
+	a, b     int
+	Bad      bool ` + "`json:bad`" + `
+}`,
+		},
+		{
+			desc: "clear",
+			mod:  TagModification{Clear: true},
+			want: `type T struct {
+	ID       int
+	UserName string
+	Email    string
+	a, b     int
+	Bad      bool
+}`,
+		},
+		{
+			desc: "remove absent key",
+			mod:  TagModification{Remove: []string{"db"}},
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tok := fset.File(file.Pos())
+			var rng span.Range
+			if test.sel != "" {
+				pos := tok.Pos(strings.Index(src, test.sel))
+				rng = span.NewRange(fset, pos, pos+token.Pos(len(test.sel)))
+			} else {
+				pos := tok.Pos(strings.Index(src, "struct"))
+				rng = span.NewRange(fset, pos, pos)
+			}
+			fields := StructFieldsAt(file, rng)
+			got, ok := modifyTags(tok, []byte(src), file, fields, test.mod)
+			if test.want == "" {
+				if ok {
+					t.Errorf("modifyTags changed the tags:\n%s", got)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("modifyTags left the tags unchanged")
+			}
+			want := "package p\n\n" + test.want + "\n"
+			if string(got) != want {
+				t.Errorf("modifyTags returned:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
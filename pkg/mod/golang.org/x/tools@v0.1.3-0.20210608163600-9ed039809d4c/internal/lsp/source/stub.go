@@ -0,0 +1,288 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/internal/lsp/analysis/stubmethods"
+	"golang.org/x/tools/internal/span"
+	errors "golang.org/x/xerrors"
+)
+
+// stubMethods declares the methods that the concrete type of the value in
+// rng is missing to implement the interface type it is used as. The
+// methods are declared after the declaration of the type, which may be in
+// another file of the package, and panic when called.
+func stubMethods(fset *token.FileSet, rng span.Range, pgf *ParsedGoFile, pkg Package) (*analysis.SuggestedFix, error) {
+	path, _ := astutil.PathEnclosingInterval(pgf.File, rng.Start, rng.End)
+	si := stubmethods.GetStubInfo(pkg.GetTypesInfo(), path)
+	if si == nil {
+		return nil, errors.Errorf("no interface to implement at %s", fset.Position(rng.Start))
+	}
+	tname := si.Concrete.Obj()
+	if tname.Pkg() != pkg.GetTypes() {
+		return nil, errors.Errorf("cannot declare methods of %s in package %s", tname.Name(), pkg.PkgPath())
+	}
+
+	// Find the declaration of the type.
+	var declFile *ParsedGoFile
+	for _, f := range pkg.CompiledGoFiles() {
+		if f.File.Pos() <= tname.Pos() && tname.Pos() < f.File.End() {
+			declFile = f
+			break
+		}
+	}
+	if declFile == nil {
+		return nil, errors.Errorf("no file declares %s", tname.Name())
+	}
+	declPath, _ := astutil.PathEnclosingInterval(declFile.File, tname.Pos(), tname.Pos())
+	var decl *ast.GenDecl
+	for _, n := range declPath {
+		if d, ok := n.(*ast.GenDecl); ok && d.Tok == token.TYPE {
+			decl = d
+			break
+		}
+	}
+	if decl == nil {
+		return nil, errors.Errorf("%s is not declared at the package level", tname.Name())
+	}
+
+	// The concrete value implements the interface through the method set
+	// of its type, so a value needs value receivers, and lacks the promoted
+	// methods with pointer receivers.
+	T := types.Type(si.Concrete)
+	if si.Pointer {
+		T = types.NewPointer(T)
+	}
+	var missing []*types.Func
+	mset := types.NewMethodSet(si.Interface)
+	for i := 0; i < mset.Len(); i++ {
+		m := mset.At(i).Obj().(*types.Func)
+		if declares(si.Concrete, m.Name()) {
+			continue // declared, but with a different signature or receiver
+		}
+		if obj, _, _ := types.LookupFieldOrMethod(T, false, m.Pkg(), m.Name()); obj != nil && types.Identical(obj.Type(), m.Type()) {
+			continue
+		}
+		missing = append(missing, m)
+	}
+	if len(missing) == 0 {
+		return nil, errors.Errorf("%s has no missing methods", tname.Name())
+	}
+
+	recvName, pointer := receiver(si.Concrete)
+	if !si.Pointer {
+		pointer = false
+	}
+	recv := tname.Name()
+	if pointer {
+		recv = "*" + recv
+	}
+	var ifaceName string
+	if _, ok := si.Interface.(*types.Named); ok {
+		ifaceName = types.TypeString(si.Interface, types.RelativeTo(pkg.GetTypes()))
+	}
+
+	imp := newStubImports(declFile.File, pkg.GetTypes())
+	var buf bytes.Buffer
+	for _, m := range missing {
+		sig := m.Type().(*types.Signature)
+		name := recvName
+		for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+			for i := 0; i < tuple.Len(); i++ {
+				if tuple.At(i).Name() == name {
+					name = ""
+				}
+			}
+		}
+		buf.WriteString("\n\n")
+		if ifaceName != "" {
+			fmt.Fprintf(&buf, "// %s implements %s.\n", m.Name(), ifaceName)
+		}
+		if name != "" {
+			fmt.Fprintf(&buf, "func (%s %s) %s", name, recv, m.Name())
+		} else {
+			fmt.Fprintf(&buf, "func (%s) %s", recv, m.Name())
+		}
+		types.WriteSignature(&buf, sig, imp.qualifier)
+		buf.WriteString(" {\n\tpanic(\"unimplemented\")\n}")
+	}
+	edits := imp.edits(declFile)
+	edits = append(edits, analysis.TextEdit{
+		Pos:     decl.End(),
+		End:     decl.End(),
+		NewText: buf.Bytes(),
+	})
+	return &analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Implement %s", types.TypeString(si.Interface, types.RelativeTo(pkg.GetTypes()))),
+		TextEdits: edits,
+	}, nil
+}
+
+// declares reports whether named declares a method or, if it is a struct, a
+// field with the name, so that no method of that name can be added.
+func declares(named *types.Named, name string) bool {
+	for i := 0; i < named.NumMethods(); i++ {
+		if named.Method(i).Name() == name {
+			return true
+		}
+	}
+	if s, ok := named.Underlying().(*types.Struct); ok {
+		for i := 0; i < s.NumFields(); i++ {
+			if s.Field(i).Name() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// receiver returns the receiver name and kind used by the methods of
+// named: the first non-blank receiver name, and whether any method has a
+// pointer receiver. Types without methods get the lowercased first letter
+// of their name, and pointer receivers.
+func receiver(named *types.Named) (string, bool) {
+	if named.NumMethods() == 0 {
+		r, _ := utf8.DecodeRuneInString(named.Obj().Name())
+		return string(unicode.ToLower(r)), true
+	}
+	var name string
+	var pointer bool
+	for i := 0; i < named.NumMethods(); i++ {
+		recv := named.Method(i).Type().(*types.Signature).Recv()
+		if name == "" && recv.Name() != "_" {
+			name = recv.Name()
+		}
+		if _, ok := recv.Type().(*types.Pointer); ok {
+			pointer = true
+		}
+	}
+	return name, pointer
+}
+
+// stubImports qualifies the types of the stubs by the names of the imports
+// of a file, and records the imports that the file is missing. A missing
+// import whose name is already used in the file gets a unique alias.
+type stubImports struct {
+	file    *ast.File
+	pkg     *types.Package
+	missing map[string]string // package path to name
+}
+
+func newStubImports(file *ast.File, pkg *types.Package) *stubImports {
+	return &stubImports{file: file, pkg: pkg, missing: make(map[string]string)}
+}
+
+func (s *stubImports) qualifier(p *types.Package) string {
+	if p == s.pkg {
+		return ""
+	}
+	for _, spec := range s.file.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err != nil || path != p.Path() {
+			continue
+		}
+		if spec.Name == nil {
+			return p.Name()
+		}
+		switch spec.Name.Name {
+		case "_":
+			continue
+		case ".":
+			return ""
+		}
+		return spec.Name.Name
+	}
+	if name, ok := s.missing[p.Path()]; ok {
+		return name
+	}
+	name := p.Name()
+	for i := 2; s.used(name); i++ {
+		name = fmt.Sprintf("%s%d", p.Name(), i)
+	}
+	s.missing[p.Path()] = name
+	return name
+}
+
+// used reports whether name is declared in the package, or imported by the
+// file, including the imports that are missing.
+func (s *stubImports) used(name string) bool {
+	if s.pkg.Scope().Lookup(name) != nil {
+		return true
+	}
+	for _, spec := range s.file.Imports {
+		ipath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		imported := path.Base(ipath)
+		for _, imp := range s.pkg.Imports() {
+			if imp.Path() == ipath {
+				imported = imp.Name()
+			}
+		}
+		if spec.Name != nil {
+			imported = spec.Name.Name
+		}
+		if imported == name {
+			return true
+		}
+	}
+	for _, missing := range s.missing {
+		if missing == name {
+			return true
+		}
+	}
+	return false
+}
+
+// edits returns the edits that add the missing imports to pgf.
+func (s *stubImports) edits(pgf *ParsedGoFile) []analysis.TextEdit {
+	if len(s.missing) == 0 {
+		return nil
+	}
+	var specs []string
+	for p, name := range s.missing {
+		spec := strconv.Quote(p)
+		if name != path.Base(p) {
+			spec = name + " " + spec
+		}
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+
+	var last *ast.GenDecl
+	for _, decl := range pgf.File.Decls {
+		if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.IMPORT {
+			last = d
+		}
+	}
+	switch {
+	case last == nil:
+		text := "\n\nimport " + specs[0]
+		if len(specs) > 1 {
+			text = "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"
+		}
+		return []analysis.TextEdit{{Pos: pgf.File.Name.End(), End: pgf.File.Name.End(), NewText: []byte(text)}}
+	case last.Rparen.IsValid():
+		text := "\t" + strings.Join(specs, "\n\t") + "\n"
+		return []analysis.TextEdit{{Pos: last.Rparen, End: last.Rparen, NewText: []byte(text)}}
+	default:
+		text := "\nimport " + strings.Join(specs, "\nimport ")
+		return []analysis.TextEdit{{Pos: last.End(), End: last.End(), NewText: []byte(text)}}
+	}
+}
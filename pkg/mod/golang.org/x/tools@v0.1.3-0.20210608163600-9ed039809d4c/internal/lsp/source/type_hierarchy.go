@@ -0,0 +1,226 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	errors "golang.org/x/xerrors"
+)
+
+// PrepareTypeHierarchy returns an array of TypeHierarchyItem for the named
+// type at the given position within the file.
+func PrepareTypeHierarchy(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.PrepareTypeHierarchy")
+	defer done()
+
+	obj, pkg, err := typeNameAtPos(ctx, snapshot, fh, pos)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	item, err := toTypeHierarchyItem(snapshot, pkg, obj)
+	if err != nil {
+		return nil, err
+	}
+	return []protocol.TypeHierarchyItem{item}, nil
+}
+
+// Supertypes returns the types embedded by the named type at the given
+// position, and the interfaces that it implements.
+func Supertypes(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.Supertypes")
+	defer done()
+
+	return typeHierarchy(ctx, snapshot, fh, pos, func(obj, cand *types.TypeName) bool {
+		return isSubtype(obj, cand)
+	})
+}
+
+// Subtypes returns the types embedding the named type at the given position
+// and, if it is an interface, the types that implement it.
+func Subtypes(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "source.Subtypes")
+	defer done()
+
+	return typeHierarchy(ctx, snapshot, fh, pos, func(obj, cand *types.TypeName) bool {
+		return isSubtype(cand, obj)
+	})
+}
+
+// typeHierarchy returns the items for all named types in the scope
+// configured by the TypeHierarchyScope option that are related to the
+// named type at the given position according to the related function.
+func typeHierarchy(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position, related func(obj, cand *types.TypeName) bool) ([]protocol.TypeHierarchyItem, error) {
+	obj, _, err := typeNameAtPos(ctx, snapshot, fh, pos)
+	if err != nil || obj == nil {
+		return nil, err
+	}
+
+	knownPkgs, err := snapshot.KnownPackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make(map[*types.Package]Package)
+	for _, pkg := range knownPkgs {
+		pkgs[pkg.GetTypes()] = pkg
+	}
+	searchPkgs := knownPkgs
+	if snapshot.View().Options().TypeHierarchyScope != AllTypeHierarchy {
+		if searchPkgs, err = snapshot.WorkspacePackages(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Embedded types are always reported as supertypes, even if they
+	// are declared outside of the search scope.
+	candidates := embeddedTypeNames(obj.Type())
+	for _, pkg := range searchPkgs {
+		for _, def := range pkg.GetTypesInfo().Defs {
+			cand, ok := def.(*types.TypeName)
+			// We ignore aliases 'type M = N' to avoid duplicate reporting
+			// of the Named type N.
+			if !ok || cand.IsAlias() {
+				continue
+			}
+			if _, ok := cand.Type().(*types.Named); ok {
+				candidates = append(candidates, cand)
+			}
+		}
+	}
+
+	var (
+		items []protocol.TypeHierarchyItem
+		seen  = make(map[token.Position]bool)
+		fset  = snapshot.FileSet()
+	)
+	for _, cand := range candidates {
+		if cand.Pkg() == nil || pkgs[cand.Pkg()] == nil {
+			continue
+		}
+		pos := fset.Position(cand.Pos())
+		if seen[pos] || !related(obj, cand) {
+			continue
+		}
+		seen[pos] = true
+		item, err := toTypeHierarchyItem(snapshot, pkgs[cand.Pkg()], cand)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		li, lj := items[i], items[j]
+		if li.URI == lj.URI {
+			return protocol.CompareRange(li.SelectionRange, lj.SelectionRange) < 0
+		}
+		return li.URI < lj.URI
+	})
+	return items, nil
+}
+
+// typeNameAtPos returns the named type referenced at the given position,
+// along with the package that declares it. Aliases are resolved to the
+// type they denote.
+func typeNameAtPos(ctx context.Context, snapshot Snapshot, fh FileHandle, pos protocol.Position) (*types.TypeName, Package, error) {
+	qos, err := qualifiedObjsAtProtocolPos(ctx, snapshot, fh, pos)
+	if err != nil {
+		if errors.Is(err, ErrNoIdentFound) || errors.Is(err, errNoObjectFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	for _, qo := range qos {
+		obj, ok := qo.obj.(*types.TypeName)
+		if !ok {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() == nil {
+			continue
+		}
+		if named.Obj() == obj {
+			return obj, qo.pkg, nil
+		}
+		// The alias may be declared in a different package than its type.
+		if pkg, err := FindPackageFromPos(ctx, snapshot, named.Obj().Pos()); err == nil {
+			return named.Obj(), pkg, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// isSubtype reports whether sub embeds super, or whether super is a
+// non-empty interface implemented by sub.
+func isSubtype(sub, super *types.TypeName) bool {
+	if sub == super || !isValid(sub.Type()) || !isValid(super.Type()) {
+		return false
+	}
+	for _, embedded := range embeddedTypeNames(sub.Type()) {
+		if embedded == super {
+			return true
+		}
+	}
+	if !IsInterface(super.Type()) || types.NewMethodSet(super.Type()).Len() == 0 {
+		return false
+	}
+	return types.AssignableTo(ensurePointer(sub.Type()), super.Type())
+}
+
+// isValid reports whether T was successfully type-checked. Invalid types are
+// assignable to anything, so they must not be reported as subtypes.
+func isValid(T types.Type) bool {
+	return T.Underlying() != types.Typ[types.Invalid]
+}
+
+// embeddedTypeNames returns the named types embedded in the struct or
+// interface type T.
+func embeddedTypeNames(T types.Type) []*types.TypeName {
+	var names []*types.TypeName
+	add := func(typ types.Type) {
+		if named, ok := Deref(typ).(*types.Named); ok {
+			names = append(names, named.Obj())
+		}
+	}
+	switch T := T.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			if f := T.Field(i); f.Embedded() {
+				add(f.Type())
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < T.NumEmbeddeds(); i++ {
+			add(T.EmbeddedType(i))
+		}
+	}
+	return names
+}
+
+func toTypeHierarchyItem(snapshot Snapshot, pkg Package, obj *types.TypeName) (protocol.TypeHierarchyItem, error) {
+	declMappedRange, err := objToMappedRange(snapshot, pkg, obj)
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	rng, err := declMappedRange.Range()
+	if err != nil {
+		return protocol.TypeHierarchyItem{}, err
+	}
+	return protocol.TypeHierarchyItem{
+		Name:           obj.Name(),
+		Kind:           typeToKind(obj.Type()),
+		Tags:           []protocol.SymbolTag{},
+		Detail:         fmt.Sprintf("%s • %s", obj.Pkg().Path(), filepath.Base(declMappedRange.URI().Filename())),
+		URI:            protocol.DocumentURI(declMappedRange.URI()),
+		Range:          rng,
+		SelectionRange: rng,
+	}, nil
+}
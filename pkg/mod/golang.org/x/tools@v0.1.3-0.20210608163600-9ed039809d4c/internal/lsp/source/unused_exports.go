@@ -0,0 +1,221 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/span"
+)
+
+// An UnusedExport is an exported symbol of a workspace package that no
+// other package refers to.
+type UnusedExport struct {
+	Package  string // the package path
+	Name     string // Name, or Type.Member for fields and methods
+	Kind     string // "const", "var", "func", "type", "field" or "method"
+	Location protocol.Location
+}
+
+// UnusedExports returns the exported symbols of the workspace packages of
+// the snapshots that are not referred to outside of their package. The
+// snapshots are those of all the views of a session, since a workspace
+// module may be used by another one, for example through a replace
+// directive.
+//
+// Some symbols are used without a reference, or by code that is not in
+// the workspace, and are never reported:
+//   - the symbols of main packages, which cannot be imported;
+//   - the symbols declared in test files, such as test helpers;
+//   - the fields that have a struct tag, as they are used by reflection;
+//   - the methods that implement a method of a known interface.
+//
+// The symbols of generated files are not reported either.
+func UnusedExports(ctx context.Context, snapshots []Snapshot) ([]UnusedExport, error) {
+	ctx, done := event.Start(ctx, "source.UnusedExports")
+	defer done()
+
+	// Test variants and views type check the same declarations again, so
+	// objects are identified by the position of their declaration.
+	used := make(map[token.Position]bool)
+	workspace := make([][]Package, len(snapshots))
+	var known []Package
+	for i, snapshot := range snapshots {
+		pkgs, err := snapshot.WorkspacePackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		workspace[i] = pkgs
+		k, err := snapshot.KnownPackages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		known = append(known, k...)
+		addUses(used, snapshot.FileSet(), pkgs)
+	}
+	interfaces := knownInterfaces(known)
+
+	var result []UnusedExport
+	seen := make(map[token.Position]bool)
+	for i, snapshot := range snapshots {
+		result = append(result, unusedExports(ctx, snapshot, workspace[i], used, seen, interfaces)...)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Location.URI != result[j].Location.URI {
+			return result[i].Location.URI < result[j].Location.URI
+		}
+		return protocol.CompareRange(result[i].Location.Range, result[j].Location.Range) < 0
+	})
+	return result, nil
+}
+
+// addUses records in used the objects of pkgs that are used outside of
+// their package.
+func addUses(used map[token.Position]bool, fset *token.FileSet, pkgs []Package) {
+	use := func(obj types.Object) {
+		used[fset.Position(obj.Pos())] = true
+	}
+	for _, pkg := range pkgs {
+		path := strings.TrimSuffix(pkg.PkgPath(), "_test")
+		outside := func(obj types.Object) bool {
+			return obj.Pkg() != nil && obj.Pkg().Path() != path && obj.Pkg().Path() != pkg.PkgPath()
+		}
+		info := pkg.GetTypesInfo()
+		for _, obj := range info.Uses {
+			if outside(obj) {
+				use(obj)
+			}
+		}
+		// A type is used through its members, for example when its values
+		// are obtained from a function.
+		for _, sel := range info.Selections {
+			if named := namedType(sel.Recv()); named != nil && outside(sel.Obj()) {
+				use(named.Obj())
+			}
+		}
+	}
+}
+
+// unusedExports returns the exported symbols of pkgs, the workspace
+// packages of snapshot, that are neither used nor seen yet.
+func unusedExports(ctx context.Context, snapshot Snapshot, pkgs []Package, used, seen map[token.Position]bool, interfaces map[string][]*types.Func) []UnusedExport {
+	fset := snapshot.FileSet()
+
+	var result []UnusedExport
+	report := func(pkg Package, obj types.Object, name string) {
+		pos := fset.Position(obj.Pos())
+		if used[pos] || seen[pos] || strings.HasSuffix(pos.Filename, "_test.go") {
+			return
+		}
+		seen[pos] = true
+		pgf, err := pkg.File(span.URIFromPath(pos.Filename))
+		if err != nil || IsGenerated(ctx, snapshot, pgf.URI) {
+			return
+		}
+		rng, err := NewMappedRange(fset, pgf.Mapper, obj.Pos(), obj.Pos()+token.Pos(len(obj.Name()))).Range()
+		if err != nil {
+			return
+		}
+		result = append(result, UnusedExport{
+			Package:  obj.Pkg().Path(),
+			Name:     name,
+			Kind:     objectKind(obj),
+			Location: protocol.Location{URI: protocol.URIFromSpanURI(pgf.URI), Range: rng},
+		})
+	}
+	for _, pkg := range pkgs {
+		if pkg.Name() == "main" || pkg.ForTest() != "" || strings.HasSuffix(pkg.PkgPath(), "_test") {
+			continue
+		}
+		scope := pkg.GetTypes().Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !obj.Exported() {
+				continue
+			}
+			report(pkg, obj, name)
+			tname, ok := obj.(*types.TypeName)
+			if !ok || tname.IsAlias() {
+				continue
+			}
+			named, ok := tname.Type().(*types.Named)
+			if !ok {
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				m := named.Method(i)
+				if m.Exported() && !implementsKnown(m, interfaces) {
+					report(pkg, m, name+"."+m.Name())
+				}
+			}
+			if s, ok := named.Underlying().(*types.Struct); ok {
+				for i := 0; i < s.NumFields(); i++ {
+					f := s.Field(i)
+					if f.Exported() && !f.Embedded() && s.Tag(i) == "" {
+						report(pkg, f, name+"."+f.Name())
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+func namedType(T types.Type) *types.Named {
+	if ptr, ok := T.(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	named, _ := T.(*types.Named)
+	return named
+}
+
+// knownInterfaces returns the methods of the interfaces declared at the
+// package level of pkgs, by name.
+func knownInterfaces(pkgs []Package) map[string][]*types.Func {
+	methods := make(map[string][]*types.Func)
+	seen := make(map[*types.Package]bool)
+	for _, pkg := range pkgs {
+		if seen[pkg.GetTypes()] {
+			continue
+		}
+		seen[pkg.GetTypes()] = true
+		scope := pkg.GetTypes().Scope()
+		for _, name := range scope.Names() {
+			tname, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			iface, ok := tname.Type().Underlying().(*types.Interface)
+			if !ok {
+				continue
+			}
+			for i := 0; i < iface.NumMethods(); i++ {
+				m := iface.Method(i)
+				methods[m.Name()] = append(methods[m.Name()], m)
+			}
+		}
+	}
+	return methods
+}
+
+// implementsKnown reports whether method m has the name and signature of
+// a method of one of the interfaces.
+func implementsKnown(m *types.Func, interfaces map[string][]*types.Func) bool {
+	sig := m.Type().(*types.Signature)
+	for _, im := range interfaces[m.Name()] {
+		isig := im.Type().(*types.Signature)
+		if types.Identical(types.NewSignature(nil, sig.Params(), sig.Results(), sig.Variadic()),
+			types.NewSignature(nil, isig.Params(), isig.Results(), isig.Variadic())) {
+			return true
+		}
+	}
+	return false
+}
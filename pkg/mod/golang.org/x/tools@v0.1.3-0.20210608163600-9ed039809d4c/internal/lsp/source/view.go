@@ -125,6 +125,16 @@ type Snapshot interface {
 	// the given go.mod file.
 	ModTidy(ctx context.Context, pm *ParsedModule) (*TidiedModule, error)
 
+	// VulnDatabase returns the entries of the vulnerability database of the
+	// VulnDatabase option. The files of the database are checked for changes
+	// once per snapshot, and parsed again only when they change.
+	VulnDatabase(ctx context.Context) ([]*OSVEntry, error)
+
+	// PackageExamples returns the examples declared in the _test.go files
+	// of the package with the given path, which are known only for the
+	// packages whose tests are loaded.
+	PackageExamples(ctx context.Context, pkgPath string) ([]*Example, error)
+
 	// GoModForFile returns the URI of the go.mod file for the given URI.
 	GoModForFile(uri span.URI) span.URI
 
@@ -158,10 +168,32 @@ type Snapshot interface {
 	// WorkspacePackages returns the snapshot's top-level packages.
 	WorkspacePackages(ctx context.Context) ([]Package, error)
 
+	// StoredWorkspacePackages returns the snapshot's top-level packages,
+	// like WorkspacePackages, except for those whose diagnostics are stored
+	// in the persistent cache because their inputs are unchanged since they
+	// were diagnosed, or are computed against the stored export data of
+	// their dependencies. These are returned separately, without
+	// type-checking them, or their dependencies, from source.
+	StoredWorkspacePackages(ctx context.Context) ([]Package, []*StoredPackage, error)
+
+	// PersistDiagnostics stores the diagnostics of pkg, as returned by
+	// DiagnosePackage, in the persistent cache, if they only depend on the
+	// inputs of the package.
+	PersistDiagnostics(ctx context.Context, pkg Package, diags map[span.URI][]*Diagnostic)
+
 	// GetCriticalError returns any critical errors in the workspace.
 	GetCriticalError(ctx context.Context) *CriticalError
 }
 
+// A StoredPackage is a top-level package whose diagnostics, as returned by
+// DiagnosePackage, were read from the persistent cache, or computed with
+// it.
+type StoredPackage struct {
+	ID              string
+	CompiledGoFiles []span.URI
+	Diagnostics     map[span.URI][]*Diagnostic
+}
+
 // PackageFilter sets how a package is filtered out from a set of packages
 // containing a given file.
 type PackageFilter int
@@ -247,6 +279,9 @@ type View interface {
 	// by the GOPRIVATE environment variable.
 	IsGoPrivatePath(path string) bool
 
+	// GoModCache returns the module cache directory, GOMODCACHE.
+	GoModCache() string
+
 	// ModuleUpgrades returns known module upgrades.
 	ModuleUpgrades() map[string]string
 
@@ -291,6 +326,15 @@ type TidiedModule struct {
 	TidiedContent []byte
 }
 
+// An Example is an example function of a package.
+type Example struct {
+	// The name of the example function, without "Example".
+	Name string
+	// The body of the example, without its braces and expected output.
+	Code   string
+	Output string
+}
+
 // Session represents a single connection from a client.
 // This is the level at which things like open files are maintained on behalf
 // of the client.
@@ -616,6 +660,9 @@ const (
 	ModTidyError             DiagnosticSource = "go mod tidy"
 	OptimizationDetailsError DiagnosticSource = "optimizer details"
 	UpgradeNotification      DiagnosticSource = "upgrade available"
+	Vulnerability            DiagnosticSource = "vulnerability"
+	RetractedVersion         DiagnosticSource = "retracted version"
+	DeprecatedModule         DiagnosticSource = "deprecated module"
 )
 
 func AnalyzerErrorKind(name string) DiagnosticSource {
@@ -0,0 +1,205 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// OSVEntry is the part of a vulnerability report in the OSV format that
+// gopls uses. See https://ossf.github.io/osv-schema.
+type OSVEntry struct {
+	ID         string        `json:"id"`
+	Summary    string        `json:"summary"`
+	Details    string        `json:"details"`
+	Aliases    []string      `json:"aliases"`
+	Affected   []OSVAffected `json:"affected"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+	DatabaseSpecific struct {
+		URL string `json:"url"`
+	} `json:"database_specific"`
+}
+
+// OSVAffected describes the affected versions of a module, and the
+// affected packages.
+type OSVAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string     `json:"type"`
+		Events []OSVEvent `json:"events"`
+	} `json:"ranges"`
+	EcosystemSpecific struct {
+		// Symbols is the older form of Imports, for a package named
+		// by Package.Name.
+		Symbols []string    `json:"symbols"`
+		Imports []OSVImport `json:"imports"`
+	} `json:"ecosystem_specific"`
+}
+
+// OSVEvent is an event of an affected range, which sets exactly one of
+// its fields.
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// OSVImport is an affected package. An empty list of symbols means that
+// the whole package is affected.
+type OSVImport struct {
+	Path    string   `json:"path"`
+	Symbols []string `json:"symbols"`
+}
+
+// URL returns a link to the description of the vulnerability.
+func (e *OSVEntry) URL() string {
+	if e.DatabaseSpecific.URL != "" {
+		return e.DatabaseSpecific.URL
+	}
+	for _, ref := range e.References {
+		if ref.Type == "ADVISORY" || ref.Type == "WEB" {
+			return ref.URL
+		}
+	}
+	return ""
+}
+
+// Imports returns the packages affected by a.
+func (a *OSVAffected) Imports() []OSVImport {
+	if len(a.EcosystemSpecific.Imports) > 0 {
+		return a.EcosystemSpecific.Imports
+	}
+	return []OSVImport{{Path: a.Package.Name, Symbols: a.EcosystemSpecific.Symbols}}
+}
+
+// Affects reports whether version v of module modPath is affected by a,
+// along with the earliest version after v that fixes it, if any.
+//
+// In each range, v is affected from an introduced event up to the next
+// fixed event, exclusive, or last_affected event, inclusive. A limit event
+// ends the range: no version from the limit on is affected by it.
+func (a *OSVAffected) Affects(modPath, v string) (bool, string) {
+	if a.Package.Ecosystem != "" && a.Package.Ecosystem != "Go" {
+		return false, ""
+	}
+	// Older entries name the affected package rather than the module.
+	if name := a.Package.Name; name != modPath && !strings.HasPrefix(name, modPath+"/") {
+		return false, ""
+	}
+	affected, fixed := false, ""
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "" {
+			continue
+		}
+		var events []OSVEvent
+		for _, e := range r.Events {
+			if e.version() != "" {
+				events = append(events, e)
+			}
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return semver.Compare(events[i].version(), events[j].version()) < 0
+		})
+		in := false
+	events:
+		for _, e := range events {
+			cmp := semver.Compare(e.version(), v)
+			switch {
+			case e.Introduced != "":
+				if cmp > 0 {
+					break events
+				}
+				in = true
+			case e.Fixed != "":
+				if cmp > 0 {
+					if in && (fixed == "" || semver.Compare(e.version(), fixed) < 0) {
+						fixed = e.version()
+					}
+					break events
+				}
+				in = false
+			case e.LastAffected != "":
+				if cmp >= 0 {
+					break events
+				}
+				in = false
+			case e.Limit != "":
+				if cmp <= 0 {
+					in = false
+				}
+				break events
+			}
+		}
+		affected = affected || in
+	}
+	if !affected {
+		return false, ""
+	}
+	return true, fixed
+}
+
+// version returns the canonical semantic version of e, or "" for the limit
+// "*", which bounds nothing. The introduced version "0" stands for the
+// start of history.
+func (e OSVEvent) version() string {
+	var v string
+	switch {
+	case e.Introduced != "":
+		v = e.Introduced
+	case e.Fixed != "":
+		v = e.Fixed
+	case e.LastAffected != "":
+		v = e.LastAffected
+	case e.Limit != "" && e.Limit != "*":
+		v = e.Limit
+	default:
+		return ""
+	}
+	if v == "0" {
+		return "v0.0.0-0"
+	}
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	return v
+}
+
+// ParseOSV parses the contents of a file of a vulnerability database, which
+// hold either one entry or a list of them. Other JSON files, such as
+// indexes, have no entries.
+func ParseOSV(data []byte) ([]*OSVEntry, error) {
+	data = bytes.TrimSpace(data)
+	var entries []*OSVEntry
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	} else {
+		var e OSVEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	var result []*OSVEntry
+	for _, e := range entries {
+		if e != nil && e.ID != "" && len(e.Affected) > 0 {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
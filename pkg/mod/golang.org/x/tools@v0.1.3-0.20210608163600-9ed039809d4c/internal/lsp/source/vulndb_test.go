@@ -0,0 +1,55 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import "testing"
+
+func TestOSVAffects(t *testing.T) {
+	entries, err := ParseOSV([]byte(`[{
+	"id": "GO-2021-0113",
+	"affected": [{
+		"package": {"name": "golang.org/x/text", "ecosystem": "Go"},
+		"ranges": [{"type": "SEMVER", "events": [
+			{"introduced": "0.4.0"}, {"fixed": "0.4.2"},
+			{"introduced": "0"}, {"fixed": "0.3.7"},
+			{"introduced": "0.5.0"}, {"last_affected": "0.5.3"},
+			{"introduced": "0.6.0"}, {"limit": "0.7.0"}
+		]}],
+		"database_specific": {"url": "https://pkg.go.dev/vuln/GO-2021-0113"}
+	}]
+}, {"id": "not a vulnerability"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ParseOSV returned %d entries, want 1", len(entries))
+	}
+	a := &entries[0].Affected[0]
+	for _, tt := range []struct {
+		version  string
+		affected bool
+		fixed    string
+	}{
+		{"v0.3.0", true, "v0.3.7"},
+		{"v0.3.7", false, ""},
+		{"v0.3.8", false, ""},
+		{"v0.4.1", true, "v0.4.2"},
+		{"v0.4.2", false, ""},
+		{"v0.5.0", true, ""},
+		{"v0.5.3", true, ""},
+		{"v0.5.4", false, ""},
+		{"v0.6.5", true, ""},
+		{"v0.7.0", false, ""},
+		{"v0.8.0", false, ""},
+	} {
+		affected, fixed := a.Affects("golang.org/x/text", tt.version)
+		if affected != tt.affected || fixed != tt.fixed {
+			t.Errorf("Affects(%s) = %v, %q, want %v, %q", tt.version, affected, fixed, tt.affected, tt.fixed)
+		}
+	}
+	if affected, _ := a.Affects("golang.org/x/tools", "v0.3.0"); affected {
+		t.Errorf("golang.org/x/tools is affected by a vulnerability of golang.org/x/text")
+	}
+}
@@ -5,17 +5,270 @@
 package template
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"go/types"
+	"sort"
+	"strings"
 
 	"golang.org/x/tools/internal/lsp/protocol"
 	"golang.org/x/tools/internal/lsp/source"
-	"golang.org/x/tools/internal/lsp/source/completion"
 )
 
-func Completion(ctx context.Context, snapshot source.Snapshot, fh source.VersionedFileHandle, pos protocol.Position, context protocol.CompletionContext) ([]completion.CompletionItem, *completion.Selection, error) {
+// keywords are the words that can start an action.
+var keywords = []string{"block", "break", "continue", "define", "else", "end", "if", "range", "template", "with"}
+
+// Completion offers the fields and methods of the operand before the
+// cursor, or the functions, variables and keywords that can start an operand.
+// Fields and methods are only known for templates whose dot has a type.
+func Completion(ctx context.Context, snapshot source.Snapshot, fh source.VersionedFileHandle, pos protocol.Position, context protocol.CompletionContext) (*protocol.CompletionList, error) {
 	if skipTemplates(snapshot) {
-		return nil, nil, nil
+		return nil, nil
+	}
+	buf, err := fh.Read()
+	if err != nil {
+		return nil, err
+	}
+	p := parseBuffer(buf)
+	if len(p.buf) == 0 {
+		return nil, nil
+	}
+	env, err := newTypeEnv(ctx, snapshot, p)
+	if err != nil {
+		return nil, err
+	}
+	offset := p.FromPosition(pos)
+	items, word := complete(p.buf, offset, env)
+	if items == nil {
+		return nil, nil
+	}
+	rng := p.Range(offset-len(word), len(word))
+	for i := range items {
+		items[i].TextEdit = &protocol.TextEdit{NewText: items[i].Label, Range: rng}
+	}
+	return &protocol.CompletionList{Items: items}, nil
+}
+
+// complete returns the completions at offset in buf, along with the
+// partial word before offset that they replace. The items have no edits.
+func complete(buf []byte, offset int, env *typeEnv) ([]protocol.CompletionItem, string) {
+	start := bytes.LastIndex(buf[:offset], Left)
+	if start < 0 || bytes.Contains(buf[start:offset], Right) {
+		return nil, "" // not in an action
+	}
+	prefix := string(buf[start+len(Left) : offset])
+	if strings.HasPrefix(strings.TrimLeft(prefix, "- "), "/*") {
+		return nil, ""
+	}
+
+	// Replace the action by text, so that the rest of the template parses
+	// and records the dot and the variables in effect at the action. An
+	// action that opens or closes a block is kept as a stand-in.
+	end := offset
+	if i := bytes.Index(buf[offset:], Right); i >= 0 && !bytes.Contains(buf[offset:offset+i], Left) {
+		end = offset + i + len(Right)
+	}
+	var standIn string
+	if words := strings.Fields(strings.TrimLeft(prefix, "-")); len(words) > 1 || len(words) == 1 && strings.HasSuffix(prefix, " ") {
+		switch words[0] {
+		case "if", "range", "with":
+			standIn = "{{if 1}}"
+		case "define", "block":
+			standIn = `{{define " "}}`
+		case "else", "end":
+			standIn = "{{" + words[0] + "}}"
+		}
+	}
+	replaced := append(append([]byte(nil), buf[:start]...), ' ')
+	replaced = append(append(replaced, standIn...), buf[end:]...)
+	q := parseBuffer(replaced)
+	if q.ParseErr != nil {
+		return nil, ""
+	}
+	sc, ok := checkTypes(q, env).scopeAt(start)
+	if !ok {
+		return nil, ""
+	}
+
+	// The operand is the trailing run of identifiers, dots and dollars,
+	// such as ".Items.Na", "$x." or "pri".
+	i := len(prefix)
+	for i > 0 && isOperandByte(prefix[i-1]) {
+		i--
+	}
+	operand := prefix[i:]
+	dot := strings.LastIndexByte(operand, '.')
+	if dot < 0 {
+		var items []protocol.CompletionItem
+		if strings.HasPrefix(operand, "$") {
+			items = variables(sc.vars, operand)
+		} else {
+			items = functions(env.funcs, operand)
+			if strings.TrimLeft(prefix[:i], "- ") == "" {
+				items = append(items, keywordItems(operand)...)
+			}
+		}
+		return items, operand
+	}
+	if i > 0 && prefix[i-1] == ')' {
+		return nil, "" // the fields of a parenthesized pipeline
+	}
+	base, partial := operand[:dot], operand[dot+1:]
+	var T types.Type
+	var names []string
+	switch {
+	case base == "":
+		T = sc.dot
+	case base[0] == '.':
+		T = sc.dot
+		names = strings.Split(base[1:], ".")
+	case base[0] == '$':
+		names = strings.Split(base, ".")
+		T, names = sc.vars[names[0]], names[1:]
+	default:
+		return nil, ""
+	}
+	for _, name := range names {
+		var ok bool
+		if T, _, ok = lookupField(T, name); !ok || T == nil {
+			return nil, ""
+		}
+	}
+	return members(T, partial), partial
+}
+
+func isOperandByte(b byte) bool {
+	return b == '.' || b == '$' || b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// members returns the exported fields and methods of T that start with
+// prefix.
+func members(T types.Type, prefix string) []protocol.CompletionItem {
+	if T == nil {
+		return nil
+	}
+	var names []string
+	for _, fn := range methods(T) {
+		names = append(names, fn.Name())
+	}
+	seen := make(map[types.Type]bool)
+	var addFields func(T types.Type)
+	addFields = func(T types.Type) {
+		if ptr, ok := T.Underlying().(*types.Pointer); ok {
+			T = ptr.Elem()
+		}
+		if seen[T] {
+			return
+		}
+		seen[T] = true
+		s, ok := T.Underlying().(*types.Struct)
+		if !ok {
+			return
+		}
+		for i := 0; i < s.NumFields(); i++ {
+			f := s.Field(i)
+			names = append(names, f.Name())
+			if f.Embedded() {
+				addFields(f.Type())
+			}
+		}
+	}
+	addFields(T)
+
+	var items []protocol.CompletionItem
+	done := make(map[string]bool)
+	for _, name := range names {
+		if done[name] || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		done[name] = true
+		F, obj, ok := lookupField(T, name)
+		if !ok || obj == nil {
+			continue
+		}
+		item := protocol.CompletionItem{Label: name, Kind: protocol.FieldCompletion}
+		if fn, ok := obj.(*types.Func); ok {
+			item.Kind = protocol.MethodCompletion
+			item.Detail = types.TypeString(fn.Type(), qualifier)
+		} else {
+			item.Detail = types.TypeString(F, qualifier)
+		}
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+// methods returns the methods that can be called on a value of
+// type T, or on its address.
+func methods(T types.Type) []*types.Func {
+	var fns []*types.Func
+	mset := types.NewMethodSet(T)
+	if _, ok := T.Underlying().(*types.Interface); !ok {
+		if _, ok := T.(*types.Pointer); !ok {
+			mset = types.NewMethodSet(types.NewPointer(T))
+		}
+	}
+	for i := 0; i < mset.Len(); i++ {
+		if fn, ok := mset.At(i).Obj().(*types.Func); ok {
+			fns = append(fns, fn)
+		}
 	}
-	return nil, nil, fmt.Errorf("implement template completion")
+	return fns
+}
+
+// variables returns the variables in vars that start with prefix.
+func variables(vars map[string]types.Type, prefix string) []protocol.CompletionItem {
+	var items []protocol.CompletionItem
+	for name, T := range vars {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		item := protocol.CompletionItem{Label: name, Kind: protocol.VariableCompletion}
+		if T != nil {
+			item.Detail = types.TypeString(T, qualifier)
+		}
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+// functions returns the builtin and FuncMap functions that start with
+// prefix.
+func functions(funcs map[string]types.Type, prefix string) []protocol.CompletionItem {
+	var items []protocol.CompletionItem
+	for _, name := range builtins {
+		if _, ok := funcs[name]; !ok && strings.HasPrefix(name, prefix) {
+			items = append(items, protocol.CompletionItem{Label: name, Kind: protocol.FunctionCompletion, Detail: "builtin"})
+		}
+	}
+	for name, T := range funcs {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		item := protocol.CompletionItem{Label: name, Kind: protocol.FunctionCompletion}
+		if T != nil {
+			item.Detail = types.TypeString(T, qualifier)
+		}
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+func keywordItems(prefix string) []protocol.CompletionItem {
+	var items []protocol.CompletionItem
+	for _, kw := range keywords {
+		if strings.HasPrefix(kw, prefix) {
+			items = append(items, protocol.CompletionItem{Label: kw, Kind: protocol.KeywordCompletion})
+		}
+	}
+	return items
+}
+
+func sortItems(items []protocol.CompletionItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Label < items[j].Label
+	})
 }
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"time"
 
+	"golang.org/x/tools/internal/event"
 	"golang.org/x/tools/internal/lsp/protocol"
 	"golang.org/x/tools/internal/lsp/source"
 	"golang.org/x/tools/internal/span"
@@ -22,7 +23,8 @@ var errRe = regexp.MustCompile(`template.*:(\d+): (.*)`)
 // Diagnose returns parse errors. There is only one.
 // The errors are not always helpful. For instance { {end}}
 // will likely point to the end of the file.
-func Diagnose(f source.VersionedFileHandle) []*source.Diagnostic {
+// Templates that parse are type checked (see types.go).
+func Diagnose(ctx context.Context, snapshot source.Snapshot, f source.VersionedFileHandle) []*source.Diagnostic {
 	// no need for skipTemplate check, as Diagnose is called on the
 	// snapshot's template files
 	buf, err := f.Read()
@@ -34,7 +36,7 @@ func Diagnose(f source.VersionedFileHandle) []*source.Diagnostic {
 	}
 	p := parseBuffer(buf)
 	if p.ParseErr == nil {
-		return nil
+		return typeErrors(ctx, snapshot, f, p)
 	}
 	unknownError := func(msg string) []*source.Diagnostic {
 		s := fmt.Sprintf("malformed template error %q: %s", p.ParseErr.Error(), msg)
@@ -65,6 +67,29 @@ func Diagnose(f source.VersionedFileHandle) []*source.Diagnostic {
 	return []*source.Diagnostic{&d}
 }
 
+// typeErrors returns the errors of the gotype annotations of p, and the
+// type errors of its templates.
+func typeErrors(ctx context.Context, snapshot source.Snapshot, f source.VersionedFileHandle, p *Parsed) []*source.Diagnostic {
+	if len(p.annotations) == 0 {
+		return nil
+	}
+	env, err := newTypeEnv(ctx, snapshot, p)
+	if err != nil {
+		event.Error(ctx, "template types", err)
+		return nil
+	}
+	var diags []*source.Diagnostic
+	for _, d := range append(env.diags, checkTypes(p, env).diags...) {
+		diags = append(diags, &source.Diagnostic{
+			URI:      f.URI(),
+			Range:    p.Range(d.start, d.length),
+			Severity: protocol.SeverityError,
+			Message:  d.msg,
+		})
+	}
+	return diags
+}
+
 func skipTemplates(s source.Snapshot) bool {
 	return !s.View().Options().ExperimentalTemplateSupport
 }
@@ -105,6 +130,16 @@ func Hover(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle,
 		return nil, err
 	}
 	ans := protocol.Hover{Range: p.Range(sym.start, sym.length), Contents: protocol.MarkupContent{Kind: protocol.Markdown}}
+	if len(p.annotations) > 0 && p.ParseErr == nil {
+		env, err := newTypeEnv(ctx, snapshot, p)
+		if err != nil {
+			return nil, err
+		}
+		if text := checkTypes(p, env).noteAt(p.FromPosition(position)); text != "" {
+			ans.Contents.Value = "```go\n" + text + "\n```"
+			return &ans, nil
+		}
+	}
 	switch sym.kind {
 	case protocol.Function:
 		ans.Contents.Value = fmt.Sprintf("function: %s", sym.name)
@@ -43,6 +43,9 @@ type Parsed struct {
 	// tokens, computed before trying to parse
 	tokens []Token
 
+	// the gotype annotations, also found before trying to parse
+	annotations []annotation
+
 	// result of parsing
 	named    []*template.Template // the template and embedded templates
 	ParseErr error
@@ -110,6 +113,7 @@ func parseBuffer(buf []byte) *Parsed {
 		}
 	}
 	ans.setTokens()
+	ans.setAnnotations()
 	t, err := template.New("").Parse(string(buf))
 	if err != nil {
 		funcs := make(template.FuncMap)
@@ -0,0 +1,742 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package template
+
+// This file contains the type checking of templates whose dot has a
+// declared Go type. The type is declared by a comment
+//
+//	{{/* gotype: example.com/app.Page */}}
+//
+// which applies to the template, or the {{define}} or {{block}}, that
+// contains it. The package may be given by its path or, if no other package
+// of that name defines the type, by its name. A template invoked by {{template "name" pipeline}}
+// that declares no type gets the type of the pipeline.
+//
+// The functions, other than the builtin ones, are those of the
+// template.FuncMap literals of the workspace.
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+// gotypeRe matches the text of a gotype annotation.
+var gotypeRe = regexp.MustCompile(`^/\*\s*gotype:\s*(\S+?)\s*\*/$`)
+
+// defineRe matches the start of the actions that define a template.
+var defineRe = regexp.MustCompile("^(?:define|block)\\s+(\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`)")
+
+// annotation is a gotype annotation, in local coordinates.
+type annotation struct {
+	start, length int    // of the type expression
+	expr          string // e.g. "*example.com/app.Page"
+	template      string // the name of the annotated template
+}
+
+// setAnnotations finds the gotype annotations among the tokens. It does not
+// need a successful parse, as the nesting of the actions is enough to tell
+// which template an annotation belongs to.
+func (p *Parsed) setAnnotations() {
+	type open struct {
+		define bool
+		name   string
+	}
+	var stack []open
+	current := func() string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].define {
+				return stack[i].name
+			}
+		}
+		return ""
+	}
+	for _, tok := range p.tokens {
+		at, text := trimAction(p.buf[tok.Start:tok.End])
+		at += tok.Start
+		if m := gotypeRe.FindStringSubmatchIndex(text); m != nil {
+			p.annotations = append(p.annotations, annotation{
+				start:    at + m[2],
+				length:   m[3] - m[2],
+				expr:     text[m[2]:m[3]],
+				template: current(),
+			})
+			continue
+		}
+		if strings.HasPrefix(text, "/*") {
+			continue
+		}
+		if m := defineRe.FindStringSubmatch(text); m != nil {
+			name, err := strconv.Unquote(m[1])
+			stack = append(stack, open{define: err == nil, name: name})
+			continue
+		}
+		switch word := strings.Fields(text + " ")[0]; word {
+		case "if", "range", "with":
+			stack = append(stack, open{})
+		case "end":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// trimAction strips the delimiters, trim markers and spaces from the action
+// tok, returning its text and the offset of the text in tok.
+func trimAction(tok []byte) (int, string) {
+	text := string(tok[len(Left) : len(tok)-len(Right)])
+	at := len(Left)
+	if strings.HasPrefix(text, "- ") {
+		text = text[1:]
+		at++
+	}
+	text = strings.TrimSuffix(text, " -")
+	trimmed := strings.TrimLeft(text, " \t\r\n")
+	at += len(text) - len(trimmed)
+	return at, strings.TrimRight(trimmed, " \t\r\n")
+}
+
+// typeEnv is what the templates of a file are type checked against.
+type typeEnv struct {
+	dots  map[string]types.Type // the declared type of dot, by template name
+	funcs map[string]types.Type // the types of the FuncMap functions
+	diags []typeDiag            // for the annotations that don't resolve
+}
+
+// newTypeEnv resolves the gotype annotations of p among the workspace
+// packages of the snapshot and their dependencies, and collects the FuncMap
+// functions of the workspace. The templates of a file without annotations
+// are not type checked, so that the packages are only needed for those that
+// have some.
+func newTypeEnv(ctx context.Context, snapshot source.Snapshot, p *Parsed) (*typeEnv, error) {
+	if len(p.annotations) == 0 {
+		return resolveAnnotations(nil, nil), nil
+	}
+	pkgs, err := snapshot.WorkspacePackages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var tpkgs []*types.Package
+	for _, pkg := range pkgs {
+		tpkgs = append(tpkgs, pkg.GetTypes())
+	}
+	env := resolveAnnotations(tpkgs, p.annotations)
+	for _, pkg := range pkgs {
+		funcMapFuncs(pkg.GetSyntax(), pkg.GetTypesInfo(), env.funcs)
+	}
+	return env, nil
+}
+
+// resolveAnnotations returns an environment with the types of the
+// annotations, looked up in pkgs and their dependencies, and no functions.
+func resolveAnnotations(pkgs []*types.Package, annotations []annotation) *typeEnv {
+	env := &typeEnv{
+		dots:  make(map[string]types.Type),
+		funcs: make(map[string]types.Type),
+	}
+	if len(annotations) == 0 {
+		return env
+	}
+	byPath := make(map[string]*types.Package)
+	var add func(pkg *types.Package)
+	add = func(pkg *types.Package) {
+		if byPath[pkg.Path()] != nil {
+			return
+		}
+		byPath[pkg.Path()] = pkg
+		for _, imp := range pkg.Imports() {
+			add(imp)
+		}
+	}
+	for _, pkg := range pkgs {
+		add(pkg)
+	}
+	for _, a := range annotations {
+		T, err := lookupGoType(byPath, a.expr)
+		if err != nil {
+			env.diags = append(env.diags, typeDiag{a.start, a.length, err.Error()})
+			continue
+		}
+		env.dots[a.template] = T
+	}
+	return env
+}
+
+// lookupGoType finds the type named by expr, such as "*example.com/app.Page"
+// or "app.Page", among the packages, keyed by path. A package name that is
+// not a path must name a single package that defines the type.
+func lookupGoType(byPath map[string]*types.Package, expr string) (types.Type, error) {
+	name := strings.TrimPrefix(expr, "*")
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return nil, fmt.Errorf("gotype %s is not a qualified type name", expr)
+	}
+	path, name := name[:dot], name[dot+1:]
+	var found []*types.TypeName
+	if pkg, ok := byPath[path]; ok {
+		if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+			found = append(found, obj)
+		}
+	} else if !strings.Contains(path, "/") {
+		for _, pkg := range byPath {
+			if pkg.Name() != path {
+				continue
+			}
+			if obj, ok := pkg.Scope().Lookup(name).(*types.TypeName); ok {
+				found = append(found, obj)
+			}
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("undefined gotype %s", expr)
+	case 1:
+	default:
+		var paths []string
+		for _, obj := range found {
+			paths = append(paths, obj.Pkg().Path())
+		}
+		sort.Strings(paths)
+		return nil, fmt.Errorf("ambiguous gotype %s, defined in %s", expr, strings.Join(paths, ", "))
+	}
+	T := found[0].Type()
+	if strings.HasPrefix(expr, "*") {
+		T = types.NewPointer(T)
+	}
+	return T, nil
+}
+
+// funcMapFuncs adds the functions of the FuncMap literals of files, and of
+// the assignments to the elements of FuncMaps, to funcs.
+func funcMapFuncs(files []*ast.File, info *types.Info, funcs map[string]types.Type) {
+	add := func(key, value ast.Expr) {
+		tv, ok := info.Types[key]
+		if ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+			funcs[constant.StringVal(tv.Value)] = info.TypeOf(value)
+		}
+	}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CompositeLit:
+				if !isFuncMap(info.TypeOf(n)) {
+					break
+				}
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						add(kv.Key, kv.Value)
+					}
+				}
+			case *ast.AssignStmt:
+				if len(n.Lhs) != len(n.Rhs) {
+					break
+				}
+				for i, lhs := range n.Lhs {
+					if index, ok := lhs.(*ast.IndexExpr); ok && isFuncMap(info.TypeOf(index.X)) {
+						add(index.Index, n.Rhs[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+}
+
+// isFuncMap reports whether T is the FuncMap of text/template, or its alias
+// in html/template.
+func isFuncMap(T types.Type) bool {
+	if T == nil {
+		return false
+	}
+	s := types.TypeString(T, nil)
+	return s == "text/template.FuncMap" || s == "html/template.FuncMap"
+}
+
+// builtins are the functions predefined by text/template.
+var builtins = []string{"and", "call", "eq", "ge", "gt", "html", "index", "js", "le", "len",
+	"lt", "ne", "not", "or", "print", "printf", "println", "slice", "urlquery"}
+
+// builtinResult returns the type of the result of the builtin function name
+// called with arguments of the given types, or false if there is no such
+// builtin. A nil type means that the result is not known statically.
+func builtinResult(name string, args []types.Type) (types.Type, bool) {
+	switch name {
+	case "and", "or":
+		for _, T := range args {
+			if T == nil || !types.Identical(T, args[0]) {
+				return nil, true
+			}
+		}
+		if len(args) == 0 {
+			return nil, true
+		}
+		return args[0], true
+	case "eq", "ge", "gt", "le", "lt", "ne", "not":
+		return types.Typ[types.Bool], true
+	case "html", "js", "print", "printf", "println", "urlquery":
+		return types.Typ[types.String], true
+	case "len":
+		return types.Typ[types.Int], true
+	case "call":
+		if len(args) > 0 && args[0] != nil {
+			if sig, ok := args[0].Underlying().(*types.Signature); ok && sig.Results().Len() > 0 {
+				return sig.Results().At(0).Type(), true
+			}
+		}
+		return nil, true
+	case "index":
+		if len(args) == 0 {
+			return nil, true
+		}
+		T := args[0]
+		for range args[1:] {
+			T = indexed(T)
+		}
+		return T, true
+	case "slice":
+		if len(args) == 0 || args[0] == nil {
+			return nil, true
+		}
+		switch u := args[0].Underlying().(type) {
+		case *types.Array:
+			return types.NewSlice(u.Elem()), true
+		case *types.Pointer:
+			if a, ok := u.Elem().Underlying().(*types.Array); ok {
+				return types.NewSlice(a.Elem()), true
+			}
+		}
+		return args[0], true
+	}
+	return nil, false
+}
+
+// indexed returns the type of the elements of T, if it can be indexed.
+func indexed(T types.Type) types.Type {
+	if T == nil {
+		return nil
+	}
+	switch u := T.Underlying().(type) {
+	case *types.Array:
+		return u.Elem()
+	case *types.Slice:
+		return u.Elem()
+	case *types.Map:
+		return u.Elem()
+	case *types.Basic:
+		if u.Info()&types.IsString != 0 {
+			return types.Typ[types.Byte]
+		}
+	}
+	return nil
+}
+
+// rangeTypes returns the types of the keys and elements of a range over T.
+func rangeTypes(T types.Type) (key, elem types.Type) {
+	if T == nil {
+		return nil, nil
+	}
+	if ptr, ok := T.Underlying().(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	switch u := T.Underlying().(type) {
+	case *types.Array:
+		return types.Typ[types.Int], u.Elem()
+	case *types.Slice:
+		return types.Typ[types.Int], u.Elem()
+	case *types.Map:
+		return u.Key(), u.Elem()
+	case *types.Chan:
+		return u.Elem(), u.Elem()
+	case *types.Basic:
+		if u.Info()&types.IsInteger != 0 {
+			return T, T
+		}
+	}
+	return nil, nil
+}
+
+// lookupField returns the type of the field or method name of T, as
+// evaluated by a template, and its object, if any. It reports false if T
+// has no such field or method. A nil type means that the type is not known
+// statically, as for the fields of an interface value.
+func lookupField(T types.Type, name string) (types.Type, types.Object, bool) {
+	if T == nil || T.Underlying() == types.Typ[types.Invalid] {
+		return nil, nil, true
+	}
+	under := T.Underlying()
+	if ptr, ok := under.(*types.Pointer); ok {
+		under = ptr.Elem().Underlying()
+	}
+	if m, ok := under.(*types.Map); ok {
+		return m.Elem(), nil, true
+	}
+	if !token.IsExported(name) {
+		return nil, nil, false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(T, true, nil, name)
+	switch obj := obj.(type) {
+	case *types.Var:
+		return obj.Type(), obj, true
+	case *types.Func:
+		if res := obj.Type().(*types.Signature).Results(); res.Len() > 0 {
+			return res.At(0).Type(), obj, true
+		}
+		return nil, obj, true
+	}
+	if _, ok := under.(*types.Interface); ok {
+		// The template evaluates fields on the dynamic value.
+		return nil, nil, true
+	}
+	return nil, nil, false
+}
+
+// qualifier qualifies the types of the hovers and diagnostics by package
+// name.
+func qualifier(pkg *types.Package) string {
+	return pkg.Name()
+}
+
+// typeDiag is an error found by the type checker, in local coordinates.
+type typeDiag struct {
+	start, length int
+	msg           string
+}
+
+// typeNote describes an operand, for hover.
+type typeNote struct {
+	start, length int
+	text          string
+}
+
+// scope is the dot and the variables in effect in a run of text, which are
+// what a new action there would see.
+type scope struct {
+	start, end int
+	dot        types.Type
+	vars       map[string]types.Type
+}
+
+// A checker holds the results of type checking the templates of a file.
+type checker struct {
+	p      *Parsed
+	env    *typeEnv
+	strict bool // report undefined functions
+
+	called map[string]types.Type // the dot passed to invoked templates
+	diags  []typeDiag
+	notes  []typeNote
+	scopes []scope
+}
+
+// checkTypes type checks the templates of p, which must have parsed,
+// against env. Templates that declare no type get the type they are invoked
+// with, so the templates are checked again until no more types are
+// inferred.
+func checkTypes(p *Parsed, env *typeEnv) *checker {
+	dots := make(map[string]types.Type)
+	for name, T := range env.dots {
+		dots[name] = T
+	}
+	for i := 0; ; i++ {
+		c := &checker{
+			p:      p,
+			env:    env,
+			strict: len(p.annotations) > 0,
+			called: make(map[string]types.Type),
+		}
+		for _, t := range p.named {
+			if t.Tree == nil {
+				continue
+			}
+			dot := dots[t.Name()]
+			c.list(t.Root, dot, map[string]types.Type{"$": dot})
+		}
+		inferred := false
+		for name, T := range c.called {
+			if _, ok := dots[name]; !ok && T != nil {
+				dots[name] = T
+				inferred = true
+			}
+		}
+		if !inferred || i >= len(p.named) {
+			return c
+		}
+	}
+}
+
+func (c *checker) errorf(start, length int, format string, args ...interface{}) {
+	c.diags = append(c.diags, typeDiag{start, length, fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) note(start, length int, text string) {
+	c.notes = append(c.notes, typeNote{start, length, text})
+}
+
+func copyVars(vars map[string]types.Type) map[string]types.Type {
+	cp := make(map[string]types.Type, len(vars))
+	for k, v := range vars {
+		cp[k] = v
+	}
+	return cp
+}
+
+func (c *checker) list(l *parse.ListNode, dot types.Type, vars map[string]types.Type) {
+	if l == nil { // nil ElseList
+		return
+	}
+	for _, n := range l.Nodes {
+		c.node(n, dot, vars)
+	}
+}
+
+func (c *checker) node(n parse.Node, dot types.Type, vars map[string]types.Type) {
+	switch x := n.(type) {
+	case *parse.ActionNode:
+		c.declare(x.Pipe, c.pipe(x.Pipe, dot, vars), vars)
+	case *parse.IfNode:
+		inner := copyVars(vars)
+		c.declare(x.Pipe, c.pipe(x.Pipe, dot, vars), inner)
+		c.list(x.List, dot, copyVars(inner))
+		c.list(x.ElseList, dot, copyVars(inner))
+	case *parse.WithNode:
+		inner := copyVars(vars)
+		T := c.pipe(x.Pipe, dot, vars)
+		c.declare(x.Pipe, T, inner)
+		c.list(x.List, T, copyVars(inner))
+		c.list(x.ElseList, dot, copyVars(inner))
+	case *parse.RangeNode:
+		inner := copyVars(vars)
+		key, elem := rangeTypes(c.pipe(x.Pipe, dot, vars))
+		switch len(x.Pipe.Decl) {
+		case 1:
+			c.declare(x.Pipe, elem, inner)
+		case 2:
+			c.variable(x.Pipe.Decl[0], key, inner)
+			c.variable(x.Pipe.Decl[1], elem, inner)
+		}
+		c.list(x.List, elem, copyVars(inner))
+		c.list(x.ElseList, dot, copyVars(inner))
+	case *parse.TemplateNode:
+		T := c.pipe(x.Pipe, dot, vars)
+		if prev, ok := c.called[x.Name]; ok && (prev == nil || T == nil || !types.Identical(prev, T)) {
+			T = nil // invoked with different types
+		}
+		c.called[x.Name] = T
+	case *parse.TextNode:
+		c.scopes = append(c.scopes, scope{
+			start: int(x.Pos),
+			end:   int(x.Pos) + len(x.Text),
+			dot:   dot,
+			vars:  copyVars(vars),
+		})
+	}
+}
+
+// declare records the type of the variable declared or assigned by pipe,
+// if any. An assignment does not change the type of the variable.
+func (c *checker) declare(pipe *parse.PipeNode, T types.Type, vars map[string]types.Type) {
+	if pipe == nil || len(pipe.Decl) != 1 {
+		return
+	}
+	if _, ok := vars[pipe.Decl[0].Ident[0]]; ok && pipe.IsAssign {
+		return
+	}
+	c.variable(pipe.Decl[0], T, vars)
+}
+
+func (c *checker) variable(v *parse.VariableNode, T types.Type, vars map[string]types.Type) {
+	vars[v.Ident[0]] = T
+	if extents := c.extents(v.Ident[:1], v); len(extents) > 0 && T != nil {
+		c.note(extents[0].start, extents[0].length, fmt.Sprintf("var %s %s", v.Ident[0], types.TypeString(T, qualifier)))
+	}
+}
+
+// extent locates a name, in local coordinates.
+type extent struct {
+	start, length int
+}
+
+// extents returns the extents of the names of the field chain or variable
+// node. Unlike the symbols, it takes the $ of a variable to be part of its
+// name.
+func (c *checker) extents(names []string, node parse.Node) []extent {
+	lookfor := "." + strings.Join(names, ".")
+	if _, ok := node.(*parse.VariableNode); ok {
+		lookfor = lookfor[1:]
+	}
+	// The position of a node may be past the start of its first name
+	// (golang/go#43388).
+	pos := int(node.Position())
+	from := pos - len(lookfor)
+	if from < 0 {
+		from = 0
+	}
+	i := strings.Index(string(c.p.buf[from:]), lookfor)
+	if i < 0 || from+i > pos+1 {
+		return nil
+	}
+	at := from + i
+	if lookfor[0] == '.' {
+		at++
+	}
+	var extents []extent
+	for _, name := range names {
+		extents = append(extents, extent{at, utf8.RuneCountInString(name)})
+		at += len(name) + 1
+	}
+	return extents
+}
+
+// pipe returns the type of the value of pipe.
+func (c *checker) pipe(pipe *parse.PipeNode, dot types.Type, vars map[string]types.Type) types.Type {
+	if pipe == nil { // {{template "name"}}
+		return nil
+	}
+	var T types.Type
+	for i, cmd := range pipe.Cmds {
+		var piped []types.Type
+		if i > 0 {
+			piped = append(piped, T)
+		}
+		T = c.command(cmd, dot, vars, piped)
+	}
+	return T
+}
+
+// command returns the type of the value of cmd, whose final argument is
+// piped if it is the continuation of a pipeline.
+func (c *checker) command(cmd *parse.CommandNode, dot types.Type, vars map[string]types.Type, piped []types.Type) types.Type {
+	var args []types.Type
+	for _, arg := range cmd.Args[1:] {
+		args = append(args, c.operand(arg, dot, vars))
+	}
+	args = append(args, piped...)
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return c.call(id, args)
+	}
+	return c.operand(cmd.Args[0], dot, vars)
+}
+
+func (c *checker) operand(n parse.Node, dot types.Type, vars map[string]types.Type) types.Type {
+	switch x := n.(type) {
+	case *parse.DotNode:
+		if dot != nil {
+			c.note(int(x.Pos), 1, "dot "+types.TypeString(dot, qualifier))
+		}
+		return dot
+	case *parse.FieldNode:
+		return c.fields(dot, x.Ident, x, 0)
+	case *parse.VariableNode:
+		T := vars[x.Ident[0]]
+		if extents := c.extents(x.Ident[:1], x); len(extents) > 0 && T != nil {
+			c.note(extents[0].start, extents[0].length, fmt.Sprintf("var %s %s", x.Ident[0], types.TypeString(T, qualifier)))
+		}
+		return c.fields(T, x.Ident, x, 1)
+	case *parse.ChainNode:
+		return c.fields(c.operand(x.Node, dot, vars), x.Field, x, 0)
+	case *parse.PipeNode:
+		return c.pipe(x, dot, vars)
+	case *parse.IdentifierNode:
+		return c.call(x, nil)
+	case *parse.StringNode:
+		return types.Typ[types.String]
+	case *parse.BoolNode:
+		return types.Typ[types.Bool]
+	case *parse.NumberNode:
+		switch {
+		case x.IsInt:
+			return types.Typ[types.UntypedInt]
+		case x.IsFloat:
+			return types.Typ[types.UntypedFloat]
+		case x.IsComplex:
+			return types.Typ[types.UntypedComplex]
+		}
+	}
+	return nil
+}
+
+// fields returns the type of the field chain names[skip:] of node,
+// evaluated on a value of type T.
+func (c *checker) fields(T types.Type, names []string, node parse.Node, skip int) types.Type {
+	extents := c.extents(names, node)
+	for i := skip; i < len(names); i++ {
+		if T == nil {
+			return nil
+		}
+		name := names[i]
+		F, obj, ok := lookupField(T, name)
+		if !ok {
+			if i < len(extents) {
+				c.errorf(extents[i].start, extents[i].length, "can't evaluate field %s in type %s", name, types.TypeString(T, qualifier))
+			}
+			return nil
+		}
+		if obj != nil && i < len(extents) {
+			c.note(extents[i].start, extents[i].length, types.ObjectString(obj, qualifier))
+		}
+		T = F
+	}
+	return T
+}
+
+// call returns the type of the result of the function id called with
+// arguments of the given types.
+func (c *checker) call(id *parse.IdentifierNode, args []types.Type) types.Type {
+	// The FuncMap functions override the builtin ones.
+	T, ok := c.env.funcs[id.Ident]
+	if !ok {
+		if T, ok := builtinResult(id.Ident, args); ok {
+			c.note(int(id.Pos), len(id.Ident), "builtin function "+id.Ident)
+			return T
+		}
+		if c.strict {
+			c.errorf(int(id.Pos), len(id.Ident), "function %q not defined", id.Ident)
+		}
+		return nil
+	}
+	sig, ok := T.(*types.Signature)
+	if !ok {
+		return nil
+	}
+	c.note(int(id.Pos), len(id.Ident), "func "+id.Ident+strings.TrimPrefix(types.TypeString(sig, qualifier), "func"))
+	if sig.Results().Len() == 0 {
+		return nil
+	}
+	return sig.Results().At(0).Type()
+}
+
+// scopeAt returns the scope of the text at offset.
+func (c *checker) scopeAt(offset int) (scope, bool) {
+	var found scope
+	ok := false
+	for _, s := range c.scopes {
+		if s.start <= offset && offset <= s.end && (!ok || s.start > found.start) {
+			found, ok = s, true
+		}
+	}
+	return found, ok
+}
+
+// noteAt returns the description of the operand at offset.
+func (c *checker) noteAt(offset int) string {
+	for _, n := range c.notes {
+		if n.start <= offset && offset < n.start+n.length {
+			return n.text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,224 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const appSrc = `package app
+
+import "text/template"
+
+type Page struct {
+	Title string
+	Items []*Item
+	User
+	Meta  map[string]int
+}
+
+type User struct{ Name string }
+
+func (u *User) Greeting() string { return "hi " + u.Name }
+
+type Item struct {
+	Label string
+	Price float64
+}
+
+func (i Item) Cents() int { return int(i.Price * 100) }
+
+var funcs = template.FuncMap{
+	"upper": func(s string) string { return s },
+}
+
+func init() {
+	funcs["item"] = func() *Item { return nil }
+}
+`
+
+// fakeTemplate stands in for text/template, which is only needed for its
+// FuncMap.
+type fakeTemplate map[string]*types.Package
+
+func (f fakeTemplate) Import(path string) (*types.Package, error) {
+	return f[path], nil
+}
+
+// appEnv returns the environment of a template with the given annotations
+// for the package of appSrc.
+func appEnv(t *testing.T, annotations []annotation) *typeEnv {
+	fset := token.NewFileSet()
+	check := func(path, src string, imp types.Importer, info *types.Info) (*types.Package, *ast.File) {
+		f, err := parser.ParseFile(fset, path+".go", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conf := types.Config{Importer: imp}
+		pkg, err := conf.Check(path, fset, []*ast.File{f}, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pkg, f
+	}
+	tmpl, _ := check("text/template", "package template\n\ntype FuncMap map[string]interface{}\n", nil, nil)
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	app, f := check("example.com/app", appSrc, fakeTemplate{"text/template": tmpl}, info)
+
+	env := resolveAnnotations([]*types.Package{tmpl, app}, annotations)
+	funcMapFuncs([]*ast.File{f}, info, env.funcs)
+	return env
+}
+
+func TestAnnotations(t *testing.T) {
+	src := `{{/* gotype: example.com/app.Page */}}{{range .Items}}
+{{define "item"}}{{- /* gotype: *app.Item */ -}}{{if .}}x{{end}}{{end}}{{end}}
+{{block "user" .User}}{{/* gotype: app.Missing */}}{{end}}`
+	p := parseBuffer([]byte(src))
+	var got []string
+	for _, a := range p.annotations {
+		got = append(got, a.template+"="+src[a.start:a.start+a.length])
+	}
+	want := []string{"=example.com/app.Page", "item=*app.Item", "user=app.Missing"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("annotations = %v, want %v", got, want)
+	}
+
+	env := appEnv(t, p.annotations)
+	if len(env.diags) != 1 || env.diags[0].msg != "undefined gotype app.Missing" {
+		t.Errorf("diags = %v, want the undefined app.Missing", env.diags)
+	}
+	for name, want := range map[string]string{"": "example.com/app.Page", "item": "*example.com/app.Item"} {
+		if got := env.dots[name]; got == nil || got.String() != want {
+			t.Errorf("dot of %q = %v, want %s", name, got, want)
+		}
+	}
+}
+
+func TestLookupGoType(t *testing.T) {
+	newPkg := func(path, name string, typeNames ...string) *types.Package {
+		pkg := types.NewPackage(path, name)
+		for _, n := range typeNames {
+			obj := types.NewTypeName(token.NoPos, pkg, n, nil)
+			types.NewNamed(obj, types.NewStruct(nil, nil), nil)
+			pkg.Scope().Insert(obj)
+		}
+		return pkg
+	}
+	app := newPkg("example.com/app", "app", "Page", "User")
+	other := newPkg("example.com/other/app", "app", "Page")
+	root := newPkg("example.com/cmd", "main")
+	root.SetImports([]*types.Package{app, other})
+
+	exprs := []string{"app.User", "*example.com/other/app.Page", "app.Page", "other/app.Page", "app.Item"}
+	var annotations []annotation
+	for _, expr := range exprs {
+		annotations = append(annotations, annotation{length: len(expr), expr: expr, template: expr})
+	}
+	// The packages are found among the dependencies of the workspace.
+	env := resolveAnnotations([]*types.Package{root}, annotations)
+	var got []string
+	for _, expr := range exprs {
+		if T := env.dots[expr]; T != nil {
+			got = append(got, T.String())
+		}
+	}
+	for _, d := range env.diags {
+		got = append(got, d.msg)
+	}
+	want := []string{
+		"example.com/app.User",
+		"*example.com/other/app.Page",
+		"ambiguous gotype app.Page, defined in example.com/app, example.com/other/app",
+		"undefined gotype other/app.Page",
+		"undefined gotype app.Item",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckTypes(t *testing.T) {
+	src := `{{/* gotype: app.Page */}}
+{{.Title}} {{.Titel}} {{.Greeting}} {{.Meta.anything}}
+{{range $i, $it := .Items}}{{$it.Label}}{{.Cents}}{{.Name}}{{end}}
+{{with $u := .User}}{{.Name | upper}}{{$u.Age}}{{end}}
+{{(item).Label}} {{lower .Title}} {{(index .Items 0).Prices}}
+{{template "price" index .Items 0}}
+{{define "price"}}{{printf "%.2f" .Price}}{{.Cost}}{{end}}`
+	p := parseBuffer([]byte(src))
+	if p.ParseErr != nil {
+		t.Fatal(p.ParseErr)
+	}
+	c := checkTypes(p, appEnv(t, p.annotations))
+	var got []string
+	for _, d := range c.diags {
+		got = append(got, src[d.start:d.start+d.length]+": "+d.msg)
+	}
+	sort.Strings(got)
+	want := []string{
+		"Age: can't evaluate field Age in type app.User",
+		"Cost: can't evaluate field Cost in type *app.Item",
+		"Name: can't evaluate field Name in type *app.Item",
+		"Prices: can't evaluate field Prices in type *app.Item",
+		"Titel: can't evaluate field Titel in type app.Page",
+		`lower: function "lower" not defined`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got diagnostics\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	for at, want := range map[string]string{
+		"Greeting": "func (*app.User).Greeting() string",
+		"Label}}{{.Cents": "field Label string",
+		"upper":          "func upper(s string) string",
+		"printf":         "builtin function printf",
+		"Price}}":        "field Price float64",
+	} {
+		if got := c.noteAt(strings.Index(src, at)); got != want {
+			t.Errorf("note at %q = %q, want %q", at, got, want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	const header = "{{/* gotype: app.Page */}}\n"
+	env := appEnv(t, parseBuffer([]byte(header)).annotations)
+	// ^ marks the cursor.
+	for _, tt := range []struct {
+		src, word string
+		want      []string
+	}{
+		{"{{range $i, $it := .Items}}{{$it.^}}{{end}}", "", []string{"Cents", "Label", "Price"}},
+		{"{{range .Items}}{{.C^}}{{end}}", "C", []string{"Cents"}},
+		{"{{.User.^}}", "", []string{"Greeting", "Name"}},
+		{"{{.Greeting.^}}", "", nil},
+		{"{{.Meta.^}}", "", nil},
+		{"{{.Title | up^}}", "up", []string{"upper"}},
+		{"{{with $u := .User}}{{$^}}{{end}}", "$", []string{"$", "$u"}},
+		{"{{e^}}", "e", []string{"eq", "else", "end"}},
+		{"{{if .Ti^}}x{{end}}", "Ti", []string{"Title"}},
+		{"{{/* .^ */}}", "", nil},
+		{"text .^", "", nil},
+	} {
+		src := header + tt.src
+		offset := strings.Index(src, "^")
+		src = src[:offset] + src[offset+1:]
+		items, word := complete([]byte(src), offset, env)
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Label)
+		}
+		if word != tt.word || strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("complete(%s) = %q %v, want %q %v", tt.src, word, got, tt.word, tt.want)
+		}
+	}
+}
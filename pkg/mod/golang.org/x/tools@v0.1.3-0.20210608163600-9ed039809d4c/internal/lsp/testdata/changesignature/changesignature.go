@@ -0,0 +1,20 @@
+package changesignature
+
+import "strings"
+
+type Shape interface {
+	Scale(factor float64, center bool) //@changesignature("center", "Move parameter left")
+}
+
+func resize(s Shape) {
+	s.Scale(2, false)
+}
+
+func join(sep string, verbose bool, parts ...string) string { //@changesignature("verbose", "Remove parameter")
+	return strings.Join(parts, sep)
+}
+
+func _() {
+	_ = join(",", true, "a", "b")
+	_ = join(join("-", false), false)
+}
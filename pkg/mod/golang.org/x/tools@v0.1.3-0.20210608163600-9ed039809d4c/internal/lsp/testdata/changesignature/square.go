@@ -0,0 +1,11 @@
+package changesignature
+
+type square struct{ side float64 }
+
+func (s *square) Scale(factor float64, center bool) {
+	s.side *= factor
+}
+
+func _(s *square) {
+	s.Scale(0.5, true)
+}
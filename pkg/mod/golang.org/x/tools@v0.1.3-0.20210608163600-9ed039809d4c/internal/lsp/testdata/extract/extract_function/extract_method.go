@@ -0,0 +1,23 @@
+package extract
+
+type A struct {
+	x int
+	y int
+}
+
+func (a *A) XLessThanY() bool {
+	return a.x < a.y //@extractmethod("return", "a.y")
+}
+
+func (a *A) AddP() int {
+	sum := a.x + a.y //@extractmethod("sum", "a.y")
+	return sum       //@extractmethod("return", "sum")
+}
+
+func (a *A) Incr() {
+	a.x++ //@extractmethod("a", "++"),extractfunc("a", "++")
+}
+
+func (a A) Scale(k int) int {
+	return a.x * k //@extractmethod("return", "k")
+}
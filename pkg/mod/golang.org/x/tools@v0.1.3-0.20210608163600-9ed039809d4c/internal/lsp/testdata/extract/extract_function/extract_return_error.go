@@ -0,0 +1,25 @@
+package extract
+
+import "errors"
+
+func _(a int) (int, error) {
+	if a < 0 { //@mark(exStErr, "if")
+		return 0, errors.New("negative")
+	}
+	b := a + 1 //@mark(exEnErr, "1")
+	return b, nil
+	//@extractfunc(exStErr, exEnErr)
+}
+
+func check(a int) error {
+	return nil
+}
+
+func _(a int) (int, error) {
+	if err := check(a); a < 0 { //@mark(exStErrVar, "if")
+		return 0, err
+	}
+	b := a + 1 //@mark(exEnErrVar, "1")
+	return b, nil
+	//@extractfunc(exStErrVar, exEnErrVar)
+}
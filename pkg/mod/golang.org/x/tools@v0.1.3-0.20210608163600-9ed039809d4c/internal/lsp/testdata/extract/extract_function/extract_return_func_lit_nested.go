@@ -0,0 +1,13 @@
+package extract
+
+func apply(f func() bool) bool {
+	return f()
+}
+
+func _() bool {
+	ok := apply(func() bool { //@mark(exStLitNested, "ok")
+		return true
+	}) //@mark(exEnLitNested, ")")
+	return ok
+	//@extractfunc(exStLitNested, exEnLitNested)
+}
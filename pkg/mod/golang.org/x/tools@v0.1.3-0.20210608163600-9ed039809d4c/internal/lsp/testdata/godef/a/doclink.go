@@ -0,0 +1,8 @@
+package a
+
+// Linked calls [Random] and [Pos.Sum], unlike Typ or an [unknown] name.
+type Linked struct{} //@mark(Linked, "Linked")
+
+func _() {
+	var _ Linked //@godef("Linked", Linked)
+}
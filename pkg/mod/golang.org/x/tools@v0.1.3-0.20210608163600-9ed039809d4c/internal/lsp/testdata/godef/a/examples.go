@@ -0,0 +1,10 @@
+package a
+
+// Greet returns a greeting for name.
+func Greet(name string) string { //@mark(Greet, "Greet")
+	return "hello, " + name
+}
+
+func _() {
+	_ = Greet("gopher") //@fulldochover("Greet", Greet)
+}
@@ -0,0 +1,21 @@
+package a_test
+
+import (
+	"fmt"
+
+	"golang.org/x/tools/internal/lsp/godef/a"
+)
+
+func ExampleGreet() {
+	fmt.Println(a.Greet("gopher"))
+	// Output: hello, gopher
+}
+
+func ExampleGreet_twice() {
+	greeting := a.Greet("gopher")
+	fmt.Println(greeting, greeting)
+}
+
+// ExampleGreet_Upper is not an example of Greet, since its suffix is not
+// lowercase.
+func ExampleGreet_Upper() {}
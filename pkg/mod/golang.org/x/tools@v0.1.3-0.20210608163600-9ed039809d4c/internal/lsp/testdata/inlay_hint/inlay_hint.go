@@ -0,0 +1,43 @@
+package inlayHint //@inlayhint("package")
+
+import "fmt"
+
+type Kind int
+
+const (
+	KindNone Kind = iota
+	KindPrint
+	KindPrintf
+	_
+	KindErrorf
+)
+
+const (
+	a, b = 1 << iota, 2 << iota
+	c, d
+)
+
+const answer = 42
+
+type Point struct {
+	X, Y int
+}
+
+func draw(p Point, filled, dashed bool, width int, labels ...string) {}
+
+func hints() {
+	x := 1
+	p, q := Point{x, 2}, &Point{3, 4}
+	points := []*Point{{5, 6}, {X: 7, Y: 8}}
+	for i, pt := range points {
+		fmt.Println(i, pt)
+	}
+	width := 3
+	draw(*p.add(*q), true, false, width, "a", "b")
+	draw(p, false, true, 0, []string{"c"}...)
+	_ = Kind(answer)
+}
+
+func (p Point) add(q Point) *Point {
+	return &Point{p.X + q.X, p.Y + q.Y}
+}
@@ -0,0 +1,53 @@
+package inline
+
+import "fmt"
+
+type counter struct {
+	n int
+}
+
+func (c *counter) add(k int) {
+	c.n += k
+}
+
+func (c counter) twice() int {
+	return c.n * 2
+}
+
+func sum(a, b int) int {
+	return a + b
+}
+
+func half(x float64) float64 {
+	return x / 2
+}
+
+func square(n int) int {
+	return n * n
+}
+
+func greet(name string) {
+	msg := "hello, " + name
+	fmt.Println(msg)
+}
+
+func describe(n int) string {
+	s := fmt.Sprint(n)
+	return "n=" + s
+}
+
+func pair(x int) (int, error) {
+	return x, nil
+}
+
+func setFirst(p *int, a int) int {
+	*p = 5
+	return a
+}
+
+var g int
+
+func bump(a int) int {
+	g++
+	return a
+}
@@ -0,0 +1,49 @@
+package inline
+
+import "fmt"
+
+func next() int { return 1 }
+
+func _() {
+	x := sum(1, 2) * 3 //@inline("sum", "sum")
+	fmt.Println(x)
+}
+
+func _() {
+	y := half(3) //@inline("half", "half")
+	fmt.Println(y)
+}
+
+func _() {
+	fmt.Println(square(next())) //@inline("square", "square")
+}
+
+func _() {
+	msg := "world"
+	greet(msg) //@inline("greet", "greet")
+}
+
+func _(n int) string {
+	return describe(n + 1) //@inline("describe", "describe")
+}
+
+func _(c *counter, v counter) {
+	c.add(next()) //@inline("add", "add")
+	fmt.Println(v.twice()) //@inline("twice", "twice")
+}
+
+func _() (int, error) {
+	a, err := pair(2) //@inline("pair", "pair")
+	return a, err
+}
+
+func _() {
+	x := 1
+	y := setFirst(&x, x) //@inline("setFirst", "setFirst")
+	fmt.Println(y)
+}
+
+func _() {
+	z := bump(g) //@inline("bump", "bump")
+	fmt.Println(z)
+}
@@ -40,3 +40,13 @@ func _() {
 
 		foo = nil
 }
+
+func _() {
+	/* errwrap! */ //@item(postfixErrwrap, "errwrap!", "wrap error", "snippet")
+
+	var err error
+	err.errwrap //@complete(" //", postfixErrwrap)
+
+	var foo []int
+	foo.errwrap //@complete(" //")
+}
@@ -0,0 +1,13 @@
+package structtag
+
+type User struct {
+	UserID string `j`               //@complete("` ", tagJSON)
+	Name   string `json:"na"`       //@complete("\"` ", tagName, tagNameUpper)
+	Email  string `json:"email,om"` //@complete("\"` ", tagOmitEmpty, tagOmitZero)
+}
+
+/* json */ //@item(tagJSON, "json", "json:\"userID\"", "property")
+/* name */ //@item(tagName, "name", "name of Name", "value")
+/* Name */ //@item(tagNameUpper, "Name", "name of Name", "value")
+/* omitempty */ //@item(tagOmitEmpty, "omitempty", "json option", "enumMember")
+/* omitzero */ //@item(tagOmitZero, "omitzero", "json option", "enumMember")
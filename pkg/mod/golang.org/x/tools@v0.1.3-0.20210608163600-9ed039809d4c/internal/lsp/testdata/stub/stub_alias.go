@@ -0,0 +1,11 @@
+package stub
+
+import "bytes"
+
+type bufferer interface {
+	Buffer() *bytes.Buffer
+}
+
+func _() {
+	var _ bufferer = buffered{} //@suggestedfix("buffered{}", "quickfix")
+}
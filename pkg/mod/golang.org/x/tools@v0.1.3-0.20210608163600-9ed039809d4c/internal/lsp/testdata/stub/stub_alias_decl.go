@@ -0,0 +1,7 @@
+package stub
+
+import bytes "strings"
+
+type buffered struct{}
+
+var _ = bytes.NewReader
@@ -0,0 +1,12 @@
+package stub
+
+import "io"
+
+type writer struct{}
+
+func (w writer) String() string { return "writer" }
+
+func _() {
+	var w io.Writer = writer{} //@suggestedfix("writer{}", "quickfix")
+	_ = w
+}
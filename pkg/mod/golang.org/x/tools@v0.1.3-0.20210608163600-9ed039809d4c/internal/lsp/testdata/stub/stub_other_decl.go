@@ -0,0 +1,3 @@
+package stub
+
+type other struct{}
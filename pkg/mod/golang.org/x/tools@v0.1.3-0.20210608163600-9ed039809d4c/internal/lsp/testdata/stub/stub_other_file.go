@@ -0,0 +1,13 @@
+package stub
+
+import "context"
+
+type reader interface {
+	Read(ctx context.Context, p []byte) (n int, err error)
+}
+
+func read(r reader) {}
+
+func _() {
+	read(new(other)) //@suggestedfix("new", "quickfix")
+}
@@ -0,0 +1,11 @@
+package stub
+
+import (
+	"sort"
+)
+
+type byName []string
+
+func names() sort.Interface {
+	return &byName{} //@suggestedfix("&", "quickfix")
+}
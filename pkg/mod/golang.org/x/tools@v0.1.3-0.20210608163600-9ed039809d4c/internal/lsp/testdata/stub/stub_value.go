@@ -0,0 +1,15 @@
+package stub
+
+import "fmt"
+
+type base struct{}
+
+func (*base) String() string { return "" }
+
+// valueStringer is used as a value, which lacks the String method that
+// base declares with a pointer receiver.
+type valueStringer struct{ base }
+
+func _() {
+	var _ fmt.Stringer = valueStringer{} //@suggestedfix("valueStringer{}", "quickfix")
+}
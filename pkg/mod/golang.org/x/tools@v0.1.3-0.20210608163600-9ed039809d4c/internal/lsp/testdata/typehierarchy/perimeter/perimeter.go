@@ -0,0 +1,6 @@
+package perimeter
+
+// Perimeter is implemented by types in another package.
+type Perimeter interface { //@mark(thPerimeter, "Perimeter"),subtypes(thPerimeter, thSquare, thCube)
+	Perimeter() float64
+}
@@ -0,0 +1,36 @@
+package typehierarchy
+
+import "golang.org/x/tools/internal/lsp/typehierarchy/perimeter"
+
+type Shape interface { //@mark(thShape, "Shape"),subtypes(thShape, thSolid, thSquare, thCube, thCircle)
+	Area() float64
+}
+
+type Solid interface { //@mark(thSolid, "Solid"),supertypes(thSolid, thShape),subtypes(thSolid, thCube)
+	Shape
+	Volume() float64
+}
+
+// Any is empty, so it is never reported as a supertype.
+type Any interface{}
+
+type Square struct { //@mark(thSquare, "Square"),supertypes(thSquare, thShape, thPerimeter),subtypes(thSquare, thCube)
+	side float64
+}
+
+func (s Square) Area() float64      { return s.side * s.side }
+func (s Square) Perimeter() float64 { return 4 * s.side }
+
+type Cube struct { //@mark(thCube, "Cube"),supertypes(thCube, thSquare, thShape, thSolid, thPerimeter)
+	Square
+}
+
+func (c Cube) Volume() float64 { return c.Area() * c.side }
+
+type Circle struct { //@mark(thCircle, "Circle"),supertypes(thCircle, thShape)
+	radius float64
+}
+
+func (c *Circle) Area() float64 { return 3 * c.radius * c.radius }
+
+var _ perimeter.Perimeter = Square{}
@@ -47,6 +47,7 @@ var UpdateGolden = flag.Bool("golden", false, "Update golden files")
 
 type CallHierarchy map[span.Span]*CallHierarchyResult
 type CodeLens map[span.URI][]protocol.CodeLens
+type TypeHierarchy map[span.Span]*TypeHierarchyResult
 type Diagnostics map[span.URI][]*source.Diagnostic
 type CompletionItems map[token.Pos]*completion.CompletionItem
 type Completions map[span.Span][]Completion
@@ -60,8 +61,12 @@ type FoldingRanges []span.Span
 type Formats []span.Span
 type Imports []span.Span
 type SemanticTokens []span.Span
+type InlayHints []span.Span
 type SuggestedFixes map[span.Span][]string
 type FunctionExtractions map[span.Span]span.Span
+type MethodExtractions map[span.Span]span.Span
+type InlineCalls map[span.Span]span.Span
+type ChangeSignatures map[span.Span]string
 type Definitions map[span.Span]Definition
 type Implementations map[span.Span][]span.Span
 type Highlights map[span.Span][]span.Span
@@ -80,6 +85,7 @@ type Data struct {
 	Config                   packages.Config
 	Exported                 *packagestest.Exported
 	CallHierarchy            CallHierarchy
+	TypeHierarchy            TypeHierarchy
 	CodeLens                 CodeLens
 	Diagnostics              Diagnostics
 	CompletionItems          CompletionItems
@@ -94,8 +100,12 @@ type Data struct {
 	Formats                  Formats
 	Imports                  Imports
 	SemanticTokens           SemanticTokens
+	InlayHints               InlayHints
 	SuggestedFixes           SuggestedFixes
 	FunctionExtractions      FunctionExtractions
+	MethodExtractions        MethodExtractions
+	InlineCalls              InlineCalls
+	ChangeSignatures         ChangeSignatures
 	Definitions              Definitions
 	Implementations          Implementations
 	Highlights               Highlights
@@ -124,6 +134,7 @@ type Data struct {
 
 type Tests interface {
 	CallHierarchy(*testing.T, span.Span, *CallHierarchyResult)
+	TypeHierarchy(*testing.T, span.Span, *TypeHierarchyResult)
 	CodeLens(*testing.T, span.URI, []protocol.CodeLens)
 	Diagnostics(*testing.T, span.URI, []*source.Diagnostic)
 	Completion(*testing.T, span.Span, Completion, CompletionItems)
@@ -137,8 +148,12 @@ type Tests interface {
 	Format(*testing.T, span.Span)
 	Import(*testing.T, span.Span)
 	SemanticTokens(*testing.T, span.Span)
+	InlayHints(*testing.T, span.Span)
 	SuggestedFix(*testing.T, span.Span, []string, int)
 	FunctionExtraction(*testing.T, span.Span, span.Span)
+	MethodExtraction(*testing.T, span.Span, span.Span)
+	InlineCall(*testing.T, span.Span, span.Span)
+	ChangeSignature(*testing.T, span.Span, string)
 	Definition(*testing.T, span.Span, Definition)
 	Implementation(*testing.T, span.Span, []span.Span)
 	Highlight(*testing.T, span.Span, []span.Span)
@@ -156,7 +171,10 @@ type Definition struct {
 	Name      string
 	IsType    bool
 	OnlyHover bool
-	Src, Def  span.Span
+	// FullDocumentation reports whether the hover is computed with the
+	// FullDocumentation hover kind, instead of SynopsisDocumentation.
+	FullDocumentation bool
+	Src, Def          span.Span
 }
 
 type CompletionTestType int
@@ -208,6 +226,10 @@ type CallHierarchyResult struct {
 	IncomingCalls, OutgoingCalls []protocol.CallHierarchyItem
 }
 
+type TypeHierarchyResult struct {
+	Supertypes, Subtypes []protocol.TypeHierarchyItem
+}
+
 type Link struct {
 	Src          span.Span
 	Target       string
@@ -231,6 +253,7 @@ func DefaultOptions(o *source.Options) {
 			protocol.QuickFix:              true,
 			protocol.RefactorRewrite:       true,
 			protocol.RefactorExtract:       true,
+			protocol.RefactorInline:        true,
 			protocol.SourceFixAll:          true,
 		},
 		source.Mod: {
@@ -246,6 +269,10 @@ func DefaultOptions(o *source.Options) {
 	o.HierarchicalDocumentSymbolSupport = true
 	o.ExperimentalWorkspaceModule = true
 	o.SemanticTokens = true
+	o.Hints = map[string]bool{}
+	for name := range source.AllInlayHints() {
+		o.Hints[name] = true
+	}
 }
 
 func RunTests(t *testing.T, dataDir string, includeMultiModule bool, f func(*testing.T, *Data)) {
@@ -272,6 +299,7 @@ func load(t testing.TB, mode string, dir string) *Data {
 
 	datum := &Data{
 		CallHierarchy:            make(CallHierarchy),
+		TypeHierarchy:            make(TypeHierarchy),
 		CodeLens:                 make(CodeLens),
 		Diagnostics:              make(Diagnostics),
 		CompletionItems:          make(CompletionItems),
@@ -290,6 +318,9 @@ func load(t testing.TB, mode string, dir string) *Data {
 		PrepareRenames:           make(PrepareRenames),
 		SuggestedFixes:           make(SuggestedFixes),
 		FunctionExtractions:      make(FunctionExtractions),
+		MethodExtractions:        make(MethodExtractions),
+		InlineCalls:              make(InlineCalls),
+		ChangeSignatures:         make(ChangeSignatures),
 		Symbols:                  make(Symbols),
 		symbolsChildren:          make(SymbolsChildren),
 		symbolInformation:        make(SymbolInformation),
@@ -436,10 +467,12 @@ func load(t testing.TB, mode string, dir string) *Data {
 		"format":          datum.collectFormats,
 		"import":          datum.collectImports,
 		"semantic":        datum.collectSemanticTokens,
+		"inlayhint":       datum.collectInlayHints,
 		"godef":           datum.collectDefinitions,
 		"implementations": datum.collectImplementations,
 		"typdef":          datum.collectTypeDefinitions,
 		"hover":           datum.collectHoverDefinitions,
+		"fulldochover":    datum.collectFullDocHoverDefinitions,
 		"highlight":       datum.collectHighlights,
 		"refs":            datum.collectReferences,
 		"rename":          datum.collectRenames,
@@ -449,8 +482,13 @@ func load(t testing.TB, mode string, dir string) *Data {
 		"link":            datum.collectLinks,
 		"suggestedfix":    datum.collectSuggestedFixes,
 		"extractfunc":     datum.collectFunctionExtractions,
+		"extractmethod":   datum.collectMethodExtractions,
+		"inline":          datum.collectInlineCalls,
+		"changesignature": datum.collectChangeSignatures,
 		"incomingcalls":   datum.collectIncomingCalls,
 		"outgoingcalls":   datum.collectOutgoingCalls,
+		"supertypes":      datum.collectSupertypes,
+		"subtypes":        datum.collectSubtypes,
 		"addimport":       datum.collectAddImports,
 	}); err != nil {
 		t.Fatal(err)
@@ -465,6 +503,7 @@ func load(t testing.TB, mode string, dir string) *Data {
 	if err := datum.Exported.Expect(map[string]interface{}{
 		"godef":                        datum.collectDefinitionNames,
 		"hover":                        datum.collectDefinitionNames,
+		"fulldochover":                 datum.collectDefinitionNames,
 		"workspacesymbol":              datum.collectWorkspaceSymbols(WorkspaceSymbolsDefault),
 		"workspacesymbolfuzzy":         datum.collectWorkspaceSymbols(WorkspaceSymbolsFuzzy),
 		"workspacesymbolcasesensitive": datum.collectWorkspaceSymbols(WorkspaceSymbolsCaseSensitive),
@@ -514,6 +553,16 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("TypeHierarchy", func(t *testing.T) {
+		t.Helper()
+		for spn, typeHierarchyResult := range data.TypeHierarchy {
+			t.Run(SpanName(spn), func(t *testing.T) {
+				t.Helper()
+				tests.TypeHierarchy(t, spn, typeHierarchyResult)
+			})
+		}
+	})
+
 	t.Run("Completion", func(t *testing.T) {
 		t.Helper()
 		eachCompletion(t, data.Completions, tests.Completion)
@@ -631,6 +680,16 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("InlayHints", func(t *testing.T) {
+		t.Helper()
+		for _, spn := range data.InlayHints {
+			t.Run(uriName(spn.URI()), func(t *testing.T) {
+				t.Helper()
+				tests.InlayHints(t, spn)
+			})
+		}
+	})
+
 	t.Run("SuggestedFix", func(t *testing.T) {
 		t.Helper()
 		for spn, actionKinds := range data.SuggestedFixes {
@@ -659,6 +718,40 @@ func Run(t *testing.T, tests Tests, data *Data) {
 		}
 	})
 
+	t.Run("MethodExtraction", func(t *testing.T) {
+		t.Helper()
+		for start, end := range data.MethodExtractions {
+			// Check if we should skip this spn if the -modfile flag is not available.
+			if shouldSkip(data, start.URI()) {
+				continue
+			}
+			t.Run(SpanName(start), func(t *testing.T) {
+				t.Helper()
+				tests.MethodExtraction(t, start, end)
+			})
+		}
+	})
+
+	t.Run("InlineCall", func(t *testing.T) {
+		t.Helper()
+		for start, end := range data.InlineCalls {
+			t.Run(SpanName(start), func(t *testing.T) {
+				t.Helper()
+				tests.InlineCall(t, start, end)
+			})
+		}
+	})
+
+	t.Run("ChangeSignature", func(t *testing.T) {
+		t.Helper()
+		for spn, title := range data.ChangeSignatures {
+			t.Run(SpanName(spn), func(t *testing.T) {
+				t.Helper()
+				tests.ChangeSignature(t, spn, title)
+			})
+		}
+	})
+
 	t.Run("Definition", func(t *testing.T) {
 		t.Helper()
 		for spn, d := range data.Definitions {
@@ -864,6 +957,7 @@ func checkData(t *testing.T, data *Data) {
 	}
 
 	fmt.Fprintf(buf, "CallHierarchyCount = %v\n", len(data.CallHierarchy))
+	fmt.Fprintf(buf, "TypeHierarchyCount = %v\n", len(data.TypeHierarchy))
 	fmt.Fprintf(buf, "CodeLensCount = %v\n", countCodeLens(data.CodeLens))
 	fmt.Fprintf(buf, "CompletionsCount = %v\n", countCompletions(data.Completions))
 	fmt.Fprintf(buf, "CompletionSnippetCount = %v\n", snippetCount)
@@ -877,8 +971,12 @@ func checkData(t *testing.T, data *Data) {
 	fmt.Fprintf(buf, "FormatCount = %v\n", len(data.Formats))
 	fmt.Fprintf(buf, "ImportCount = %v\n", len(data.Imports))
 	fmt.Fprintf(buf, "SemanticTokenCount = %v\n", len(data.SemanticTokens))
+	fmt.Fprintf(buf, "InlayHintsCount = %v\n", len(data.InlayHints))
 	fmt.Fprintf(buf, "SuggestedFixCount = %v\n", len(data.SuggestedFixes))
 	fmt.Fprintf(buf, "FunctionExtractionCount = %v\n", len(data.FunctionExtractions))
+	fmt.Fprintf(buf, "MethodExtractionCount = %v\n", len(data.MethodExtractions))
+	fmt.Fprintf(buf, "InlineCallCount = %v\n", len(data.InlineCalls))
+	fmt.Fprintf(buf, "ChangeSignatureCount = %v\n", len(data.ChangeSignatures))
 	fmt.Fprintf(buf, "DefinitionsCount = %v\n", definitionCount)
 	fmt.Fprintf(buf, "TypeDefinitionsCount = %v\n", typeDefinitionCount)
 	fmt.Fprintf(buf, "HighlightsCount = %v\n", len(data.Highlights))
@@ -1099,6 +1197,10 @@ func (data *Data) collectSemanticTokens(spn span.Span) {
 	data.SemanticTokens = append(data.SemanticTokens, spn)
 }
 
+func (data *Data) collectInlayHints(spn span.Span) {
+	data.InlayHints = append(data.InlayHints, spn)
+}
+
 func (data *Data) collectSuggestedFixes(spn span.Span, actionKind string) {
 	if _, ok := data.SuggestedFixes[spn]; !ok {
 		data.SuggestedFixes[spn] = []string{}
@@ -1112,6 +1214,22 @@ func (data *Data) collectFunctionExtractions(start span.Span, end span.Span) {
 	}
 }
 
+func (data *Data) collectMethodExtractions(start span.Span, end span.Span) {
+	if _, ok := data.MethodExtractions[start]; !ok {
+		data.MethodExtractions[start] = end
+	}
+}
+
+func (data *Data) collectInlineCalls(start span.Span, end span.Span) {
+	if _, ok := data.InlineCalls[start]; !ok {
+		data.InlineCalls[start] = end
+	}
+}
+
+func (data *Data) collectChangeSignatures(spn span.Span, title string) {
+	data.ChangeSignatures[spn] = title
+}
+
 func (data *Data) collectDefinitions(src, target span.Span) {
 	data.Definitions[src] = Definition{
 		Src: src,
@@ -1172,6 +1290,40 @@ func (data *Data) collectOutgoingCalls(src span.Span, calls []span.Span) {
 	}
 }
 
+func (data *Data) collectSupertypes(src span.Span, types []span.Span) {
+	if data.TypeHierarchy[src] == nil {
+		data.TypeHierarchy[src] = &TypeHierarchyResult{}
+	}
+	data.TypeHierarchy[src].Supertypes = append(data.TypeHierarchy[src].Supertypes, data.typeHierarchyItems(types)...)
+}
+
+func (data *Data) collectSubtypes(src span.Span, types []span.Span) {
+	if data.TypeHierarchy[src] == nil {
+		data.TypeHierarchy[src] = &TypeHierarchyResult{}
+	}
+	data.TypeHierarchy[src].Subtypes = append(data.TypeHierarchy[src].Subtypes, data.typeHierarchyItems(types)...)
+}
+
+func (data *Data) typeHierarchyItems(types []span.Span) []protocol.TypeHierarchyItem {
+	var items []protocol.TypeHierarchyItem
+	for _, typ := range types {
+		m, err := data.Mapper(typ.URI())
+		if err != nil {
+			data.t.Fatal(err)
+		}
+		rng, err := m.Range(typ)
+		if err != nil {
+			data.t.Fatal(err)
+		}
+		// we're only comparing protocol.range
+		items = append(items, protocol.TypeHierarchyItem{
+			URI:   protocol.DocumentURI(typ.URI()),
+			Range: rng,
+		})
+	}
+	return items
+}
+
 func (data *Data) collectHoverDefinitions(src, target span.Span) {
 	data.Definitions[src] = Definition{
 		Src:       src,
@@ -1180,6 +1332,15 @@ func (data *Data) collectHoverDefinitions(src, target span.Span) {
 	}
 }
 
+func (data *Data) collectFullDocHoverDefinitions(src, target span.Span) {
+	data.Definitions[src] = Definition{
+		Src:               src,
+		Def:               target,
+		OnlyHover:         true,
+		FullDocumentation: true,
+	}
+}
+
 func (data *Data) collectTypeDefinitions(src, target span.Span) {
 	data.Definitions[src] = Definition{
 		Src:    src,
@@ -293,6 +293,27 @@ func DiffCallHierarchyItems(gotCalls []protocol.CallHierarchyItem, expectedCalls
 	return ""
 }
 
+func DiffTypeHierarchyItems(gotTypes []protocol.TypeHierarchyItem, expectedTypes []protocol.TypeHierarchyItem) string {
+	expected := make(map[protocol.Location]bool)
+	for _, typ := range expectedTypes {
+		expected[protocol.Location{URI: typ.URI, Range: typ.Range}] = true
+	}
+
+	got := make(map[protocol.Location]bool)
+	for _, typ := range gotTypes {
+		got[protocol.Location{URI: typ.URI, Range: typ.Range}] = true
+	}
+	if len(got) != len(expected) {
+		return fmt.Sprintf("expected %d types but got %d", len(expected), len(got))
+	}
+	for loc := range got {
+		if !expected[loc] {
+			return fmt.Sprintf("incorrect types, expected locations %v but got locations %v", expected, got)
+		}
+	}
+	return ""
+}
+
 func ToProtocolCompletionItems(items []completion.CompletionItem) []protocol.CompletionItem {
 	var result []protocol.CompletionItem
 	for _, item := range items {
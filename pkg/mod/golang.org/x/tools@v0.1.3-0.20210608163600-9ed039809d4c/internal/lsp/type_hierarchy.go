@@ -0,0 +1,50 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"golang.org/x/tools/internal/lsp/protocol"
+	"golang.org/x/tools/internal/lsp/source"
+)
+
+func typeHierarchyRegistration() protocol.Registration {
+	return protocol.Registration{
+		ID:              "textDocument/prepareTypeHierarchy",
+		Method:          "textDocument/prepareTypeHierarchy",
+		RegisterOptions: &protocol.TypeHierarchyOptions{},
+	}
+}
+
+func (s *Server) PrepareTypeHierarchy(ctx context.Context, params *protocol.TypeHierarchyPrepareParams) ([]protocol.TypeHierarchyItem, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.TextDocument.URI, source.Go)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+
+	return source.PrepareTypeHierarchy(ctx, snapshot, fh, params.Position)
+}
+
+func (s *Server) Supertypes(ctx context.Context, params *protocol.TypeHierarchySupertypesParams) ([]protocol.TypeHierarchyItem, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.Item.URI, source.Go)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+
+	return source.Supertypes(ctx, snapshot, fh, params.Item.SelectionRange.Start)
+}
+
+func (s *Server) Subtypes(ctx context.Context, params *protocol.TypeHierarchySubtypesParams) ([]protocol.TypeHierarchyItem, error) {
+	snapshot, fh, ok, release, err := s.beginFileRequest(ctx, params.Item.URI, source.Go)
+	defer release()
+	if !ok {
+		return nil, err
+	}
+
+	return source.Subtypes(ctx, snapshot, fh, params.Item.SelectionRange.Start)
+}
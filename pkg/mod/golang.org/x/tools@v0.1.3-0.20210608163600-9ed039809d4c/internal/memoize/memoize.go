@@ -12,9 +12,11 @@ package memoize
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"sync/atomic"
 
@@ -37,6 +39,124 @@ type Store struct {
 
 	// generations is the set of generations live in this store.
 	generations map[*Generation]struct{}
+
+	// budget is the number of bytes that the values of sized handles may
+	// use before some are evicted, or 0 for no limit.
+	budget int64
+	// size is the sum of the sizes of the completed sized handles. Atomic.
+	size int64
+	// evictions is the number of values evicted to honor the budget. Atomic.
+	evictions int64
+	// clock is incremented on each access to a sized handle. Atomic.
+	clock uint64
+	// evicting is 1 while the store is evicting values. Atomic.
+	evicting uint32
+}
+
+// A Sizer is a value that reports an estimate of the number of bytes that
+// it uses. The values of a Store that are Sizers may be evicted from the
+// store when its budget is exceeded, and computed again on demand.
+type Sizer interface {
+	Size() int64
+}
+
+// SetBudget sets the number of bytes that the values of s that are Sizers
+// may use. When their sizes exceed the budget, the least recently used
+// values that are not in use are evicted until they use less than nine
+// tenths of the budget. A value is in use if another value depends on it,
+// as recorded by DependsOn, or if one of its generations is acquired, as an
+// operation in progress may then hold the value and expect to read it again.
+// A budget of 0, the default, means no limit.
+func (s *Store) SetBudget(bytes int64) {
+	s.mu.Lock()
+	s.budget = bytes
+	s.mu.Unlock()
+	s.maybeEvict()
+}
+
+// MemStats describes the memory used by the sized values of a Store.
+type MemStats struct {
+	Budget    int64 // the budget of the store, or 0
+	Size      int64 // the sum of the sizes of the values
+	Evictions int64 // the number of values evicted so far
+}
+
+// MemStats returns the memory statistics of the sized values of s.
+func (s *Store) MemStats() MemStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return MemStats{
+		Budget:    s.budget,
+		Size:      atomic.LoadInt64(&s.size),
+		Evictions: atomic.LoadInt64(&s.evictions),
+	}
+}
+
+// maybeEvict evicts the least recently used values of s that no other value
+// depends on, if the sized values of s exceed its budget.
+func (s *Store) maybeEvict() {
+	s.mu.Lock()
+	budget := s.budget
+	s.mu.Unlock()
+	if budget <= 0 || atomic.LoadInt64(&s.size) <= budget {
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&s.evicting, 0, 1) {
+		return // another goroutine is evicting
+	}
+	defer atomic.StoreUint32(&s.evicting, 0)
+
+	target := budget / 10 * 9
+	for atomic.LoadInt64(&s.size) > target {
+		leaves := s.leaves()
+		if len(leaves) == 0 {
+			return
+		}
+		sort.Slice(leaves, func(i, j int) bool {
+			return atomic.LoadUint64(&leaves[i].lastUsed) < atomic.LoadUint64(&leaves[j].lastUsed)
+		})
+		for _, h := range leaves {
+			if atomic.LoadInt64(&s.size) <= target {
+				return
+			}
+			h.evict()
+		}
+	}
+}
+
+// leaves returns the completed sized handles of s that are not in use: no
+// other completed or running handle depends on them, and none of their
+// generations is acquired.
+func (s *Store) leaves() []*Handle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	needed := make(map[*Handle]bool)
+	var completed []*Handle
+	for _, h := range s.handles {
+		h.mu.Lock()
+		switch h.state {
+		case stateRunning:
+			// A running function may still read its dependencies.
+			for _, dep := range h.deps {
+				needed[dep] = true
+			}
+		case stateCompleted:
+			for _, dep := range h.deps {
+				needed[dep] = true
+			}
+			if h.sized && !h.acquired() {
+				completed = append(completed, h)
+			}
+		}
+		h.mu.Unlock()
+	}
+	var leaves []*Handle
+	for _, h := range completed {
+		if !needed[h] {
+			leaves = append(leaves, h)
+		}
+	}
+	return leaves
 }
 
 // Generation creates a new Generation associated with s. Destroy must be
@@ -64,6 +184,9 @@ type Generation struct {
 	name      string
 	// wg tracks the reference count of this generation.
 	wg sync.WaitGroup
+	// active is the number of outstanding references to this generation.
+	// Atomic.
+	active int32
 }
 
 // Destroy waits for all operations referencing g to complete, then removes
@@ -81,6 +204,9 @@ func (g *Generation) Destroy() {
 			delete(e.generations, g) // delete even if it's dead, in case of dangling references to the entry.
 			if len(e.generations) == 0 {
 				delete(g.store.handles, k)
+				if e.state == stateCompleted && e.sized {
+					atomic.AddInt64(&g.store.size, -e.size)
+				}
 				e.state = stateDestroyed
 				if e.cleanup != nil && e.value != nil {
 					e.cleanup(e.value)
@@ -103,7 +229,14 @@ func (g *Generation) Acquire(ctx context.Context) func() {
 		panic("acquire on destroyed generation " + g.name)
 	}
 	g.wg.Add(1)
-	return g.wg.Done
+	atomic.AddInt32(&g.active, 1)
+	return func() {
+		if atomic.AddInt32(&g.active, -1) == 0 {
+			// The values of g may have been in use until now.
+			defer g.store.maybeEvict()
+		}
+		g.wg.Done()
+	}
 }
 
 // Arg is a marker interface that can be embedded to indicate a type is
@@ -135,9 +268,14 @@ const (
 // they decrement waiters. If it drops to zero, the inner context is cancelled,
 // computation is abandoned, and state resets to idle to start the process over
 // again.
+//
+// A completed handle whose value is a Sizer may be evicted by its store to
+// honor its budget. Its state then resets to idle, and the value is computed
+// again by the next Get.
 type Handle struct {
-	key interface{}
-	mu  sync.Mutex
+	key   interface{}
+	store *Store
+	mu    sync.Mutex
 
 	// generations is the set of generations in which this handle is valid.
 	generations map[*Generation]struct{}
@@ -156,6 +294,17 @@ type Handle struct {
 	// cleanup, if non-nil, is used to perform any necessary clean-up on values
 	// produced by function.
 	cleanup func(interface{})
+
+	// sized reports whether value is a Sizer, whose size is size. The
+	// function of a sized handle is kept to compute its value again after
+	// it is evicted.
+	sized bool
+	size  int64
+	// lastUsed is the value of the store clock when h was last accessed.
+	// Atomic.
+	lastUsed uint64
+	// deps are the handles whose values the value of h depends on.
+	deps []*Handle
 }
 
 // Bind returns a handle for the given key and function.
@@ -183,6 +332,7 @@ func (g *Generation) Bind(key interface{}, function Function, cleanup func(inter
 	if !ok {
 		h := &Handle{
 			key:         key,
+			store:       g.store,
 			function:    function,
 			generations: map[*Generation]struct{}{g: {}},
 			cleanup:     cleanup,
@@ -198,6 +348,64 @@ func (g *Generation) Bind(key interface{}, function Function, cleanup func(inter
 	return h
 }
 
+// DependsOn records that the value of h depends on the values of deps, so
+// that they are not evicted from the store before the value of h. Values
+// that depend on each other must be evicted in this order, as a value
+// computed again is not identical to the evicted one.
+func (h *Handle) DependsOn(deps ...*Handle) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.deps) == 0 {
+		h.deps = deps
+	}
+}
+
+// Size returns the size of the value of h if it is a completed Sizer, or 0.
+// Unlike Cached, it does not count as an access to h.
+func (h *Handle) Size() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state != stateCompleted || !h.sized {
+		return 0
+	}
+	return h.size
+}
+
+// acquired reports whether a generation of h is acquired. h.mu must be
+// locked.
+func (h *Handle) acquired() bool {
+	for g := range h.generations {
+		if atomic.LoadInt32(&g.active) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// touch records an access to h.
+func (h *Handle) touch() {
+	atomic.StoreUint64(&h.lastUsed, atomic.AddUint64(&h.store.clock, 1))
+}
+
+// evict resets h to idle state if it is a completed sized handle, so that
+// its value is computed again when it is next requested.
+func (h *Handle) evict() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state != stateCompleted || !h.sized || h.waiters > 0 || h.acquired() {
+		return // the value may be in use
+	}
+	if h.cleanup != nil && h.value != nil {
+		h.cleanup(h.value)
+	}
+	atomic.AddInt64(&h.store.size, -h.size)
+	atomic.AddInt64(&h.store.evictions, 1)
+	h.value = nil
+	h.sized = false
+	h.size = 0
+	h.state = stateIdle
+}
+
 // Stats returns the number of each type of value in the store.
 func (s *Store) Stats() map[reflect.Type]int {
 	s.mu.Lock()
@@ -256,6 +464,7 @@ func (h *Handle) Cached(g *Generation) interface{} {
 		return nil
 	}
 	if h.state == stateCompleted {
+		h.touch()
 		return h.value
 	}
 	return nil
@@ -269,6 +478,19 @@ func (h *Handle) Get(ctx context.Context, g *Generation, arg Arg) (interface{},
 	release := g.Acquire(ctx)
 	defer release()
 
+	for {
+		v, err := h.get(ctx, g, arg)
+		if err != errEvicted {
+			return v, err
+		}
+	}
+}
+
+// errEvicted is returned by wait when the value was evicted before it could
+// be read, so that Get computes it again.
+var errEvicted = errors.New("evicted")
+
+func (h *Handle) get(ctx context.Context, g *Generation, arg Arg) (interface{}, error) {
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
@@ -289,6 +511,7 @@ func (h *Handle) Get(ctx context.Context, g *Generation, arg Arg) (interface{},
 		return h.wait(ctx)
 	case stateCompleted:
 		defer h.mu.Unlock()
+		h.touch()
 		return h.value, nil
 	case stateDestroyed:
 		h.mu.Unlock()
@@ -330,12 +553,12 @@ func (h *Handle) run(ctx context.Context, g *Generation, arg Arg) (interface{},
 		}
 
 		h.mu.Lock()
-		defer h.mu.Unlock()
 		// It's theoretically possible that the handle has been cancelled out
 		// of the run that started us, and then started running again since we
 		// checked childCtx above. Even so, that should be harmless, since each
 		// run should produce the same results.
 		if h.state != stateRunning {
+			h.mu.Unlock()
 			// v will never be used, so ensure that it is cleaned up.
 			if h.cleanup != nil && v != nil {
 				h.cleanup(v)
@@ -344,9 +567,19 @@ func (h *Handle) run(ctx context.Context, g *Generation, arg Arg) (interface{},
 		}
 		// At this point v will be cleaned up whenever h is destroyed.
 		h.value = v
-		h.function = nil
+		if sizer, ok := v.(Sizer); ok {
+			h.sized = true
+			h.size = sizer.Size()
+			atomic.AddInt64(&h.store.size, h.size)
+			h.touch()
+		} else {
+			h.function = nil
+		}
 		h.state = stateCompleted
 		close(h.done)
+		h.mu.Unlock()
+
+		h.store.maybeEvict()
 	}()
 
 	return h.wait(ctx)
@@ -362,9 +595,13 @@ func (h *Handle) wait(ctx context.Context) (interface{}, error) {
 	case <-done:
 		h.mu.Lock()
 		defer h.mu.Unlock()
+		h.waiters--
 		if h.state == stateCompleted {
 			return h.value, nil
 		}
+		if ctx.Err() == nil && h.state != stateDestroyed {
+			return nil, errEvicted
+		}
 		return nil, nil
 	case <-ctx.Done():
 		h.mu.Lock()
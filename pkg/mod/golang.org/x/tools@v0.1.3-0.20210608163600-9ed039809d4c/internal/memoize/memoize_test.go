@@ -105,3 +105,53 @@ func TestCleanup(t *testing.T) {
 		t.Error("after destroying g2, v2 is not cleaned up")
 	}
 }
+
+type sized struct {
+	name string
+	size int64
+}
+
+func (s *sized) Size() int64 { return s.size }
+
+func TestEviction(t *testing.T) {
+	s := &memoize.Store{}
+	g := s.Generation("g")
+	evaled := map[string]int{}
+	bind := func(name string) *memoize.Handle {
+		return g.Bind(name, func(context.Context, memoize.Arg) interface{} {
+			evaled[name]++
+			return &sized{name, 100}
+		}, nil)
+	}
+	dep, top, other := bind("dep"), bind("top"), bind("other")
+	top.DependsOn(dep)
+	for _, h := range []*memoize.Handle{dep, top, other, top} {
+		if _, err := h.Get(context.Background(), g, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := s.MemStats(), (memoize.MemStats{Size: 300}); got != want {
+		t.Fatalf("before budget: MemStats() = %+v, want %+v", got, want)
+	}
+
+	// The least recently used value that no other depends on is evicted,
+	// although dep was used before it.
+	s.SetBudget(250)
+	if got, want := s.MemStats(), (memoize.MemStats{Budget: 250, Size: 200, Evictions: 1}); got != want {
+		t.Errorf("after budget: MemStats() = %+v, want %+v", got, want)
+	}
+	for h, cached := range map[*memoize.Handle]bool{dep: true, top: true, other: false} {
+		if got := h.Cached(g) != nil; got != cached {
+			t.Errorf("%v cached: %v, want %v", h, got, cached)
+		}
+	}
+
+	// An evicted value is computed again on demand.
+	v, err := other.Get(context.Background(), g, nil)
+	if err != nil || v.(*sized).name != "other" {
+		t.Fatalf("Get() after eviction = %v, %v", v, err)
+	}
+	if evaled["other"] != 2 || evaled["top"] != 1 || evaled["dep"] != 1 {
+		t.Errorf("evaluations = %v, want other evaluated twice", evaled)
+	}
+}